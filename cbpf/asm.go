@@ -0,0 +1,473 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbpf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A SyntaxError reports an error in the source of a program passed to
+// Assemble.
+type SyntaxError struct {
+	Line int // 1-based line number
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("cbpf: line %d: %s", e.Line, e.Msg)
+}
+
+// A jump target which is resolved once all labels are known.
+type target struct {
+	label string
+	off   uint32 // relative offset if label is empty
+}
+
+type asmInsn struct {
+	Instruction
+	line   int
+	jt, jf *target // conditional jump targets
+	ja     *target // unconditional jump target
+}
+
+// Assemble translates the program src, written in the syntax of the Linux
+// bpf_asm tool, into instructions.
+//
+// Each line holds an optional "label:" and an optional instruction, and a
+// ';' starts a comment which extends to the end of the line. Jump targets
+// are either labels or offsets relative to the next instruction such as
+// "+2". A conditional jump with a single target falls through when its
+// condition is false, and the negated forms jneq (or jne), jlt and jle are
+// accepted. The directive ".word code, jt, jf, k" emits a raw instruction.
+//
+// Assemble does not verify the resulting program; see Verify.
+func Assemble(src string) ([]Instruction, error) {
+	labels := make(map[string]int)
+	var insns []asmInsn
+	for i, line := range strings.Split(src, "\n") {
+		lineno := i + 1
+		if c := strings.IndexByte(line, ';'); c >= 0 {
+			line = line[:c]
+		}
+		line = strings.TrimSpace(line)
+		for {
+			c := strings.IndexByte(line, ':')
+			if c < 0 || !isLabel(line[:c]) {
+				break
+			}
+			name := line[:c]
+			if _, ok := labels[name]; ok {
+				return nil, &SyntaxError{lineno, fmt.Sprintf("label %q redefined", name)}
+			}
+			labels[name] = len(insns)
+			line = strings.TrimSpace(line[c+1:])
+		}
+		if line == "" {
+			continue
+		}
+		insn, err := parseInsn(line)
+		if err != nil {
+			return nil, &SyntaxError{lineno, err.Error()}
+		}
+		insn.line = lineno
+		insns = append(insns, insn)
+	}
+
+	prog := make([]Instruction, len(insns))
+	for pc, insn := range insns {
+		resolve := func(t *target, max uint32) (uint32, error) {
+			if t.label == "" {
+				if t.off > max {
+					return 0, fmt.Errorf("jump offset %d out of range", t.off)
+				}
+				return t.off, nil
+			}
+			dst, ok := labels[t.label]
+			if !ok {
+				return 0, fmt.Errorf("undefined label %q", t.label)
+			}
+			if dst <= pc {
+				return 0, fmt.Errorf("backward jump to label %q", t.label)
+			}
+			off := uint32(dst - pc - 1)
+			if off > max {
+				return 0, fmt.Errorf("jump to label %q out of range", t.label)
+			}
+			return off, nil
+		}
+		var err error
+		var off uint32
+		if insn.ja != nil {
+			if off, err = resolve(insn.ja, ^uint32(0)); err == nil {
+				insn.K = off
+			}
+		}
+		if insn.jt != nil && err == nil {
+			if off, err = resolve(insn.jt, 0xff); err == nil {
+				insn.Jt = uint8(off)
+			}
+		}
+		if insn.jf != nil && err == nil {
+			if off, err = resolve(insn.jf, 0xff); err == nil {
+				insn.Jf = uint8(off)
+			}
+		}
+		if err != nil {
+			return nil, &SyntaxError{insn.line, err.Error()}
+		}
+		prog[pc] = insn.Instruction
+	}
+	return prog, nil
+}
+
+// Disassemble returns the program in the syntax accepted by Assemble, with
+// one instruction per line. Jump targets are labeled with the index of the
+// instruction they refer to, such as "L12".
+func Disassemble(prog []Instruction) string {
+	targets := make(map[int]bool)
+	for pc, ins := range prog {
+		if ins.Code&0x07 != classJmp {
+			continue
+		}
+		if ins.Code&0xf0 == jmpJa {
+			if uint64(pc)+1+uint64(ins.K) < uint64(len(prog)) {
+				targets[pc+1+int(ins.K)] = true
+			}
+			continue
+		}
+		for _, off := range []uint8{ins.Jt, ins.Jf} {
+			if pc+1+int(off) < len(prog) {
+				targets[pc+1+int(off)] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	for pc, ins := range prog {
+		if targets[pc] {
+			fmt.Fprintf(&b, "L%d:", pc)
+		}
+		b.WriteByte('\t')
+		b.WriteString(ins.format(func(off uint32) string {
+			dst := uint64(pc) + 1 + uint64(off)
+			if dst < uint64(len(prog)) {
+				return fmt.Sprintf("L%d", dst)
+			}
+			return fmt.Sprintf("+%d", off)
+		}))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func isLabel(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || c == '.' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseInsn parses a single instruction without label or comment.
+func parseInsn(s string) (asmInsn, error) {
+	mnemonic, rest := s, ""
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		mnemonic, rest = s[:i], strings.TrimSpace(s[i+1:])
+	}
+	var args []string
+	if rest != "" {
+		args = strings.Split(rest, ",")
+		for i := range args {
+			args[i] = strings.TrimSpace(args[i])
+		}
+	}
+	nargs := func(min, max int) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("wrong number of operands for %s", mnemonic)
+		}
+		return nil
+	}
+
+	var insn asmInsn
+	mnemonic = strings.ToLower(mnemonic)
+	switch mnemonic {
+	case "ld", "ldh", "ldb":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		size := map[string]uint16{"ld": sizeW, "ldh": sizeH, "ldb": sizeB}[mnemonic]
+		arg := args[0]
+		if strings.HasPrefix(arg, "[") && strings.HasSuffix(arg, "]") {
+			k, ind, err := parseIndex(arg[1 : len(arg)-1])
+			if err != nil {
+				return insn, err
+			}
+			mode := uint16(modeAbs)
+			if ind {
+				mode = modeInd
+			}
+			insn.Code = classLd | size | mode
+			insn.K = k
+			return insn, nil
+		}
+		if size != sizeW {
+			break
+		}
+		return parseLoad(insn, classLd, arg)
+	case "ldi":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		k, err := parseImm(args[0])
+		insn.Code = classLd | sizeW | modeImm
+		insn.K = k
+		return insn, err
+	case "ldx", "ldxb":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		arg := strings.ReplaceAll(args[0], " ", "")
+		if strings.HasPrefix(arg, "4*([") && strings.HasSuffix(arg, "]&0xf)") {
+			k, err := parseNum(arg[len("4*([") : len(arg)-len("]&0xf)")])
+			insn.Code = classLdx | sizeB | modeMsh
+			insn.K = k
+			return insn, err
+		}
+		if mnemonic == "ldxb" {
+			break
+		}
+		return parseLoad(insn, classLdx, arg)
+	case "ldxi":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		k, err := parseImm(args[0])
+		insn.Code = classLdx | sizeW | modeImm
+		insn.K = k
+		return insn, err
+	case "st", "stx":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		k, ok, err := parseMem(args[0])
+		if err != nil || !ok {
+			break
+		}
+		insn.Code = classSt
+		if mnemonic == "stx" {
+			insn.Code = classStx
+		}
+		insn.K = k
+		return insn, nil
+	case "add", "sub", "mul", "div", "mod", "and", "or", "xor", "lsh", "rsh":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		for op, name := range aluNames {
+			if name == mnemonic {
+				insn.Code = classAlu | op
+			}
+		}
+		if isX(args[0]) {
+			insn.Code |= srcX
+			return insn, nil
+		}
+		k, err := parseImm(args[0])
+		insn.K = k
+		return insn, err
+	case "neg":
+		if err := nargs(0, 0); err != nil {
+			return insn, err
+		}
+		insn.Code = classAlu | aluNeg
+		return insn, nil
+	case "ja", "jmp":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		t, err := parseTarget(args[0])
+		insn.Code = classJmp | jmpJa
+		insn.ja = t
+		return insn, err
+	case "jeq", "jneq", "jne", "jlt", "jle", "jgt", "jge", "jset":
+		if err := nargs(2, 3); err != nil {
+			return insn, err
+		}
+		op, negate := map[string]uint16{
+			"jeq": jmpJeq, "jneq": jmpJeq, "jne": jmpJeq, "jlt": jmpJge,
+			"jle": jmpJgt, "jgt": jmpJgt, "jge": jmpJge, "jset": jmpJset,
+		}[mnemonic], strings.HasPrefix(mnemonic, "jn") || strings.HasPrefix(mnemonic, "jl")
+		insn.Code = classJmp | op
+		if isX(args[0]) {
+			insn.Code |= srcX
+		} else {
+			k, err := parseImm(args[0])
+			if err != nil {
+				return insn, err
+			}
+			insn.K = k
+		}
+		jt, err := parseTarget(args[1])
+		if err != nil {
+			return insn, err
+		}
+		jf := &target{}
+		if len(args) == 3 {
+			if jf, err = parseTarget(args[2]); err != nil {
+				return insn, err
+			}
+		}
+		if negate {
+			jt, jf = jf, jt
+		}
+		insn.jt, insn.jf = jt, jf
+		return insn, nil
+	case "tax":
+		if err := nargs(0, 0); err != nil {
+			return insn, err
+		}
+		insn.Code = classMisc | miscTax
+		return insn, nil
+	case "txa":
+		if err := nargs(0, 0); err != nil {
+			return insn, err
+		}
+		insn.Code = classMisc | miscTxa
+		return insn, nil
+	case "ret":
+		if err := nargs(1, 1); err != nil {
+			return insn, err
+		}
+		insn.Code = classRet
+		if a := strings.TrimPrefix(strings.ToLower(args[0]), "%"); a == "a" {
+			insn.Code |= retA
+			return insn, nil
+		}
+		k, err := parseImm(args[0])
+		insn.K = k
+		return insn, err
+	case ".word":
+		if err := nargs(4, 4); err != nil {
+			return insn, err
+		}
+		var v [4]uint32
+		for i, arg := range args {
+			n, err := parseNum(arg)
+			if err != nil {
+				return insn, err
+			}
+			v[i] = n
+		}
+		if v[0] > 0xffff || v[1] > 0xff || v[2] > 0xff {
+			return insn, fmt.Errorf("operand out of range in %q", s)
+		}
+		insn.Instruction = Instruction{Code: uint16(v[0]), Jt: uint8(v[1]), Jf: uint8(v[2]), K: v[3]}
+		return insn, nil
+	default:
+		return insn, fmt.Errorf("unknown instruction %q", mnemonic)
+	}
+	return insn, fmt.Errorf("invalid operand for %s: %q", mnemonic, args[0])
+}
+
+// parseLoad parses the operand of a word-sized ld or ldx which is not a
+// packet load: an immediate, a scratch memory word, the packet length or,
+// for ld only, ancillary data.
+func parseLoad(insn asmInsn, class uint16, arg string) (asmInsn, error) {
+	if k, ok, err := parseMem(arg); ok {
+		insn.Code = class | sizeW | modeMem
+		insn.K = k
+		return insn, err
+	}
+	name := strings.ToLower(strings.TrimPrefix(arg, "#"))
+	if name == "len" || name == "pktlen" {
+		insn.Code = class | sizeW | modeLen
+		return insn, nil
+	}
+	if class == classLd {
+		for off, anc := range ancNames {
+			if name == anc {
+				insn.Code = classLd | sizeW | modeAbs
+				insn.K = AncillaryOff + off
+				return insn, nil
+			}
+		}
+	}
+	k, err := parseImm(arg)
+	insn.Code = class | sizeW | modeImm
+	insn.K = k
+	return insn, err
+}
+
+// parseMem parses a scratch memory operand "M[k]". It reports whether arg
+// has that form.
+func parseMem(arg string) (uint32, bool, error) {
+	if !strings.HasPrefix(arg, "M[") && !strings.HasPrefix(arg, "m[") || !strings.HasSuffix(arg, "]") {
+		return 0, false, nil
+	}
+	k, err := parseNum(strings.TrimSpace(arg[2 : len(arg)-1]))
+	return k, true, err
+}
+
+// parseIndex parses the contents of a packet load operand: "k", "x", "x + k"
+// or "x + -k". It reports whether the load is relative to the X register.
+func parseIndex(s string) (uint32, bool, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	switch {
+	case isX(s):
+		return 0, true, nil
+	case strings.HasPrefix(s, "x+") || strings.HasPrefix(s, "%x+"):
+		k, err := parseNum(s[strings.IndexByte(s, '+')+1:])
+		return k, true, err
+	}
+	k, err := parseNum(s)
+	return k, false, err
+}
+
+func isX(s string) bool {
+	s = strings.ToLower(s)
+	return s == "x" || s == "%x"
+}
+
+// parseImm parses an immediate operand "#k".
+func parseImm(s string) (uint32, error) {
+	if !strings.HasPrefix(s, "#") {
+		return 0, fmt.Errorf("invalid immediate %q", s)
+	}
+	return parseNum(s[1:])
+}
+
+// parseNum parses a decimal, octal or hexadecimal number which fits into
+// 32 bits. Negative numbers are encoded in two's complement.
+func parseNum(s string) (uint32, error) {
+	n, err := strconv.ParseInt(s, 0, 64)
+	if err != nil || n < -1<<31 || n > 1<<32-1 {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return uint32(n), nil
+}
+
+// parseTarget parses a jump target, either a label or "+off".
+func parseTarget(s string) (*target, error) {
+	if strings.HasPrefix(s, "+") {
+		off, err := strconv.ParseUint(s[1:], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jump offset %q", s)
+		}
+		return &target{off: uint32(off)}, nil
+	}
+	if !isLabel(s) {
+		return nil, fmt.Errorf("invalid jump target %q", s)
+	}
+	return &target{label: s}, nil
+}
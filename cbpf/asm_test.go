@@ -0,0 +1,160 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbpf_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/cbpf"
+)
+
+const ipv4TCP = `
+	ldh [12]
+	jneq #0x800, drop    ; not IPv4
+	ldb [23]
+	jeq #6, keep, drop   ; TCP
+keep:	ret #0xffff
+drop:	ret #0
+`
+
+func TestAssemble(t *testing.T) {
+	prog, err := cbpf.Assemble(ipv4TCP)
+	if err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+	want := []cbpf.Instruction{
+		{Code: 0x28, K: 12},
+		{Code: 0x15, Jt: 0, Jf: 3, K: 0x800},
+		{Code: 0x30, K: 23},
+		{Code: 0x15, Jt: 0, Jf: 1, K: 6},
+		{Code: 0x06, K: 0xffff},
+		{Code: 0x06, K: 0},
+	}
+	if !reflect.DeepEqual(prog, want) {
+		t.Fatalf("unexpected program:\ngot  %v\nwant %v", prog, want)
+	}
+}
+
+func TestAssembleForms(t *testing.T) {
+	tests := []struct {
+		src  string
+		want cbpf.Instruction
+	}{
+		{"ld #len", cbpf.Instruction{Code: 0x80}},
+		{"ld #proto", cbpf.Instruction{Code: 0x20, K: cbpf.AncillaryOff + cbpf.AncProtocol}},
+		{"ld #-1", cbpf.Instruction{Code: 0x00, K: 0xffffffff}},
+		{"ld M[3]", cbpf.Instruction{Code: 0x60, K: 3}},
+		{"ld [x + 4]", cbpf.Instruction{Code: 0x40, K: 4}},
+		{"ldh [x]", cbpf.Instruction{Code: 0x48}},
+		{"ldb [-4096]", cbpf.Instruction{Code: 0x30, K: cbpf.AncillaryOff}},
+		{"ldx #0x10", cbpf.Instruction{Code: 0x01, K: 0x10}},
+		{"ldx len", cbpf.Instruction{Code: 0x81}},
+		{"ldxb 4*([14]&0xf)", cbpf.Instruction{Code: 0xb1, K: 14}},
+		{"st M[15]", cbpf.Instruction{Code: 0x02, K: 15}},
+		{"stx M[0]", cbpf.Instruction{Code: 0x03}},
+		{"add x", cbpf.Instruction{Code: 0x0c}},
+		{"rsh #4", cbpf.Instruction{Code: 0x74, K: 4}},
+		{"neg", cbpf.Instruction{Code: 0x84}},
+		{"ja +2", cbpf.Instruction{Code: 0x05, K: 2}},
+		{"jgt x, +1, +2", cbpf.Instruction{Code: 0x2d, Jt: 1, Jf: 2}},
+		{"jlt #5, +1, +2", cbpf.Instruction{Code: 0x35, Jt: 2, Jf: 1, K: 5}},
+		{"jset #0x1fff, +4", cbpf.Instruction{Code: 0x45, Jt: 4, K: 0x1fff}},
+		{"tax", cbpf.Instruction{Code: 0x07}},
+		{"txa", cbpf.Instruction{Code: 0x87}},
+		{"ret a", cbpf.Instruction{Code: 0x16}},
+		{".word 0xffff, 1, 2, 3", cbpf.Instruction{Code: 0xffff, Jt: 1, Jf: 2, K: 3}},
+	}
+	for _, tt := range tests {
+		prog, err := cbpf.Assemble(tt.src)
+		if err != nil {
+			t.Errorf("Assemble(%q): %v", tt.src, err)
+			continue
+		}
+		if len(prog) != 1 || prog[0] != tt.want {
+			t.Errorf("Assemble(%q) = %v, want %v", tt.src, prog, tt.want)
+		}
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+	}{
+		{"foo #1", 1},
+		{"ret #1\nldh [12", 2},
+		{"ld #0x100000000", 1},
+		{"ja nowhere", 1},
+		{"l:\tret #0\nja l", 2},
+		{"a: ret #0\na: ret #1", 2},
+		{"jeq #1\nret #0", 1},
+		{"ldb M[1]", 1},
+		{"jeq #1, +256, +0", 1},
+	}
+	for _, tt := range tests {
+		_, err := cbpf.Assemble(tt.src)
+		serr, ok := err.(*cbpf.SyntaxError)
+		if !ok {
+			t.Errorf("Assemble(%q): got %v, want a SyntaxError", tt.src, err)
+			continue
+		}
+		if serr.Line != tt.line {
+			t.Errorf("Assemble(%q): error %q on line %d, want %d", tt.src, serr, serr.Line, tt.line)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	prog, err := cbpf.Assemble(ipv4TCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := cbpf.Disassemble(prog)
+	want := strings.Join([]string{
+		"\tldh [12]",
+		"\tjeq #0x800, L2, L5",
+		"L2:\tldb [23]",
+		"\tjeq #0x6, L4, L5",
+		"L4:\tret #0xffff",
+		"L5:\tret #0x0",
+		"",
+	}, "\n")
+	if text != want {
+		t.Fatalf("unexpected disassembly:\n%s\nwant:\n%s", text, want)
+	}
+
+	again, err := cbpf.Assemble(text)
+	if err != nil {
+		t.Fatalf("Assemble(Disassemble(prog)): %v", err)
+	}
+	if !reflect.DeepEqual(again, prog) {
+		t.Fatalf("round trip changed program:\ngot  %v\nwant %v", again, prog)
+	}
+}
+
+func TestDisassembleRoundTrip(t *testing.T) {
+	// Every instruction, including invalid ones, must survive a round
+	// trip through its text form.
+	for code := 0; code <= 0xff; code++ {
+		for _, ins := range []cbpf.Instruction{
+			{Code: uint16(code)},
+			{Code: uint16(code), K: 3},
+			{Code: uint16(code), Jt: 1, K: 0xfffff004},
+		} {
+			prog := []cbpf.Instruction{ins, {Code: 0x06}, {Code: 0x06}}
+			text := cbpf.Disassemble(prog)
+			again, err := cbpf.Assemble(text)
+			if err != nil {
+				t.Errorf("%v: Assemble(%q): %v", ins, text, err)
+				continue
+			}
+			if !reflect.DeepEqual(again, prog) {
+				t.Errorf("%v: round trip of %q: got %v", ins, text, again)
+			}
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cbpf implements an assembler, a disassembler, a verifier and an
+// interpreter for classic BPF programs, as attached to sockets with
+// SO_ATTACH_FILTER and SO_ATTACH_REUSEPORT_CBPF, installed with seccomp or
+// loaded into bpf(4) devices.
+//
+// Programs are written in the syntax of the Linux bpf_asm tool, which is
+// also the output of the disassembler:
+//
+//	        ldh [12]
+//	        jneq #0x800, drop   ; not IPv4
+//	        ldb [23]
+//	        jeq #6, keep, drop  ; TCP
+//	keep:   ret #0xffff
+//	drop:   ret #0
+//
+// The instructions are encoded in the layout shared by the SockFilter and
+// BpfInsn types of golang.org/x/sys/unix, to which they can be converted on
+// the systems supporting them. Verify and VM can then be used to check and
+// test a program in ordinary Go tests before attaching it to a live socket.
+package cbpf
+
+import "fmt"
+
+// MaxInstructions is the maximum number of instructions in a program.
+const MaxInstructions = 4096
+
+// ScratchWords is the number of 32-bit words of scratch memory M[].
+const ScratchWords = 16
+
+// Offsets of the special areas addressed by absolute loads. On Linux, loads
+// at AncillaryOff+n read metadata of the packet instead of its data.
+const (
+	AncillaryOff = 0xfffff000 // SKF_AD_OFF
+	NetOff       = 0xfff00000 // SKF_NET_OFF
+	LLOff        = 0xffe00000 // SKF_LL_OFF
+)
+
+// Linux ancillary data, loaded at AncillaryOff plus the given offset.
+const (
+	AncProtocol   = 0  // SKF_AD_PROTOCOL
+	AncPktType    = 4  // SKF_AD_PKTTYPE
+	AncIfIndex    = 8  // SKF_AD_IFINDEX
+	AncNlAttr     = 12 // SKF_AD_NLATTR
+	AncNlAttrNest = 16 // SKF_AD_NLATTR_NEST
+	AncMark       = 20 // SKF_AD_MARK
+	AncQueue      = 24 // SKF_AD_QUEUE
+	AncHatype     = 28 // SKF_AD_HATYPE
+	AncRxhash     = 32 // SKF_AD_RXHASH
+	AncCPU        = 36 // SKF_AD_CPU
+	AncAluXorX    = 40 // SKF_AD_ALU_XOR_X
+	AncVlanTag    = 44 // SKF_AD_VLAN_TAG
+	AncVlanTagPst = 48 // SKF_AD_VLAN_TAG_PRESENT
+	AncPayOffset  = 52 // SKF_AD_PAY_OFFSET
+	AncRandom     = 56 // SKF_AD_RANDOM
+	AncVlanTPID   = 60 // SKF_AD_VLAN_TPID
+	ancMax        = 64 // SKF_AD_MAX
+)
+
+// Instruction encoding, as in <linux/filter.h> and <net/bpf.h>.
+const (
+	// Instruction classes.
+	classLd   = 0x00
+	classLdx  = 0x01
+	classSt   = 0x02
+	classStx  = 0x03
+	classAlu  = 0x04
+	classJmp  = 0x05
+	classRet  = 0x06
+	classMisc = 0x07
+
+	// Load sizes.
+	sizeW = 0x00
+	sizeH = 0x08
+	sizeB = 0x10
+
+	// Load modes.
+	modeImm = 0x00
+	modeAbs = 0x20
+	modeInd = 0x40
+	modeMem = 0x60
+	modeLen = 0x80
+	modeMsh = 0xa0
+
+	// ALU operations.
+	aluAdd = 0x00
+	aluSub = 0x10
+	aluMul = 0x20
+	aluDiv = 0x30
+	aluOr  = 0x40
+	aluAnd = 0x50
+	aluLsh = 0x60
+	aluRsh = 0x70
+	aluNeg = 0x80
+	aluMod = 0x90
+	aluXor = 0xa0
+
+	// Jump operations.
+	jmpJa   = 0x00
+	jmpJeq  = 0x10
+	jmpJgt  = 0x20
+	jmpJge  = 0x30
+	jmpJset = 0x40
+
+	// Operand sources.
+	srcK = 0x00
+	srcX = 0x08
+
+	// Return value sources.
+	retK = 0x00
+	retA = 0x10
+
+	// Miscellaneous operations.
+	miscTax = 0x00
+	miscTxa = 0x80
+)
+
+// An Instruction is a single classic BPF instruction. Its layout matches
+// the SockFilter and BpfInsn types of golang.org/x/sys/unix.
+type Instruction struct {
+	Code uint16
+	Jt   uint8
+	Jf   uint8
+	K    uint32
+}
+
+var aluNames = map[uint16]string{
+	aluAdd: "add",
+	aluSub: "sub",
+	aluMul: "mul",
+	aluDiv: "div",
+	aluOr:  "or",
+	aluAnd: "and",
+	aluLsh: "lsh",
+	aluRsh: "rsh",
+	aluMod: "mod",
+	aluXor: "xor",
+}
+
+var jmpNames = map[uint16]string{
+	jmpJeq:  "jeq",
+	jmpJgt:  "jgt",
+	jmpJge:  "jge",
+	jmpJset: "jset",
+}
+
+var ancNames = map[uint32]string{
+	AncProtocol:   "proto",
+	AncPktType:    "type",
+	AncIfIndex:    "ifidx",
+	AncNlAttr:     "nla",
+	AncNlAttrNest: "nlan",
+	AncMark:       "mark",
+	AncQueue:      "queue",
+	AncHatype:     "hatype",
+	AncRxhash:     "rxhash",
+	AncCPU:        "cpu",
+	AncVlanTag:    "vlan_tci",
+	AncVlanTagPst: "vlan_avail",
+	AncPayOffset:  "poff",
+	AncRandom:     "rand",
+	AncVlanTPID:   "vlan_tpid",
+}
+
+var sizeSuffixes = map[uint16]string{
+	sizeW: "",
+	sizeH: "h",
+	sizeB: "b",
+}
+
+// String returns the instruction in assembler syntax. The targets of
+// conditional jumps are written as offsets relative to the next
+// instruction, such as "jeq #0x800, +0, +3".
+func (ins Instruction) String() string {
+	return ins.format(func(off uint32) string { return fmt.Sprintf("+%d", off) })
+}
+
+// format returns the instruction in assembler syntax, using target to
+// format the relative offsets of jumps.
+func (ins Instruction) format(target func(off uint32) string) string {
+	code := ins.Code
+	k := ins.K
+	// Instructions with fields which are ignored but not zero are written
+	// as raw words, so that Assemble reproduces them exactly.
+	isCondJump := code&0x07 == classJmp && code&0xf0 != jmpJa
+	if !isCondJump && (ins.Jt != 0 || ins.Jf != 0) {
+		return ins.word()
+	}
+	switch code & 0x07 {
+	case classLd:
+		size, ok := sizeSuffixes[code&0x18]
+		if !ok {
+			break
+		}
+		switch code & 0xe0 {
+		case modeImm:
+			if size == "" {
+				return fmt.Sprintf("ld #%#x", k)
+			}
+		case modeAbs:
+			if k >= AncillaryOff && size == "" {
+				if name, ok := ancNames[k-AncillaryOff]; ok {
+					return "ld #" + name
+				}
+			}
+			return fmt.Sprintf("ld%s [%d]", size, int32(k))
+		case modeInd:
+			return fmt.Sprintf("ld%s [x + %d]", size, int32(k))
+		case modeMem:
+			if size == "" {
+				return fmt.Sprintf("ld M[%d]", k)
+			}
+		case modeLen:
+			if size == "" && k == 0 {
+				return "ld #len"
+			}
+		}
+	case classLdx:
+		switch code & 0xf8 {
+		case modeImm | sizeW:
+			return fmt.Sprintf("ldx #%#x", k)
+		case modeMem | sizeW:
+			return fmt.Sprintf("ldx M[%d]", k)
+		case modeLen | sizeW:
+			if k == 0 {
+				return "ldx #len"
+			}
+		case modeMsh | sizeB:
+			return fmt.Sprintf("ldxb 4*([%d]&0xf)", k)
+		}
+	case classSt:
+		if code == classSt {
+			return fmt.Sprintf("st M[%d]", k)
+		}
+	case classStx:
+		if code == classStx {
+			return fmt.Sprintf("stx M[%d]", k)
+		}
+	case classAlu:
+		op := code & 0xf0
+		if op == aluNeg {
+			if code&0xff08 == 0 && k == 0 {
+				return "neg"
+			}
+			break
+		}
+		name, ok := aluNames[op]
+		if !ok || code&0xff00 != 0 {
+			break
+		}
+		if code&srcX == 0 {
+			return fmt.Sprintf("%s #%#x", name, k)
+		}
+		if k == 0 {
+			return name + " x"
+		}
+	case classJmp:
+		op := code & 0xf0
+		if op == jmpJa {
+			if code&0xff08 == 0 {
+				return "ja " + target(k)
+			}
+			break
+		}
+		name, ok := jmpNames[op]
+		if !ok || code&0xff00 != 0 {
+			break
+		}
+		src := fmt.Sprintf("#%#x", k)
+		if code&srcX != 0 {
+			if k != 0 {
+				break
+			}
+			src = "x"
+		}
+		return fmt.Sprintf("%s %s, %s, %s", name, src, target(uint32(ins.Jt)), target(uint32(ins.Jf)))
+	case classRet:
+		switch code &^ classRet {
+		case retK:
+			return fmt.Sprintf("ret #%#x", k)
+		case retA:
+			if k == 0 {
+				return "ret a"
+			}
+		}
+	case classMisc:
+		if k != 0 {
+			break
+		}
+		switch code &^ classMisc {
+		case miscTax:
+			return "tax"
+		case miscTxa:
+			return "txa"
+		}
+	}
+	return ins.word()
+}
+
+// word returns the instruction as a raw .word directive.
+func (ins Instruction) word() string {
+	return fmt.Sprintf(".word %#04x, %d, %d, %#x", ins.Code, ins.Jt, ins.Jf, ins.K)
+}
@@ -0,0 +1,39 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package cbpf
+
+import "golang.org/x/sys/unix"
+
+// BpfInsns converts prog to the instructions of a unix.BpfProgram.
+func BpfInsns(prog []Instruction) []unix.BpfInsn {
+	insns := make([]unix.BpfInsn, len(prog))
+	for i, ins := range prog {
+		insns[i] = unix.BpfInsn{Code: ins.Code, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	return insns
+}
+
+// FromBpfInsns converts the instructions of a unix.BpfProgram to a program.
+func FromBpfInsns(insns []unix.BpfInsn) []Instruction {
+	prog := make([]Instruction, len(insns))
+	for i, ins := range insns {
+		prog[i] = Instruction{Code: ins.Code, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	return prog
+}
+
+// BpfProgram returns prog as a unix.BpfProgram, as passed to the BIOCSETF
+// ioctl of a bpf(4) device. BpfProgram does not verify prog; see Verify.
+func BpfProgram(prog []Instruction) *unix.BpfProgram {
+	insns := BpfInsns(prog)
+	p := &unix.BpfProgram{Len: uint32(len(insns))}
+	if len(insns) > 0 {
+		p.Insns = &insns[0]
+	}
+	return p
+}
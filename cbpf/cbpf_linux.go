@@ -0,0 +1,41 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package cbpf
+
+import "golang.org/x/sys/unix"
+
+// SockFilters converts prog to the instructions of a unix.SockFprog.
+func SockFilters(prog []Instruction) []unix.SockFilter {
+	filters := make([]unix.SockFilter, len(prog))
+	for i, ins := range prog {
+		filters[i] = unix.SockFilter{Code: ins.Code, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	return filters
+}
+
+// FromSockFilters converts the instructions of a unix.SockFprog to a
+// program.
+func FromSockFilters(filters []unix.SockFilter) []Instruction {
+	prog := make([]Instruction, len(filters))
+	for i, f := range filters {
+		prog[i] = Instruction{Code: f.Code, Jt: f.Jt, Jf: f.Jf, K: f.K}
+	}
+	return prog
+}
+
+// SockFprog returns prog as a unix.SockFprog, as passed to
+// SetsockoptSockFprog with SO_ATTACH_FILTER or SO_ATTACH_REUSEPORT_CBPF and
+// to SeccompSetModeFilter. SockFprog does not verify prog; see Verify.
+func SockFprog(prog []Instruction) *unix.SockFprog {
+	filters := SockFilters(prog)
+	fprog := &unix.SockFprog{Len: uint16(len(filters))}
+	if len(filters) > 0 {
+		fprog.Filter = &filters[0]
+	}
+	return fprog
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package cbpf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/cbpf"
+	"golang.org/x/sys/unix"
+)
+
+func TestSockFilters(t *testing.T) {
+	prog, err := cbpf.Assemble(ipv4TCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fprog := cbpf.SockFprog(prog)
+	if int(fprog.Len) != len(prog) || fprog.Filter == nil || fprog.Filter.K != 12 {
+		t.Fatalf("unexpected SockFprog: %+v", fprog)
+	}
+	if got := cbpf.FromSockFilters(cbpf.SockFilters(prog)); !reflect.DeepEqual(got, prog) {
+		t.Fatalf("round trip changed program: got %v, want %v", got, prog)
+	}
+}
+
+func TestSockFprogAttach(t *testing.T) {
+	// Keep UDP datagrams whose payload starts with 'y'. Socket filters of
+	// UDP sockets see the packet starting at the UDP header.
+	prog, err := cbpf.Assemble(`
+		ldb [8]
+		jeq #0x79, keep ; 'y'
+		ret #0
+	keep:	ret #-1
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm, err := cbpf.NewVM(prog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fd)
+	if err := unix.Bind(fd, &unix.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	sa, err := unix.Getsockname(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, cbpf.SockFprog(prog)); err != nil {
+		t.Fatalf("SO_ATTACH_FILTER: %v", err)
+	}
+
+	for _, msg := range []string{"no", "yes"} {
+		if err := unix.Sendto(fd, []byte(msg), 0, sa); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := make([]byte, 16)
+	var got []string
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err == unix.EAGAIN {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(buf[:n]))
+	}
+	if !reflect.DeepEqual(got, []string{"yes"}) {
+		t.Errorf("unexpected datagrams received: %q", got)
+	}
+
+	// The interpreter must agree with the kernel.
+	for _, msg := range []string{"no", "yes"} {
+		pkt := append(make([]byte, 8), msg...)
+		want := uint32(0)
+		if msg == "yes" {
+			want = 0xffffffff
+		}
+		runTestVM(t, vm, pkt, want)
+	}
+}
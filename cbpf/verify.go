@@ -0,0 +1,143 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbpf
+
+import "fmt"
+
+// A VerifyError reports why Verify rejected a program.
+type VerifyError struct {
+	PC  int // index of the offending instruction
+	Msg string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("cbpf: instruction %d: %s", e.PC, e.Msg)
+}
+
+// validCodes is the set of opcodes accepted by the Linux kernel, as listed
+// in bpf_check_classic.
+var validCodes = map[uint16]bool{
+	classAlu | aluAdd | srcK: true, classAlu | aluAdd | srcX: true,
+	classAlu | aluSub | srcK: true, classAlu | aluSub | srcX: true,
+	classAlu | aluMul | srcK: true, classAlu | aluMul | srcX: true,
+	classAlu | aluDiv | srcK: true, classAlu | aluDiv | srcX: true,
+	classAlu | aluMod | srcK: true, classAlu | aluMod | srcX: true,
+	classAlu | aluAnd | srcK: true, classAlu | aluAnd | srcX: true,
+	classAlu | aluOr | srcK: true, classAlu | aluOr | srcX: true,
+	classAlu | aluXor | srcK: true, classAlu | aluXor | srcX: true,
+	classAlu | aluLsh | srcK: true, classAlu | aluLsh | srcX: true,
+	classAlu | aluRsh | srcK: true, classAlu | aluRsh | srcX: true,
+	classAlu | aluNeg: true,
+
+	classLd | sizeW | modeAbs: true, classLd | sizeH | modeAbs: true, classLd | sizeB | modeAbs: true,
+	classLd | sizeW | modeInd: true, classLd | sizeH | modeInd: true, classLd | sizeB | modeInd: true,
+	classLd | sizeW | modeLen: true, classLd | sizeW | modeImm: true, classLd | sizeW | modeMem: true,
+
+	classLdx | sizeW | modeImm: true, classLdx | sizeW | modeMem: true,
+	classLdx | sizeW | modeLen: true, classLdx | sizeB | modeMsh: true,
+
+	classSt: true, classStx: true,
+
+	classMisc | miscTax: true, classMisc | miscTxa: true,
+
+	classRet | retK: true, classRet | retA: true,
+
+	classJmp | jmpJa:         true,
+	classJmp | jmpJeq | srcK: true, classJmp | jmpJeq | srcX: true,
+	classJmp | jmpJge | srcK: true, classJmp | jmpJge | srcX: true,
+	classJmp | jmpJgt | srcK: true, classJmp | jmpJgt | srcX: true,
+	classJmp | jmpJset | srcK: true, classJmp | jmpJset | srcX: true,
+}
+
+// Verify checks prog according to the rules the Linux kernel applies to
+// classic BPF programs: its length must be between 1 and MaxInstructions,
+// every opcode must be known, divisions by a constant zero and shifts by
+// 32 or more are rejected, scratch memory accesses must be in bounds and
+// every word must be stored before it is loaded on all paths, jumps must
+// stay within the program, which must end with a ret instruction, and
+// absolute loads at AncillaryOff must name known ancillary data.
+func Verify(prog []Instruction) error {
+	if len(prog) == 0 || len(prog) > MaxInstructions {
+		return &VerifyError{0, fmt.Sprintf("invalid program length %d", len(prog))}
+	}
+	for pc, ins := range prog {
+		if !validCodes[ins.Code] {
+			return &VerifyError{pc, fmt.Sprintf("invalid opcode %#04x", ins.Code)}
+		}
+		switch ins.Code {
+		case classAlu | aluDiv | srcK, classAlu | aluMod | srcK:
+			if ins.K == 0 {
+				return &VerifyError{pc, "division by zero"}
+			}
+		case classAlu | aluLsh | srcK, classAlu | aluRsh | srcK:
+			if ins.K >= 32 {
+				return &VerifyError{pc, fmt.Sprintf("shift by %d", ins.K)}
+			}
+		case classLd | sizeW | modeMem, classLdx | sizeW | modeMem, classSt, classStx:
+			if ins.K >= ScratchWords {
+				return &VerifyError{pc, fmt.Sprintf("scratch memory index %d out of range", ins.K)}
+			}
+		case classLd | sizeW | modeAbs, classLd | sizeH | modeAbs, classLd | sizeB | modeAbs:
+			if ins.K >= AncillaryOff {
+				off := ins.K - AncillaryOff
+				if off >= ancMax || off%4 != 0 {
+					return &VerifyError{pc, fmt.Sprintf("unknown ancillary data at offset %d", off)}
+				}
+			}
+		case classJmp | jmpJa:
+			if uint64(ins.K) >= uint64(len(prog)-pc-1) {
+				return &VerifyError{pc, "jump out of range"}
+			}
+		default:
+			if ins.Code&0x07 == classJmp {
+				if pc+int(ins.Jt)+1 >= len(prog) || pc+int(ins.Jf)+1 >= len(prog) {
+					return &VerifyError{pc, "jump out of range"}
+				}
+			}
+		}
+	}
+	if prog[len(prog)-1].Code&0x07 != classRet {
+		return &VerifyError{len(prog) - 1, "program does not end with ret"}
+	}
+	return checkMemory(prog)
+}
+
+// checkMemory checks that every scratch memory word is stored before it is
+// loaded, following all forward paths through the program like the kernel's
+// check_load_and_stores.
+func checkMemory(prog []Instruction) error {
+	const all = 1<<ScratchWords - 1
+	// valid[pc] holds the words which are stored on every path to pc.
+	valid := make([]uint16, len(prog))
+	for i := range valid {
+		valid[i] = all
+	}
+	valid[0] = 0
+	for pc, ins := range prog {
+		mem := valid[pc]
+		switch ins.Code {
+		case classSt, classStx:
+			mem |= 1 << ins.K
+		case classLd | sizeW | modeMem, classLdx | sizeW | modeMem:
+			if mem&(1<<ins.K) == 0 {
+				return &VerifyError{pc, fmt.Sprintf("load of scratch memory word %d before store", ins.K)}
+			}
+		}
+		switch {
+		case ins.Code == classJmp|jmpJa:
+			valid[pc+1+int(ins.K)] &= mem
+			continue
+		case ins.Code&0x07 == classJmp:
+			valid[pc+1+int(ins.Jt)] &= mem
+			valid[pc+1+int(ins.Jf)] &= mem
+			continue
+		}
+		// Like the kernel, assume that a ret instruction may fall through.
+		if pc+1 < len(prog) {
+			valid[pc+1] &= mem
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbpf_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/cbpf"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		src string
+		pc  int // -1 if valid
+	}{
+		{ipv4TCP, -1},
+		{"ret a", -1},
+		{"ldh [12]", 0},
+		{"ld #1\n.word 0xffff, 0, 0, 0\nret a", 1},
+		{"div #0\nret a", 0},
+		{"mod #0\nret a", 0},
+		{"div x\nret a", -1},
+		{"lsh #32\nret a", 0},
+		{"rsh #31\nret a", -1},
+		{"st M[16]\nret a", 0},
+		{"ld #proto\nret a", -1},
+		{"ld [-4032]\nret a", 0},
+		{"ld [-4090]\nret a", 0},
+		{"ldx M[0]\nret a", 0},
+		{"st M[0]\nldx M[0]\nret a", -1},
+		// M[1] is only stored when the jump is not taken.
+		{"jeq #1, +1\nst M[1]\nld M[1]\nret a", 2},
+		// Both paths store M[1].
+		{"jeq #1, +2\nst M[1]\nja +1\nst M[1]\nld M[1]\nret a", -1},
+		// The kernel assumes that ret falls through.
+		{"jeq #1, +1\nret #0\nld M[1]\nret a", 2},
+		{"ja +1\nret a", 0},
+		{"jeq #1, +0, +1\nret a", 0},
+		{".word 0x0e, 0, 0, 0", 0}, // ret x
+	}
+	for _, tt := range tests {
+		prog, err := cbpf.Assemble(tt.src)
+		if err != nil {
+			t.Errorf("Assemble(%q): %v", tt.src, err)
+			continue
+		}
+		err = cbpf.Verify(prog)
+		if tt.pc < 0 {
+			if err != nil {
+				t.Errorf("Verify(%q): %v", tt.src, err)
+			}
+			continue
+		}
+		verr, ok := err.(*cbpf.VerifyError)
+		if !ok {
+			t.Errorf("Verify(%q): got %v, want a VerifyError", tt.src, err)
+			continue
+		}
+		if verr.PC != tt.pc {
+			t.Errorf("Verify(%q): error %q at instruction %d, want %d", tt.src, verr, verr.PC, tt.pc)
+		}
+	}
+}
+
+func TestVerifyLength(t *testing.T) {
+	if err := cbpf.Verify(nil); err == nil {
+		t.Error("empty program accepted")
+	}
+	prog, err := cbpf.Assemble(strings.Repeat("ret #0\n", cbpf.MaxInstructions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cbpf.Verify(prog); err != nil {
+		t.Errorf("program of maximum length rejected: %v", err)
+	}
+	if err := cbpf.Verify(append(prog, prog[0])); err == nil {
+		t.Error("program longer than the maximum length accepted")
+	}
+}
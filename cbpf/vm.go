@@ -0,0 +1,196 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbpf
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// A VM runs a verified program over packets in userspace, following the
+// semantics of the Linux kernel.
+//
+// Loads beyond the end of the packet, and loads relative to NetOff and
+// LLOff, which depend on the headers known to the kernel, end the program
+// with a return value of 0, as do divisions by a zero X register.
+type VM struct {
+	prog []Instruction
+
+	// Ancillary, if not nil, returns the ancillary data at offset off
+	// relative to AncillaryOff, such as AncProtocol, for the packet being
+	// filtered. If Ancillary is nil, running a program which loads
+	// ancillary data fails. Loads at AncAluXorX compute A ^= X and do not
+	// call Ancillary.
+	Ancillary func(off uint32) uint32
+}
+
+// NewVM verifies prog and returns a VM running it.
+func NewVM(prog []Instruction) (*VM, error) {
+	if err := Verify(prog); err != nil {
+		return nil, err
+	}
+	return &VM{prog: append([]Instruction(nil), prog...)}, nil
+}
+
+// Run runs the program over pkt and returns the value of its ret
+// instruction, such as the number of bytes of the packet to keep for
+// socket filters or the action for seccomp filters.
+func (vm *VM) Run(pkt []byte) (uint32, error) {
+	var (
+		a, x uint32
+		mem  [ScratchWords]uint32
+	)
+	load := func(off uint32, size uint16) (uint32, bool) {
+		n := uint64(4)
+		switch size {
+		case sizeH:
+			n = 2
+		case sizeB:
+			n = 1
+		}
+		if off >= LLOff || uint64(off)+n > uint64(len(pkt)) {
+			return 0, false
+		}
+		switch size {
+		case sizeW:
+			return binary.BigEndian.Uint32(pkt[off:]), true
+		case sizeH:
+			return uint32(binary.BigEndian.Uint16(pkt[off:])), true
+		default:
+			return uint32(pkt[off]), true
+		}
+	}
+
+	for pc := 0; pc < len(vm.prog); pc++ {
+		ins := vm.prog[pc]
+		k := ins.K
+		switch ins.Code & 0x07 {
+		case classLd:
+			switch ins.Code & 0xe0 {
+			case modeImm:
+				a = k
+			case modeMem:
+				a = mem[k]
+			case modeLen:
+				a = uint32(len(pkt))
+			case modeAbs:
+				if k >= AncillaryOff {
+					off := k - AncillaryOff
+					if off == AncAluXorX {
+						a ^= x
+						break
+					}
+					if vm.Ancillary == nil {
+						return 0, fmt.Errorf("cbpf: instruction %d: load of ancillary data at offset %d", pc, off)
+					}
+					a = vm.Ancillary(off)
+					break
+				}
+				v, ok := load(k, ins.Code&0x18)
+				if !ok {
+					return 0, nil
+				}
+				a = v
+			case modeInd:
+				v, ok := load(x+k, ins.Code&0x18)
+				if !ok {
+					return 0, nil
+				}
+				a = v
+			}
+		case classLdx:
+			switch ins.Code & 0xe0 {
+			case modeImm:
+				x = k
+			case modeMem:
+				x = mem[k]
+			case modeLen:
+				x = uint32(len(pkt))
+			case modeMsh:
+				v, ok := load(k, sizeB)
+				if !ok {
+					return 0, nil
+				}
+				x = 4 * (v & 0xf)
+			}
+		case classSt:
+			mem[k] = a
+		case classStx:
+			mem[k] = x
+		case classAlu:
+			operand := k
+			if ins.Code&srcX != 0 {
+				operand = x
+			}
+			switch ins.Code & 0xf0 {
+			case aluAdd:
+				a += operand
+			case aluSub:
+				a -= operand
+			case aluMul:
+				a *= operand
+			case aluDiv:
+				if operand == 0 {
+					return 0, nil
+				}
+				a /= operand
+			case aluMod:
+				if operand == 0 {
+					return 0, nil
+				}
+				a %= operand
+			case aluAnd:
+				a &= operand
+			case aluOr:
+				a |= operand
+			case aluXor:
+				a ^= operand
+			case aluLsh:
+				a <<= operand
+			case aluRsh:
+				a >>= operand
+			case aluNeg:
+				a = -a
+			}
+		case classJmp:
+			operand := k
+			if ins.Code&srcX != 0 {
+				operand = x
+			}
+			var cond bool
+			switch ins.Code & 0xf0 {
+			case jmpJa:
+				pc += int(k)
+				continue
+			case jmpJeq:
+				cond = a == operand
+			case jmpJgt:
+				cond = a > operand
+			case jmpJge:
+				cond = a >= operand
+			case jmpJset:
+				cond = a&operand != 0
+			}
+			if cond {
+				pc += int(ins.Jt)
+			} else {
+				pc += int(ins.Jf)
+			}
+		case classRet:
+			if ins.Code&retA != 0 {
+				return a, nil
+			}
+			return k, nil
+		case classMisc:
+			if ins.Code&miscTxa != 0 {
+				a = x
+			} else {
+				x = a
+			}
+		}
+	}
+	// Not reached for verified programs.
+	return 0, fmt.Errorf("cbpf: program did not return")
+}
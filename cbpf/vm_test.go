@@ -0,0 +1,144 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbpf_test
+
+import (
+	"testing"
+
+	"golang.org/x/sys/cbpf"
+)
+
+func newTestVM(t *testing.T, src string) *cbpf.VM {
+	t.Helper()
+	prog, err := cbpf.Assemble(src)
+	if err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+	vm, err := cbpf.NewVM(prog)
+	if err != nil {
+		t.Fatalf("NewVM: %v", err)
+	}
+	return vm
+}
+
+func runTestVM(t *testing.T, vm *cbpf.VM, pkt []byte, want uint32) {
+	t.Helper()
+	got, err := vm.Run(pkt)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got != want {
+		t.Errorf("Run(%x) = %#x, want %#x", pkt, got, want)
+	}
+}
+
+func ethernetFrame(ethertype uint16, payload ...byte) []byte {
+	pkt := make([]byte, 14, 14+len(payload))
+	pkt[12] = byte(ethertype >> 8)
+	pkt[13] = byte(ethertype)
+	return append(pkt, payload...)
+}
+
+func TestVMFilter(t *testing.T) {
+	vm := newTestVM(t, ipv4TCP)
+
+	ipv4 := func(proto byte) []byte {
+		hdr := make([]byte, 20)
+		hdr[0] = 0x45
+		hdr[9] = proto
+		return ethernetFrame(0x800, hdr...)
+	}
+	runTestVM(t, vm, ipv4(6), 0xffff)
+	runTestVM(t, vm, ipv4(17), 0)
+	runTestVM(t, vm, ethernetFrame(0x86dd, make([]byte, 40)...), 0)
+	// Loads past the end of the packet drop it.
+	runTestVM(t, vm, ethernetFrame(0x800), 0)
+	runTestVM(t, vm, nil, 0)
+}
+
+func TestVMTCPPort(t *testing.T) {
+	// Accept TCP segments to port 80, using the IP header length to find
+	// the TCP header.
+	vm := newTestVM(t, `
+		ldxb 4*([14]&0xf)
+		ldh [x + 16]
+		jeq #80, keep
+		ret #0
+	keep:	ret #-1
+	`)
+	for _, ihl := range []byte{5, 6} {
+		pkt := ethernetFrame(0x800, make([]byte, 4*int(ihl)+20)...)
+		pkt[14] = 0x40 | ihl
+		off := 14 + 4*int(ihl) + 2
+		pkt[off+1] = 80
+		runTestVM(t, vm, pkt, 0xffffffff)
+		pkt[off+1] = 81
+		runTestVM(t, vm, pkt, 0)
+	}
+}
+
+func TestVMArithmetic(t *testing.T) {
+	tests := []struct {
+		src  string
+		want uint32
+	}{
+		{"ld #7\nadd #3\nret a", 10},
+		{"ld #7\nsub #8\nret a", 0xffffffff},
+		{"ld #7\nldx #3\nmul x\nret a", 21},
+		{"ld #7\ndiv #2\nret a", 3},
+		{"ld #7\nmod #4\nret a", 3},
+		{"ld #7\nldx #0\ndiv x\nret #1", 0},
+		{"ld #7\nldx #0\nmod x\nret #1", 0},
+		{"ld #0xf0\nand #0x3c\nret a", 0x30},
+		{"ld #0xf0\nor #0x0f\nret a", 0xff},
+		{"ld #0xff\nxor #0x0f\nret a", 0xf0},
+		{"ld #1\nlsh #31\nret a", 0x80000000},
+		{"ld #0x80000000\nrsh #31\nret a", 1},
+		{"ld #1\nneg\nret a", 0xffffffff},
+		{"ld #5\ntax\nld #0\ntxa\nret a", 5},
+		{"ld #5\nst M[3]\nld #0\nldx M[3]\ntxa\nret a", 5},
+		{"ld #len\nret a", 4},
+		{"ldx #len\ntxa\nret a", 4},
+		{"ld [0]\nret a", 0x01020304},
+		{"ldx #2\nldh [x + 0]\nret a", 0x0304},
+		{"ld #5\njgt #4, +1\nret #0\nret #1", 1},
+		{"ld #5\njge #6, +1\nret #0\nret #1", 0},
+		{"ld #5\njset #4, +1\nret #0\nret #1", 1},
+		{"ld #5\nldx #5\njeq x, +1\nret #0\nret #1", 1},
+		{"ja +1\nret #0\nret #1", 1},
+		{"ld #6\nldx #3\nld [-4056]\nret a", 5},
+	}
+	pkt := []byte{1, 2, 3, 4}
+	for _, tt := range tests {
+		prog, err := cbpf.Assemble(tt.src)
+		if err != nil {
+			t.Errorf("Assemble(%q): %v", tt.src, err)
+			continue
+		}
+		vm, err := cbpf.NewVM(prog)
+		if err != nil {
+			t.Errorf("NewVM(%q): %v", tt.src, err)
+			continue
+		}
+		got, err := vm.Run(pkt)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %#x, %v, want %#x", tt.src, got, err, tt.want)
+		}
+	}
+}
+
+func TestVMAncillary(t *testing.T) {
+	vm := newTestVM(t, "ld #proto\nret a")
+	if _, err := vm.Run(nil); err == nil {
+		t.Error("ancillary load without an Ancillary function succeeded")
+	}
+	vm.Ancillary = func(off uint32) uint32 {
+		if off != cbpf.AncProtocol {
+			t.Errorf("unexpected ancillary offset %d", off)
+		}
+		return 0x800
+	}
+	runTestVM(t, vm, nil, 0x800)
+}
@@ -8,6 +8,8 @@ package unix
 
 import (
 	"math/bits"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -84,3 +86,327 @@ func (s *CPUSet) Count() int {
 	}
 	return c
 }
+
+// DynamicCPUSet represents a CPU affinity mask of arbitrary size, for
+// systems with more CPUs than a CPUSet can hold. The zero value is an empty
+// set, which grows as CPUs are added.
+type DynamicCPUSet struct {
+	mask []cpuMask
+}
+
+// NewDynamicCPUSet returns a DynamicCPUSet containing cpus.
+func NewDynamicCPUSet(cpus ...int) *DynamicCPUSet {
+	s := new(DynamicCPUSet)
+	for _, cpu := range cpus {
+		s.Set(cpu)
+	}
+	return s
+}
+
+// DynamicCPUSet returns a DynamicCPUSet containing the CPUs of the set s.
+func (s *CPUSet) DynamicCPUSet() *DynamicCPUSet {
+	return &DynamicCPUSet{mask: append([]cpuMask(nil), s[:]...)}
+}
+
+// grow makes s hold at least n words.
+func (s *DynamicCPUSet) grow(n int) {
+	if n > len(s.mask) {
+		s.mask = append(s.mask, make([]cpuMask, n-len(s.mask))...)
+	}
+}
+
+// Zero clears the set s, so that it contains no CPUs.
+func (s *DynamicCPUSet) Zero() {
+	for i := range s.mask {
+		s.mask[i] = 0
+	}
+}
+
+// Set adds cpu to the set s.
+func (s *DynamicCPUSet) Set(cpu int) {
+	if cpu < 0 {
+		return
+	}
+	i := cpuBitsIndex(cpu)
+	s.grow(i + 1)
+	s.mask[i] |= cpuBitsMask(cpu)
+}
+
+// Clear removes cpu from the set s.
+func (s *DynamicCPUSet) Clear(cpu int) {
+	i := cpuBitsIndex(cpu)
+	if cpu >= 0 && i < len(s.mask) {
+		s.mask[i] &^= cpuBitsMask(cpu)
+	}
+}
+
+// IsSet reports whether cpu is in the set s.
+func (s *DynamicCPUSet) IsSet(cpu int) bool {
+	i := cpuBitsIndex(cpu)
+	if cpu >= 0 && i < len(s.mask) {
+		return s.mask[i]&cpuBitsMask(cpu) != 0
+	}
+	return false
+}
+
+// Count returns the number of CPUs in the set s.
+func (s *DynamicCPUSet) Count() int {
+	c := 0
+	for _, b := range s.mask {
+		c += bits.OnesCount64(uint64(b))
+	}
+	return c
+}
+
+// Iterate calls f for each CPU in the set s, in increasing order, until f
+// returns false.
+func (s *DynamicCPUSet) Iterate(f func(cpu int) bool) {
+	for i, b := range s.mask {
+		for w := uint64(b); w != 0; w &= w - 1 {
+			if !f(i*_NCPUBITS + bits.TrailingZeros64(w)) {
+				return
+			}
+		}
+	}
+}
+
+// CPUs returns the CPUs in the set s, in increasing order.
+func (s *DynamicCPUSet) CPUs() []int {
+	cpus := make([]int, 0, s.Count())
+	s.Iterate(func(cpu int) bool {
+		cpus = append(cpus, cpu)
+		return true
+	})
+	return cpus
+}
+
+// Equal reports whether the sets s and t contain the same CPUs.
+func (s *DynamicCPUSet) Equal(t *DynamicCPUSet) bool {
+	a, b := s.mask, t.mask
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	for i := range a {
+		var w cpuMask
+		if i < len(b) {
+			w = b[i]
+		}
+		if a[i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *DynamicCPUSet) combine(t *DynamicCPUSet, op func(a, b cpuMask) cpuMask) *DynamicCPUSet {
+	n := len(s.mask)
+	if len(t.mask) > n {
+		n = len(t.mask)
+	}
+	r := &DynamicCPUSet{mask: make([]cpuMask, n)}
+	for i := range r.mask {
+		var a, b cpuMask
+		if i < len(s.mask) {
+			a = s.mask[i]
+		}
+		if i < len(t.mask) {
+			b = t.mask[i]
+		}
+		r.mask[i] = op(a, b)
+	}
+	return r
+}
+
+// And returns the intersection of the sets s and t.
+func (s *DynamicCPUSet) And(t *DynamicCPUSet) *DynamicCPUSet {
+	return s.combine(t, func(a, b cpuMask) cpuMask { return a & b })
+}
+
+// AndNot returns the CPUs of the set s which are not in the set t.
+func (s *DynamicCPUSet) AndNot(t *DynamicCPUSet) *DynamicCPUSet {
+	return s.combine(t, func(a, b cpuMask) cpuMask { return a &^ b })
+}
+
+// Or returns the union of the sets s and t.
+func (s *DynamicCPUSet) Or(t *DynamicCPUSet) *DynamicCPUSet {
+	return s.combine(t, func(a, b cpuMask) cpuMask { return a | b })
+}
+
+// Xor returns the CPUs which are in exactly one of the sets s and t.
+func (s *DynamicCPUSet) Xor(t *DynamicCPUSet) *DynamicCPUSet {
+	return s.combine(t, func(a, b cpuMask) cpuMask { return a ^ b })
+}
+
+// String formats the set s in the kernel's cpulist format, such as
+// "0-3,8,10-15", which is used by /sys/devices/system/cpu/online and the
+// cpuset.cpus file of cgroups.
+func (s *DynamicCPUSet) String() string {
+	var b strings.Builder
+	first, last := -1, -1
+	flush := func() {
+		if first < 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(first))
+		if last > first {
+			b.WriteByte('-')
+			b.WriteString(strconv.Itoa(last))
+		}
+	}
+	s.Iterate(func(cpu int) bool {
+		if cpu != last+1 || first < 0 {
+			flush()
+			first = cpu
+		}
+		last = cpu
+		return true
+	})
+	flush()
+	return b.String()
+}
+
+// ParseCPUList parses a set of CPUs in the kernel's cpulist format: a
+// comma-separated list of CPU numbers and ranges, such as "0-3,8,10-15".
+// A range may be followed by a stride, such as "0-15:2/4", which selects
+// the first 2 CPUs of each group of 4 CPUs in the range.
+func ParseCPUList(list string) (*DynamicCPUSet, error) {
+	s := new(DynamicCPUSet)
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return s, nil
+	}
+	for _, r := range strings.Split(list, ",") {
+		used, group := 1, 1
+		if i := strings.IndexByte(r, ':'); i >= 0 {
+			stride := r[i+1:]
+			j := strings.IndexByte(stride, '/')
+			if j < 0 {
+				return nil, EINVAL
+			}
+			var err1, err2 error
+			used, err1 = strconv.Atoi(stride[:j])
+			group, err2 = strconv.Atoi(stride[j+1:])
+			if err1 != nil || err2 != nil || used <= 0 || group <= 0 || used > group {
+				return nil, EINVAL
+			}
+			r = r[:i]
+		}
+		lo, hi := r, ""
+		if i := strings.IndexByte(r, '-'); i >= 0 {
+			lo, hi = r[:i], r[i+1:]
+		}
+		first, err := strconv.Atoi(lo)
+		if err != nil || first < 0 {
+			return nil, EINVAL
+		}
+		last := first
+		if hi != "" || lo != r {
+			if last, err = strconv.Atoi(hi); err != nil || last < first {
+				return nil, EINVAL
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			if (cpu-first)%group < used {
+				s.Set(cpu)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Mask formats the set s in the kernel's hexadecimal cpumask format: comma
+// separated groups of 32 CPUs, most significant first, such as
+// "ff,0000000f", which is used by /proc/pid/status and the cpu_map files
+// of sysfs.
+func (s *DynamicCPUSet) Mask() string {
+	n := 0
+	for i, b := range s.mask {
+		if b != 0 {
+			n = (i*_NCPUBITS + bits.Len64(uint64(b)) + 31) / 32
+		}
+	}
+	if n == 0 {
+		return "0"
+	}
+	var b strings.Builder
+	for g := n - 1; g >= 0; g-- {
+		var w uint32
+		for i := 0; i < 32; i++ {
+			if s.IsSet(g*32 + i) {
+				w |= 1 << uint(i)
+			}
+		}
+		if g == n-1 {
+			b.WriteString(strconv.FormatUint(uint64(w), 16))
+		} else {
+			b.WriteByte(',')
+			hex := strconv.FormatUint(uint64(w), 16)
+			b.WriteString(strings.Repeat("0", 8-len(hex)))
+			b.WriteString(hex)
+		}
+	}
+	return b.String()
+}
+
+// ParseCPUMask parses a set of CPUs in the kernel's hexadecimal cpumask
+// format, such as "ff,0000000f".
+func ParseCPUMask(mask string) (*DynamicCPUSet, error) {
+	s := new(DynamicCPUSet)
+	groups := strings.Split(strings.TrimSpace(mask), ",")
+	for i := range groups {
+		w, err := strconv.ParseUint(groups[len(groups)-1-i], 16, 32)
+		if err != nil {
+			return nil, EINVAL
+		}
+		for ; w != 0; w &= w - 1 {
+			s.Set(i*32 + bits.TrailingZeros64(w))
+		}
+	}
+	return s, nil
+}
+
+// maxCPUSetWords bounds the size of the mask SchedGetaffinityDynamic tries,
+// 4M CPUs, which is far beyond the kernel's NR_CPUS limit.
+const maxCPUSetWords = 1 << 22 / _NCPUBITS
+
+// SchedGetaffinityDynamic gets the CPU affinity mask of the thread specified
+// by pid into set, which grows to the number of CPUs supported by the
+// kernel. If pid is 0 the calling thread is used.
+func SchedGetaffinityDynamic(pid int, set *DynamicCPUSet) error {
+	n := len(set.mask)
+	if n < cpuSetSize {
+		n = cpuSetSize
+	}
+	for {
+		mask := make([]cpuMask, n)
+		r, _, e := RawSyscall(SYS_SCHED_GETAFFINITY, uintptr(pid), uintptr(n*_NCPUBITS/8), uintptr(unsafe.Pointer(&mask[0])))
+		if e == 0 {
+			// The kernel returns the size of its mask, and leaves the
+			// rest of the buffer unchanged.
+			set.mask = mask[:int(r)*8/_NCPUBITS]
+			return nil
+		}
+		if e != EINVAL || n >= maxCPUSetWords {
+			return errnoErr(e)
+		}
+		n *= 2
+	}
+}
+
+// SchedSetaffinityDynamic sets the CPU affinity mask of the thread specified
+// by pid to set. If pid is 0 the calling thread is used.
+func SchedSetaffinityDynamic(pid int, set *DynamicCPUSet) error {
+	mask := set.mask
+	if len(mask) == 0 {
+		// The kernel rejects an empty set with EINVAL.
+		mask = make([]cpuMask, 1)
+	}
+	_, _, e := RawSyscall(SYS_SCHED_SETAFFINITY, uintptr(pid), uintptr(len(mask)*_NCPUBITS/8), uintptr(unsafe.Pointer(&mask[0])))
+	if e != 0 {
+		return errnoErr(e)
+	}
+	return nil
+}
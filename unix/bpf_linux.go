@@ -0,0 +1,442 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Dst returns the destination register of the instruction.
+func (insn *BpfInsn) Dst() uint8 {
+	if isBigEndian {
+		return insn.Regs >> 4
+	}
+	return insn.Regs & 0xf
+}
+
+// Src returns the source register of the instruction.
+func (insn *BpfInsn) Src() uint8 {
+	if isBigEndian {
+		return insn.Regs & 0xf
+	}
+	return insn.Regs >> 4
+}
+
+// SetRegs sets the destination and source registers of the instruction,
+// which share a byte whose layout depends on the byte order.
+func (insn *BpfInsn) SetRegs(dst, src uint8) {
+	if isBigEndian {
+		insn.Regs = dst<<4 | src&0xf
+	} else {
+		insn.Regs = src<<4 | dst&0xf
+	}
+}
+
+// bpfEscapeSink is never written to. Storing to it on a branch which is
+// never taken makes the compiler allocate the memory passed to bpfPtr on
+// the heap, where it does not move while the kernel refers to it.
+var bpfEscapeSink struct {
+	enabled bool
+	p       unsafe.Pointer
+}
+
+// bpfPtr returns p as a __u64 of a bpf(2) attribute. The caller must keep
+// the memory at p alive until the system call returns.
+func bpfPtr(p unsafe.Pointer) uint64 {
+	if bpfEscapeSink.enabled {
+		bpfEscapeSink.p = p
+	}
+	return uint64(uintptr(p))
+}
+
+var bpfPossibleCPUs struct {
+	once sync.Once
+	n    int
+	err  error
+}
+
+// bpfNumPossibleCPUs returns the number of possible CPUs, which determines
+// the size of the values of per-CPU maps.
+func bpfNumPossibleCPUs() (int, error) {
+	c := &bpfPossibleCPUs
+	c.once.Do(func() {
+		var b [128]byte
+		fd, err := Open("/sys/devices/system/cpu/possible", O_RDONLY|O_CLOEXEC, 0)
+		if err != nil {
+			c.err = err
+			return
+		}
+		n, err := Read(fd, b[:])
+		Close(fd)
+		if err != nil {
+			c.err = err
+			return
+		}
+		// The list has the form "0-N", possibly with several ranges.
+		list := strings.TrimSpace(string(b[:n]))
+		last := list[strings.LastIndexAny(list, ",-")+1:]
+		max, err := strconv.Atoi(last)
+		if err != nil {
+			c.err = EINVAL
+			return
+		}
+		c.n = max + 1
+	})
+	return c.n, c.err
+}
+
+// A BpfMap is a BPF map, which holds key/value pairs shared between BPF
+// programs and user space.
+type BpfMap struct {
+	fd        int
+	mapType   uint32
+	keySize   int
+	valueSize int
+}
+
+// NewBpfMap creates a BPF map described by attr.
+func NewBpfMap(attr *BpfMapCreateAttr) (*BpfMap, error) {
+	fd, err := BpfMapCreate(attr)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newBpfMap(fd, attr.Map_type, attr.Key_size, attr.Value_size)
+	if err != nil {
+		Close(fd)
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewBpfMapFromFd returns a BpfMap for the map file descriptor fd, whose
+// properties are queried from the kernel. The map takes ownership of fd.
+func NewBpfMapFromFd(fd int) (*BpfMap, error) {
+	info, err := BpfMapGetInfo(fd)
+	if err != nil {
+		return nil, err
+	}
+	return newBpfMap(fd, info.Type, info.Key_size, info.Value_size)
+}
+
+// OpenPinnedBpfMap opens the map pinned at path on a bpf filesystem. flags
+// is 0, BPF_F_RDONLY or BPF_F_WRONLY.
+func OpenPinnedBpfMap(path string, flags uint32) (*BpfMap, error) {
+	fd, err := bpfObjGet(path, flags)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewBpfMapFromFd(fd)
+	if err != nil {
+		Close(fd)
+		return nil, err
+	}
+	return m, nil
+}
+
+func newBpfMap(fd int, mapType, keySize, valueSize uint32) (*BpfMap, error) {
+	m := &BpfMap{fd: fd, mapType: mapType, keySize: int(keySize), valueSize: int(valueSize)}
+	switch mapType {
+	case BPF_MAP_TYPE_PERCPU_HASH, BPF_MAP_TYPE_PERCPU_ARRAY,
+		BPF_MAP_TYPE_LRU_PERCPU_HASH, BPF_MAP_TYPE_PERCPU_CGROUP_STORAGE:
+		// Lookups return one value per possible CPU, each of which is
+		// padded to 8 bytes.
+		n, err := bpfNumPossibleCPUs()
+		if err != nil {
+			return nil, err
+		}
+		m.valueSize = (m.valueSize + 7) &^ 7 * n
+	}
+	return m, nil
+}
+
+// BpfMapGetInfo returns information about the map fd.
+func BpfMapGetInfo(fd int) (*BpfMapInfo, error) {
+	var info BpfMapInfo
+	err := BpfObjGetInfoByFd(&BpfObjGetInfoAttr{
+		Bpf_fd:   uint32(fd),
+		Info_len: uint32(unsafe.Sizeof(info)),
+		Info:     bpfPtr(unsafe.Pointer(&info)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Fd returns the map file descriptor.
+func (m *BpfMap) Fd() int { return m.fd }
+
+// Type returns the BPF_MAP_TYPE_* type of the map.
+func (m *BpfMap) Type() uint32 { return m.mapType }
+
+// KeySize returns the size of the keys of the map.
+func (m *BpfMap) KeySize() int { return m.keySize }
+
+// ValueSize returns the size of the values passed to Lookup and Update.
+// For per-CPU maps, this is the size of one value per possible CPU, each
+// padded to a multiple of 8 bytes.
+func (m *BpfMap) ValueSize() int { return m.valueSize }
+
+// Close closes the map file descriptor. The map is freed once it is no
+// longer used by programs or pinned.
+func (m *BpfMap) Close() error {
+	return Close(m.fd)
+}
+
+func (m *BpfMap) elem(cmd int, key, value []byte, flags uint64) error {
+	if len(key) != m.keySize {
+		return EINVAL
+	}
+	attr := BpfMapElemAttr{
+		Map_fd: uint32(m.fd),
+		Key:    bpfPtr(bytesPointer(key)),
+		Value:  bpfPtr(bytesPointer(value)),
+		Flags:  flags,
+	}
+	_, err := bpf(cmd, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(value)
+	return err
+}
+
+// Lookup copies the value of the element at key to value, which must be
+// ValueSize bytes long. It returns ENOENT if there is no such element.
+func (m *BpfMap) Lookup(key, value []byte) error {
+	if len(value) != m.valueSize {
+		return EINVAL
+	}
+	return m.elem(BPF_MAP_LOOKUP_ELEM, key, value, 0)
+}
+
+// Update sets the value of the element at key. flags is one of BPF_ANY,
+// BPF_NOEXIST or BPF_EXIST.
+func (m *BpfMap) Update(key, value []byte, flags uint64) error {
+	if len(value) != m.valueSize {
+		return EINVAL
+	}
+	return m.elem(BPF_MAP_UPDATE_ELEM, key, value, flags)
+}
+
+// Delete deletes the element at key.
+func (m *BpfMap) Delete(key []byte) error {
+	return m.elem(BPF_MAP_DELETE_ELEM, key, nil, 0)
+}
+
+// NextKey copies the key following key to next. If key is nil, the first
+// key is copied. NextKey returns ENOENT after the last key.
+func (m *BpfMap) NextKey(key, next []byte) error {
+	if len(next) != m.keySize {
+		return EINVAL
+	}
+	if key == nil {
+		// A NULL key selects the first key.
+		attr := BpfMapElemAttr{Map_fd: uint32(m.fd), Value: bpfPtr(bytesPointer(next))}
+		_, err := bpf(BPF_MAP_GET_NEXT_KEY, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+		runtime.KeepAlive(next)
+		return err
+	}
+	return m.elem(BPF_MAP_GET_NEXT_KEY, key, next, 0)
+}
+
+// Pin pins the map at path on a bpf filesystem, so that it outlives its
+// file descriptors.
+func (m *BpfMap) Pin(path string) error {
+	return bpfObjPin(m.fd, path)
+}
+
+// A BpfVerifierError is returned by NewBpfProg if the kernel rejects a
+// program. Log holds the output of the verifier.
+type BpfVerifierError struct {
+	Err Errno
+	Log string
+}
+
+func (e *BpfVerifierError) Error() string {
+	msg := "bpf program rejected: " + e.Err.Error()
+	log := strings.TrimRight(e.Log, "\x00\n")
+	if i := strings.LastIndexByte(log, '\n'); i >= 0 {
+		log = log[i+1:]
+	}
+	if log != "" {
+		msg += ": " + log
+	}
+	return msg
+}
+
+func (e *BpfVerifierError) Unwrap() error { return e.Err }
+
+// Limits of the verifier log buffer allocated by NewBpfProg. Kernels before
+// 5.2 reject logs of 16 MiB or more.
+const (
+	bpfLogSizeMin = 64 << 10
+	bpfLogSizeMax = 16<<20 - 1
+)
+
+// A BpfProg is a loaded BPF program.
+type BpfProg struct {
+	fd int
+}
+
+// NewBpfProg loads the program insns of the given license, such as "GPL",
+// with the other parameters in attr, such as Prog_type. The Insns,
+// Insn_cnt and License fields of attr are ignored.
+//
+// If the program is rejected and attr does not request a verifier log, the
+// program is loaded again with a log, and a *BpfVerifierError holding the
+// log is returned.
+func NewBpfProg(attr *BpfProgLoadAttr, insns []BpfInsn, license string) (*BpfProg, error) {
+	if len(insns) == 0 {
+		return nil, EINVAL
+	}
+	lic, err := ByteSliceFromString(license)
+	if err != nil {
+		return nil, err
+	}
+	a := *attr
+	a.Insns = bpfPtr(unsafe.Pointer(&insns[0]))
+	a.Insn_cnt = uint32(len(insns))
+	a.License = bpfPtr(bytesPointer(lic))
+	fd, err := BpfProgLoad(&a)
+	if err == nil || a.Log_level != 0 || a.Log_buf != 0 {
+		runtime.KeepAlive(insns)
+		runtime.KeepAlive(lic)
+		if err != nil {
+			return nil, err
+		}
+		return &BpfProg{fd: fd}, nil
+	}
+
+	for size := bpfLogSizeMin; ; size *= 4 {
+		if size > bpfLogSizeMax {
+			size = bpfLogSizeMax
+		}
+		log := make([]byte, size)
+		a.Log_level = 1
+		a.Log_buf = bpfPtr(bytesPointer(log))
+		a.Log_size = uint32(len(log))
+		fd, err = BpfProgLoad(&a)
+		runtime.KeepAlive(insns)
+		runtime.KeepAlive(lic)
+		runtime.KeepAlive(log)
+		if err == nil {
+			// The program was accepted the second time around.
+			return &BpfProg{fd: fd}, nil
+		}
+		if err == ENOSPC && size < bpfLogSizeMax {
+			continue
+		}
+		if n := clen(log); n > 0 {
+			return nil, &BpfVerifierError{Err: err.(Errno), Log: string(log[:n])}
+		}
+		return nil, err
+	}
+}
+
+// OpenPinnedBpfProg opens the program pinned at path on a bpf filesystem.
+func OpenPinnedBpfProg(path string) (*BpfProg, error) {
+	fd, err := bpfObjGet(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &BpfProg{fd: fd}, nil
+}
+
+// Fd returns the program file descriptor.
+func (p *BpfProg) Fd() int { return p.fd }
+
+// Close closes the program file descriptor. The program is unloaded once it
+// is no longer attached or pinned.
+func (p *BpfProg) Close() error {
+	return Close(p.fd)
+}
+
+// Pin pins the program at path on a bpf filesystem.
+func (p *BpfProg) Pin(path string) error {
+	return bpfObjPin(p.fd, path)
+}
+
+// AttachSocket attaches the program, which must be of type
+// BPF_PROG_TYPE_SOCKET_FILTER, to the socket fd with SO_ATTACH_BPF.
+func (p *BpfProg) AttachSocket(fd int) error {
+	return SetsockoptInt(fd, SOL_SOCKET, SO_ATTACH_BPF, p.fd)
+}
+
+// AttachCgroup attaches the program to the cgroup v2 directory cgroupFd
+// with the given BPF_CGROUP_* attach type and BPF_F_ALLOW_* flags.
+func (p *BpfProg) AttachCgroup(cgroupFd int, attachType uint32, flags uint32) error {
+	return BpfProgAttach(&BpfProgAttachAttr{
+		Target_fd:     uint32(cgroupFd),
+		Attach_bpf_fd: uint32(p.fd),
+		Attach_type:   attachType,
+		Attach_flags:  flags,
+	})
+}
+
+// DetachCgroup detaches the program from the cgroup v2 directory cgroupFd.
+func (p *BpfProg) DetachCgroup(cgroupFd int, attachType uint32) error {
+	return BpfProgDetach(&BpfProgAttachAttr{
+		Target_fd:     uint32(cgroupFd),
+		Attach_bpf_fd: uint32(p.fd),
+		Attach_type:   attachType,
+	})
+}
+
+// Link attaches the program to targetFd, such as a cgroup directory or,
+// for BPF_XDP, a network interface index, and returns the file descriptor
+// of the link. The program stays attached until the link is closed or
+// detached with BpfLinkDetach.
+func (p *BpfProg) Link(targetFd int, attachType uint32, flags uint32) (linkFd int, err error) {
+	return BpfLinkCreate(&BpfLinkCreateAttr{
+		Prog_fd:     uint32(p.fd),
+		Target_fd:   uint32(targetFd),
+		Attach_type: attachType,
+		Flags:       flags,
+	})
+}
+
+// TestRun runs the program repeat times on data with BPF_PROG_TEST_RUN and
+// returns its return value and the average duration of a run.
+func (p *BpfProg) TestRun(data []byte, repeat uint32) (retval uint32, duration time.Duration, err error) {
+	attr := BpfProgTestRunAttr{
+		Prog_fd:      uint32(p.fd),
+		Data_size_in: uint32(len(data)),
+		Data_in:      bpfPtr(bytesPointer(data)),
+		Repeat:       repeat,
+	}
+	err = BpfProgTestRun(&attr)
+	runtime.KeepAlive(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return attr.Retval, time.Duration(attr.Duration), nil
+}
+
+func bpfObjPin(fd int, path string) error {
+	p, err := ByteSliceFromString(path)
+	if err != nil {
+		return err
+	}
+	err = BpfObjPin(&BpfObjAttr{Pathname: bpfPtr(bytesPointer(p)), Bpf_fd: uint32(fd)})
+	runtime.KeepAlive(p)
+	return err
+}
+
+func bpfObjGet(path string, flags uint32) (int, error) {
+	p, err := ByteSliceFromString(path)
+	if err != nil {
+		return -1, err
+	}
+	fd, err := BpfObjGet(&BpfObjAttr{Pathname: bpfPtr(bytesPointer(p)), File_flags: flags})
+	runtime.KeepAlive(p)
+	return fd, err
+}
@@ -0,0 +1,273 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestBpfStructSizes(t *testing.T) {
+	sizes := []struct {
+		name      string
+		got, want uintptr
+	}{
+		{"BpfInsn", unsafe.Sizeof(unix.BpfInsn{}), unix.SizeofBpfInsn},
+		{"BpfMapCreateAttr", unsafe.Sizeof(unix.BpfMapCreateAttr{}), unix.SizeofBpfMapCreateAttr},
+		{"BpfMapElemAttr", unsafe.Sizeof(unix.BpfMapElemAttr{}), unix.SizeofBpfMapElemAttr},
+		{"BpfProgLoadAttr", unsafe.Sizeof(unix.BpfProgLoadAttr{}), unix.SizeofBpfProgLoadAttr},
+		{"BpfObjAttr", unsafe.Sizeof(unix.BpfObjAttr{}), unix.SizeofBpfObjAttr},
+		{"BpfProgAttachAttr", unsafe.Sizeof(unix.BpfProgAttachAttr{}), unix.SizeofBpfProgAttachAttr},
+		{"BpfProgTestRunAttr", unsafe.Sizeof(unix.BpfProgTestRunAttr{}), unix.SizeofBpfProgTestRunAttr},
+		{"BpfObjGetInfoAttr", unsafe.Sizeof(unix.BpfObjGetInfoAttr{}), unix.SizeofBpfObjGetInfoAttr},
+		{"BpfLinkCreateAttr", unsafe.Sizeof(unix.BpfLinkCreateAttr{}), unix.SizeofBpfLinkCreateAttr},
+		{"BpfMapInfo", unsafe.Sizeof(unix.BpfMapInfo{}), unix.SizeofBpfMapInfo},
+	}
+	for _, s := range sizes {
+		if s.got != s.want {
+			t.Errorf("unexpected %s size: got %d, want %d", s.name, s.got, s.want)
+		}
+	}
+}
+
+func TestBpfInsnRegs(t *testing.T) {
+	var insn unix.BpfInsn
+	insn.SetRegs(unix.BPF_REG_1, unix.BPF_REG_10)
+	if insn.Dst() != unix.BPF_REG_1 || insn.Src() != unix.BPF_REG_10 {
+		t.Fatalf("unexpected registers: dst %d, src %d", insn.Dst(), insn.Src())
+	}
+}
+
+func newTestBpfMap(t *testing.T, attr *unix.BpfMapCreateAttr) *unix.BpfMap {
+	t.Helper()
+	m, err := unix.NewBpfMap(attr)
+	if err == unix.ENOSYS || err == unix.EPERM {
+		t.Skipf("bpf not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("NewBpfMap: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestBpfMap(t *testing.T) {
+	m := newTestBpfMap(t, &unix.BpfMapCreateAttr{
+		Map_type:    unix.BPF_MAP_TYPE_HASH,
+		Key_size:    4,
+		Value_size:  8,
+		Max_entries: 16,
+	})
+
+	key := func(k uint32) []byte {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, k)
+		return b
+	}
+	value := make([]byte, 8)
+	for k := uint32(1); k <= 3; k++ {
+		binary.LittleEndian.PutUint64(value, uint64(k)*100)
+		if err := m.Update(key(k), value, unix.BPF_NOEXIST); err != nil {
+			t.Fatalf("Update(%d): %v", k, err)
+		}
+	}
+	if err := m.Update(key(1), value, unix.BPF_NOEXIST); err != unix.EEXIST {
+		t.Errorf("Update of existing key with BPF_NOEXIST: got %v, want %v", err, unix.EEXIST)
+	}
+	if err := m.Lookup(key(2), value); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if v := binary.LittleEndian.Uint64(value); v != 200 {
+		t.Errorf("unexpected value: got %d, want 200", v)
+	}
+	if err := m.Lookup(key(2), value[:4]); err != unix.EINVAL {
+		t.Errorf("Lookup with short value: got %v, want %v", err, unix.EINVAL)
+	}
+
+	if err := m.Delete(key(2)); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := m.Lookup(key(2), value); err != unix.ENOENT {
+		t.Errorf("Lookup of deleted key: got %v, want %v", err, unix.ENOENT)
+	}
+
+	seen := make(map[uint32]bool)
+	var cur []byte
+	next := make([]byte, 4)
+	for {
+		err := m.NextKey(cur, next)
+		if err == unix.ENOENT {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextKey: %v", err)
+		}
+		seen[binary.LittleEndian.Uint32(next)] = true
+		cur = append(cur[:0], next...)
+	}
+	if len(seen) != 2 || !seen[1] || !seen[3] {
+		t.Errorf("unexpected keys: %v", seen)
+	}
+
+	info, err := unix.BpfMapGetInfo(m.Fd())
+	if err != nil {
+		t.Fatalf("BpfMapGetInfo: %v", err)
+	}
+	if info.Type != unix.BPF_MAP_TYPE_HASH || info.Key_size != 4 || info.Value_size != 8 || info.Max_entries != 16 {
+		t.Errorf("unexpected map info: %+v", info)
+	}
+}
+
+func TestBpfMapPin(t *testing.T) {
+	m := newTestBpfMap(t, &unix.BpfMapCreateAttr{
+		Map_type:    unix.BPF_MAP_TYPE_ARRAY,
+		Key_size:    4,
+		Value_size:  4,
+		Max_entries: 1,
+	})
+	dir := t.TempDir()
+	if err := unix.Mount("bpf", dir, "bpf", 0, ""); err != nil {
+		t.Skipf("cannot mount bpf filesystem: %v", err)
+	}
+	defer unix.Unmount(dir, unix.MNT_DETACH)
+
+	path := filepath.Join(dir, "map")
+	if err := m.Pin(path); err != nil {
+		t.Fatalf("Pin: %v", err)
+	}
+	if err := m.Update(make([]byte, 4), []byte{1, 2, 3, 4}, unix.BPF_ANY); err != nil {
+		t.Fatal(err)
+	}
+	pinned, err := unix.OpenPinnedBpfMap(path, unix.BPF_F_RDONLY)
+	if err != nil {
+		t.Fatalf("OpenPinnedBpfMap: %v", err)
+	}
+	defer pinned.Close()
+	if pinned.Type() != unix.BPF_MAP_TYPE_ARRAY || pinned.KeySize() != 4 || pinned.ValueSize() != 4 {
+		t.Fatalf("unexpected pinned map: type %d, key size %d, value size %d", pinned.Type(), pinned.KeySize(), pinned.ValueSize())
+	}
+	value := make([]byte, 4)
+	if err := pinned.Lookup(make([]byte, 4), value); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if string(value) != "\x01\x02\x03\x04" {
+		t.Errorf("unexpected value: %x", value)
+	}
+}
+
+func bpfInsn(code uint8, dst, src uint8, off int16, imm int32) unix.BpfInsn {
+	insn := unix.BpfInsn{Code: code, Off: off, Imm: imm}
+	insn.SetRegs(dst, src)
+	return insn
+}
+
+func TestBpfProgSocket(t *testing.T) {
+	counter := newTestBpfMap(t, &unix.BpfMapCreateAttr{
+		Map_type:    unix.BPF_MAP_TYPE_ARRAY,
+		Key_size:    4,
+		Value_size:  8,
+		Max_entries: 1,
+	})
+
+	const bpfFuncMapLookupElem = 1
+	insns := []unix.BpfInsn{
+		// r1 = counter
+		bpfInsn(unix.BPF_LD|unix.BPF_DW|unix.BPF_IMM, unix.BPF_REG_1, unix.BPF_PSEUDO_MAP_FD, 0, int32(counter.Fd())),
+		bpfInsn(0, 0, 0, 0, 0),
+		// *(u32 *)(r10 - 4) = 0
+		bpfInsn(unix.BPF_ST|unix.BPF_MEM|unix.BPF_W, unix.BPF_REG_10, 0, -4, 0),
+		// r2 = r10 - 4
+		bpfInsn(unix.BPF_ALU64|unix.BPF_MOV|unix.BPF_X, unix.BPF_REG_2, unix.BPF_REG_10, 0, 0),
+		bpfInsn(unix.BPF_ALU64|unix.BPF_ADD|unix.BPF_K, unix.BPF_REG_2, 0, 0, -4),
+		// r0 = bpf_map_lookup_elem(r1, r2)
+		bpfInsn(unix.BPF_JMP|unix.BPF_CALL, 0, 0, 0, bpfFuncMapLookupElem),
+		// if r0 == 0 goto out
+		bpfInsn(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, unix.BPF_REG_0, 0, 2, 0),
+		// lock *(u64 *)(r0 + 0) += 1
+		bpfInsn(unix.BPF_ALU64|unix.BPF_MOV|unix.BPF_K, unix.BPF_REG_1, 0, 0, 1),
+		bpfInsn(unix.BPF_STX|unix.BPF_ATOMIC|unix.BPF_DW, unix.BPF_REG_0, unix.BPF_REG_1, 0, unix.BPF_ADD),
+		// out: return -1, keeping the whole packet
+		bpfInsn(unix.BPF_ALU64|unix.BPF_MOV|unix.BPF_K, unix.BPF_REG_0, 0, 0, -1),
+		bpfInsn(unix.BPF_JMP|unix.BPF_EXIT, 0, 0, 0, 0),
+	}
+	prog, err := unix.NewBpfProg(&unix.BpfProgLoadAttr{Prog_type: unix.BPF_PROG_TYPE_SOCKET_FILTER}, insns, "GPL")
+	if err != nil {
+		t.Fatalf("NewBpfProg: %v", err)
+	}
+	defer prog.Close()
+
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fd)
+	if err := unix.Bind(fd, &unix.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	sa, err := unix.Getsockname(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prog.AttachSocket(fd); err != nil {
+		t.Fatalf("AttachSocket: %v", err)
+	}
+	const n = 3
+	for i := 0; i < n; i++ {
+		if err := unix.Sendto(fd, []byte("ping"), 0, sa); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := make([]byte, 16)
+	for i := 0; i < n; i++ {
+		if _, _, err := unix.Recvfrom(fd, buf, 0); err != nil {
+			t.Fatalf("Recvfrom %d: %v", i, err)
+		}
+	}
+
+	value := make([]byte, 8)
+	if err := counter.Lookup(make([]byte, 4), value); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	// The counter is in native byte order.
+	if got := *(*uint64)(unsafe.Pointer(&value[0])); got != n {
+		t.Errorf("unexpected packet count: got %d, want %d", got, n)
+	}
+
+	retval, _, err := prog.TestRun(make([]byte, 64), 1)
+	if err != nil {
+		t.Fatalf("TestRun: %v", err)
+	}
+	if retval != 0xffffffff {
+		t.Errorf("unexpected return value of test run: %#x", retval)
+	}
+}
+
+func TestBpfVerifierError(t *testing.T) {
+	// Exit without setting r0.
+	insns := []unix.BpfInsn{bpfInsn(unix.BPF_JMP|unix.BPF_EXIT, 0, 0, 0, 0)}
+	_, err := unix.NewBpfProg(&unix.BpfProgLoadAttr{Prog_type: unix.BPF_PROG_TYPE_SOCKET_FILTER}, insns, "GPL")
+	if err == unix.ENOSYS || err == unix.EPERM {
+		t.Skipf("bpf not available: %v", err)
+	}
+	var verr *unix.BpfVerifierError
+	if !errors.As(err, &verr) {
+		t.Fatalf("unexpected error: got %v, want a BpfVerifierError", err)
+	}
+	if verr.Err != unix.EACCES || !strings.Contains(verr.Log, "R0") {
+		t.Errorf("unexpected verifier error %v with log:\n%s", verr.Err, verr.Log)
+	}
+	if !errors.Is(err, unix.EACCES) {
+		t.Errorf("error does not unwrap to EACCES: %v", err)
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux && !sparc64
+// +build linux,!sparc64
+
+package unix
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// Clone3 creates a child process as described by args (clone3(2)) and
+// returns the process ID of the child in the parent, and 0 in the child.
+// Pointers stored in args, such as Pidfd and Set_tid, must refer to memory
+// that is not moved or freed until Clone3 returns.
+//
+// The child is a copy of the calling thread only, without a working Go
+// runtime: unless it immediately makes an execve(2) or _exit(2) system call,
+// it may only make raw system calls from code that does not grow the stack.
+// Use CloneExec to start a program in a child process created with clone3.
+//
+// Requires kernel >= 5.3.
+//
+//go:norace
+//go:nosplit
+func Clone3(args *CloneArgs) (pid int, err error) {
+	r1, _, e1 := RawSyscall(SYS_CLONE3, uintptr(unsafe.Pointer(args)), unsafe.Sizeof(*args), 0)
+	if e1 != 0 {
+		return -1, errnoErr(e1)
+	}
+	return int(r1), nil
+}
+
+// CloneExecAttr holds the attributes of a process started by CloneExec.
+// Unlike syscall.SysProcAttr, it has no Credential, Setctty, Ctty,
+// Foreground, Noctty or AmbientCaps: use syscall.ForkExec to change the
+// credentials or the controlling terminal of the child.
+type CloneExecAttr struct {
+	Chroot string   // root directory, if not empty
+	Dir    string   // current working directory, if not empty, after Chroot
+	Env    []string // environment
+	// Files are the file descriptors of the child: file descriptor i of
+	// the child is a duplicate of Files[i], or closed if Files[i] is -1.
+	Files []int
+
+	// Cloneflags are CLONE_NEW* flags, such as CLONE_NEWPID, CLONE_NEWNS
+	// or CLONE_NEWNET, to start the child in new namespaces. Other flags
+	// are rejected with EINVAL.
+	Cloneflags uint64
+	// UseCgroupFD starts the child in the cgroup v2 directory open as
+	// CgroupFD, instead of the cgroup of the parent (CLONE_INTO_CGROUP,
+	// kernel >= 5.7).
+	UseCgroupFD bool
+	CgroupFD    int
+	// SetTid requests process IDs for the child: SetTid[0] in the
+	// innermost PID namespace, SetTid[1] in its parent namespace and so
+	// on (kernel >= 5.5). This requires CAP_CHECKPOINT_RESTORE or
+	// CAP_SYS_ADMIN in the user namespaces owning the PID namespaces.
+	SetTid []int
+
+	// UidMappings and GidMappings are the user and group ID mappings of
+	// the new user namespace of the child, with CLONE_NEWUSER. The parent
+	// writes them before the child continues its setup. Unless
+	// GidMappingsEnableSetgroups is set, setgroups(2) is denied in the
+	// namespace, which is required to write GidMappings without CAP_SETGID.
+	UidMappings                []IDMap
+	GidMappings                []IDMap
+	GidMappingsEnableSetgroups bool
+
+	Setsid    bool   // create a new session
+	Setpgid   bool   // set the process group ID to Pgid, or the child's PID if 0
+	Pgid      int    // process group ID, with Setpgid
+	Pdeathsig Signal // signal sent to the child when its parent thread exits
+}
+
+// cloneExecNsFlags are the flags permitted in CloneExecAttr.Cloneflags.
+// Other flags, such as CLONE_VM, CLONE_VFORK or CLONE_THREAD, would make
+// the child share the memory of the parent, on whose stack it runs until
+// it executes the program.
+const cloneExecNsFlags = CLONE_NEWCGROUP | CLONE_NEWIPC | CLONE_NEWNET | CLONE_NEWNS |
+	CLONE_NEWPID | CLONE_NEWTIME | CLONE_NEWUSER | CLONE_NEWUTS
+
+// cloneExecFailed is the exit status of a child which failed to execute
+// the program, as in the syscall package.
+const cloneExecFailed = 253
+
+// CloneExec starts the program argv0 with the arguments argv in a child
+// process created with clone3(2), as described by attr, and returns the
+// process ID of the child and a pidfd referring to it. The pidfd has the
+// close-on-exec flag set, and must be closed by the caller. As with
+// syscall.ForkExec, argv0 is not looked up in PATH, an error during the
+// setup of the child or from execve(2) is reported by CloneExec, and only
+// the file descriptors in attr.Files are passed to the program, provided
+// that all other file descriptors have the close-on-exec flag set.
+//
+// The signal handlers of the parent are reset to their defaults in the
+// child (CLONE_CLEAR_SIGHAND), while ignored signals stay ignored, as they
+// do across execve(2) and with syscall.ForkExec. Requires kernel >= 5.5.
+func CloneExec(argv0 string, argv []string, attr *CloneExecAttr) (pid, pidfd int, err error) {
+	if attr == nil {
+		attr = &CloneExecAttr{}
+	}
+	if attr.Cloneflags&^cloneExecNsFlags != 0 {
+		return -1, -1, EINVAL
+	}
+	argv0p, err := BytePtrFromString(argv0)
+	if err != nil {
+		return -1, -1, err
+	}
+	argvp, err := bytePtrsFromStrings(argv)
+	if err != nil {
+		return -1, -1, err
+	}
+	envvp, err := bytePtrsFromStrings(attr.Env)
+	if err != nil {
+		return -1, -1, err
+	}
+	var chroot, dir *byte
+	if attr.Chroot != "" {
+		if chroot, err = BytePtrFromString(attr.Chroot); err != nil {
+			return -1, -1, err
+		}
+	}
+	if attr.Dir != "" {
+		if dir, err = BytePtrFromString(attr.Dir); err != nil {
+			return -1, -1, err
+		}
+	}
+	fd := make([]int, len(attr.Files))
+	copy(fd, attr.Files)
+	var tids []int32
+	for _, tid := range attr.SetTid {
+		tids = append(tids, int32(tid))
+	}
+
+	flags := attr.Cloneflags | CLONE_PIDFD | CLONE_CLEAR_SIGHAND
+	var cgroup int
+	if attr.UseCgroupFD {
+		flags |= CLONE_INTO_CGROUP
+		cgroup = attr.CgroupFD
+	}
+
+	// Hold the fork lock so that no other file descriptor created without
+	// the close-on-exec flag leaks into the child.
+	syscall.ForkLock.Lock()
+	var p [2]int
+	if err := Pipe2(p[:], O_CLOEXEC); err != nil {
+		syscall.ForkLock.Unlock()
+		return -1, -1, err
+	}
+	// With CLONE_NEWUSER, the child waits for the ID mappings to be written
+	// until the parent closes the write end of sync.
+	sync := [2]int{-1, -1}
+	if flags&CLONE_NEWUSER != 0 {
+		if err := Pipe2(sync[:], O_CLOEXEC); err != nil {
+			Close(p[0])
+			Close(p[1])
+			syscall.ForkLock.Unlock()
+			return -1, -1, err
+		}
+	}
+	mask := blockSignals()
+	ppid, _ := RawSyscallNoError(SYS_GETPID, 0, 0, 0)
+	pid, pidfd, errno := cloneExecChild(flags, cgroup, tids, argv0p, argvp, envvp, chroot, dir, attr, fd, ppid, p[1], sync, &mask)
+	restoreSignals(&mask)
+	Close(p[1])
+	syscall.ForkLock.Unlock()
+	runtime.KeepAlive(tids)
+	defer Close(p[0])
+	if sync[0] >= 0 {
+		Close(sync[0])
+	}
+	if errno != 0 {
+		if sync[1] >= 0 {
+			Close(sync[1])
+		}
+		return -1, -1, errnoErr(errno)
+	}
+
+	if sync[1] >= 0 {
+		setgroups := attr.GidMappingsEnableSetgroups || attr.GidMappings == nil
+		err = writeIDMaps(pid, attr.UidMappings, attr.GidMappings, setgroups)
+		Close(sync[1])
+		if err != nil {
+			Kill(pid, SIGKILL)
+		}
+	}
+	if err == nil {
+		// Wait for the child to execute the program, which closes the pipe,
+		// or to report an error.
+		var e syscall.Errno
+		var n int
+		for {
+			n, err = Read(p[0], (*[unsafe.Sizeof(e)]byte)(unsafe.Pointer(&e))[:])
+			if err != EINTR {
+				break
+			}
+		}
+		if err == nil && n == 0 {
+			return pid, pidfd, nil
+		}
+		if err == nil {
+			if n == int(unsafe.Sizeof(e)) {
+				err = errnoErr(e)
+			} else {
+				err = EPIPE
+			}
+		}
+	}
+	for {
+		if _, err := Wait4(pid, nil, 0, nil); err != EINTR {
+			break
+		}
+	}
+	Close(pidfd)
+	return -1, -1, err
+}
+
+// bytePtrsFromStrings converts ss to a nil-terminated array of pointers to
+// NUL-terminated strings, as expected by execve(2).
+func bytePtrsFromStrings(ss []string) ([]*byte, error) {
+	ps := make([]*byte, len(ss)+1)
+	for i, s := range ss {
+		p, err := BytePtrFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = p
+	}
+	return ps, nil
+}
+
+// cloneExecChild creates the child process with clone3(2) and, in the
+// child, sets it up as described by attr and executes argv0. The child
+// reports an error to the parent by writing it to pipe and exits. If
+// sync[0] is not -1, the child first waits for the parent to close sync[1].
+//
+// All signals are blocked by blockSignals, and the child restores mask
+// once its signal handlers are reset. Pointers are converted to integers in
+// this function only, as its stack frame cannot move.
+//
+//go:norace
+//go:nosplit
+func cloneExecChild(flags uint64, cgroup int, tids []int32, argv0 *byte, argv, envv []*byte, chroot, dir *byte, attr *CloneExecAttr, fd []int, ppid uintptr, pipe int, sync [2]int, mask *Sigset_t) (pid, pidfd int, errno syscall.Errno) {
+	var (
+		args   CloneArgs
+		fd32   int32 = -1
+		r1     uintptr
+		err1   syscall.Errno
+		b      byte
+		nextfd int
+		i      int
+	)
+	args.Flags = flags
+	args.Pidfd = uint64(uintptr(unsafe.Pointer(&fd32)))
+	args.Exit_signal = uint64(SIGCHLD)
+	args.Cgroup = uint64(cgroup)
+	if len(tids) > 0 {
+		args.Set_tid = uint64(uintptr(unsafe.Pointer(&tids[0])))
+		args.Set_tid_size = uint64(len(tids))
+	}
+
+	// The file descriptors to move out of the way start above all those of
+	// the child.
+	nextfd = len(fd)
+	for i = 0; i < len(fd); i++ {
+		if nextfd < fd[i] {
+			nextfd = fd[i]
+		}
+	}
+	nextfd++
+
+	r1, _, err1 = RawSyscall(SYS_CLONE3, uintptr(unsafe.Pointer(&args)), unsafe.Sizeof(args), 0)
+	if err1 != 0 || r1 != 0 {
+		return int(r1), int(fd32), err1
+	}
+
+	// In the child.
+	if sync[0] >= 0 {
+		RawSyscall(SYS_CLOSE, uintptr(sync[1]), 0, 0)
+		for {
+			r1, _, err1 = RawSyscall(SYS_READ, uintptr(sync[0]), uintptr(unsafe.Pointer(&b)), 1)
+			if err1 != EINTR {
+				break
+			}
+		}
+		if err1 != 0 {
+			goto childerror
+		}
+		RawSyscall(SYS_CLOSE, uintptr(sync[0]), 0, 0)
+	}
+	if attr.Setsid {
+		if _, _, err1 = RawSyscall(SYS_SETSID, 0, 0, 0); err1 != 0 {
+			goto childerror
+		}
+	}
+	if attr.Setpgid {
+		if _, _, err1 = RawSyscall(SYS_SETPGID, 0, uintptr(attr.Pgid), 0); err1 != 0 {
+			goto childerror
+		}
+	}
+	if attr.Pdeathsig != 0 {
+		if _, _, err1 = RawSyscall6(SYS_PRCTL, PR_SET_PDEATHSIG, uintptr(attr.Pdeathsig), 0, 0, 0, 0); err1 != 0 {
+			goto childerror
+		}
+		// Signal ourselves if the parent already exited. The parent is not
+		// visible from a new PID namespace.
+		if flags&CLONE_NEWPID == 0 {
+			r1, _ = RawSyscallNoError(SYS_GETPPID, 0, 0, 0)
+			if r1 != ppid {
+				r1, _ = RawSyscallNoError(SYS_GETPID, 0, 0, 0)
+				if _, _, err1 = RawSyscall(SYS_KILL, r1, uintptr(attr.Pdeathsig), 0); err1 != 0 {
+					goto childerror
+				}
+			}
+		}
+	}
+	if chroot != nil {
+		if _, _, err1 = RawSyscall(SYS_CHROOT, uintptr(unsafe.Pointer(chroot)), 0, 0); err1 != 0 {
+			goto childerror
+		}
+	}
+	if dir != nil {
+		if _, _, err1 = RawSyscall(SYS_CHDIR, uintptr(unsafe.Pointer(dir)), 0, 0); err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// Move the pipe and the file descriptors fd[i] < i out of the way, so
+	// that duplicating fd[i] onto i does not close one needed later.
+	if pipe < nextfd {
+		if _, _, err1 = RawSyscall(SYS_DUP3, uintptr(pipe), uintptr(nextfd), O_CLOEXEC); err1 != 0 {
+			goto childerror
+		}
+		pipe = nextfd
+		nextfd++
+	}
+	for i = 0; i < len(fd); i++ {
+		if fd[i] >= 0 && fd[i] < i {
+			if nextfd == pipe {
+				nextfd++
+			}
+			if _, _, err1 = RawSyscall(SYS_DUP3, uintptr(fd[i]), uintptr(nextfd), O_CLOEXEC); err1 != 0 {
+				goto childerror
+			}
+			fd[i] = nextfd
+			nextfd++
+		}
+	}
+	for i = 0; i < len(fd); i++ {
+		if fd[i] == -1 {
+			RawSyscall(SYS_CLOSE, uintptr(i), 0, 0)
+			continue
+		}
+		if fd[i] == i {
+			// dup3 cannot clear the close-on-exec flag of i itself.
+			if _, _, err1 = RawSyscall(fcntl64Syscall, uintptr(i), F_SETFD, 0); err1 != 0 {
+				goto childerror
+			}
+			continue
+		}
+		// The duplicate does not have the close-on-exec flag set.
+		if _, _, err1 = RawSyscall(SYS_DUP3, uintptr(fd[i]), uintptr(i), 0); err1 != 0 {
+			goto childerror
+		}
+	}
+	// The standard file descriptors are not close-on-exec, so close those
+	// not passed to the child.
+	for i = len(fd); i < 3; i++ {
+		RawSyscall(SYS_CLOSE, uintptr(i), 0, 0)
+	}
+
+	// Restore the signal mask of the parent, now that the signal handlers
+	// are reset.
+	if _, _, err1 = RawSyscall6(SYS_RT_SIGPROCMASK, SIG_SETMASK, uintptr(unsafe.Pointer(mask)), 0, _C__NSIG/8, 0, 0); err1 != 0 {
+		goto childerror
+	}
+	_, _, err1 = RawSyscall(SYS_EXECVE, uintptr(unsafe.Pointer(argv0)), uintptr(unsafe.Pointer(&argv[0])), uintptr(unsafe.Pointer(&envv[0])))
+
+childerror:
+	RawSyscall(SYS_WRITE, uintptr(pipe), uintptr(unsafe.Pointer(&err1)), unsafe.Sizeof(err1))
+	for {
+		RawSyscall(SYS_EXIT_GROUP, cloneExecFailed, 0, 0)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux && !sparc64
+// +build linux,!sparc64
+
+package unix_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneExecOutput runs the shell script with CloneExec and returns its
+// standard output.
+func cloneExecOutput(t *testing.T, script string, attr *unix.CloneExecAttr) string {
+	var p [2]int
+	if err := unix.Pipe2(p[:], unix.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(p[0])
+	attr.Files = []int{-1, p[1], 2}
+	pid, pidfd, err := unix.CloneExec("/bin/sh", []string{"sh", "-c", script}, attr)
+	unix.Close(p[1])
+	if err == unix.ENOSYS {
+		t.Skipf("CloneExec: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("CloneExec: %v", err)
+	}
+	defer unix.Close(pidfd)
+
+	out, err := readAll(p[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ws unix.WaitStatus
+	if _, err := unix.Wait4(pid, &ws, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !ws.Exited() || ws.ExitStatus() != 0 {
+		t.Fatalf("%q: got wait status %#x", script, ws)
+	}
+	return strings.TrimSpace(out)
+}
+
+func readAll(fd int) (string, error) {
+	var out []byte
+	buf := make([]byte, 512)
+	for {
+		n, err := unix.Read(fd, buf)
+		if err != nil || n == 0 {
+			return string(out), err
+		}
+		out = append(out, buf[:n]...)
+	}
+}
+
+func TestCloneExec(t *testing.T) {
+	dir := t.TempDir()
+	attr := &unix.CloneExecAttr{Dir: dir, Env: []string{"X=y"}, Setsid: true}
+	out := cloneExecOutput(t, `echo "$PWD $X"; test -e /proc/self/fd/0 || echo closed`, attr)
+	if want := dir + " y\nclosed"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+
+	// The pidfd refers to the child.
+	pid, pidfd, err := unix.CloneExec("/bin/sh", []string{"sh", "-c", "exit 3"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(pidfd)
+	var info unix.Siginfo
+	if err := unix.Waitid(unix.P_PIDFD, pidfd, &info, unix.WEXITED, nil); err != nil {
+		t.Fatalf("Waitid: %v", err)
+	}
+	if info.Signo != int32(unix.SIGCHLD) {
+		t.Errorf("Waitid on pid %d: got %+v", pid, info)
+	}
+
+	// Errors are reported by CloneExec.
+	_, _, err = unix.CloneExec(filepath.Join(dir, "missing"), nil, nil)
+	if err != unix.ENOENT {
+		t.Errorf("missing program: got %v, want %v", err, unix.ENOENT)
+	}
+	_, _, err = unix.CloneExec("/bin/sh", nil, &unix.CloneExecAttr{Dir: filepath.Join(dir, "missing")})
+	if err != unix.ENOENT {
+		t.Errorf("missing directory: got %v, want %v", err, unix.ENOENT)
+	}
+
+	// Only namespace flags are accepted.
+	for _, flag := range []uint64{unix.CLONE_VM, unix.CLONE_VFORK, unix.CLONE_THREAD | unix.CLONE_SIGHAND, unix.CLONE_SETTLS, unix.CLONE_PARENT} {
+		if _, _, err := unix.CloneExec("/bin/sh", nil, &unix.CloneExecAttr{Cloneflags: flag}); err != unix.EINVAL {
+			t.Errorf("Cloneflags %#x: got %v, want %v", flag, err, unix.EINVAL)
+		}
+	}
+}
+
+func TestCloneExecNamespaces(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("new PID namespaces require root")
+	}
+	attr := &unix.CloneExecAttr{Cloneflags: unix.CLONE_NEWPID | unix.CLONE_NEWUTS, SetTid: []int{1}}
+	if out := cloneExecOutput(t, "echo $$", attr); out != "1" {
+		t.Errorf("got PID %q, want 1", out)
+	}
+}
+
+func TestCloneExecUserns(t *testing.T) {
+	if _, err := os.Stat("/proc/self/ns/user"); err != nil {
+		t.Skip("kernel without user namespaces")
+	}
+	attr := &unix.CloneExecAttr{
+		Cloneflags:  unix.CLONE_NEWUSER,
+		UidMappings: []unix.IDMap{{ContainerID: 0, HostID: os.Geteuid(), Size: 1}},
+		GidMappings: []unix.IDMap{{ContainerID: 0, HostID: os.Getegid(), Size: 1}},
+	}
+	_, pidfd, err := unix.CloneExec("/bin/true", []string{"true"}, attr)
+	if err == unix.EPERM || err == unix.ENOSYS {
+		t.Skipf("CloneExec: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("CloneExec: %v", err)
+	}
+	unix.Close(pidfd)
+	if out := cloneExecOutput(t, "id -u; id -g; cat /proc/self/setgroups", attr); out != "0\n0\ndeny" {
+		t.Errorf("got %q, want user and group 0 with setgroups denied", out)
+	}
+
+	// The root directory of the child is changed before the program is
+	// executed.
+	attr.Chroot = t.TempDir()
+	if _, _, err := unix.CloneExec("/bin/sh", nil, attr); err != unix.ENOENT {
+		t.Errorf("Chroot: got %v, want %v", err, unix.ENOENT)
+	}
+}
+
+func TestCloneExecCgroup(t *testing.T) {
+	var root string
+	for _, dir := range []string{"/sys/fs/cgroup", "/sys/fs/cgroup/unified"} {
+		var st unix.Statfs_t
+		if err := unix.Statfs(dir, &st); err == nil && st.Type == unix.CGROUP2_SUPER_MAGIC {
+			root = dir
+			break
+		}
+	}
+	if root == "" {
+		t.Skip("no cgroup v2 hierarchy")
+	}
+	dir := filepath.Join(root, "x-sys-unix-test")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Skipf("creating cgroup: %v", err)
+	}
+	defer os.Remove(dir)
+	fd, err := unix.Open(dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fd)
+
+	attr := &unix.CloneExecAttr{UseCgroupFD: true, CgroupFD: fd}
+	out := cloneExecOutput(t, "grep ^0:: /proc/self/cgroup", attr)
+	if !strings.HasSuffix(out, "/x-sys-unix-test") {
+		t.Errorf("got cgroup %q", out)
+	}
+}
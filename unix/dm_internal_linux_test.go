@@ -0,0 +1,104 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func Test_marshalDmTable(t *testing.T) {
+	targets := []DmTarget{
+		NewDmTarget(0, 2048, DmLinear{Device: "7:0", Offset: 8}),
+		NewDmTarget(2048, 1024, DmZero{}),
+	}
+	b, err := marshalDmTable(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// For DM_TABLE_LOAD, Next is relative to the current spec.
+	off := 0
+	for i, want := range targets {
+		var spec DmTargetSpec
+		copy(structBytes(unsafe.Pointer(&spec), SizeofDmTargetSpec), b[off:])
+		got := DmTarget{
+			Start:  spec.Sector_start,
+			Length: spec.Length,
+			Type:   ByteSliceToString(spec.Target_type[:]),
+			Params: ByteSliceToString(b[off+SizeofDmTargetSpec:]),
+		}
+		if got != want {
+			t.Errorf("target %d: got %+v, want %+v", i, got, want)
+		}
+		if spec.Next%8 != 0 {
+			t.Errorf("target %d: next %d is not aligned", i, spec.Next)
+		}
+		off += int(spec.Next)
+	}
+	if off != len(b) {
+		t.Errorf("got %d bytes, want %d", len(b), off)
+	}
+
+	// For DM_TABLE_STATUS, Next is relative to the start of the data.
+	status := append([]byte(nil), b...)
+	first := (*DmTargetSpec)(unsafe.Pointer(&status[0]))
+	second := (*DmTargetSpec)(unsafe.Pointer(&status[first.Next]))
+	second.Next = uint32(len(status))
+	parsed, err := parseDmTable(status, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, targets) {
+		t.Errorf("parseDmTable: got %+v, want %+v", parsed, targets)
+	}
+
+	if _, err := marshalDmTable([]DmTarget{{Type: "a-very-long-target-type"}}); err != EINVAL {
+		t.Errorf("long target type: got %v, want %v", err, EINVAL)
+	}
+}
+
+func Test_parseDmNameList(t *testing.T) {
+	// Entries are 8-byte aligned: dev, next, name, event number, flags
+	// and UUID.
+	entry := func(dev uint64, name string, event, flags uint32, uuid string) []byte {
+		b := make([]byte, align8(12+len(name)+1))
+		nativeEndian.PutUint64(b, dev)
+		copy(b[12:], name)
+		x := make([]byte, 8)
+		nativeEndian.PutUint32(x, event)
+		nativeEndian.PutUint32(x[4:], flags)
+		b = append(b, x...)
+		if uuid != "" {
+			b = append(b, make([]byte, align8(len(uuid)+1))...)
+			copy(b[len(b)-align8(len(uuid)+1):], uuid)
+		}
+		return b
+	}
+	e1 := entry(Mkdev(253, 0), "root", 3, DM_NAME_LIST_FLAG_HAS_UUID, "LVM-abc")
+	e2 := entry(Mkdev(253, 1), "swap", 0, DM_NAME_LIST_FLAG_DOESNT_HAVE_UUID, "")
+	nativeEndian.PutUint32(e1[8:], uint32(len(e1)))
+	data := append(e1, e2...)
+
+	got, err := parseDmNameList(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DmNameListEntry{
+		{Dev: Mkdev(253, 0), Name: "root", EventNr: 3, UUID: "LVM-abc"},
+		{Dev: Mkdev(253, 1), Name: "swap"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got, err := parseDmNameList(make([]byte, 16)); err != nil || got != nil {
+		t.Errorf("empty list: got %v, %v", got, err)
+	}
+}
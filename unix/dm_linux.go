@@ -0,0 +1,648 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// dmMinBufferSize is the initial size of the ioctl buffer, which is grown
+// when the kernel sets DM_BUFFER_FULL_FLAG.
+const dmMinBufferSize = 16 << 10
+
+// A DmControl is the open device-mapper control device, which creates,
+// configures and removes mapped devices like dmsetup(8).
+type DmControl struct {
+	fd int
+}
+
+// OpenDmControl opens the device-mapper control device,
+// /dev/mapper/control.
+func OpenDmControl() (*DmControl, error) {
+	fd, err := Open("/dev/"+DM_DIR+"/"+DM_CONTROL_NODE, O_RDWR|O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &DmControl{fd: fd}, nil
+}
+
+// Fd returns the file descriptor of the control device.
+func (c *DmControl) Fd() int { return c.fd }
+
+// Close closes the control device.
+func (c *DmControl) Close() error { return Close(c.fd) }
+
+// A DmDeviceInfo describes a mapped device, as returned in the dm_ioctl
+// structure.
+type DmDeviceInfo struct {
+	Name        string
+	UUID        string
+	Dev         uint64 // the device number of the block device
+	OpenCount   int32
+	TargetCount uint32 // the number of targets of the live table
+	EventNr     uint32
+	Flags       uint32 // such as DM_SUSPEND_FLAG, DM_ACTIVE_PRESENT_FLAG or DM_READONLY_FLAG
+}
+
+func newDmDeviceInfo(io *DmIoctl) *DmDeviceInfo {
+	return &DmDeviceInfo{
+		Name:        ByteSliceToString(io.Name[:]),
+		UUID:        ByteSliceToString(io.Uuid[:]),
+		Dev:         io.Dev,
+		OpenCount:   io.Open_count,
+		TargetCount: io.Target_count,
+		EventNr:     io.Event_nr,
+		Flags:       io.Flags,
+	}
+}
+
+// newDmIoctl returns a request for the mapped device with the given name.
+func newDmIoctl(name string, flags uint32) (*DmIoctl, error) {
+	io := &DmIoctl{Flags: flags}
+	if len(name) >= len(io.Name) {
+		return nil, EINVAL
+	}
+	copy(io.Name[:], name)
+	return io, nil
+}
+
+// ioctl performs the device-mapper ioctl req with the header io and the
+// payload following it, and returns the updated header in io and the
+// output data.
+func (c *DmControl) ioctl(req uint, io *DmIoctl, payload []byte) ([]byte, error) {
+	n := SizeofDmIoctl + len(payload)
+	if n < dmMinBufferSize {
+		n = dmMinBufferSize
+	}
+	for {
+		// The kernel aligns the output structures to 8 bytes.
+		buf := make([]uint64, (n+7)/8)
+		b := unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*8)
+		hdr := (*DmIoctl)(unsafe.Pointer(&buf[0]))
+		*hdr = *io
+		// Any 4.x kernel accepts version 4.0.0 and returns its version.
+		hdr.Version = [3]uint32{DM_VERSION_MAJOR, 0, 0}
+		hdr.Data_size = uint32(len(b))
+		hdr.Data_start = SizeofDmIoctl
+		copy(b[SizeofDmIoctl:], payload)
+		if err := ioctlPtr(c.fd, req, unsafe.Pointer(hdr)); err != nil {
+			return nil, err
+		}
+		if hdr.Flags&DM_BUFFER_FULL_FLAG != 0 {
+			n *= 2
+			continue
+		}
+		*io = *hdr
+		if hdr.Data_start > hdr.Data_size || int(hdr.Data_size) > len(b) {
+			return nil, EPROTO
+		}
+		return b[hdr.Data_start:hdr.Data_size], nil
+	}
+}
+
+// Version returns the version of the device-mapper ioctl interface of the
+// kernel (DM_VERSION).
+func (c *DmControl) Version() (major, minor, patchlevel uint32, err error) {
+	var io DmIoctl
+	if _, err := c.ioctl(DM_VERSION, &io, nil); err != nil {
+		return 0, 0, 0, err
+	}
+	return io.Version[0], io.Version[1], io.Version[2], nil
+}
+
+// DevCreate creates a mapped device with the given name and UUID, which may
+// be empty, without a table (DM_DEV_CREATE). The device is created
+// suspended; load a table with TableLoad and activate it with DevResume.
+// Flags may include DM_READONLY_FLAG.
+func (c *DmControl) DevCreate(name, uuid string, flags uint32) (*DmDeviceInfo, error) {
+	io, err := newDmIoctl(name, flags)
+	if err != nil {
+		return nil, err
+	}
+	if len(uuid) >= len(io.Uuid) {
+		return nil, EINVAL
+	}
+	copy(io.Uuid[:], uuid)
+	if _, err := c.ioctl(DM_DEV_CREATE, io, nil); err != nil {
+		return nil, err
+	}
+	return newDmDeviceInfo(io), nil
+}
+
+// DevRemove removes the mapped device with the given name
+// (DM_DEV_REMOVE). With DM_DEFERRED_REMOVE in flags, a device which is
+// still open is removed when it is last closed instead of failing with
+// EBUSY.
+func (c *DmControl) DevRemove(name string, flags uint32) error {
+	io, err := newDmIoctl(name, flags)
+	if err != nil {
+		return err
+	}
+	_, err = c.ioctl(DM_DEV_REMOVE, io, nil)
+	return err
+}
+
+// DevSuspend suspends the mapped device with the given name
+// (DM_DEV_SUSPEND with DM_SUSPEND_FLAG), which queues new I/O. Flags may
+// include DM_NOFLUSH_FLAG and DM_SKIP_LOCKFS_FLAG.
+func (c *DmControl) DevSuspend(name string, flags uint32) error {
+	io, err := newDmIoctl(name, flags|DM_SUSPEND_FLAG)
+	if err != nil {
+		return err
+	}
+	_, err = c.ioctl(DM_DEV_SUSPEND, io, nil)
+	return err
+}
+
+// DevResume resumes the mapped device with the given name
+// (DM_DEV_SUSPEND without DM_SUSPEND_FLAG), making the table loaded with
+// TableLoad live if there is one.
+func (c *DmControl) DevResume(name string, flags uint32) (*DmDeviceInfo, error) {
+	io, err := newDmIoctl(name, flags&^DM_SUSPEND_FLAG)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.ioctl(DM_DEV_SUSPEND, io, nil); err != nil {
+		return nil, err
+	}
+	return newDmDeviceInfo(io), nil
+}
+
+// DevStatus returns information about the mapped device with the given
+// name (DM_DEV_STATUS).
+func (c *DmControl) DevStatus(name string) (*DmDeviceInfo, error) {
+	io, err := newDmIoctl(name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.ioctl(DM_DEV_STATUS, io, nil); err != nil {
+		return nil, err
+	}
+	return newDmDeviceInfo(io), nil
+}
+
+// A DmTarget is a line of a device-mapper table: a range of sectors of the
+// mapped device and the target mapping it.
+type DmTarget struct {
+	Start  uint64 // the first sector
+	Length uint64 // the number of 512-byte sectors
+	Type   string // the target type, such as "linear"
+
+	// Params are the target parameters, in the format of the target
+	// constructor for TableLoad and for TableStatus with
+	// DM_STATUS_TABLE_FLAG, and in the status format of the target
+	// otherwise.
+	Params string
+}
+
+// DmTargetParams are the typed parameters of a target type, such as
+// DmLinear.
+type DmTargetParams interface {
+	// Type returns the target type, such as "linear".
+	Type() string
+	// String returns the parameters in the format of the target
+	// constructor.
+	String() string
+}
+
+// NewDmTarget returns a table line mapping length sectors from start with
+// the target p.
+func NewDmTarget(start, length uint64, p DmTargetParams) DmTarget {
+	return DmTarget{Start: start, Length: length, Type: p.Type(), Params: p.String()}
+}
+
+// DmDevName returns the name of the block device with the given device
+// number in the "major:minor" format of target parameters.
+func DmDevName(dev uint64) string {
+	return strconv.FormatUint(uint64(Major(dev)), 10) + ":" + strconv.FormatUint(uint64(Minor(dev)), 10)
+}
+
+// DmLinear maps sectors linearly onto a device, from Offset.
+type DmLinear struct {
+	Device string // a path or "major:minor"
+	Offset uint64 // in sectors
+}
+
+func (DmLinear) Type() string { return "linear" }
+
+func (t DmLinear) String() string {
+	return t.Device + " " + strconv.FormatUint(t.Offset, 10)
+}
+
+// DmStriped stripes sectors over devices, in chunks of ChunkSectors, which
+// must be a power of two of at least 8.
+type DmStriped struct {
+	ChunkSectors uint64
+	Stripes      []DmLinear
+}
+
+func (DmStriped) Type() string { return "striped" }
+
+func (t DmStriped) String() string {
+	s := strconv.Itoa(len(t.Stripes)) + " " + strconv.FormatUint(t.ChunkSectors, 10)
+	for _, st := range t.Stripes {
+		s += " " + st.String()
+	}
+	return s
+}
+
+// DmZero returns zeros on reads and discards writes.
+type DmZero struct{}
+
+func (DmZero) Type() string   { return "zero" }
+func (DmZero) String() string { return "" }
+
+// DmError fails all I/O.
+type DmError struct{}
+
+func (DmError) Type() string   { return "error" }
+func (DmError) String() string { return "" }
+
+// DmSnapshotOrigin maps a device which has snapshots, copying the chunks
+// to the exception stores of the snapshots before they are overwritten.
+type DmSnapshotOrigin struct {
+	Origin string
+}
+
+func (DmSnapshotOrigin) Type() string { return "snapshot-origin" }
+
+func (t DmSnapshotOrigin) String() string { return t.Origin }
+
+// DmSnapshot is a writable snapshot of Origin whose changes are stored in
+// the exception store COW, in chunks of ChunkSectors. A persistent
+// exception store survives reboots.
+type DmSnapshot struct {
+	Origin       string
+	COW          string
+	Persistent   bool
+	ChunkSectors uint64
+}
+
+func (DmSnapshot) Type() string { return "snapshot" }
+
+func (t DmSnapshot) String() string {
+	p := "N"
+	if t.Persistent {
+		p = "P"
+	}
+	return t.Origin + " " + t.COW + " " + p + " " + strconv.FormatUint(t.ChunkSectors, 10)
+}
+
+// ParseDmTargetParams parses the constructor parameters of the linear,
+// striped, zero, error, snapshot-origin and snapshot target types, as
+// returned by TableStatus with DM_STATUS_TABLE_FLAG. It returns EOPNOTSUPP
+// for other target types.
+func ParseDmTargetParams(typ, params string) (DmTargetParams, error) {
+	f := strings.Fields(params)
+	num := func(s string) (uint64, error) {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, EINVAL
+		}
+		return n, nil
+	}
+	switch typ {
+	case "linear":
+		if len(f) != 2 {
+			return nil, EINVAL
+		}
+		off, err := num(f[1])
+		if err != nil {
+			return nil, err
+		}
+		return DmLinear{Device: f[0], Offset: off}, nil
+	case "striped":
+		if len(f) < 2 {
+			return nil, EINVAL
+		}
+		n, err := num(f[0])
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := num(f[1])
+		if err != nil {
+			return nil, err
+		}
+		// Stripes may be followed by optional parameters.
+		if uint64(len(f)-2) < 2*n {
+			return nil, EINVAL
+		}
+		t := DmStriped{ChunkSectors: chunk, Stripes: make([]DmLinear, n)}
+		for i := range t.Stripes {
+			off, err := num(f[3+2*i])
+			if err != nil {
+				return nil, err
+			}
+			t.Stripes[i] = DmLinear{Device: f[2+2*i], Offset: off}
+		}
+		return t, nil
+	case "zero":
+		return DmZero{}, nil
+	case "error":
+		return DmError{}, nil
+	case "snapshot-origin":
+		if len(f) != 1 {
+			return nil, EINVAL
+		}
+		return DmSnapshotOrigin{Origin: f[0]}, nil
+	case "snapshot":
+		if len(f) != 4 {
+			return nil, EINVAL
+		}
+		chunk, err := num(f[3])
+		if err != nil {
+			return nil, err
+		}
+		// Persistent stores may have an option letter, as in "PO".
+		return DmSnapshot{Origin: f[0], COW: f[1], Persistent: strings.HasPrefix(f[2], "P"), ChunkSectors: chunk}, nil
+	}
+	return nil, EOPNOTSUPP
+}
+
+// DmStripedStatus is the status of a striped target.
+type DmStripedStatus struct {
+	Devices []string
+	Alive   []bool // whether each device is alive ('A') rather than dead ('D')
+}
+
+// ParseDmStripedStatus parses the status of a striped target, as returned
+// by TableStatus.
+func ParseDmStripedStatus(params string) (*DmStripedStatus, error) {
+	f := strings.Fields(params)
+	if len(f) < 1 {
+		return nil, EINVAL
+	}
+	n, err := strconv.Atoi(f[0])
+	if err != nil || n < 0 || len(f) < n+2 || len(f[n+1]) != n {
+		return nil, EINVAL
+	}
+	s := &DmStripedStatus{Devices: f[1 : n+1], Alive: make([]bool, n)}
+	for i, c := range f[n+1] {
+		s.Alive[i] = c == 'A'
+	}
+	return s, nil
+}
+
+// DmSnapshotStatus is the status of a snapshot target.
+type DmSnapshotStatus struct {
+	Allocated uint64 // the sectors of the exception store in use
+	Total     uint64 // the size of the exception store in sectors
+	Metadata  uint64 // the sectors of Allocated used for metadata
+	Invalid   bool   // the snapshot is invalid, such as after an I/O error
+	Overflow  bool   // the exception store is full
+}
+
+// ParseDmSnapshotStatus parses the status of a snapshot target, as returned
+// by TableStatus.
+func ParseDmSnapshotStatus(params string) (*DmSnapshotStatus, error) {
+	switch params {
+	case "Invalid":
+		return &DmSnapshotStatus{Invalid: true}, nil
+	case "Overflow":
+		return &DmSnapshotStatus{Invalid: true, Overflow: true}, nil
+	}
+	var s DmSnapshotStatus
+	f := strings.Fields(params)
+	if len(f) != 2 {
+		return nil, EINVAL
+	}
+	used := strings.SplitN(f[0], "/", 2)
+	if len(used) != 2 {
+		return nil, EINVAL
+	}
+	var err error
+	if s.Allocated, err = strconv.ParseUint(used[0], 10, 64); err != nil {
+		return nil, EINVAL
+	}
+	if s.Total, err = strconv.ParseUint(used[1], 10, 64); err != nil {
+		return nil, EINVAL
+	}
+	if s.Metadata, err = strconv.ParseUint(f[1], 10, 64); err != nil {
+		return nil, EINVAL
+	}
+	return &s, nil
+}
+
+// align8 rounds n up to a multiple of 8, the alignment of the structures
+// in the ioctl data.
+func align8(n int) int {
+	return (n + 7) &^ 7
+}
+
+// marshalDmTable returns the DM_TABLE_LOAD payload for targets: a
+// dm_target_spec for each target, followed by its NUL-terminated
+// parameters, with Next the offset of the following spec.
+func marshalDmTable(targets []DmTarget) ([]byte, error) {
+	var b []byte
+	for _, t := range targets {
+		var spec DmTargetSpec
+		if len(t.Type) >= len(spec.Target_type) {
+			return nil, EINVAL
+		}
+		n := align8(SizeofDmTargetSpec + len(t.Params) + 1)
+		spec.Sector_start = t.Start
+		spec.Length = t.Length
+		spec.Next = uint32(n)
+		copy(spec.Target_type[:], t.Type)
+		off := len(b)
+		b = append(b, make([]byte, n)...)
+		copy(b[off:], structBytes(unsafe.Pointer(&spec), SizeofDmTargetSpec))
+		copy(b[off+SizeofDmTargetSpec:], t.Params)
+	}
+	return b, nil
+}
+
+// parseDmTable parses the output of DM_TABLE_STATUS, in which Next is the
+// offset of the following spec from the start of the data.
+func parseDmTable(data []byte, count uint32) ([]DmTarget, error) {
+	targets := make([]DmTarget, 0, count)
+	off := 0
+	for i := uint32(0); i < count; i++ {
+		if off+SizeofDmTargetSpec > len(data) {
+			return nil, EPROTO
+		}
+		var spec DmTargetSpec
+		copy(structBytes(unsafe.Pointer(&spec), SizeofDmTargetSpec), data[off:])
+		params := data[off+SizeofDmTargetSpec:]
+		targets = append(targets, DmTarget{
+			Start:  spec.Sector_start,
+			Length: spec.Length,
+			Type:   ByteSliceToString(spec.Target_type[:]),
+			Params: ByteSliceToString(params),
+		})
+		if int(spec.Next) <= off {
+			break
+		}
+		off = int(spec.Next)
+	}
+	return targets, nil
+}
+
+// TableLoad loads targets as the inactive table of the mapped device with
+// the given name (DM_TABLE_LOAD), which becomes live on DevResume. Flags
+// may include DM_READONLY_FLAG.
+func (c *DmControl) TableLoad(name string, targets []DmTarget, flags uint32) error {
+	io, err := newDmIoctl(name, flags)
+	if err != nil {
+		return err
+	}
+	payload, err := marshalDmTable(targets)
+	if err != nil {
+		return err
+	}
+	io.Target_count = uint32(len(targets))
+	_, err = c.ioctl(DM_TABLE_LOAD, io, payload)
+	return err
+}
+
+// TableClear discards the inactive table of the mapped device with the
+// given name (DM_TABLE_CLEAR).
+func (c *DmControl) TableClear(name string) error {
+	io, err := newDmIoctl(name, 0)
+	if err != nil {
+		return err
+	}
+	_, err = c.ioctl(DM_TABLE_CLEAR, io, nil)
+	return err
+}
+
+// TableStatus returns the targets of the live table of the mapped device
+// with the given name (DM_TABLE_STATUS), with their status, or with their
+// constructor parameters if flags includes DM_STATUS_TABLE_FLAG. With
+// DM_QUERY_INACTIVE_TABLE_FLAG, it returns the inactive table.
+func (c *DmControl) TableStatus(name string, flags uint32) ([]DmTarget, error) {
+	io, err := newDmIoctl(name, flags)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.ioctl(DM_TABLE_STATUS, io, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseDmTable(data, io.Target_count)
+}
+
+// A DmNameListEntry is a mapped device, as returned by ListDevices.
+type DmNameListEntry struct {
+	Dev     uint64
+	Name    string
+	EventNr uint32
+	UUID    string
+}
+
+// parseDmNameList parses the output of DM_LIST_DEVICES: dm_name_list
+// structures, each followed by the event number, flags and UUID on kernels
+// supporting DM_UUID_FLAG, with Next the offset of the following entry
+// from this one.
+func parseDmNameList(data []byte) ([]DmNameListEntry, error) {
+	const nameOff = 12 // offsetof(struct dm_name_list, name)
+	var list []DmNameListEntry
+	off := 0
+	for {
+		if off+nameOff > len(data) {
+			return nil, EPROTO
+		}
+		dev := nativeEndian.Uint64(data[off:])
+		next := int(nativeEndian.Uint32(data[off+8:]))
+		if dev == 0 && off == 0 {
+			// No devices.
+			return nil, nil
+		}
+		end := len(data)
+		if next != 0 {
+			end = off + next
+		}
+		if end > len(data) || end < off+nameOff {
+			return nil, EPROTO
+		}
+		rest := data[off+nameOff : end]
+		name := ByteSliceToString(rest)
+		e := DmNameListEntry{Dev: dev, Name: name}
+		// The extra fields are aligned relative to the buffer, whose data
+		// starts 8-byte aligned.
+		x := align8(off+nameOff+len(name)+1) - off - nameOff
+		if x+8 <= len(rest) {
+			e.EventNr = nativeEndian.Uint32(rest[x:])
+			if nativeEndian.Uint32(rest[x+4:])&DM_NAME_LIST_FLAG_HAS_UUID != 0 {
+				e.UUID = ByteSliceToString(rest[x+8:])
+			}
+		}
+		list = append(list, e)
+		if next == 0 {
+			return list, nil
+		}
+		off = end
+	}
+}
+
+// ListDevices returns the mapped devices (DM_LIST_DEVICES).
+func (c *DmControl) ListDevices() ([]DmNameListEntry, error) {
+	io := &DmIoctl{Flags: DM_UUID_FLAG}
+	data, err := c.ioctl(DM_LIST_DEVICES, io, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseDmNameList(data)
+}
+
+// A DmTargetVersion is a target type registered in the kernel, as returned
+// by ListVersions.
+type DmTargetVersion struct {
+	Name    string
+	Version [3]uint32
+}
+
+// ListVersions returns the target types registered in the kernel
+// (DM_LIST_VERSIONS). Target types provided by modules which are not
+// loaded are not listed.
+func (c *DmControl) ListVersions() ([]DmTargetVersion, error) {
+	var io DmIoctl
+	data, err := c.ioctl(DM_LIST_VERSIONS, &io, nil)
+	if err != nil {
+		return nil, err
+	}
+	const nameOff = 16 // offsetof(struct dm_target_versions, name)
+	var list []DmTargetVersion
+	for off := 0; off+nameOff <= len(data); {
+		var v DmTargetVersions
+		copy(structBytes(unsafe.Pointer(&v), nameOff), data[off:])
+		list = append(list, DmTargetVersion{
+			Name:    ByteSliceToString(data[off+nameOff:]),
+			Version: v.Version,
+		})
+		if v.Next == 0 {
+			break
+		}
+		off += int(v.Next)
+	}
+	return list, nil
+}
+
+// TargetMsg sends the message msg to the target of the mapped device with
+// the given name at the given sector (DM_TARGET_MSG), such as "@stats_list"
+// or a target-specific message, and returns the response of the target, if
+// any.
+func (c *DmControl) TargetMsg(name string, sector uint64, msg string) (string, error) {
+	io, err := newDmIoctl(name, 0)
+	if err != nil {
+		return "", err
+	}
+	payload := make([]byte, 8+len(msg)+1)
+	nativeEndian.PutUint64(payload, sector)
+	copy(payload[8:], msg)
+	data, err := c.ioctl(DM_TARGET_MSG, io, payload)
+	if err != nil {
+		return "", err
+	}
+	if io.Flags&DM_DATA_OUT_FLAG == 0 {
+		return "", nil
+	}
+	return ByteSliceToString(data), nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseDmTargetParams(t *testing.T) {
+	tests := []unix.DmTargetParams{
+		unix.DmLinear{Device: "8:0", Offset: 2048},
+		unix.DmStriped{ChunkSectors: 128, Stripes: []unix.DmLinear{{"8:0", 0}, {"8:16", 64}}},
+		unix.DmZero{},
+		unix.DmError{},
+		unix.DmSnapshotOrigin{Origin: "253:0"},
+		unix.DmSnapshot{Origin: "253:0", COW: "253:1", Persistent: true, ChunkSectors: 16},
+	}
+	for _, want := range tests {
+		got, err := unix.ParseDmTargetParams(want.Type(), want.String())
+		if err != nil {
+			t.Errorf("%s %q: %v", want.Type(), want.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s %q: got %+v, want %+v", want.Type(), want.String(), got, want)
+		}
+	}
+
+	if _, err := unix.ParseDmTargetParams("linear", "8:0"); err != unix.EINVAL {
+		t.Errorf("short linear: got %v, want %v", err, unix.EINVAL)
+	}
+	if _, err := unix.ParseDmTargetParams("crypt", ""); err != unix.EOPNOTSUPP {
+		t.Errorf("crypt: got %v, want %v", err, unix.EOPNOTSUPP)
+	}
+}
+
+func TestParseDmStatus(t *testing.T) {
+	ss, err := unix.ParseDmSnapshotStatus("64/2048 16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *ss != (unix.DmSnapshotStatus{Allocated: 64, Total: 2048, Metadata: 16}) {
+		t.Errorf("got %+v", ss)
+	}
+	if ss, err := unix.ParseDmSnapshotStatus("Overflow"); err != nil || !ss.Overflow || !ss.Invalid {
+		t.Errorf("Overflow: got %+v, %v", ss, err)
+	}
+
+	st, err := unix.ParseDmStripedStatus("2 8:0 8:16 AD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &unix.DmStripedStatus{Devices: []string{"8:0", "8:16"}, Alive: []bool{true, false}}
+	if !reflect.DeepEqual(st, want) {
+		t.Errorf("got %+v, want %+v", st, want)
+	}
+}
+
+func TestDmControl(t *testing.T) {
+	c, err := unix.OpenDmControl()
+	if err != nil {
+		t.Skipf("OpenDmControl: %v", err)
+	}
+	defer c.Close()
+
+	major, _, _, err := c.Version()
+	if err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	if major != unix.DM_VERSION_MAJOR {
+		t.Errorf("got major version %d", major)
+	}
+
+	const name = "x-sys-unix-test"
+	info, err := c.DevCreate(name, "", 0)
+	if err != nil {
+		t.Fatalf("DevCreate: %v", err)
+	}
+	defer c.DevRemove(name, 0)
+	if info.Name != name || info.Dev == 0 {
+		t.Errorf("DevCreate: got %+v", info)
+	}
+
+	table := []unix.DmTarget{
+		unix.NewDmTarget(0, 1024, unix.DmZero{}),
+		unix.NewDmTarget(1024, 1024, unix.DmError{}),
+	}
+	if err := c.TableLoad(name, table, 0); err != nil {
+		t.Fatalf("TableLoad: %v", err)
+	}
+	if info, err = c.DevResume(name, 0); err != nil {
+		t.Fatalf("DevResume: %v", err)
+	}
+	if info.TargetCount != 2 || info.Flags&unix.DM_SUSPEND_FLAG != 0 {
+		t.Errorf("DevResume: got %+v", info)
+	}
+
+	got, err := c.TableStatus(name, unix.DM_STATUS_TABLE_FLAG)
+	if err != nil {
+		t.Fatalf("TableStatus: %v", err)
+	}
+	if !reflect.DeepEqual(got, table) {
+		t.Errorf("TableStatus: got %+v, want %+v", got, table)
+	}
+
+	devs, err := c.ListDevices()
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+	found := false
+	for _, d := range devs {
+		if d.Name == name {
+			found = d.Dev == info.Dev
+		}
+	}
+	if !found {
+		t.Errorf("ListDevices: %s not found in %+v", name, devs)
+	}
+
+	if err := c.DevSuspend(name, 0); err != nil {
+		t.Fatalf("DevSuspend: %v", err)
+	}
+	if info, err = c.DevStatus(name); err != nil {
+		t.Fatalf("DevStatus: %v", err)
+	}
+	if info.Flags&unix.DM_SUSPEND_FLAG == 0 {
+		t.Errorf("DevStatus after DevSuspend: got flags %#x", info.Flags)
+	}
+	if err := c.DevRemove(name, 0); err != nil {
+		t.Fatalf("DevRemove: %v", err)
+	}
+}
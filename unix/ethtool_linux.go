@@ -0,0 +1,518 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "unsafe"
+
+// An EthtoolLinkKsettings holds the link settings and link mode bitmaps of a
+// network device, as used by IoctlGetEthtoolLinkSettings and
+// IoctlSetEthtoolLinkSettings. Link mode ETHTOOL_LINK_MODE_*_BIT n is bit
+// n%32 of word n/32 of the bitmaps.
+type EthtoolLinkKsettings struct {
+	Settings      EthtoolLinkSettings
+	Supported     []uint32
+	Advertising   []uint32
+	LpAdvertising []uint32
+}
+
+// An EthtoolConn queries and configures network devices with the ethtool
+// generic netlink family, which is the netlink counterpart of the
+// SIOCETHTOOL ioctls. Devices are identified by name.
+type EthtoolConn struct {
+	*GenlConn
+	family *GenlFamily
+}
+
+// NewEthtoolConn opens a generic netlink socket and resolves the ethtool
+// family. It returns ENOENT if the kernel was built without
+// CONFIG_ETHTOOL_NETLINK.
+func NewEthtoolConn() (*EthtoolConn, error) {
+	c, err := NewGenlConn()
+	if err != nil {
+		return nil, err
+	}
+	f, err := c.Family(ETHTOOL_GENL_NAME)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &EthtoolConn{GenlConn: c, family: f}, nil
+}
+
+// All request headers, such as ETHTOOL_A_LINKMODES_HEADER, have the same
+// attribute type.
+const ethtoolHeader = ETHTOOL_A_HEADER_UNSPEC + 1
+
+// request sends cmd for the device ifname, if not empty, with the attributes
+// added by attrs after the request header and returns the attributes of
+// the reply.
+func (c *EthtoolConn) request(cmd uint8, ifname string, attrs func(*NetlinkAttrEncoder)) (*NetlinkAttrDecoder, error) {
+	// The header is required, even if empty.
+	var e NetlinkAttrEncoder
+	e.Nested(ethtoolHeader)
+	if ifname != "" {
+		e.String(ETHTOOL_A_HEADER_DEV_NAME, ifname)
+	}
+	e.EndNested()
+	if attrs != nil {
+		attrs(&e)
+	}
+	msgs, err := c.Request(c.family.ID, cmd, ETHTOOL_GENL_VERSION, 0, nil, &e)
+	if err != nil {
+		return nil, err
+	}
+	for i := range msgs {
+		if msgs[i].Family == c.family.ID {
+			return msgs[i].Attrs(0), nil
+		}
+	}
+	// Set commands do not always reply.
+	return NewNetlinkAttrDecoder(nil), nil
+}
+
+// An EthtoolBit is a bit of an EthtoolBitset.
+type EthtoolBit struct {
+	Index uint32 // ETHTOOL_A_BITSET_BIT_INDEX
+	Name  string // ETHTOOL_A_BITSET_BIT_NAME
+	Value bool   // ETHTOOL_A_BITSET_BIT_VALUE
+}
+
+// An EthtoolBitset is a set of named bits, such as link modes or features.
+// A bitset with a mask lists the bits of the mask with their values; a
+// bitset without a mask lists the bits which are set.
+type EthtoolBitset struct {
+	Size uint32 // ETHTOOL_A_BITSET_SIZE, the number of bits
+	Bits []EthtoolBit
+}
+
+// Lookup returns the value of the bit with the given name and whether the
+// bit is listed.
+func (s *EthtoolBitset) Lookup(name string) (value, ok bool) {
+	if s == nil {
+		return false, false
+	}
+	for _, b := range s.Bits {
+		if b.Name == name {
+			return b.Value, true
+		}
+	}
+	return false, false
+}
+
+// Names returns the names of the bits which are set.
+func (s *EthtoolBitset) Names() []string {
+	if s == nil {
+		return nil
+	}
+	var names []string
+	for _, b := range s.Bits {
+		if b.Value {
+			names = append(names, b.Name)
+		}
+	}
+	return names
+}
+
+// parseEthtoolBitset parses a bitset in either the verbose form, with named
+// bits, or the compact form, with value and mask bitmaps.
+func parseEthtoolBitset(d *NetlinkAttrDecoder) (*EthtoolBitset, error) {
+	s := new(EthtoolBitset)
+	var nomask bool
+	var value, mask []byte
+	for d.Next() {
+		switch d.Type() {
+		case ETHTOOL_A_BITSET_NOMASK:
+			nomask = true
+		case ETHTOOL_A_BITSET_SIZE:
+			s.Size = d.Uint32()
+		case ETHTOOL_A_BITSET_VALUE:
+			value = d.Value()
+		case ETHTOOL_A_BITSET_MASK:
+			mask = d.Value()
+		case ETHTOOL_A_BITSET_BITS:
+			bits := d.Nested()
+			for bits.Next() {
+				if bits.Type() != ETHTOOL_A_BITSET_BITS_BIT {
+					continue
+				}
+				var b EthtoolBit
+				bd := bits.Nested()
+				for bd.Next() {
+					switch bd.Type() {
+					case ETHTOOL_A_BITSET_BIT_INDEX:
+						b.Index = bd.Uint32()
+					case ETHTOOL_A_BITSET_BIT_NAME:
+						b.Name = bd.String()
+					case ETHTOOL_A_BITSET_BIT_VALUE:
+						b.Value = true
+					}
+				}
+				if err := bd.Err(); err != nil {
+					return nil, err
+				}
+				s.Bits = append(s.Bits, b)
+			}
+			if err := bits.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if nomask {
+		for i := range s.Bits {
+			s.Bits[i].Value = true
+		}
+		mask = value
+	}
+	if s.Bits == nil && mask != nil {
+		// Compact bitmaps are arrays of 32-bit words in host byte order.
+		isSet := func(b []byte, i uint32) bool {
+			w := int(i/32) * 4
+			return w+4 <= len(b) && nativeEndian.Uint32(b[w:])&(1<<(i%32)) != 0
+		}
+		for i := uint32(0); i < s.Size; i++ {
+			if isSet(mask, i) {
+				s.Bits = append(s.Bits, EthtoolBit{Index: i, Value: isSet(value, i)})
+			}
+		}
+	}
+	return s, nil
+}
+
+// encodeEthtoolBitset adds a verbose bitset with a mask, which changes the
+// named bits only.
+func encodeEthtoolBitset(e *NetlinkAttrEncoder, typ uint16, bits map[string]bool) {
+	e.Nested(typ)
+	e.Nested(ETHTOOL_A_BITSET_BITS)
+	for name, v := range bits {
+		e.Nested(ETHTOOL_A_BITSET_BITS_BIT)
+		e.String(ETHTOOL_A_BITSET_BIT_NAME, name)
+		if v {
+			e.Flag(ETHTOOL_A_BITSET_BIT_VALUE)
+		}
+		e.EndNested()
+	}
+	e.EndNested()
+	e.EndNested()
+}
+
+// Strings returns the strings of the string set, such as ETH_SS_FEATURES
+// or ETH_SS_LINK_MODES, of the device ifname (ETHTOOL_MSG_STRSET_GET). If
+// ifname is empty, it returns the global string set.
+func (c *EthtoolConn) Strings(ifname string, set uint32) ([]string, error) {
+	d, err := c.request(ETHTOOL_MSG_STRSET_GET, ifname, func(e *NetlinkAttrEncoder) {
+		e.Nested(ETHTOOL_A_STRSET_STRINGSETS)
+		e.Nested(ETHTOOL_A_STRINGSETS_STRINGSET)
+		e.Uint32(ETHTOOL_A_STRINGSET_ID, set)
+		e.EndNested()
+		e.EndNested()
+	})
+	if err != nil {
+		return nil, err
+	}
+	var strs []string
+	for d.Next() {
+		if d.Type() != ETHTOOL_A_STRSET_STRINGSETS {
+			continue
+		}
+		sets := d.Nested()
+		for sets.Next() {
+			sd := sets.Nested()
+			for sd.Next() {
+				switch sd.Type() {
+				case ETHTOOL_A_STRINGSET_COUNT:
+					if n := int(sd.Uint32()); n > len(strs) {
+						strs = append(strs, make([]string, n-len(strs))...)
+					}
+				case ETHTOOL_A_STRINGSET_STRINGS:
+					ss := sd.Nested()
+					for ss.Next() {
+						var idx uint32
+						var val string
+						st := ss.Nested()
+						for st.Next() {
+							switch st.Type() {
+							case ETHTOOL_A_STRING_INDEX:
+								idx = st.Uint32()
+							case ETHTOOL_A_STRING_VALUE:
+								val = st.String()
+							}
+						}
+						if err := st.Err(); err != nil {
+							return nil, err
+						}
+						if int(idx) >= len(strs) {
+							strs = append(strs, make([]string, int(idx)+1-len(strs))...)
+						}
+						strs[idx] = val
+					}
+					if err := ss.Err(); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if err := sd.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if err := sets.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return strs, d.Err()
+}
+
+// EthtoolLinkModes holds the link mode settings of a network device.
+type EthtoolLinkModes struct {
+	Autoneg uint8  // ETHTOOL_A_LINKMODES_AUTONEG, AUTONEG_DISABLE or AUTONEG_ENABLE
+	Speed   uint32 // ETHTOOL_A_LINKMODES_SPEED, in Mb/s, or SPEED_UNKNOWN
+	Duplex  uint8  // ETHTOOL_A_LINKMODES_DUPLEX, such as DUPLEX_FULL
+	Lanes   uint32 // ETHTOOL_A_LINKMODES_LANES, or 0 if unknown
+
+	// Ours holds the supported link modes, with the advertised ones set
+	// (ETHTOOL_A_LINKMODES_OURS). Peer holds the link modes advertised by
+	// the link partner (ETHTOOL_A_LINKMODES_PEER), if known.
+	Ours *EthtoolBitset
+	Peer *EthtoolBitset
+}
+
+// LinkModes returns the link mode settings of the device ifname
+// (ETHTOOL_MSG_LINKMODES_GET).
+func (c *EthtoolConn) LinkModes(ifname string) (*EthtoolLinkModes, error) {
+	d, err := c.request(ETHTOOL_MSG_LINKMODES_GET, ifname, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := new(EthtoolLinkModes)
+	for d.Next() {
+		switch d.Type() {
+		case ETHTOOL_A_LINKMODES_AUTONEG:
+			m.Autoneg = d.Uint8()
+		case ETHTOOL_A_LINKMODES_SPEED:
+			m.Speed = d.Uint32()
+		case ETHTOOL_A_LINKMODES_DUPLEX:
+			m.Duplex = d.Uint8()
+		case ETHTOOL_A_LINKMODES_LANES:
+			m.Lanes = d.Uint32()
+		case ETHTOOL_A_LINKMODES_OURS:
+			if m.Ours, err = parseEthtoolBitset(d.Nested()); err != nil {
+				return nil, err
+			}
+		case ETHTOOL_A_LINKMODES_PEER:
+			if m.Peer, err = parseEthtoolBitset(d.Nested()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, d.Err()
+}
+
+// SetLinkModes changes the link mode settings of the device ifname
+// (ETHTOOL_MSG_LINKMODES_SET). The speed is set unless it is 0 or
+// SPEED_UNKNOWN, the duplex unless it is DUPLEX_UNKNOWN, the lanes unless
+// they are 0, and the advertised link modes in m.Ours, if not nil. Peer is
+// ignored.
+func (c *EthtoolConn) SetLinkModes(ifname string, m *EthtoolLinkModes) error {
+	_, err := c.request(ETHTOOL_MSG_LINKMODES_SET, ifname, func(e *NetlinkAttrEncoder) {
+		e.Uint8(ETHTOOL_A_LINKMODES_AUTONEG, m.Autoneg)
+		if m.Speed != 0 && int32(m.Speed) != SPEED_UNKNOWN {
+			e.Uint32(ETHTOOL_A_LINKMODES_SPEED, m.Speed)
+		}
+		if m.Duplex != DUPLEX_UNKNOWN {
+			e.Uint8(ETHTOOL_A_LINKMODES_DUPLEX, m.Duplex)
+		}
+		if m.Lanes != 0 {
+			e.Uint32(ETHTOOL_A_LINKMODES_LANES, m.Lanes)
+		}
+		if m.Ours != nil {
+			bits := make(map[string]bool, len(m.Ours.Bits))
+			for _, b := range m.Ours.Bits {
+				bits[b.Name] = b.Value
+			}
+			encodeEthtoolBitset(e, ETHTOOL_A_LINKMODES_OURS, bits)
+		}
+	})
+	return err
+}
+
+// EthtoolFeatures holds the offload features of a network device.
+type EthtoolFeatures struct {
+	Hw       *EthtoolBitset // ETHTOOL_A_FEATURES_HW, the features which can be changed
+	Wanted   *EthtoolBitset // ETHTOOL_A_FEATURES_WANTED, the features requested by the user
+	Active   *EthtoolBitset // ETHTOOL_A_FEATURES_ACTIVE
+	NoChange *EthtoolBitset // ETHTOOL_A_FEATURES_NOCHANGE, the features which are fixed
+}
+
+// Features returns the offload features of the device ifname
+// (ETHTOOL_MSG_FEATURES_GET).
+func (c *EthtoolConn) Features(ifname string) (*EthtoolFeatures, error) {
+	d, err := c.request(ETHTOOL_MSG_FEATURES_GET, ifname, nil)
+	if err != nil {
+		return nil, err
+	}
+	f := new(EthtoolFeatures)
+	for d.Next() {
+		var p **EthtoolBitset
+		switch d.Type() {
+		case ETHTOOL_A_FEATURES_HW:
+			p = &f.Hw
+		case ETHTOOL_A_FEATURES_WANTED:
+			p = &f.Wanted
+		case ETHTOOL_A_FEATURES_ACTIVE:
+			p = &f.Active
+		case ETHTOOL_A_FEATURES_NOCHANGE:
+			p = &f.NoChange
+		default:
+			continue
+		}
+		if *p, err = parseEthtoolBitset(d.Nested()); err != nil {
+			return nil, err
+		}
+	}
+	return f, d.Err()
+}
+
+// SetFeatures enables or disables the features of the device ifname with
+// the given names, such as "rx-gro" (ETHTOOL_MSG_FEATURES_SET). The other
+// features are left unchanged. The kernel does not report an error if a
+// wanted feature cannot be activated; Features returns the result.
+func (c *EthtoolConn) SetFeatures(ifname string, features map[string]bool) error {
+	_, err := c.request(ETHTOOL_MSG_FEATURES_SET, ifname, func(e *NetlinkAttrEncoder) {
+		encodeEthtoolBitset(e, ETHTOOL_A_FEATURES_WANTED, features)
+	})
+	return err
+}
+
+// decodeUint32s sets the fields of the struct of uint32 fields at p, such
+// as EthtoolRingparam, from the attributes of types first to first+n-1.
+// The Cmd field of the struct is skipped.
+func decodeUint32s(d *NetlinkAttrDecoder, p unsafe.Pointer, first uint16, n int) error {
+	fields := unsafe.Slice((*uint32)(p), n+1)[1:]
+	for d.Next() {
+		if i := int(d.Type()) - int(first); i >= 0 && i < n {
+			fields[i] = d.Uint32()
+		}
+	}
+	return d.Err()
+}
+
+// Rings returns the ring sizes of the device ifname
+// (ETHTOOL_MSG_RINGS_GET). The Cmd field of the result is not set.
+func (c *EthtoolConn) Rings(ifname string) (*EthtoolRingparam, error) {
+	d, err := c.request(ETHTOOL_MSG_RINGS_GET, ifname, nil)
+	if err != nil {
+		return nil, err
+	}
+	r := new(EthtoolRingparam)
+	// The attributes ETHTOOL_A_RINGS_RX_MAX to ETHTOOL_A_RINGS_TX are in
+	// the order of the fields.
+	if err := decodeUint32s(d, unsafe.Pointer(r), ETHTOOL_A_RINGS_RX_MAX, 8); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetRings sets the ring sizes of the device ifname
+// (ETHTOOL_MSG_RINGS_SET). The maximums are ignored.
+func (c *EthtoolConn) SetRings(ifname string, r *EthtoolRingparam) error {
+	_, err := c.request(ETHTOOL_MSG_RINGS_SET, ifname, func(e *NetlinkAttrEncoder) {
+		e.Uint32(ETHTOOL_A_RINGS_RX, r.Rx_pending)
+		e.Uint32(ETHTOOL_A_RINGS_RX_MINI, r.Rx_mini_pending)
+		e.Uint32(ETHTOOL_A_RINGS_RX_JUMBO, r.Rx_jumbo_pending)
+		e.Uint32(ETHTOOL_A_RINGS_TX, r.Tx_pending)
+	})
+	return err
+}
+
+// Channels returns the channel counts of the device ifname
+// (ETHTOOL_MSG_CHANNELS_GET). The Cmd field of the result is not set.
+func (c *EthtoolConn) Channels(ifname string) (*EthtoolChannels, error) {
+	d, err := c.request(ETHTOOL_MSG_CHANNELS_GET, ifname, nil)
+	if err != nil {
+		return nil, err
+	}
+	ch := new(EthtoolChannels)
+	if err := decodeUint32s(d, unsafe.Pointer(ch), ETHTOOL_A_CHANNELS_RX_MAX, 8); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SetChannels sets the channel counts of the device ifname
+// (ETHTOOL_MSG_CHANNELS_SET). The maximums are ignored.
+func (c *EthtoolConn) SetChannels(ifname string, ch *EthtoolChannels) error {
+	_, err := c.request(ETHTOOL_MSG_CHANNELS_SET, ifname, func(e *NetlinkAttrEncoder) {
+		e.Uint32(ETHTOOL_A_CHANNELS_RX_COUNT, ch.Rx_count)
+		e.Uint32(ETHTOOL_A_CHANNELS_TX_COUNT, ch.Tx_count)
+		e.Uint32(ETHTOOL_A_CHANNELS_OTHER_COUNT, ch.Other_count)
+		e.Uint32(ETHTOOL_A_CHANNELS_COMBINED_COUNT, ch.Combined_count)
+	})
+	return err
+}
+
+// The attributes ETHTOOL_A_COALESCE_RX_USECS to
+// ETHTOOL_A_COALESCE_RATE_SAMPLE_INTERVAL are in the order of the fields of
+// EthtoolCoalesce; the adaptive flags are u8 attributes.
+const ethtoolCoalesceFields = ETHTOOL_A_COALESCE_RATE_SAMPLE_INTERVAL - ETHTOOL_A_COALESCE_RX_USECS + 1
+
+func isEthtoolCoalesceFlag(typ uint16) bool {
+	return typ == ETHTOOL_A_COALESCE_USE_ADAPTIVE_RX || typ == ETHTOOL_A_COALESCE_USE_ADAPTIVE_TX
+}
+
+// Coalesce returns the interrupt coalescing parameters of the device
+// ifname (ETHTOOL_MSG_COALESCE_GET). The Cmd field of the result is not
+// set.
+func (c *EthtoolConn) Coalesce(ifname string) (*EthtoolCoalesce, error) {
+	d, err := c.request(ETHTOOL_MSG_COALESCE_GET, ifname, nil)
+	if err != nil {
+		return nil, err
+	}
+	co := new(EthtoolCoalesce)
+	fields := unsafe.Slice((*uint32)(unsafe.Pointer(co)), ethtoolCoalesceFields+1)[1:]
+	for d.Next() {
+		typ := d.Type()
+		if typ < ETHTOOL_A_COALESCE_RX_USECS || typ > ETHTOOL_A_COALESCE_RATE_SAMPLE_INTERVAL {
+			continue
+		}
+		if isEthtoolCoalesceFlag(typ) {
+			fields[typ-ETHTOOL_A_COALESCE_RX_USECS] = uint32(d.Uint8())
+		} else {
+			fields[typ-ETHTOOL_A_COALESCE_RX_USECS] = d.Uint32()
+		}
+	}
+	return co, d.Err()
+}
+
+// SetCoalesce sets the interrupt coalescing parameters of the device ifname
+// (ETHTOOL_MSG_COALESCE_SET). Only the parameters which differ from the
+// current ones are sent, since drivers reject the parameters they do not
+// support.
+func (c *EthtoolConn) SetCoalesce(ifname string, co *EthtoolCoalesce) error {
+	cur, err := c.Coalesce(ifname)
+	if err != nil {
+		return err
+	}
+	old := unsafe.Slice((*uint32)(unsafe.Pointer(cur)), ethtoolCoalesceFields+1)[1:]
+	fields := unsafe.Slice((*uint32)(unsafe.Pointer(co)), ethtoolCoalesceFields+1)[1:]
+	_, err = c.request(ETHTOOL_MSG_COALESCE_SET, ifname, func(e *NetlinkAttrEncoder) {
+		for i, v := range fields {
+			if v == old[i] {
+				continue
+			}
+			typ := uint16(i) + ETHTOOL_A_COALESCE_RX_USECS
+			if isEthtoolCoalesceFlag(typ) {
+				e.Uint8(typ, uint8(v))
+			} else {
+				e.Uint32(typ, v)
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// withVeth runs f in a new network namespace with the veth pair veth0 and
+// veth1.
+func withVeth(t *testing.T, f func()) {
+	inNewNetNs(t, func() {
+		c, err := unix.NewRtnlConn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "veth0", Data: &unix.RtnlVeth{PeerName: "veth1"}}); err != nil {
+			t.Fatalf("LinkAdd(veth): %v", err)
+		}
+		f()
+	})
+}
+
+func TestEthtoolIoctl(t *testing.T) {
+	withVeth(t, func() {
+		fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unix.Close(fd)
+
+		ls, err := unix.IoctlGetEthtoolLinkSettings(fd, "veth0")
+		if err != nil {
+			t.Fatalf("IoctlGetEthtoolLinkSettings: %v", err)
+		}
+		if ls.Settings.Speed != 10000 || ls.Settings.Duplex != unix.DUPLEX_FULL {
+			t.Errorf("got speed %d, duplex %d", ls.Settings.Speed, ls.Settings.Duplex)
+		}
+		if n := int(ls.Settings.Link_mode_masks_nwords); n == 0 || len(ls.Supported) != n || len(ls.LpAdvertising) != n {
+			t.Errorf("got %d words, bitmaps of %d and %d words", n, len(ls.Supported), len(ls.LpAdvertising))
+		}
+
+		names, err := unix.IoctlGetEthtoolStrings(fd, "veth0", unix.ETH_SS_STATS)
+		if err != nil {
+			t.Fatalf("IoctlGetEthtoolStrings(ETH_SS_STATS): %v", err)
+		}
+		stats, err := unix.IoctlGetEthtoolStats(fd, "veth0")
+		if err != nil {
+			t.Fatalf("IoctlGetEthtoolStats: %v", err)
+		}
+		if len(names) == 0 || len(stats) != len(names) {
+			t.Errorf("got %d statistics for %d names", len(stats), len(names))
+		}
+		for i, name := range names {
+			if name == "" {
+				t.Errorf("statistic %d has no name", i)
+			}
+		}
+
+		features, err := unix.IoctlGetEthtoolStrings(fd, "veth0", unix.ETH_SS_FEATURES)
+		if err != nil {
+			t.Fatalf("IoctlGetEthtoolStrings(ETH_SS_FEATURES): %v", err)
+		}
+		blocks, err := unix.IoctlGetEthtoolFeatures(fd, "veth0")
+		if err != nil {
+			t.Fatalf("IoctlGetEthtoolFeatures: %v", err)
+		}
+		if len(blocks) != (len(features)+31)/32 {
+			t.Errorf("got %d feature blocks for %d features", len(blocks), len(features))
+		}
+
+		ch, err := unix.IoctlGetEthtoolChannels(fd, "veth0")
+		if err != nil {
+			t.Fatalf("IoctlGetEthtoolChannels: %v", err)
+		}
+		if ch.Max_rx == 0 || ch.Rx_count == 0 {
+			t.Errorf("got channels %+v", ch)
+		}
+
+		if _, err := unix.IoctlGetEthtoolRingparam(fd, "veth0"); err != nil && !errors.Is(err, unix.EOPNOTSUPP) {
+			t.Errorf("IoctlGetEthtoolRingparam: %v", err)
+		}
+		if _, err := unix.IoctlGetEthtoolStringSetLen(fd, "veth0", unix.ETH_SS_TEST); err != unix.EOPNOTSUPP {
+			t.Errorf("IoctlGetEthtoolStringSetLen(ETH_SS_TEST): got %v, want %v", err, unix.EOPNOTSUPP)
+		}
+	})
+}
+
+func TestEthtoolNetlink(t *testing.T) {
+	withVeth(t, func() {
+		c, err := unix.NewEthtoolConn()
+		if err != nil {
+			t.Skipf("NewEthtoolConn: %v", err)
+		}
+		defer c.Close()
+
+		lm, err := c.LinkModes("veth0")
+		if err != nil {
+			t.Fatalf("LinkModes: %v", err)
+		}
+		if lm.Speed != 10000 || lm.Duplex != unix.DUPLEX_FULL {
+			t.Errorf("got speed %d, duplex %d", lm.Speed, lm.Duplex)
+		}
+
+		modes, err := c.Strings("", unix.ETH_SS_LINK_MODES)
+		if err != nil {
+			t.Fatalf("Strings(ETH_SS_LINK_MODES): %v", err)
+		}
+		if len(modes) <= unix.ETHTOOL_LINK_MODE_10000baseT_Full_BIT || modes[unix.ETHTOOL_LINK_MODE_10000baseT_Full_BIT] != "10000baseT/Full" {
+			t.Errorf("got %d link modes", len(modes))
+		}
+
+		f, err := c.Features("veth0")
+		if err != nil {
+			t.Fatalf("Features: %v", err)
+		}
+		const feature = "tx-checksum-ip-generic"
+		if _, ok := f.Hw.Lookup(feature); !ok {
+			t.Fatalf("%s is not a hardware feature: %v", feature, f.Hw.Names())
+		}
+		active, _ := f.Active.Lookup(feature)
+		if err := c.SetFeatures("veth0", map[string]bool{feature: !active}); err != nil {
+			t.Fatalf("SetFeatures: %v", err)
+		}
+		if f, err = c.Features("veth0"); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := f.Active.Lookup(feature); got == active {
+			t.Errorf("%s: got %v after SetFeatures", feature, got)
+		}
+
+		ch, err := c.Channels("veth0")
+		if err != nil {
+			t.Fatalf("Channels: %v", err)
+		}
+		if ch.Max_rx == 0 || ch.Rx_count == 0 {
+			t.Errorf("got channels %+v", ch)
+		}
+		if ch.Rx_count < ch.Max_rx {
+			want := ch.Rx_count + 1
+			ch.Rx_count = want
+			if err := c.SetChannels("veth0", ch); err != nil {
+				t.Fatalf("SetChannels: %v", err)
+			}
+			if ch, err = c.Channels("veth0"); err != nil {
+				t.Fatal(err)
+			}
+			if ch.Rx_count != want {
+				t.Errorf("got %d rx channels, want %d", ch.Rx_count, want)
+			}
+		}
+
+		if _, err := c.Rings("veth0"); err != nil && !errors.Is(err, unix.EOPNOTSUPP) {
+			t.Errorf("Rings: %v", err)
+		}
+		if _, err := c.Coalesce("veth0"); err != nil && !errors.Is(err, unix.EOPNOTSUPP) {
+			t.Errorf("Coalesce: %v", err)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "strings"
+
+// An FsContext is a filesystem context of the new mount API, opened with
+// fsopen(2) to create a filesystem instance or with fspick(2) to
+// reconfigure a mounted one. Operations failing with an error return an
+// *FsContextError holding the messages logged by the kernel, which explain
+// the error, such as an unknown or invalid parameter.
+//
+// Requires kernel >= 5.2.
+type FsContext struct {
+	fd int
+}
+
+// NewFsContext opens a context to create an instance of the filesystem
+// type fstype, such as "tmpfs" or "ext4" (fsopen(2)).
+func NewFsContext(fstype string) (*FsContext, error) {
+	fd, err := Fsopen(fstype, FSOPEN_CLOEXEC)
+	if err != nil {
+		return nil, &FsContextError{Op: "fsopen", Key: fstype, Err: err}
+	}
+	return &FsContext{fd: fd}, nil
+}
+
+// PickFsContext opens a context to reconfigure the filesystem mounted at
+// path, relative to dirfd (fspick(2)). Flags may include
+// FSPICK_SYMLINK_NOFOLLOW, FSPICK_NO_AUTOMOUNT and FSPICK_EMPTY_PATH.
+func PickFsContext(dirfd int, path string, flags int) (*FsContext, error) {
+	fd, err := Fspick(dirfd, path, flags|FSPICK_CLOEXEC)
+	if err != nil {
+		return nil, &FsContextError{Op: "fspick", Key: path, Err: err}
+	}
+	return &FsContext{fd: fd}, nil
+}
+
+// Fd returns the file descriptor of the context.
+func (c *FsContext) Fd() int { return c.fd }
+
+// Close closes the context.
+func (c *FsContext) Close() error { return Close(c.fd) }
+
+// An FsContextError records a failed filesystem context operation and the
+// messages the kernel logged to the context.
+type FsContextError struct {
+	Op  string // the operation, such as "fsconfig" or "fsmount"
+	Key string // the parameter key, filesystem type or path, if any
+	Err error
+
+	// Messages are the logged messages, each prefixed by its severity:
+	// "e " for errors, "w " for warnings and "i " for information.
+	Messages []string
+}
+
+func (e *FsContextError) Error() string {
+	s := e.Op
+	if e.Key != "" {
+		s += " " + e.Key
+	}
+	s += ": " + e.Err.Error()
+	if len(e.Messages) > 0 {
+		s += " (" + strings.Join(e.Messages, "; ") + ")"
+	}
+	return s
+}
+
+func (e *FsContextError) Unwrap() error { return e.Err }
+
+// Messages reads and returns the messages logged to the context since the
+// last call. The kernel keeps only the latest messages.
+func (c *FsContext) Messages() []string {
+	var msgs []string
+	buf := make([]byte, 4096)
+	for {
+		n, err := Read(c.fd, buf)
+		if err == EINTR {
+			continue
+		}
+		if err != nil || n <= 0 {
+			// ENODATA means that there are no more messages.
+			return msgs
+		}
+		msgs = append(msgs, strings.TrimRight(string(buf[:n]), "\n"))
+	}
+}
+
+func (c *FsContext) wrap(op, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FsContextError{Op: op, Key: key, Err: err, Messages: c.Messages()}
+}
+
+// SetFlag sets the parameter key, which takes no value, such as "ro"
+// (FSCONFIG_SET_FLAG).
+func (c *FsContext) SetFlag(key string) error {
+	return c.wrap("fsconfig", key, FsconfigSetFlag(c.fd, key))
+}
+
+// SetString sets the parameter key to value, such as "size" to "16m"
+// (FSCONFIG_SET_STRING). The "source" parameter names the device or other
+// source of the filesystem.
+func (c *FsContext) SetString(key, value string) error {
+	return c.wrap("fsconfig", key, FsconfigSetString(c.fd, key, value))
+}
+
+// SetBinary sets the parameter key to the binary blob value
+// (FSCONFIG_SET_BINARY).
+func (c *FsContext) SetBinary(key string, value []byte) error {
+	return c.wrap("fsconfig", key, FsconfigSetBinary(c.fd, key, value))
+}
+
+// SetPath sets the parameter key to the object at path, relative to atfd
+// (FSCONFIG_SET_PATH), or to the object atfd refers to if path is empty
+// (FSCONFIG_SET_PATH_EMPTY).
+func (c *FsContext) SetPath(key, path string, atfd int) error {
+	var err error
+	if path == "" {
+		err = FsconfigSetPathEmpty(c.fd, key, path, atfd)
+	} else {
+		err = FsconfigSetPath(c.fd, key, path, atfd)
+	}
+	return c.wrap("fsconfig", key, err)
+}
+
+// SetFd sets the parameter key to the object open as fd
+// (FSCONFIG_SET_FD).
+func (c *FsContext) SetFd(key string, fd int) error {
+	return c.wrap("fsconfig", key, FsconfigSetFd(c.fd, key, fd))
+}
+
+// Create creates the superblock from the parameters
+// (FSCONFIG_CMD_CREATE). It may reuse an existing superblock of the same
+// source.
+func (c *FsContext) Create() error {
+	return c.wrap("fsconfig", "create", FsconfigCreate(c.fd))
+}
+
+// Reconfigure applies the parameters to the superblock of a context opened
+// with PickFsContext (FSCONFIG_CMD_RECONFIGURE).
+func (c *FsContext) Reconfigure() error {
+	return c.wrap("fsconfig", "reconfigure", FsconfigReconfigure(c.fd))
+}
+
+// Mount creates a detached mount of the superblock created with Create,
+// with the MOUNT_ATTR_* attributes attrs, such as MOUNT_ATTR_NODEV, and
+// returns its file descriptor (fsmount(2)). The mount can be attached with
+// MoveMount, or used through the file descriptor; it is unmounted when the
+// file descriptor is closed if it was not attached.
+func (c *FsContext) Mount(attrs int) (int, error) {
+	fd, err := Fsmount(c.fd, FSMOUNT_CLOEXEC, attrs)
+	if err != nil {
+		return -1, c.wrap("fsmount", "", err)
+	}
+	return fd, nil
+}
+
+// MountAt creates the superblock, creates a detached mount of it with the
+// attributes attrs and attaches it at target, relative to dirfd, with
+// move_mount(2).
+func (c *FsContext) MountAt(dirfd int, target string, attrs int) error {
+	if err := c.Create(); err != nil {
+		return err
+	}
+	fd, err := c.Mount(attrs)
+	if err != nil {
+		return err
+	}
+	defer Close(fd)
+	if err := MoveMount(fd, "", dirfd, target, MOVE_MOUNT_F_EMPTY_PATH); err != nil {
+		return &FsContextError{Op: "move_mount", Key: target, Err: err}
+	}
+	return nil
+}
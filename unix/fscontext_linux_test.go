@@ -0,0 +1,132 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// inNewMountNs runs f on a locked thread in a new mount namespace in which
+// all mounts are private.
+func inNewMountNs(t *testing.T, f func()) {
+	errc := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// The thread is not unlocked, so it exits with the goroutine.
+		runtime.LockOSThread()
+		err := unix.Unshare(unix.CLONE_NEWNS)
+		if err == nil {
+			err = unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, "")
+		}
+		errc <- err
+		if err == nil {
+			f()
+		}
+	}()
+	if err := <-errc; err != nil {
+		t.Skipf("Unshare(CLONE_NEWNS): %v", err)
+	}
+	<-done
+}
+
+func newFsContext(t *testing.T, fstype string) *unix.FsContext {
+	c, err := unix.NewFsContext(fstype)
+	if err != nil {
+		if errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EPERM) {
+			t.Skipf("NewFsContext: %v", err)
+		}
+		t.Fatalf("NewFsContext: %v", err)
+	}
+	return c
+}
+
+func TestFsContext(t *testing.T) {
+	dir := t.TempDir()
+	inNewMountNs(t, func() {
+		c := newFsContext(t, "tmpfs")
+		defer c.Close()
+		if err := c.SetString("source", "x-sys-unix-test"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SetString("size", "1m"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SetString("mode", "0700"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.MountAt(unix.AT_FDCWD, dir, unix.MOUNT_ATTR_NODEV); err != nil {
+			t.Fatalf("MountAt: %v", err)
+		}
+		defer unix.Unmount(dir, unix.MNT_DETACH)
+
+		var st unix.Statfs_t
+		if err := unix.Statfs(dir, &st); err != nil {
+			t.Fatal(err)
+		}
+		if st.Type != unix.TMPFS_MAGIC || int64(st.Blocks)*int64(st.Bsize) != 1<<20 {
+			t.Errorf("got type %#x, %d blocks of %d bytes", st.Type, st.Blocks, st.Bsize)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		// Reconfigure the mounted filesystem.
+		p, err := unix.PickFsContext(unix.AT_FDCWD, dir, 0)
+		if err != nil {
+			t.Fatalf("PickFsContext: %v", err)
+		}
+		defer p.Close()
+		if err := p.SetString("size", "2m"); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Reconfigure(); err != nil {
+			t.Fatalf("Reconfigure: %v", err)
+		}
+		if err := unix.Statfs(dir, &st); err != nil {
+			t.Fatal(err)
+		}
+		if int64(st.Blocks)*int64(st.Bsize) != 2<<20 {
+			t.Errorf("after Reconfigure: got %d blocks of %d bytes", st.Blocks, st.Bsize)
+		}
+	})
+}
+
+func TestFsContextError(t *testing.T) {
+	c := newFsContext(t, "tmpfs")
+	defer c.Close()
+
+	err := c.SetString("x-sys-unix-test", "1")
+	var fserr *unix.FsContextError
+	if !errors.As(err, &fserr) {
+		t.Fatalf("got %v, want an *FsContextError", err)
+	}
+	if fserr.Err != unix.EINVAL || fserr.Key != "x-sys-unix-test" {
+		t.Errorf("got %+v", fserr)
+	}
+	if len(fserr.Messages) == 0 || !strings.HasPrefix(fserr.Messages[0], "e ") {
+		t.Errorf("got messages %q, want an error message", fserr.Messages)
+	}
+	if msgs := c.Messages(); len(msgs) != 0 {
+		t.Errorf("got messages %q after reading them", msgs)
+	}
+
+	if err := c.SetFlag("size"); !errors.Is(err, unix.EINVAL) {
+		t.Errorf("SetFlag(size): got %v, want %v", err, unix.EINVAL)
+	}
+	if err := unix.FsconfigSetBinary(c.Fd(), "size", nil); err != unix.EINVAL {
+		t.Errorf("FsconfigSetBinary with no data: got %v, want %v", err, unix.EINVAL)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "unsafe"
+
+// The futex functions operate on 32-bit futex words, which are shared
+// between processes if they are in a shared mapping, such as one created
+// with Mmap and MAP_SHARED or of a MemfdCreate file. The flags argument may
+// be FUTEX_PRIVATE_FLAG for a futex word used by a single process only,
+// which is faster. The futex word must be accessed atomically, such as with
+// the sync/atomic package, and must not be moved by the garbage collector,
+// which is the case for memory not allocated by Go.
+
+// FutexWait blocks until the futex word at addr is woken by FutexWake, if
+// it contains val (FUTEX_WAIT). It returns EAGAIN if the word does not
+// contain val, and ETIMEDOUT if timeout is not nil and the relative timeout
+// expires. Flags may include FUTEX_CLOCK_REALTIME to measure the timeout
+// against CLOCK_REALTIME rather than CLOCK_MONOTONIC. Like other blocking
+// system calls, it may be interrupted by a signal and return EINTR.
+func FutexWait(addr *uint32, val uint32, timeout *Timespec, flags int) error {
+	_, err := futex(addr, FUTEX_WAIT|flags, val, timeout, nil, 0)
+	return err
+}
+
+// FutexWaitBitset is like FutexWait, but the timeout is an absolute
+// deadline measured against CLOCK_MONOTONIC, or against CLOCK_REALTIME if
+// flags includes FUTEX_CLOCK_REALTIME, and the waiter can only be woken by
+// a FutexWakeBitset with a bitset sharing a bit with bitset
+// (FUTEX_WAIT_BITSET). The bitset must not be zero; FUTEX_BITSET_MATCH_ANY
+// matches all wakers.
+func FutexWaitBitset(addr *uint32, val uint32, deadline *Timespec, bitset uint32, flags int) error {
+	_, err := futex(addr, FUTEX_WAIT_BITSET|flags, val, deadline, nil, bitset)
+	return err
+}
+
+// FutexWake wakes up to n waiters of the futex word at addr (FUTEX_WAKE),
+// and returns the number of waiters woken.
+func FutexWake(addr *uint32, n int, flags int) (int, error) {
+	return futex(addr, FUTEX_WAKE|flags, uint32(n), nil, nil, 0)
+}
+
+// FutexWakeBitset is like FutexWake, but only wakes waiters of
+// FutexWaitBitset with a bitset sharing a bit with bitset
+// (FUTEX_WAKE_BITSET).
+func FutexWakeBitset(addr *uint32, n int, bitset uint32, flags int) (int, error) {
+	return futex(addr, FUTEX_WAKE_BITSET|flags, uint32(n), nil, nil, bitset)
+}
+
+// FutexRequeue wakes up to nwake waiters of the futex word at addr and
+// moves up to nrequeue of the remaining waiters to wait on the futex word
+// at addr2 (FUTEX_REQUEUE). It returns the number of waiters woken.
+// FutexCmpRequeue should be used instead, to avoid races.
+func FutexRequeue(addr *uint32, nwake, nrequeue int, addr2 *uint32, flags int) (int, error) {
+	return futexVal2(addr, FUTEX_REQUEUE|flags, uint32(nwake), uintptr(nrequeue), addr2, 0)
+}
+
+// FutexCmpRequeue is like FutexRequeue, but first checks that the futex
+// word at addr still contains val, and fails with EAGAIN otherwise
+// (FUTEX_CMP_REQUEUE). It returns the number of waiters woken or requeued.
+func FutexCmpRequeue(addr *uint32, nwake, nrequeue int, addr2 *uint32, val uint32, flags int) (int, error) {
+	return futexVal2(addr, FUTEX_CMP_REQUEUE|flags, uint32(nwake), uintptr(nrequeue), addr2, val)
+}
+
+// FutexOp encodes an operation for FutexWakeOp: the futex word at addr2 is
+// set to the result of the operation op, one of the FUTEX_OP_* constants,
+// applied to its old value and oparg, and the waiters of addr2 are woken
+// if the comparison cmp, one of the FUTEX_OP_CMP_* constants, of the old
+// value and cmparg holds. Oparg and cmparg are 12-bit values; if op
+// includes FUTEX_OP_OPARG_SHIFT, the operand is 1<<oparg.
+func FutexOp(op, oparg, cmp, cmparg int) uint32 {
+	return uint32(op&0xf)<<28 | uint32(cmp&0xf)<<24 | uint32(oparg&0xfff)<<12 | uint32(cmparg&0xfff)
+}
+
+// FutexWakeOp wakes up to nwake waiters of the futex word at addr,
+// atomically applies the operation op, encoded with FutexOp, to the futex
+// word at addr2, and, depending on its old value, wakes up to nwake2
+// waiters of addr2 (FUTEX_WAKE_OP). It returns the total number of waiters
+// woken.
+func FutexWakeOp(addr *uint32, nwake int, addr2 *uint32, nwake2 int, op uint32, flags int) (int, error) {
+	return futexVal2(addr, FUTEX_WAKE_OP|flags, uint32(nwake), uintptr(nwake2), addr2, op)
+}
+
+// FutexLockPI acquires the priority-inheritance futex at addr, which holds
+// the thread ID of its owner, or 0 if unlocked, when the fast path of
+// atomically setting the owner failed (FUTEX_LOCK_PI). It returns
+// ETIMEDOUT if deadline is not nil and the absolute deadline measured
+// against CLOCK_REALTIME expires. The futex is owned by the calling
+// thread, so the goroutine must be locked to its thread with
+// runtime.LockOSThread until it unlocks the futex.
+func FutexLockPI(addr *uint32, deadline *Timespec, flags int) error {
+	_, err := futex(addr, FUTEX_LOCK_PI|flags, 0, deadline, nil, 0)
+	return err
+}
+
+// FutexLockPI2 is like FutexLockPI, but the deadline is measured against
+// CLOCK_MONOTONIC, unless flags includes FUTEX_CLOCK_REALTIME
+// (FUTEX_LOCK_PI2, kernel >= 5.14).
+func FutexLockPI2(addr *uint32, deadline *Timespec, flags int) error {
+	_, err := futex(addr, FUTEX_LOCK_PI2|flags, 0, deadline, nil, 0)
+	return err
+}
+
+// FutexTrylockPI tries to acquire the priority-inheritance futex at addr
+// without blocking, and returns EAGAIN if it is owned by another thread
+// (FUTEX_TRYLOCK_PI).
+func FutexTrylockPI(addr *uint32, flags int) error {
+	_, err := futex(addr, FUTEX_TRYLOCK_PI|flags, 0, nil, nil, 0)
+	return err
+}
+
+// FutexUnlockPI releases the priority-inheritance futex at addr, owned by
+// the calling thread, and wakes its highest priority waiter, when the fast
+// path of atomically clearing the owner failed because FUTEX_WAITERS is
+// set (FUTEX_UNLOCK_PI).
+func FutexUnlockPI(addr *uint32, flags int) error {
+	_, err := futex(addr, FUTEX_UNLOCK_PI|flags, 0, nil, nil, 0)
+	return err
+}
+
+// NewFutexWaitv returns a FutexWaitv for waiting with FutexWaitAny on the
+// 32-bit futex word at addr while it contains val. Flags may be
+// FUTEX2_PRIVATE.
+func NewFutexWaitv(addr *uint32, val uint32, flags int) FutexWaitv {
+	return FutexWaitv{
+		Val:   uint64(val),
+		Uaddr: uint64(uintptr(unsafe.Pointer(addr))),
+		Flags: uint32(FUTEX2_SIZE_U32 | flags),
+	}
+}
+
+// kernelTimespec is struct __kernel_timespec, which has a 64-bit time on
+// all architectures.
+type kernelTimespec struct {
+	Sec  int64
+	Nsec int64
+}
+
+// FutexWaitAny blocks until one of the futex words described by waiters is
+// woken by FutexWake, if they all contain the expected values, and returns
+// the index of the woken futex word (futex_waitv(2), kernel >= 5.16). It
+// returns EAGAIN if a futex word does not contain the expected value, and
+// ETIMEDOUT if deadline is not nil and the absolute deadline measured
+// against the clock clockid, CLOCK_MONOTONIC or CLOCK_REALTIME, expires.
+// At most FUTEX_WAITV_MAX waiters are supported.
+func FutexWaitAny(waiters []FutexWaitv, deadline *Timespec, clockid int) (int, error) {
+	if len(waiters) == 0 {
+		return -1, EINVAL
+	}
+	var ts *kernelTimespec
+	if deadline != nil {
+		ts = &kernelTimespec{Sec: int64(deadline.Sec), Nsec: int64(deadline.Nsec)}
+	}
+	return futexWaitv(&waiters[0], len(waiters), 0, ts, clockid)
+}
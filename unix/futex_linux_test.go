@@ -0,0 +1,185 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// futexWords returns n futex words in a shared mapping.
+func futexWords(t *testing.T, n int) []*uint32 {
+	mem, err := unix.Mmap(-1, 0, 4096, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_ANONYMOUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unix.Munmap(mem) })
+	words := make([]*uint32, n)
+	for i := range words {
+		words[i] = (*uint32)(unsafe.Pointer(&mem[4*i]))
+	}
+	return words
+}
+
+// deadline returns the absolute time d from now on the clock clockid.
+func deadline(t *testing.T, clockid int32, d time.Duration) *unix.Timespec {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(clockid, &ts); err != nil {
+		t.Fatal(err)
+	}
+	ts = unix.NsecToTimespec(ts.Nano() + d.Nanoseconds())
+	return &ts
+}
+
+// wakeWhenWaiting wakes a waiter of addr as soon as there is one.
+func wakeWhenWaiting(t *testing.T, addr *uint32, flags int) {
+	for {
+		n, err := unix.FutexWake(addr, 1, flags)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if n == 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFutexWaitWake(t *testing.T) {
+	words := futexWords(t, 1)
+	addr := words[0]
+
+	if err := unix.FutexWait(addr, 1, nil, 0); err != unix.EAGAIN {
+		t.Errorf("FutexWait with another value: got %v, want %v", err, unix.EAGAIN)
+	}
+	ts := unix.NsecToTimespec(int64(time.Millisecond))
+	if err := unix.FutexWait(addr, 0, &ts, 0); err != unix.ETIMEDOUT {
+		t.Errorf("FutexWait with timeout: got %v, want %v", err, unix.ETIMEDOUT)
+	}
+	if err := unix.FutexWaitBitset(addr, 0, deadline(t, unix.CLOCK_MONOTONIC, time.Millisecond), unix.FUTEX_BITSET_MATCH_ANY, 0); err != unix.ETIMEDOUT {
+		t.Errorf("FutexWaitBitset with deadline: got %v, want %v", err, unix.ETIMEDOUT)
+	}
+
+	for _, flags := range []int{0, unix.FUTEX_PRIVATE_FLAG} {
+		errc := make(chan error, 1)
+		go func() {
+			for {
+				err := unix.FutexWait(addr, 0, nil, flags)
+				if err != unix.EINTR {
+					errc <- err
+					return
+				}
+			}
+		}()
+		wakeWhenWaiting(t, addr, flags)
+		if err := <-errc; err != nil {
+			t.Errorf("FutexWait with flags %#x: %v", flags, err)
+		}
+	}
+}
+
+func TestFutexWakeOp(t *testing.T) {
+	words := futexWords(t, 2)
+	addr, addr2 := words[0], words[1]
+
+	atomic.StoreUint32(addr2, 1)
+	n, err := unix.FutexWakeOp(addr, 1, addr2, 1, unix.FutexOp(unix.FUTEX_OP_ADD, 5, unix.FUTEX_OP_CMP_EQ, 1), 0)
+	if err != nil || n != 0 {
+		t.Fatalf("FutexWakeOp: got %d, %v", n, err)
+	}
+	if v := atomic.LoadUint32(addr2); v != 6 {
+		t.Errorf("FutexWakeOp: got %d, want 6", v)
+	}
+	if got := unix.FutexOp(unix.FUTEX_OP_OR|unix.FUTEX_OP_OPARG_SHIFT, 1, unix.FUTEX_OP_CMP_NE, 0); got != 0xa1001000 {
+		t.Errorf("FutexOp: got %#x", got)
+	}
+
+	if _, err := unix.FutexCmpRequeue(addr, 1, 1, addr2, 1, 0); err != unix.EAGAIN {
+		t.Errorf("FutexCmpRequeue with another value: got %v, want %v", err, unix.EAGAIN)
+	}
+	if n, err := unix.FutexCmpRequeue(addr, 1, 1, addr2, 0, 0); err != nil || n != 0 {
+		t.Errorf("FutexCmpRequeue: got %d, %v", n, err)
+	}
+	if n, err := unix.FutexRequeue(addr, 1, 1, addr2, 0); err != nil || n != 0 {
+		t.Errorf("FutexRequeue: got %d, %v", n, err)
+	}
+}
+
+func TestFutexPI(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	words := futexWords(t, 1)
+	addr := words[0]
+	if err := unix.FutexTrylockPI(addr, 0); err != nil {
+		t.Fatalf("FutexTrylockPI: %v", err)
+	}
+	if v := atomic.LoadUint32(addr); int(v&unix.FUTEX_TID_MASK) != unix.Gettid() {
+		t.Errorf("FutexTrylockPI: got owner %#x, want %d", v, unix.Gettid())
+	}
+	if err := unix.FutexLockPI(addr, nil, 0); err != unix.EDEADLK {
+		t.Errorf("FutexLockPI when owned: got %v, want %v", err, unix.EDEADLK)
+	}
+	if err := unix.FutexUnlockPI(addr, 0); err != nil {
+		t.Fatalf("FutexUnlockPI: %v", err)
+	}
+	if v := atomic.LoadUint32(addr); v != 0 {
+		t.Errorf("FutexUnlockPI: got %#x, want 0", v)
+	}
+	if err := unix.FutexLockPI(addr, deadline(t, unix.CLOCK_REALTIME, time.Second), unix.FUTEX_PRIVATE_FLAG); err != nil {
+		t.Fatalf("FutexLockPI: %v", err)
+	}
+	if err := unix.FutexUnlockPI(addr, unix.FUTEX_PRIVATE_FLAG); err != nil {
+		t.Fatalf("FutexUnlockPI: %v", err)
+	}
+}
+
+func TestFutexWaitAny(t *testing.T) {
+	words := futexWords(t, 2)
+	waiters := []unix.FutexWaitv{
+		unix.NewFutexWaitv(words[0], 0, 0),
+		unix.NewFutexWaitv(words[1], 1, 0),
+	}
+	_, err := unix.FutexWaitAny(waiters, nil, unix.CLOCK_MONOTONIC)
+	if err == unix.ENOSYS {
+		t.Skip("futex_waitv not supported")
+	}
+	if err != unix.EAGAIN {
+		t.Errorf("FutexWaitAny with another value: got %v, want %v", err, unix.EAGAIN)
+	}
+
+	waiters[1] = unix.NewFutexWaitv(words[1], 0, 0)
+	if _, err := unix.FutexWaitAny(waiters, deadline(t, unix.CLOCK_MONOTONIC, time.Millisecond), unix.CLOCK_MONOTONIC); err != unix.ETIMEDOUT {
+		t.Errorf("FutexWaitAny with deadline: got %v, want %v", err, unix.ETIMEDOUT)
+	}
+
+	type result struct {
+		i   int
+		err error
+	}
+	c := make(chan result, 1)
+	go func() {
+		for {
+			i, err := unix.FutexWaitAny(waiters, nil, unix.CLOCK_MONOTONIC)
+			if err != unix.EINTR {
+				c <- result{i, err}
+				return
+			}
+		}
+	}()
+	wakeWhenWaiting(t, words[1], 0)
+	if r := <-c; r.err != nil || r.i != 1 {
+		t.Errorf("FutexWaitAny: got %d, %v, want 1", r.i, r.err)
+	}
+}
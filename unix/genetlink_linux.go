@@ -0,0 +1,312 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// A GenlFamily describes a generic netlink family, as reported by the
+// CTRL_CMD_GETFAMILY command of the generic netlink controller.
+type GenlFamily struct {
+	ID      uint16 // CTRL_ATTR_FAMILY_ID, the netlink message type of the family
+	Name    string // CTRL_ATTR_FAMILY_NAME
+	Version uint32 // CTRL_ATTR_VERSION
+	HdrSize uint32 // CTRL_ATTR_HDRSIZE, the size of the family header after the genl header
+	MaxAttr uint32 // CTRL_ATTR_MAXATTR
+	Ops     []GenlOp
+	Groups  []GenlMulticastGroup
+}
+
+// A GenlOp is a command supported by a generic netlink family.
+type GenlOp struct {
+	ID    uint32 // CTRL_ATTR_OP_ID, the command
+	Flags uint32 // CTRL_ATTR_OP_FLAGS, such as GENL_ADMIN_PERM
+}
+
+// A GenlMulticastGroup is a multicast group of a generic netlink family.
+type GenlMulticastGroup struct {
+	Name string // CTRL_ATTR_MCAST_GRP_NAME
+	ID   uint32 // CTRL_ATTR_MCAST_GRP_ID
+}
+
+// Group returns the ID of the multicast group with the given name.
+func (f *GenlFamily) Group(name string) (uint32, bool) {
+	for _, g := range f.Groups {
+		if g.Name == name {
+			return g.ID, true
+		}
+	}
+	return 0, false
+}
+
+// A GenlMessage is a generic netlink message.
+type GenlMessage struct {
+	Family uint16 // the netlink message type, which is the family ID
+	Flags  uint16 // the netlink message flags
+	Header Genlmsghdr
+
+	// Data is the payload after the genl header: the family header, if
+	// any, followed by attributes.
+	Data []byte
+}
+
+// ParseGenlMessage parses the generic netlink message m.
+func ParseGenlMessage(m *NetlinkMessage) (*GenlMessage, error) {
+	if len(m.Data) < GENL_HDRLEN {
+		return nil, EINVAL
+	}
+	return &GenlMessage{
+		Family: m.Header.Type,
+		Flags:  m.Header.Flags,
+		Header: *(*Genlmsghdr)(unsafe.Pointer(&m.Data[0])),
+		Data:   m.Data[GENL_HDRLEN:],
+	}, nil
+}
+
+// Attrs returns a decoder for the attributes of the message, which follow
+// a family header of hdrsize bytes.
+func (m *GenlMessage) Attrs(hdrsize int) *NetlinkAttrDecoder {
+	if hdrsize > len(m.Data) {
+		hdrsize = len(m.Data)
+	}
+	return NewNetlinkAttrDecoder(m.Data[hdrsize:])
+}
+
+// A GenlConn is a NETLINK_GENERIC socket, which resolves families by name
+// and caches them.
+type GenlConn struct {
+	*NetlinkConn
+
+	mu       sync.Mutex
+	families map[string]*GenlFamily
+}
+
+// NewGenlConn opens a NETLINK_GENERIC socket.
+func NewGenlConn() (*GenlConn, error) {
+	c, err := NewNetlinkConn(NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	return &GenlConn{NetlinkConn: c, families: make(map[string]*GenlFamily)}, nil
+}
+
+// Request sends the command cmd with the given flags, the family header
+// hdr, if any, and the attributes to the family with the given ID and
+// returns the replies. NLM_F_REQUEST and NLM_F_ACK are always set; for dump
+// commands, flags should include NLM_F_DUMP. Errors are reported like by
+// Execute.
+func (c *GenlConn) Request(family uint16, cmd, version uint8, flags uint16, hdr []byte, attrs *NetlinkAttrEncoder) ([]GenlMessage, error) {
+	g := Genlmsghdr{Cmd: cmd, Version: version}
+	data := append(append([]byte(nil), structBytes(unsafe.Pointer(&g), GENL_HDRLEN)...), hdr...)
+	if attrs != nil {
+		data = append(data, attrs.Bytes()...)
+	}
+	msgs, err := c.Execute(NetlinkMessage{Header: NlMsghdr{Type: family, Flags: flags}, Data: data})
+	if err != nil && err != EINTR {
+		return nil, err
+	}
+	replies := make([]GenlMessage, 0, len(msgs))
+	for i := range msgs {
+		m, perr := ParseGenlMessage(&msgs[i])
+		if perr != nil {
+			return nil, perr
+		}
+		replies = append(replies, *m)
+	}
+	return replies, err
+}
+
+// Family returns the family with the given name, such as "nl80211". The
+// family is looked up with CTRL_CMD_GETFAMILY the first time, which makes
+// the kernel load the module implementing it if needed, and cached
+// afterwards.
+func (c *GenlConn) Family(name string) (*GenlFamily, error) {
+	c.mu.Lock()
+	f := c.families[name]
+	c.mu.Unlock()
+	if f != nil {
+		return f, nil
+	}
+
+	var e NetlinkAttrEncoder
+	e.String(CTRL_ATTR_FAMILY_NAME, name)
+	msgs, err := c.Request(GENL_ID_CTRL, CTRL_CMD_GETFAMILY, 1, 0, nil, &e)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) != 1 {
+		return nil, EINVAL
+	}
+	f, err = parseGenlFamily(&msgs[0])
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.families[name] = f
+	c.mu.Unlock()
+	return f, nil
+}
+
+// Families returns all registered families and replaces the cache with
+// them.
+func (c *GenlConn) Families() ([]GenlFamily, error) {
+	var msgs []GenlMessage
+	var err error
+	for i := 0; i < 10; i++ {
+		msgs, err = c.Request(GENL_ID_CTRL, CTRL_CMD_GETFAMILY, 1, NLM_F_DUMP, nil, nil)
+		if err != EINTR {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	families := make([]GenlFamily, 0, len(msgs))
+	cache := make(map[string]*GenlFamily, len(msgs))
+	for i := range msgs {
+		f, err := parseGenlFamily(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, *f)
+		cache[f.Name] = f
+	}
+	c.mu.Lock()
+	c.families = cache
+	c.mu.Unlock()
+	return families, nil
+}
+
+// ForgetFamily removes the family with the given name from the cache, so
+// that it is looked up again, such as after the module implementing it was
+// reloaded.
+func (c *GenlConn) ForgetFamily(name string) {
+	c.mu.Lock()
+	delete(c.families, name)
+	c.mu.Unlock()
+}
+
+func parseGenlFamily(m *GenlMessage) (*GenlFamily, error) {
+	f := new(GenlFamily)
+	d := m.Attrs(0)
+	for d.Next() {
+		switch d.Type() {
+		case CTRL_ATTR_FAMILY_ID:
+			f.ID = d.Uint16()
+		case CTRL_ATTR_FAMILY_NAME:
+			f.Name = d.String()
+		case CTRL_ATTR_VERSION:
+			f.Version = d.Uint32()
+		case CTRL_ATTR_HDRSIZE:
+			f.HdrSize = d.Uint32()
+		case CTRL_ATTR_MAXATTR:
+			f.MaxAttr = d.Uint32()
+		case CTRL_ATTR_OPS:
+			// The ops and groups are arrays: nested attributes whose
+			// types are indexes.
+			ops := d.Nested()
+			for ops.Next() {
+				var op GenlOp
+				od := ops.Nested()
+				for od.Next() {
+					switch od.Type() {
+					case CTRL_ATTR_OP_ID:
+						op.ID = od.Uint32()
+					case CTRL_ATTR_OP_FLAGS:
+						op.Flags = od.Uint32()
+					}
+				}
+				if err := od.Err(); err != nil {
+					return nil, err
+				}
+				f.Ops = append(f.Ops, op)
+			}
+			if err := ops.Err(); err != nil {
+				return nil, err
+			}
+		case CTRL_ATTR_MCAST_GROUPS:
+			groups := d.Nested()
+			for groups.Next() {
+				var g GenlMulticastGroup
+				gd := groups.Nested()
+				for gd.Next() {
+					switch gd.Type() {
+					case CTRL_ATTR_MCAST_GRP_NAME:
+						g.Name = gd.String()
+					case CTRL_ATTR_MCAST_GRP_ID:
+						g.ID = gd.Uint32()
+					}
+				}
+				if err := gd.Err(); err != nil {
+					return nil, err
+				}
+				f.Groups = append(f.Groups, g)
+			}
+			if err := groups.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f, d.Err()
+}
+
+// JoinFamilyGroup subscribes the socket to the multicast group with the
+// given name of the family, such as the "config" group of "nl80211".
+func (c *GenlConn) JoinFamilyGroup(family, group string) error {
+	id, err := c.familyGroup(family, group)
+	if err != nil {
+		return err
+	}
+	return c.JoinGroup(int(id))
+}
+
+// LeaveFamilyGroup unsubscribes the socket from the multicast group with
+// the given name of the family.
+func (c *GenlConn) LeaveFamilyGroup(family, group string) error {
+	id, err := c.familyGroup(family, group)
+	if err != nil {
+		return err
+	}
+	return c.LeaveGroup(int(id))
+}
+
+func (c *GenlConn) familyGroup(family, group string) (uint32, error) {
+	f, err := c.Family(family)
+	if err != nil {
+		return 0, err
+	}
+	id, ok := f.Group(group)
+	if !ok {
+		return 0, ENOENT
+	}
+	return id, nil
+}
+
+// ReceiveGenl waits for messages, such as multicast notifications, and
+// returns the generic netlink messages received in a single datagram.
+// Other messages, such as acknowledgements, are skipped.
+func (c *GenlConn) ReceiveGenl() ([]GenlMessage, error) {
+	msgs, err := c.Receive()
+	if err != nil {
+		return nil, err
+	}
+	var gmsgs []GenlMessage
+	for i := range msgs {
+		if msgs[i].Header.Type < NLMSG_MIN_TYPE {
+			continue
+		}
+		m, err := ParseGenlMessage(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		gmsgs = append(gmsgs, *m)
+	}
+	return gmsgs, nil
+}
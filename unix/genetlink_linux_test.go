@@ -0,0 +1,96 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestGenetlink(t *testing.T) {
+	c, err := unix.NewGenlConn()
+	if err != nil {
+		t.Skipf("NewGenlConn: %v", err)
+	}
+	defer c.Close()
+
+	f, err := c.Family("nlctrl")
+	if err != nil {
+		t.Fatalf("Family(nlctrl): %v", err)
+	}
+	if f.ID != unix.GENL_ID_CTRL || f.Name != "nlctrl" {
+		t.Errorf("Family(nlctrl): got ID %d, name %q", f.ID, f.Name)
+	}
+	var hasGetFamily bool
+	for _, op := range f.Ops {
+		if op.ID == unix.CTRL_CMD_GETFAMILY {
+			hasGetFamily = true
+		}
+	}
+	if !hasGetFamily {
+		t.Errorf("Family(nlctrl): CTRL_CMD_GETFAMILY missing from ops %+v", f.Ops)
+	}
+	if _, ok := f.Group("notify"); !ok {
+		t.Errorf("Family(nlctrl): notify group missing from %+v", f.Groups)
+	}
+	if f2, err := c.Family("nlctrl"); err != nil || f2 != f {
+		t.Errorf("Family(nlctrl) again: got %p, %v, want cached %p", f2, err, f)
+	}
+
+	if _, err := c.Family("no-such-family"); !errors.Is(err, unix.ENOENT) {
+		t.Errorf("Family(no-such-family): got error %v, want ENOENT", err)
+	}
+
+	families, err := c.Families()
+	if err != nil {
+		t.Fatalf("Families: %v", err)
+	}
+	var found bool
+	for _, f := range families {
+		if f.Name == "nlctrl" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("nlctrl missing from Families")
+	}
+
+	if err := c.JoinFamilyGroup("nlctrl", "notify"); err != nil {
+		t.Errorf("JoinFamilyGroup(nlctrl, notify): %v", err)
+	}
+	if err := c.LeaveFamilyGroup("nlctrl", "notify"); err != nil {
+		t.Errorf("LeaveFamilyGroup(nlctrl, notify): %v", err)
+	}
+	if err := c.JoinFamilyGroup("nlctrl", "no-such-group"); err != unix.ENOENT {
+		t.Errorf("JoinFamilyGroup(nlctrl, no-such-group): got error %v, want ENOENT", err)
+	}
+
+	// Commands with attributes are built and decoded with the attribute
+	// codec.
+	var e unix.NetlinkAttrEncoder
+	e.Uint16(unix.CTRL_ATTR_FAMILY_ID, unix.GENL_ID_CTRL)
+	msgs, err := c.Request(unix.GENL_ID_CTRL, unix.CTRL_CMD_GETFAMILY, 1, 0, nil, &e)
+	if err != nil {
+		t.Fatalf("Request(CTRL_CMD_GETFAMILY): %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Header.Cmd != unix.CTRL_CMD_NEWFAMILY {
+		t.Fatalf("Request(CTRL_CMD_GETFAMILY): got %+v", msgs)
+	}
+	var name string
+	d := msgs[0].Attrs(0)
+	for d.Next() {
+		if d.Type() == unix.CTRL_ATTR_FAMILY_NAME {
+			name = d.String()
+		}
+	}
+	if err := d.Err(); err != nil || name != "nlctrl" {
+		t.Errorf("Request(CTRL_CMD_GETFAMILY): got name %q, error %v", name, err)
+	}
+}
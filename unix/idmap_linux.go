@@ -0,0 +1,184 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"runtime"
+	"strconv"
+	"syscall"
+)
+
+// An IDMap maps a range of user or group IDs of a user namespace to IDs of
+// its parent namespace, as a line of /proc/[pid]/uid_map or gid_map.
+type IDMap struct {
+	ContainerID int // start of the range in the user namespace
+	HostID      int // start of the range in the parent namespace
+	Size        int // length of the range
+}
+
+// NewUserns creates a user namespace with the given user and group ID
+// mappings, and returns a file descriptor referring to it. The namespace
+// is created by a short-lived child process, as a multithreaded process
+// cannot unshare its own user namespace, and is kept alive by the
+// returned file descriptor.
+//
+// A caller without CAP_SETUID and CAP_SETGID in its user namespace can
+// only map its own effective user and group IDs, each with a Size of 1,
+// and mapping the user ID 0 also requires CAP_SETFCAP. So that this works
+// for gidMap, setgroups(2) is always denied in the new namespace, which
+// does not matter to ID-mapped mounts.
+func NewUserns(uidMap, gidMap []IDMap) (fd int, err error) {
+	pid, err := forkUserns()
+	if err != nil {
+		return -1, err
+	}
+	defer func() {
+		Kill(pid, SIGKILL)
+		for {
+			if _, err := Wait4(pid, nil, 0, nil); err != EINTR {
+				break
+			}
+		}
+	}()
+
+	if err := writeIDMaps(pid, uidMap, gidMap, false); err != nil {
+		return -1, err
+	}
+	return Open("/proc/"+strconv.Itoa(pid)+"/ns/user", O_RDONLY|O_CLOEXEC, 0)
+}
+
+// writeIDMaps writes the user and group ID mappings of the user namespace
+// of the process pid. Unless setgroups is true, setgroups(2) is denied in
+// the namespace first.
+func writeIDMaps(pid int, uidMap, gidMap []IDMap, setgroups bool) error {
+	dir := "/proc/" + strconv.Itoa(pid) + "/"
+	if err := writeIDMap(dir+"uid_map", uidMap); err != nil {
+		return err
+	}
+	// The kernel only accepts a gid_map written without CAP_SETGID once
+	// setgroups is denied. The setgroups file is missing before Linux 3.19.
+	if !setgroups {
+		if err := writeFile(dir+"setgroups", []byte("deny")); err != nil && err != ENOENT {
+			return err
+		}
+	}
+	return writeIDMap(dir+"gid_map", gidMap)
+}
+
+// writeIDMap writes m to the uid_map or gid_map file path, which must be
+// done in a single write.
+func writeIDMap(path string, m []IDMap) error {
+	if len(m) == 0 {
+		return nil
+	}
+	var b []byte
+	for _, e := range m {
+		b = strconv.AppendInt(b, int64(e.ContainerID), 10)
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, int64(e.HostID), 10)
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, int64(e.Size), 10)
+		b = append(b, '\n')
+	}
+	return writeFile(path, b)
+}
+
+// writeFile writes b to the existing file path, such as a file of procfs,
+// in a single write.
+func writeFile(path string, b []byte) error {
+	fd, err := Open(path, O_WRONLY|O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer Close(fd)
+	for {
+		_, err = Write(fd, b)
+		if err != EINTR {
+			return err
+		}
+	}
+}
+
+// forkUserns starts a child process in a new user namespace, which waits
+// to be killed.
+func forkUserns() (pid int, err error) {
+	old := blockSignals()
+	r, errno := rawForkUserns()
+	restoreSignals(&old)
+	if errno != 0 {
+		return -1, errnoErr(errno)
+	}
+	return r, nil
+}
+
+// rawForkUserns clones the calling thread into a child process in a new
+// user namespace, with all signals blocked by blockSignals.
+//
+//go:norace
+//go:nosplit
+func rawForkUserns() (pid int, errno syscall.Errno) {
+	var r uintptr
+	if runtime.GOARCH == "s390x" {
+		// The stack and flags arguments are swapped on s390x.
+		r, _, errno = RawSyscall6(SYS_CLONE, 0, CLONE_NEWUSER|uintptr(SIGCHLD), 0, 0, 0, 0)
+	} else {
+		r, _, errno = RawSyscall6(SYS_CLONE, CLONE_NEWUSER|uintptr(SIGCHLD), 0, 0, 0, 0, 0)
+	}
+	if errno != 0 || r != 0 {
+		return int(r), errno
+	}
+	// In the child, wait for SIGKILL, the only signal not blocked.
+	for {
+		RawSyscall6(SYS_PPOLL, 0, 0, 0, 0, 0, 0)
+	}
+}
+
+// OpenIDMappedTree creates a detached ID-mapped bind mount of source,
+// which maps the user and group IDs of the source filesystem as the given
+// mappings map the IDs of a user namespace to its parent namespace, and
+// returns a file descriptor referring to it. With AT_RECURSIVE in flags,
+// the bind mount includes the submounts of source, which are ID-mapped as
+// well. Flags may also include AT_SYMLINK_NOFOLLOW, AT_NO_AUTOMOUNT and
+// AT_EMPTY_PATH.
+//
+// The mount can be attached with MoveMount, and is unmounted when the file
+// descriptor is closed if it was not attached. Requires kernel >= 5.12 and
+// a source filesystem supporting ID-mapped mounts.
+func OpenIDMappedTree(source string, uidMap, gidMap []IDMap, flags int) (fd int, err error) {
+	userns, err := NewUserns(uidMap, gidMap)
+	if err != nil {
+		return -1, err
+	}
+	defer Close(userns)
+
+	fd, err = OpenTree(AT_FDCWD, source, uint(flags|OPEN_TREE_CLONE|O_CLOEXEC))
+	if err != nil {
+		return -1, err
+	}
+	attr := &MountAttr{
+		Attr_set:  MOUNT_ATTR_IDMAP,
+		Userns_fd: uint64(userns),
+	}
+	if err := MountSetattr(fd, "", uint(AT_EMPTY_PATH|flags&AT_RECURSIVE), attr); err != nil {
+		Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// MountIDMapped creates an ID-mapped bind mount of source, as
+// OpenIDMappedTree does, and attaches it at target. On failure, no mount
+// is left behind.
+func MountIDMapped(source, target string, uidMap, gidMap []IDMap, flags int) error {
+	fd, err := OpenIDMappedTree(source, uidMap, gidMap, flags)
+	if err != nil {
+		return err
+	}
+	defer Close(fd)
+	return MoveMount(fd, "", AT_FDCWD, target, MOVE_MOUNT_F_EMPTY_PATH)
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestNewUserns(t *testing.T) {
+	fd, err := unix.NewUserns(
+		[]unix.IDMap{{ContainerID: 0, HostID: 1000, Size: 10}},
+		[]unix.IDMap{{ContainerID: 0, HostID: 2000, Size: 10}},
+	)
+	if err != nil {
+		t.Skipf("NewUserns: %v", err)
+	}
+	defer unix.Close(fd)
+
+	if typ, err := unix.IoctlRetInt(fd, unix.NS_GET_NSTYPE); err != nil || typ != unix.CLONE_NEWUSER {
+		t.Errorf("NS_GET_NSTYPE: got %#x, %v", typ, err)
+	}
+	if uid, err := unix.IoctlGetUint32(fd, unix.NS_GET_OWNER_UID); err != nil || int(uid) != os.Getuid() {
+		t.Errorf("NS_GET_OWNER_UID: got %d, %v", uid, err)
+	}
+
+	if _, err := unix.NewUserns([]unix.IDMap{{ContainerID: 0, HostID: 0, Size: 0}}, nil); err != unix.EINVAL {
+		t.Errorf("empty mapping: got %v, want %v", err, unix.EINVAL)
+	}
+}
+
+func TestNewUsernsUnprivileged(t *testing.T) {
+	// Without CAP_SETUID and CAP_SETGID, only the caller's own IDs can be
+	// mapped, and the gid_map can only be written once setgroups(2) is
+	// denied in the namespace. Capabilities are per thread, so they are
+	// dropped on a thread which is discarded afterwards.
+	var fd int
+	var capErr, nsErr, err error
+	inThrowawayThread(func() {
+		hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+		var data [2]unix.CapUserData
+		if capErr = unix.Capset(&hdr, &data[0]); capErr != nil {
+			return
+		}
+		// Check that unprivileged user namespaces are allowed at all.
+		if fd, nsErr = unix.NewUserns(nil, nil); nsErr != nil {
+			return
+		}
+		unix.Close(fd)
+		// Mapping the user ID 0 also requires CAP_SETFCAP.
+		var uidMap []unix.IDMap
+		if uid := os.Geteuid(); uid != 0 {
+			uidMap = []unix.IDMap{{ContainerID: 0, HostID: uid, Size: 1}}
+		}
+		fd, err = unix.NewUserns(uidMap, []unix.IDMap{{ContainerID: 0, HostID: os.Getegid(), Size: 1}})
+	})
+	if capErr != nil {
+		t.Fatalf("Capset: %v", capErr)
+	}
+	if nsErr != nil {
+		t.Skipf("NewUserns without capabilities or mappings: %v", nsErr)
+	}
+	if err != nil {
+		t.Fatalf("NewUserns without capabilities: %v", err)
+	}
+	unix.Close(fd)
+}
+
+func TestMountIDMapped(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("ID-mapped mounts require root")
+	}
+	source, target := t.TempDir(), t.TempDir()
+	inNewMountNs(t, func() {
+		// tmpfs supports ID-mapped mounts since Linux 6.3.
+		c := newFsContext(t, "tmpfs")
+		defer c.Close()
+		if err := c.MountAt(unix.AT_FDCWD, source, 0); err != nil {
+			t.Fatal(err)
+		}
+		defer unix.Unmount(source, unix.MNT_DETACH)
+		if err := os.WriteFile(filepath.Join(source, "file"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chown(filepath.Join(source, "file"), 1, 2); err != nil {
+			t.Fatal(err)
+		}
+
+		uidMap := []unix.IDMap{{ContainerID: 0, HostID: 1000, Size: 10}}
+		gidMap := []unix.IDMap{{ContainerID: 0, HostID: 2000, Size: 10}}
+		err := unix.MountIDMapped(source, target, uidMap, gidMap, unix.AT_RECURSIVE)
+		if err == unix.EINVAL || err == unix.ENOSYS {
+			t.Skipf("MountIDMapped: %v", err)
+		}
+		if err != nil {
+			t.Fatalf("MountIDMapped: %v", err)
+		}
+		defer unix.Unmount(target, unix.MNT_DETACH)
+
+		var st unix.Stat_t
+		if err := unix.Stat(filepath.Join(target, "file"), &st); err != nil {
+			t.Fatal(err)
+		}
+		if st.Uid != 1001 || st.Gid != 2002 {
+			t.Errorf("got owner %d:%d, want 1001:2002", st.Uid, st.Gid)
+		}
+
+		mounts, err := os.ReadFile("/proc/thread-self/mountinfo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(mounts), " "+target+" ") || !strings.Contains(string(mounts), "idmapped") {
+			t.Errorf("ID-mapped mount of %s not found in mountinfo:\n%s", target, mounts)
+		}
+	})
+}
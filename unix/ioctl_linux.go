@@ -58,6 +58,242 @@ func IoctlGetEthtoolDrvinfo(fd int, ifname string) (*EthtoolDrvinfo, error) {
 	return &value, err
 }
 
+// ioctlEthtool performs the SIOCETHTOOL ioctl with the ethtool command
+// structure at p for the network device specified by ifname, and returns
+// the non-negative value returned by the ioctl syscall.
+func ioctlEthtool(fd int, ifname string, p unsafe.Pointer) (int, error) {
+	ifr, err := NewIfreq(ifname)
+	if err != nil {
+		return 0, err
+	}
+	ifrd := ifr.withData(p)
+	ret, _, errno := Syscall(SYS_IOCTL, uintptr(fd), SIOCETHTOOL, uintptr(unsafe.Pointer(&ifrd)))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(ret), nil
+}
+
+// IoctlGetEthtoolLinkSettings fetches the link settings and the supported,
+// advertised and link partner advertised link modes of the network device
+// specified by ifname with ETHTOOL_GLINKSETTINGS.
+func IoctlGetEthtoolLinkSettings(fd int, ifname string) (*EthtoolLinkKsettings, error) {
+	// The link mode bitmaps follow the header. The kernel reports their
+	// size, as a negative number of 32-bit words, if the request does not
+	// match it.
+	buf := make([]uint32, SizeofEthtoolLinkSettings/4+3*127)
+	hdr := (*EthtoolLinkSettings)(unsafe.Pointer(&buf[0]))
+	hdr.Cmd = ETHTOOL_GLINKSETTINGS
+	if _, err := ioctlEthtool(fd, ifname, unsafe.Pointer(hdr)); err != nil {
+		return nil, err
+	}
+	if hdr.Link_mode_masks_nwords >= 0 || hdr.Cmd != ETHTOOL_GLINKSETTINGS {
+		return nil, EPROTO
+	}
+	nwords := int(-hdr.Link_mode_masks_nwords)
+	hdr.Link_mode_masks_nwords = int8(nwords)
+	if _, err := ioctlEthtool(fd, ifname, unsafe.Pointer(hdr)); err != nil {
+		return nil, err
+	}
+	if int(hdr.Link_mode_masks_nwords) != nwords {
+		return nil, EPROTO
+	}
+	masks := buf[SizeofEthtoolLinkSettings/4:]
+	return &EthtoolLinkKsettings{
+		Settings:      *hdr,
+		Supported:     append([]uint32(nil), masks[:nwords]...),
+		Advertising:   append([]uint32(nil), masks[nwords:2*nwords]...),
+		LpAdvertising: append([]uint32(nil), masks[2*nwords:3*nwords]...),
+	}, nil
+}
+
+// IoctlSetEthtoolLinkSettings sets the link settings and the advertised
+// link modes of the network device specified by ifname with
+// ETHTOOL_SLINKSETTINGS. The bitmaps must have the size reported by
+// IoctlGetEthtoolLinkSettings.
+func IoctlSetEthtoolLinkSettings(fd int, ifname string, value *EthtoolLinkKsettings) error {
+	nwords := len(value.Advertising)
+	if nwords == 0 || nwords > 127 || len(value.Supported) > nwords || len(value.LpAdvertising) > nwords {
+		return EINVAL
+	}
+	buf := make([]uint32, SizeofEthtoolLinkSettings/4+3*nwords)
+	hdr := (*EthtoolLinkSettings)(unsafe.Pointer(&buf[0]))
+	*hdr = value.Settings
+	hdr.Cmd = ETHTOOL_SLINKSETTINGS
+	hdr.Link_mode_masks_nwords = int8(nwords)
+	masks := buf[SizeofEthtoolLinkSettings/4:]
+	copy(masks, value.Supported)
+	copy(masks[nwords:], value.Advertising)
+	copy(masks[2*nwords:], value.LpAdvertising)
+	_, err := ioctlEthtool(fd, ifname, unsafe.Pointer(hdr))
+	return err
+}
+
+// IoctlGetEthtoolStringSetLen returns the number of strings in the string
+// set, such as ETH_SS_STATS or ETH_SS_FEATURES, of the network device
+// specified by ifname with ETHTOOL_GSSET_INFO. It returns EOPNOTSUPP if
+// the device does not have the string set.
+func IoctlGetEthtoolStringSetLen(fd int, ifname string, set uint32) (int, error) {
+	if set >= 64 {
+		return 0, EINVAL
+	}
+	// The header is followed by the length of each string set of the mask.
+	var buf [SizeofEthtoolSsetInfo/8 + 1]uint64
+	info := (*EthtoolSsetInfo)(unsafe.Pointer(&buf[0]))
+	info.Cmd = ETHTOOL_GSSET_INFO
+	info.Sset_mask = 1 << set
+	if _, err := ioctlEthtool(fd, ifname, unsafe.Pointer(info)); err != nil {
+		return 0, err
+	}
+	if info.Sset_mask == 0 {
+		return 0, EOPNOTSUPP
+	}
+	return int(*(*uint32)(unsafe.Pointer(&buf[SizeofEthtoolSsetInfo/8]))), nil
+}
+
+// IoctlGetEthtoolStrings returns the strings of the string set, such as
+// the statistic names of ETH_SS_STATS, of the network device specified by
+// ifname with ETHTOOL_GSTRINGS.
+func IoctlGetEthtoolStrings(fd int, ifname string, set uint32) ([]string, error) {
+	n, err := IoctlGetEthtoolStringSetLen(fd, ifname, set)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]uint32, (SizeofEthtoolGstrings+n*ETH_GSTRING_LEN)/4)
+	gs := (*EthtoolGstrings)(unsafe.Pointer(&buf[0]))
+	gs.Cmd = ETHTOOL_GSTRINGS
+	gs.String_set = set
+	gs.Len = uint32(n)
+	if _, err := ioctlEthtool(fd, ifname, unsafe.Pointer(gs)); err != nil {
+		return nil, err
+	}
+	if int(gs.Len) < n {
+		n = int(gs.Len)
+	}
+	data := unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*4)[SizeofEthtoolGstrings:]
+	strs := make([]string, n)
+	for i := range strs {
+		s := data[i*ETH_GSTRING_LEN : (i+1)*ETH_GSTRING_LEN]
+		strs[i] = string(s[:clen(s)])
+	}
+	return strs, nil
+}
+
+// IoctlGetEthtoolStats returns the driver statistics of the network device
+// specified by ifname with ETHTOOL_GSTATS. Their names are the strings of
+// the ETH_SS_STATS string set, as returned by IoctlGetEthtoolStrings.
+func IoctlGetEthtoolStats(fd int, ifname string) ([]uint64, error) {
+	n, err := IoctlGetEthtoolStringSetLen(fd, ifname, ETH_SS_STATS)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]uint64, SizeofEthtoolStats/8+n)
+	st := (*EthtoolStats)(unsafe.Pointer(&buf[0]))
+	st.Cmd = ETHTOOL_GSTATS
+	st.N_stats = uint32(n)
+	if _, err := ioctlEthtool(fd, ifname, unsafe.Pointer(st)); err != nil {
+		return nil, err
+	}
+	if int(st.N_stats) < n {
+		n = int(st.N_stats)
+	}
+	return buf[SizeofEthtoolStats/8 : SizeofEthtoolStats/8+n], nil
+}
+
+// IoctlGetEthtoolRingparam fetches the ring sizes of the network device
+// specified by ifname with ETHTOOL_GRINGPARAM.
+func IoctlGetEthtoolRingparam(fd int, ifname string) (*EthtoolRingparam, error) {
+	value := EthtoolRingparam{Cmd: ETHTOOL_GRINGPARAM}
+	_, err := ioctlEthtool(fd, ifname, unsafe.Pointer(&value))
+	return &value, err
+}
+
+// IoctlSetEthtoolRingparam sets the ring sizes of the network device
+// specified by ifname with ETHTOOL_SRINGPARAM. The maximums are ignored.
+func IoctlSetEthtoolRingparam(fd int, ifname string, value *EthtoolRingparam) error {
+	v := *value
+	v.Cmd = ETHTOOL_SRINGPARAM
+	_, err := ioctlEthtool(fd, ifname, unsafe.Pointer(&v))
+	return err
+}
+
+// IoctlGetEthtoolChannels fetches the channel counts of the network device
+// specified by ifname with ETHTOOL_GCHANNELS.
+func IoctlGetEthtoolChannels(fd int, ifname string) (*EthtoolChannels, error) {
+	value := EthtoolChannels{Cmd: ETHTOOL_GCHANNELS}
+	_, err := ioctlEthtool(fd, ifname, unsafe.Pointer(&value))
+	return &value, err
+}
+
+// IoctlSetEthtoolChannels sets the channel counts of the network device
+// specified by ifname with ETHTOOL_SCHANNELS. The maximums are ignored.
+func IoctlSetEthtoolChannels(fd int, ifname string, value *EthtoolChannels) error {
+	v := *value
+	v.Cmd = ETHTOOL_SCHANNELS
+	_, err := ioctlEthtool(fd, ifname, unsafe.Pointer(&v))
+	return err
+}
+
+// IoctlGetEthtoolCoalesce fetches the interrupt coalescing parameters of
+// the network device specified by ifname with ETHTOOL_GCOALESCE.
+func IoctlGetEthtoolCoalesce(fd int, ifname string) (*EthtoolCoalesce, error) {
+	value := EthtoolCoalesce{Cmd: ETHTOOL_GCOALESCE}
+	_, err := ioctlEthtool(fd, ifname, unsafe.Pointer(&value))
+	return &value, err
+}
+
+// IoctlSetEthtoolCoalesce sets the interrupt coalescing parameters of the
+// network device specified by ifname with ETHTOOL_SCOALESCE. Drivers reject
+// non-zero values of the parameters they do not support.
+func IoctlSetEthtoolCoalesce(fd int, ifname string, value *EthtoolCoalesce) error {
+	v := *value
+	v.Cmd = ETHTOOL_SCOALESCE
+	_, err := ioctlEthtool(fd, ifname, unsafe.Pointer(&v))
+	return err
+}
+
+// IoctlGetEthtoolFeatures fetches the offload features of the network
+// device specified by ifname with ETHTOOL_GFEATURES. Feature i is bit i%32
+// of block i/32; the feature names are the strings of the ETH_SS_FEATURES
+// string set.
+func IoctlGetEthtoolFeatures(fd int, ifname string) ([]EthtoolGetFeaturesBlock, error) {
+	n, err := IoctlGetEthtoolStringSetLen(fd, ifname, ETH_SS_FEATURES)
+	if err != nil {
+		return nil, err
+	}
+	nblocks := (n + 31) / 32
+	buf := make([]uint32, SizeofEthtoolGfeatures/4+nblocks*SizeofEthtoolGetFeaturesBlock/4)
+	gf := (*EthtoolGfeatures)(unsafe.Pointer(&buf[0]))
+	gf.Cmd = ETHTOOL_GFEATURES
+	gf.Size = uint32(nblocks)
+	if _, err := ioctlEthtool(fd, ifname, unsafe.Pointer(gf)); err != nil {
+		return nil, err
+	}
+	blocks := make([]EthtoolGetFeaturesBlock, nblocks)
+	for i := range blocks {
+		blocks[i] = *(*EthtoolGetFeaturesBlock)(unsafe.Pointer(&buf[SizeofEthtoolGfeatures/4+4*i]))
+	}
+	return blocks, nil
+}
+
+// IoctlSetEthtoolFeatures requests the offload features of the network
+// device specified by ifname with ETHTOOL_SFEATURES: the features of the
+// Valid mask of each block are enabled or disabled according to Requested.
+// The returned mask of ETHTOOL_F_* flags reports requests which could not
+// be satisfied, such as ETHTOOL_F_WISH if a feature is wanted but not
+// active.
+func IoctlSetEthtoolFeatures(fd int, ifname string, blocks []EthtoolSetFeaturesBlock) (int, error) {
+	buf := make([]uint32, SizeofEthtoolSfeatures/4+len(blocks)*SizeofEthtoolSetFeaturesBlock/4)
+	sf := (*EthtoolSfeatures)(unsafe.Pointer(&buf[0]))
+	sf.Cmd = ETHTOOL_SFEATURES
+	sf.Size = uint32(len(blocks))
+	for i, b := range blocks {
+		buf[SizeofEthtoolSfeatures/4+2*i] = b.Valid
+		buf[SizeofEthtoolSfeatures/4+2*i+1] = b.Requested
+	}
+	return ioctlEthtool(fd, ifname, unsafe.Pointer(sf))
+}
+
 // IoctlGetWatchdogInfo fetches information about a watchdog device from the
 // Linux watchdog API. For more information, see:
 // https://www.kernel.org/doc/html/latest/watchdog/watchdog-api.html.
@@ -231,3 +467,188 @@ func IoctlLoopGetStatus64(fd int) (*LoopInfo64, error) {
 func IoctlLoopSetStatus64(fd int, value *LoopInfo64) error {
 	return ioctlPtr(fd, LOOP_SET_STATUS64, unsafe.Pointer(value))
 }
+
+// IoctlLoopConfigure attaches the backing file value.Fd to the loop device
+// associated with the file descriptor fd and configures it in a single
+// step using the LOOP_CONFIGURE operation, available since Linux 5.8.
+func IoctlLoopConfigure(fd int, value *LoopConfig) error {
+	return ioctlPtr(fd, LOOP_CONFIGURE, unsafe.Pointer(value))
+}
+
+// IoctlLoopSetFd attaches the backing file backingFd to the loop device
+// associated with the file descriptor fd using the LOOP_SET_FD operation.
+// The device is read-only if backingFd is not open for writing.
+func IoctlLoopSetFd(fd, backingFd int) error {
+	return ioctl(fd, LOOP_SET_FD, uintptr(backingFd))
+}
+
+// IoctlLoopClrFd detaches the backing file from the loop device associated
+// with the file descriptor fd using the LOOP_CLR_FD operation. It fails
+// with EBUSY while the device is open elsewhere, unless the device has
+// LO_FLAGS_AUTOCLEAR set, in which case it is detached when last closed.
+func IoctlLoopClrFd(fd int) error {
+	return ioctl(fd, LOOP_CLR_FD, 0)
+}
+
+// IoctlLoopSetCapacity makes the loop device associated with the file
+// descriptor fd reread the size of its backing file using the
+// LOOP_SET_CAPACITY operation.
+func IoctlLoopSetCapacity(fd int) error {
+	return ioctl(fd, LOOP_SET_CAPACITY, 0)
+}
+
+// IoctlLoopSetBlockSize sets the logical block size of the loop device
+// associated with the file descriptor fd using the LOOP_SET_BLOCK_SIZE
+// operation.
+func IoctlLoopSetBlockSize(fd int, size uint32) error {
+	return ioctl(fd, LOOP_SET_BLOCK_SIZE, uintptr(size))
+}
+
+// IoctlLoopSetDirectIO enables or disables direct I/O on the backing file
+// of the loop device associated with the file descriptor fd using the
+// LOOP_SET_DIRECT_IO operation.
+func IoctlLoopSetDirectIO(fd int, on bool) error {
+	var arg uintptr
+	if on {
+		arg = 1
+	}
+	return ioctl(fd, LOOP_SET_DIRECT_IO, arg)
+}
+
+// IoctlLoopCtlGetFree returns the index of a free loop device, allocating
+// one if needed, using the LOOP_CTL_GET_FREE operation on the loop control
+// device /dev/loop-control open as fd.
+func IoctlLoopCtlGetFree(fd int) (int, error) {
+	return IoctlRetInt(fd, LOOP_CTL_GET_FREE)
+}
+
+// IoctlLoopCtlAdd creates the loop device with the given index using the
+// LOOP_CTL_ADD operation on the loop control device open as fd.
+func IoctlLoopCtlAdd(fd, index int) (int, error) {
+	ret, _, err := Syscall(SYS_IOCTL, uintptr(fd), LOOP_CTL_ADD, uintptr(index))
+	if err != 0 {
+		return 0, err
+	}
+	return int(ret), nil
+}
+
+// IoctlLoopCtlRemove removes the unused loop device with the given index
+// using the LOOP_CTL_REMOVE operation on the loop control device open as fd.
+func IoctlLoopCtlRemove(fd, index int) error {
+	return ioctl(fd, LOOP_CTL_REMOVE, uintptr(index))
+}
+
+// IoctlSeccompNotifRecv receives the next seccomp user notification from the
+// listener fd using the SECCOMP_IOCTL_NOTIF_RECV operation. It blocks until a
+// notification is available.
+func IoctlSeccompNotifRecv(fd int) (*SeccompNotif, error) {
+	// The kernel requires the structure to be zeroed.
+	var value SeccompNotif
+	if err := ioctlPtr(fd, SECCOMP_IOCTL_NOTIF_RECV, unsafe.Pointer(&value)); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// IoctlSeccompNotifSend responds to a seccomp user notification received from
+// the listener fd using the SECCOMP_IOCTL_NOTIF_SEND operation.
+func IoctlSeccompNotifSend(fd int, value *SeccompNotifResp) error {
+	return ioctlPtr(fd, SECCOMP_IOCTL_NOTIF_SEND, unsafe.Pointer(value))
+}
+
+// IoctlSeccompNotifIDValid checks whether the seccomp user notification id
+// received from the listener fd is still valid, that is whether the target
+// is still blocked in the system call, using the
+// SECCOMP_IOCTL_NOTIF_ID_VALID operation. It returns ENOENT if it is not.
+func IoctlSeccompNotifIDValid(fd int, id uint64) error {
+	return ioctlPtr(fd, SECCOMP_IOCTL_NOTIF_ID_VALID, unsafe.Pointer(&id))
+}
+
+// IoctlSeccompNotifAddfd installs a file descriptor into the file descriptor
+// table of the target of a seccomp user notification received from the
+// listener fd using the SECCOMP_IOCTL_NOTIF_ADDFD operation. It returns the
+// file descriptor number in the target.
+func IoctlSeccompNotifAddfd(fd int, value *SeccompNotifAddfd) (int, error) {
+	ret, _, err := Syscall(SYS_IOCTL, uintptr(fd), SECCOMP_IOCTL_NOTIF_ADDFD, uintptr(unsafe.Pointer(value)))
+	if err != 0 {
+		return -1, err
+	}
+	return int(ret), nil
+}
+
+// IoctlUffdioAPI negotiates the API version and the features, such as
+// UFFD_FEATURE_EVENT_FORK, of the userfaultfd fd using the UFFDIO_API
+// operation. It must be the first operation on fd. On return, value holds
+// the supported features and ioctls.
+func IoctlUffdioAPI(fd int, value *UffdioAPI) error {
+	return ioctlPtr(fd, UFFDIO_API, unsafe.Pointer(value))
+}
+
+// IoctlUffdioRegister registers the memory range value.Range with the
+// userfaultfd fd, in the UFFDIO_REGISTER_MODE_* modes value.Mode, using the
+// UFFDIO_REGISTER operation. On return, value.Ioctls holds the ioctls
+// supported for the range.
+func IoctlUffdioRegister(fd int, value *UffdioRegister) error {
+	return ioctlPtr(fd, UFFDIO_REGISTER, unsafe.Pointer(value))
+}
+
+// IoctlUffdioUnregister unregisters the memory range value from the
+// userfaultfd fd using the UFFDIO_UNREGISTER operation.
+func IoctlUffdioUnregister(fd int, value *UffdioRange) error {
+	return ioctlPtr(fd, UFFDIO_UNREGISTER, unsafe.Pointer(value))
+}
+
+// IoctlUffdioWake wakes the threads waiting for page faults in the memory
+// range value, resolved with a DONTWAKE mode, using the UFFDIO_WAKE
+// operation.
+func IoctlUffdioWake(fd int, value *UffdioRange) error {
+	return ioctlPtr(fd, UFFDIO_WAKE, unsafe.Pointer(value))
+}
+
+// IoctlUffdioCopy resolves page faults in a range registered with the
+// userfaultfd fd by atomically copying value.Len bytes from value.Src to
+// value.Dst using the UFFDIO_COPY operation. On return, value.Copy holds
+// the number of bytes copied; it fails with EAGAIN if the copy was
+// incomplete and with EEXIST if a page was already mapped.
+func IoctlUffdioCopy(fd int, value *UffdioCopy) error {
+	return ioctlPtr(fd, UFFDIO_COPY, unsafe.Pointer(value))
+}
+
+// IoctlUffdioZeropage resolves page faults in the range value.Range by
+// mapping zero pages using the UFFDIO_ZEROPAGE operation. On return,
+// value.Zeropage holds the number of bytes mapped.
+func IoctlUffdioZeropage(fd int, value *UffdioZeropage) error {
+	return ioctlPtr(fd, UFFDIO_ZEROPAGE, unsafe.Pointer(value))
+}
+
+// IoctlUffdioWriteprotect write-protects the range value.Range, registered
+// in UFFDIO_REGISTER_MODE_WP mode, if value.Mode includes
+// UFFDIO_WRITEPROTECT_MODE_WP, or removes the protection and resolves
+// write-protect faults otherwise, using the UFFDIO_WRITEPROTECT operation.
+func IoctlUffdioWriteprotect(fd int, value *UffdioWriteprotect) error {
+	return ioctlPtr(fd, UFFDIO_WRITEPROTECT, unsafe.Pointer(value))
+}
+
+// IoctlUffdioContinue resolves minor faults in the range value.Range,
+// registered in UFFDIO_REGISTER_MODE_MINOR mode, by mapping the pages
+// already in the page cache using the UFFDIO_CONTINUE operation. On return,
+// value.Mapped holds the number of bytes mapped.
+func IoctlUffdioContinue(fd int, value *UffdioContinue) error {
+	return ioctlPtr(fd, UFFDIO_CONTINUE, unsafe.Pointer(value))
+}
+
+// IoctlUffdioPoison resolves page faults in the range value.Range by
+// marking the pages as poisoned, so that accessing them raises SIGBUS,
+// using the UFFDIO_POISON operation. On return, value.Updated holds the
+// number of bytes poisoned.
+func IoctlUffdioPoison(fd int, value *UffdioPoison) error {
+	return ioctlPtr(fd, UFFDIO_POISON, unsafe.Pointer(value))
+}
+
+// IoctlUffdioMove resolves page faults in a range registered with the
+// userfaultfd fd by moving value.Len bytes of anonymous pages from
+// value.Src to value.Dst using the UFFDIO_MOVE operation. On return,
+// value.Move holds the number of bytes moved.
+func IoctlUffdioMove(fd int, value *UffdioMove) error {
+	return ioctlPtr(fd, UFFDIO_MOVE, unsafe.Pointer(value))
+}
@@ -0,0 +1,411 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// An IoUring is an io_uring instance with its submission and completion
+// queues mapped into the address space of the calling process. Use
+// NewIoUring to create one.
+//
+// Entries are prepared by calling NextSqe and one of the Prep methods on the
+// returned IoUringSqe, handed to the kernel with Submit or SubmitAndWait and
+// their results collected with ReapCqe or WaitCqe.
+//
+// Memory referenced by a prepared entry (buffers, iovecs, paths, sockaddrs)
+// is read or written by the kernel asynchronously. The caller must keep it
+// alive and unmodified until the corresponding completion has been reaped.
+//
+// An IoUring is not safe for concurrent use by multiple goroutines.
+type IoUring struct {
+	fd     int
+	params IoUringParams
+
+	sqRing []byte
+	cqRing []byte
+	sqeMem []byte
+
+	sqHead    *uint32
+	sqTail    *uint32
+	sqMask    uint32
+	sqEntries uint32
+	sqFlags   *uint32
+	sqArray   []uint32
+	sqes      []IoUringSqe
+
+	// Entries handed out by NextSqe but not yet published to the kernel
+	// are in the range [sqeHead, sqeTail).
+	sqeHead uint32
+	sqeTail uint32
+
+	cqHead *uint32
+	cqTail *uint32
+	cqMask uint32
+	cqes   []IoUringCqe
+
+	// Registered buffers are kept here so that they are not garbage
+	// collected while the kernel holds references to them.
+	bufs [][]byte
+}
+
+// NewIoUring creates an io_uring instance with room for at least entries
+// submission queue entries and maps its rings. If params is not nil, it is
+// passed to io_uring_setup(2) and updated with the values returned by the
+// kernel.
+func NewIoUring(entries uint32, params *IoUringParams) (*IoUring, error) {
+	var p IoUringParams
+	if params != nil {
+		p = *params
+	}
+	fd, err := IoUringSetup(entries, &p)
+	if err != nil {
+		return nil, err
+	}
+	if params != nil {
+		*params = p
+	}
+
+	r := &IoUring{fd: fd, params: p}
+	if err := r.mmap(); err != nil {
+		r.munmap()
+		Close(fd)
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *IoUring) mmap() error {
+	p := &r.params
+	sqSize := int(p.Sq_off.Array) + int(p.Sq_entries)*4
+	cqSize := int(p.Cq_off.Cqes) + int(p.Cq_entries)*SizeofIoUringCqe
+
+	single := p.Features&IORING_FEAT_SINGLE_MMAP != 0
+	if single && cqSize > sqSize {
+		sqSize = cqSize
+	}
+
+	var err error
+	r.sqRing, err = Mmap(r.fd, IORING_OFF_SQ_RING, sqSize, PROT_READ|PROT_WRITE, MAP_SHARED|MAP_POPULATE)
+	if err != nil {
+		return err
+	}
+	if single {
+		r.cqRing = r.sqRing
+	} else {
+		r.cqRing, err = Mmap(r.fd, IORING_OFF_CQ_RING, cqSize, PROT_READ|PROT_WRITE, MAP_SHARED|MAP_POPULATE)
+		if err != nil {
+			return err
+		}
+	}
+	r.sqeMem, err = Mmap(r.fd, IORING_OFF_SQES, int(p.Sq_entries)*SizeofIoUringSqe, PROT_READ|PROT_WRITE, MAP_SHARED|MAP_POPULATE)
+	if err != nil {
+		return err
+	}
+
+	r.sqHead = (*uint32)(unsafe.Pointer(&r.sqRing[p.Sq_off.Head]))
+	r.sqTail = (*uint32)(unsafe.Pointer(&r.sqRing[p.Sq_off.Tail]))
+	r.sqMask = *(*uint32)(unsafe.Pointer(&r.sqRing[p.Sq_off.Ring_mask]))
+	r.sqEntries = *(*uint32)(unsafe.Pointer(&r.sqRing[p.Sq_off.Ring_entries]))
+	r.sqFlags = (*uint32)(unsafe.Pointer(&r.sqRing[p.Sq_off.Flags]))
+	r.sqArray = unsafe.Slice((*uint32)(unsafe.Pointer(&r.sqRing[p.Sq_off.Array])), p.Sq_entries)
+	r.sqes = unsafe.Slice((*IoUringSqe)(unsafe.Pointer(&r.sqeMem[0])), p.Sq_entries)
+
+	r.cqHead = (*uint32)(unsafe.Pointer(&r.cqRing[p.Cq_off.Head]))
+	r.cqTail = (*uint32)(unsafe.Pointer(&r.cqRing[p.Cq_off.Tail]))
+	r.cqMask = *(*uint32)(unsafe.Pointer(&r.cqRing[p.Cq_off.Ring_mask]))
+	r.cqes = unsafe.Slice((*IoUringCqe)(unsafe.Pointer(&r.cqRing[p.Cq_off.Cqes])), p.Cq_entries)
+
+	r.sqeHead = atomic.LoadUint32(r.sqTail)
+	r.sqeTail = r.sqeHead
+	return nil
+}
+
+func (r *IoUring) munmap() {
+	if r.sqeMem != nil {
+		Munmap(r.sqeMem)
+	}
+	if r.cqRing != nil && &r.cqRing[0] != &r.sqRing[0] {
+		Munmap(r.cqRing)
+	}
+	if r.sqRing != nil {
+		Munmap(r.sqRing)
+	}
+	r.sqRing, r.cqRing, r.sqeMem = nil, nil, nil
+	r.sqArray, r.sqes, r.cqes = nil, nil, nil
+}
+
+// Fd returns the file descriptor of the io_uring instance.
+func (r *IoUring) Fd() int { return r.fd }
+
+// Params returns the parameters the kernel filled in when the io_uring
+// instance was created, such as the queue sizes and supported features.
+func (r *IoUring) Params() IoUringParams { return r.params }
+
+// Close unmaps the rings and closes the io_uring file descriptor.
+func (r *IoUring) Close() error {
+	r.munmap()
+	r.bufs = nil
+	return Close(r.fd)
+}
+
+// NextSqe returns the next free submission queue entry, cleared to its zero
+// value, or nil if the submission queue is full. The entry is handed to the
+// kernel by the next call to Submit or SubmitAndWait.
+func (r *IoUring) NextSqe() *IoUringSqe {
+	head := atomic.LoadUint32(r.sqHead)
+	if r.sqeTail-head >= r.sqEntries {
+		return nil
+	}
+	sqe := &r.sqes[r.sqeTail&r.sqMask]
+	*sqe = IoUringSqe{}
+	r.sqeTail++
+	return sqe
+}
+
+// flush publishes the entries obtained by NextSqe to the kernel and returns
+// the number of entries published.
+func (r *IoUring) flush() uint32 {
+	tail := *r.sqTail
+	n := r.sqeTail - r.sqeHead
+	for ; r.sqeHead != r.sqeTail; r.sqeHead++ {
+		r.sqArray[tail&r.sqMask] = r.sqeHead & r.sqMask
+		tail++
+	}
+	atomic.StoreUint32(r.sqTail, tail)
+	return n
+}
+
+// Submit submits all prepared entries to the kernel without waiting for
+// completions. It returns the number of entries consumed by the kernel.
+func (r *IoUring) Submit() (int, error) {
+	return r.SubmitAndWait(0)
+}
+
+// SubmitAndWait submits all prepared entries to the kernel and waits until
+// at least waitNr completions are available. It returns the number of
+// entries consumed by the kernel.
+func (r *IoUring) SubmitAndWait(waitNr uint32) (int, error) {
+	n := r.flush()
+	var flags uint32
+	if waitNr > 0 {
+		flags |= IORING_ENTER_GETEVENTS
+	}
+	if r.params.Flags&IORING_SETUP_SQPOLL != 0 {
+		// The kernel thread consumes the submission queue on its own
+		// and only needs to be woken up once it went idle.
+		if atomic.LoadUint32(r.sqFlags)&IORING_SQ_NEED_WAKEUP != 0 {
+			flags |= IORING_ENTER_SQ_WAKEUP
+		} else if waitNr == 0 {
+			return int(n), nil
+		}
+	}
+	for {
+		m, err := IoUringEnter(r.fd, n, waitNr, flags, nil)
+		if err == EINTR {
+			continue
+		}
+		return m, err
+	}
+}
+
+// ReapCqe removes the next completion from the completion queue and
+// returns it. It does not block; ok is false if no completion is available.
+func (r *IoUring) ReapCqe() (cqe IoUringCqe, ok bool) {
+	head := *r.cqHead
+	if head == atomic.LoadUint32(r.cqTail) {
+		return IoUringCqe{}, false
+	}
+	cqe = r.cqes[head&r.cqMask]
+	atomic.StoreUint32(r.cqHead, head+1)
+	return cqe, true
+}
+
+// WaitCqe removes the next completion from the completion queue and
+// returns it, blocking until one is available. Prepared entries which have
+// not been submitted yet are submitted first.
+func (r *IoUring) WaitCqe() (IoUringCqe, error) {
+	for {
+		if cqe, ok := r.ReapCqe(); ok {
+			return cqe, nil
+		}
+		if _, err := r.SubmitAndWait(1); err != nil {
+			return IoUringCqe{}, err
+		}
+	}
+}
+
+// RegisterBuffers registers bufs with the kernel for use by
+// IORING_OP_READ_FIXED and IORING_OP_WRITE_FIXED. The buffers are referenced
+// by their index in bufs and are kept alive until UnregisterBuffers or Close
+// is called.
+func (r *IoUring) RegisterBuffers(bufs [][]byte) error {
+	iovs := appendBytes(make([]Iovec, 0, len(bufs)), bufs)
+	if len(iovs) == 0 {
+		return EINVAL
+	}
+	_, err := IoUringRegister(r.fd, IORING_REGISTER_BUFFERS, unsafe.Pointer(&iovs[0]), uint32(len(iovs)))
+	if err != nil {
+		return err
+	}
+	r.bufs = bufs
+	return nil
+}
+
+// UnregisterBuffers unregisters the buffers registered by RegisterBuffers.
+func (r *IoUring) UnregisterBuffers() error {
+	_, err := IoUringRegister(r.fd, IORING_UNREGISTER_BUFFERS, nil, 0)
+	if err != nil {
+		return err
+	}
+	r.bufs = nil
+	return nil
+}
+
+// RegisterFiles registers fds with the kernel. An entry with IOSQE_FIXED_FILE
+// set in its Flags refers to a registered file by its index in fds instead of
+// by file descriptor. An fd of -1 leaves the slot empty.
+func (r *IoUring) RegisterFiles(fds []int) error {
+	if len(fds) == 0 {
+		return EINVAL
+	}
+	files := make([]int32, len(fds))
+	for i, fd := range fds {
+		files[i] = int32(fd)
+	}
+	_, err := IoUringRegister(r.fd, IORING_REGISTER_FILES, unsafe.Pointer(&files[0]), uint32(len(files)))
+	return err
+}
+
+// UnregisterFiles unregisters the files registered by RegisterFiles.
+func (r *IoUring) UnregisterFiles() error {
+	_, err := IoUringRegister(r.fd, IORING_UNREGISTER_FILES, nil, 0)
+	return err
+}
+
+func (sqe *IoUringSqe) prepRW(op uint8, fd int, addr unsafe.Pointer, length uint32, offset uint64) {
+	sqe.Opcode = op
+	sqe.Fd = int32(fd)
+	sqe.Addr = uint64(uintptr(addr))
+	sqe.Len = length
+	sqe.Off = offset
+}
+
+func bytesPointer(b []byte) unsafe.Pointer {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&b[0])
+}
+
+func iovecsPointer(iovs []Iovec) unsafe.Pointer {
+	if len(iovs) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&iovs[0])
+}
+
+// PrepNop prepares sqe for an IORING_OP_NOP operation.
+func (sqe *IoUringSqe) PrepNop() {
+	sqe.prepRW(IORING_OP_NOP, -1, nil, 0, 0)
+}
+
+// PrepRead prepares sqe to read into b from fd at offset, like Pread.
+// An offset of ^uint64(0) reads from the current file position.
+func (sqe *IoUringSqe) PrepRead(fd int, b []byte, offset uint64) {
+	sqe.prepRW(IORING_OP_READ, fd, bytesPointer(b), uint32(len(b)), offset)
+}
+
+// PrepWrite prepares sqe to write b to fd at offset, like Pwrite.
+// An offset of ^uint64(0) writes at the current file position.
+func (sqe *IoUringSqe) PrepWrite(fd int, b []byte, offset uint64) {
+	sqe.prepRW(IORING_OP_WRITE, fd, bytesPointer(b), uint32(len(b)), offset)
+}
+
+// PrepReadv prepares sqe to read into iovs from fd at offset, like Preadv.
+// Per-call flags such as RWF_NOWAIT may be set in Op_flags, like Preadv2.
+func (sqe *IoUringSqe) PrepReadv(fd int, iovs []Iovec, offset uint64) {
+	sqe.prepRW(IORING_OP_READV, fd, iovecsPointer(iovs), uint32(len(iovs)), offset)
+}
+
+// PrepWritev prepares sqe to write iovs to fd at offset, like Pwritev.
+// Per-call flags such as RWF_DSYNC may be set in Op_flags, like Pwritev2.
+func (sqe *IoUringSqe) PrepWritev(fd int, iovs []Iovec, offset uint64) {
+	sqe.prepRW(IORING_OP_WRITEV, fd, iovecsPointer(iovs), uint32(len(iovs)), offset)
+}
+
+// PrepReadFixed prepares sqe to read into b from fd at offset. b must lie
+// within the buffer registered at bufIndex with RegisterBuffers.
+func (sqe *IoUringSqe) PrepReadFixed(fd int, b []byte, offset uint64, bufIndex int) {
+	sqe.prepRW(IORING_OP_READ_FIXED, fd, bytesPointer(b), uint32(len(b)), offset)
+	sqe.Buf_index = uint16(bufIndex)
+}
+
+// PrepWriteFixed prepares sqe to write b to fd at offset. b must lie within
+// the buffer registered at bufIndex with RegisterBuffers.
+func (sqe *IoUringSqe) PrepWriteFixed(fd int, b []byte, offset uint64, bufIndex int) {
+	sqe.prepRW(IORING_OP_WRITE_FIXED, fd, bytesPointer(b), uint32(len(b)), offset)
+	sqe.Buf_index = uint16(bufIndex)
+}
+
+// PrepFsync prepares sqe to sync fd to storage, like Fsync. If flags is
+// IORING_FSYNC_DATASYNC, it behaves like Fdatasync instead.
+func (sqe *IoUringSqe) PrepFsync(fd int, flags uint32) {
+	sqe.prepRW(IORING_OP_FSYNC, fd, nil, 0, 0)
+	sqe.Op_flags = flags
+}
+
+// PrepOpenat prepares sqe to open path relative to dirfd, like Openat.
+// The file descriptor is returned in the Res field of the completion.
+// path must be NUL-terminated, as returned by BytePtrFromString.
+func (sqe *IoUringSqe) PrepOpenat(dirfd int, path *byte, flags int, mode uint32) {
+	sqe.prepRW(IORING_OP_OPENAT, dirfd, unsafe.Pointer(path), mode, 0)
+	sqe.Op_flags = uint32(flags)
+}
+
+// PrepStatx prepares sqe to retrieve information about path relative to
+// dirfd into stat, like Statx. path must be NUL-terminated, as returned by
+// BytePtrFromString.
+func (sqe *IoUringSqe) PrepStatx(dirfd int, path *byte, flags int, mask int, stat *Statx_t) {
+	sqe.prepRW(IORING_OP_STATX, dirfd, unsafe.Pointer(path), uint32(mask), uint64(uintptr(unsafe.Pointer(stat))))
+	sqe.Op_flags = uint32(flags)
+}
+
+// PrepAccept prepares sqe to accept a connection on the listening socket
+// fd, like Accept4. The new file descriptor is returned in the Res field of
+// the completion.
+func (sqe *IoUringSqe) PrepAccept(fd int, flags int) {
+	sqe.prepRW(IORING_OP_ACCEPT, fd, nil, 0, 0)
+	sqe.Op_flags = uint32(flags)
+}
+
+// PrepConnect prepares sqe to connect the socket fd to sa, like Connect.
+func (sqe *IoUringSqe) PrepConnect(fd int, sa Sockaddr) error {
+	ptr, n, err := sa.sockaddr()
+	if err != nil {
+		return err
+	}
+	sqe.prepRW(IORING_OP_CONNECT, fd, ptr, 0, uint64(n))
+	return nil
+}
+
+// PrepSend prepares sqe to send b on the socket fd, like Sendto with a nil
+// destination.
+func (sqe *IoUringSqe) PrepSend(fd int, b []byte, flags int) {
+	sqe.prepRW(IORING_OP_SEND, fd, bytesPointer(b), uint32(len(b)), 0)
+	sqe.Op_flags = uint32(flags)
+}
+
+// PrepRecv prepares sqe to receive into b from the socket fd, like Recvfrom
+// without returning the source address.
+func (sqe *IoUringSqe) PrepRecv(fd int, b []byte, flags int) {
+	sqe.prepRW(IORING_OP_RECV, fd, bytesPointer(b), uint32(len(b)), 0)
+	sqe.Op_flags = uint32(flags)
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestIoUringStructSizes(t *testing.T) {
+	if got := unsafe.Sizeof(unix.IoUringSqe{}); got != unix.SizeofIoUringSqe {
+		t.Errorf("unexpected IoUringSqe size: got %d, want %d", got, unix.SizeofIoUringSqe)
+	}
+	if got := unsafe.Sizeof(unix.IoUringCqe{}); got != unix.SizeofIoUringCqe {
+		t.Errorf("unexpected IoUringCqe size: got %d, want %d", got, unix.SizeofIoUringCqe)
+	}
+	if got := unsafe.Sizeof(unix.IoUringParams{}); got != unix.SizeofIoUringParams {
+		t.Errorf("unexpected IoUringParams size: got %d, want %d", got, unix.SizeofIoUringParams)
+	}
+}
+
+func newTestIoUring(t *testing.T, entries uint32) *unix.IoUring {
+	t.Helper()
+	r, err := unix.NewIoUring(entries, nil)
+	if err == unix.ENOSYS || err == unix.EPERM {
+		t.Skipf("io_uring not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("NewIoUring: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func waitIoUringCqe(t *testing.T, r *unix.IoUring, userData uint64) int32 {
+	t.Helper()
+	cqe, err := r.WaitCqe()
+	if err != nil {
+		t.Fatalf("WaitCqe: %v", err)
+	}
+	if cqe.User_data != userData {
+		t.Fatalf("unexpected completion user data: got %d, want %d", cqe.User_data, userData)
+	}
+	if cqe.Res < 0 {
+		t.Fatalf("operation %d failed: %v", userData, unix.Errno(-cqe.Res))
+	}
+	return cqe.Res
+}
+
+func TestIoUringReadWrite(t *testing.T) {
+	r := newTestIoUring(t, 8)
+
+	path := filepath.Join(t.TempDir(), "data")
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqe := r.NextSqe()
+	sqe.PrepOpenat(unix.AT_FDCWD, p, unix.O_RDWR|unix.O_CREAT|unix.O_CLOEXEC, 0600)
+	sqe.User_data = 1
+	fd := int(waitIoUringCqe(t, r, 1))
+	defer unix.Close(fd)
+
+	want := []byte("hello, io_uring")
+	sqe = r.NextSqe()
+	sqe.PrepWrite(fd, want, 0)
+	sqe.User_data = 2
+	sqe.Flags |= unix.IOSQE_IO_LINK
+	sqe = r.NextSqe()
+	sqe.PrepFsync(fd, unix.IORING_FSYNC_DATASYNC)
+	sqe.User_data = 3
+	if _, err := r.Submit(); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if n := waitIoUringCqe(t, r, 2); int(n) != len(want) {
+		t.Fatalf("short write: %d", n)
+	}
+	waitIoUringCqe(t, r, 3)
+
+	got := make([]byte, len(want))
+	iov := unix.Iovec{Base: &got[0]}
+	iov.SetLen(len(got))
+	sqe = r.NextSqe()
+	sqe.PrepReadv(fd, []unix.Iovec{iov}, 0)
+	sqe.User_data = 4
+	if n := waitIoUringCqe(t, r, 4); int(n) != len(want) || !bytes.Equal(got, want) {
+		t.Fatalf("unexpected data read: %q", got[:n])
+	}
+
+	var stat unix.Statx_t
+	sqe = r.NextSqe()
+	sqe.PrepStatx(unix.AT_FDCWD, p, 0, unix.STATX_SIZE, &stat)
+	sqe.User_data = 5
+	waitIoUringCqe(t, r, 5)
+	if stat.Size != uint64(len(want)) {
+		t.Fatalf("unexpected size: got %d, want %d", stat.Size, len(want))
+	}
+}
+
+func TestIoUringRegistered(t *testing.T) {
+	r := newTestIoUring(t, 4)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want := []byte("registered")
+	if _, err := f.Write(want); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	if err := r.RegisterBuffers([][]byte{buf}); err != nil {
+		if err == unix.ENOMEM || err == unix.EPERM {
+			t.Skipf("cannot register buffers: %v", err)
+		}
+		t.Fatalf("RegisterBuffers: %v", err)
+	}
+	defer r.UnregisterBuffers()
+	if err := r.RegisterFiles([]int{int(f.Fd())}); err != nil {
+		t.Fatalf("RegisterFiles: %v", err)
+	}
+	defer r.UnregisterFiles()
+
+	sqe := r.NextSqe()
+	sqe.PrepReadFixed(0, buf[:len(want)], 0, 0)
+	sqe.Flags |= unix.IOSQE_FIXED_FILE
+	sqe.User_data = 42
+	if n := waitIoUringCqe(t, r, 42); int(n) != len(want) || !bytes.Equal(buf[:n], want) {
+		t.Fatalf("unexpected data read: %q", buf[:n])
+	}
+}
+
+func TestIoUringFull(t *testing.T) {
+	r := newTestIoUring(t, 2)
+	n := int(r.Params().Sq_entries)
+	for i := 0; i < n; i++ {
+		sqe := r.NextSqe()
+		if sqe == nil {
+			t.Fatalf("NextSqe returned nil after %d entries", i)
+		}
+		sqe.PrepNop()
+	}
+	if sqe := r.NextSqe(); sqe != nil {
+		t.Fatal("NextSqe returned an entry from a full queue")
+	}
+	if got, err := r.SubmitAndWait(uint32(n)); err != nil || got != n {
+		t.Fatalf("SubmitAndWait: %d, %v", got, err)
+	}
+	for i := 0; i < n; i++ {
+		if _, ok := r.ReapCqe(); !ok {
+			t.Fatalf("missing completion %d", i)
+		}
+	}
+	if _, ok := r.ReapCqe(); ok {
+		t.Fatal("unexpected extra completion")
+	}
+}
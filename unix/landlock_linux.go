@@ -0,0 +1,173 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+// Access rights which can be granted on regular files, as opposed to
+// directories, by a LANDLOCK_RULE_PATH_BENEATH rule.
+const landlockAccessFsFile = LANDLOCK_ACCESS_FS_EXECUTE |
+	LANDLOCK_ACCESS_FS_WRITE_FILE |
+	LANDLOCK_ACCESS_FS_READ_FILE |
+	LANDLOCK_ACCESS_FS_TRUNCATE |
+	LANDLOCK_ACCESS_FS_IOCTL_DEV
+
+// landlockAccessFs returns the filesystem access rights known to the given
+// Landlock ABI version.
+func landlockAccessFs(abi int) uint64 {
+	switch {
+	case abi < 1:
+		return 0
+	case abi < 2:
+		return LANDLOCK_ACCESS_FS_REFER - 1
+	case abi < 3:
+		return LANDLOCK_ACCESS_FS_TRUNCATE - 1
+	case abi < 5:
+		return LANDLOCK_ACCESS_FS_IOCTL_DEV - 1
+	default:
+		return LANDLOCK_ACCESS_FS_IOCTL_DEV<<1 - 1
+	}
+}
+
+// landlockAccessNet returns the network access rights known to the given
+// Landlock ABI version.
+func landlockAccessNet(abi int) uint64 {
+	if abi < 4 {
+		return 0
+	}
+	return LANDLOCK_ACCESS_NET_BIND_TCP | LANDLOCK_ACCESS_NET_CONNECT_TCP
+}
+
+// landlockScoped returns the scopes known to the given Landlock ABI version.
+func landlockScoped(abi int) uint64 {
+	if abi < 6 {
+		return 0
+	}
+	return LANDLOCK_SCOPE_ABSTRACT_UNIX_SOCKET | LANDLOCK_SCOPE_SIGNAL
+}
+
+// A LandlockRuleset is a Landlock ruleset which is built up by adding rules
+// and then enforced on the calling thread with RestrictSelf. Use
+// NewLandlockRuleset to create one.
+//
+// Access rights which the running kernel does not know about are silently
+// dropped, both from the set of handled accesses and from the rules, so the
+// same ruleset can be used on all kernels supporting Landlock and is
+// enforced as well as the running kernel allows. The ABI method reports the
+// version the ruleset was created for.
+type LandlockRuleset struct {
+	fd   int
+	abi  int
+	attr LandlockRulesetAttr
+}
+
+// NewLandlockRuleset probes the Landlock ABI version of the running kernel
+// and creates a ruleset handling the accesses in attr which that version
+// supports. Accesses handled by the ruleset are denied unless allowed by a
+// rule.
+//
+// If Landlock is not available, NewLandlockRuleset returns the error of
+// LandlockGetABIVersion.
+func NewLandlockRuleset(attr LandlockRulesetAttr) (*LandlockRuleset, error) {
+	abi, err := LandlockGetABIVersion()
+	if err != nil {
+		return nil, err
+	}
+	attr.Access_fs &= landlockAccessFs(abi)
+	attr.Access_net &= landlockAccessNet(abi)
+	attr.Scoped &= landlockScoped(abi)
+
+	r := &LandlockRuleset{fd: -1, abi: abi, attr: attr}
+	if attr == (LandlockRulesetAttr{}) {
+		// Nothing the running kernel knows about is left to restrict.
+		return r, nil
+	}
+	r.fd, err = LandlockCreateRuleset(&attr, 0)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ABI returns the Landlock ABI version of the running kernel.
+func (r *LandlockRuleset) ABI() int { return r.abi }
+
+// Fd returns the file descriptor of the ruleset, or -1 if none of the
+// handled accesses are supported by the running kernel.
+func (r *LandlockRuleset) Fd() int { return r.fd }
+
+// Handled returns the accesses handled by the ruleset after dropping those
+// unknown to the running kernel.
+func (r *LandlockRuleset) Handled() LandlockRulesetAttr { return r.attr }
+
+// Close closes the ruleset file descriptor. Restrictions already enforced
+// with RestrictSelf are not affected.
+func (r *LandlockRuleset) Close() error {
+	if r.fd < 0 {
+		return nil
+	}
+	return Close(r.fd)
+}
+
+// AddPath allows access to the file hierarchy at path. path is opened with
+// O_PATH and passed to AddPathFd.
+func (r *LandlockRuleset) AddPath(path string, access uint64) error {
+	fd, err := Open(path, O_PATH|O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer Close(fd)
+	return r.AddPathFd(fd, access)
+}
+
+// AddPathFd allows access to the file hierarchy referred to by fd, which is
+// typically opened with O_PATH. Accesses not handled by the ruleset are
+// dropped from access, as are accesses which only apply to directories if
+// fd does not refer to one.
+func (r *LandlockRuleset) AddPathFd(fd int, access uint64) error {
+	var st Stat_t
+	if err := Fstat(fd, &st); err != nil {
+		return err
+	}
+	access &= r.attr.Access_fs
+	if st.Mode&S_IFMT != S_IFDIR {
+		access &= landlockAccessFsFile
+	}
+	if access == 0 {
+		return nil
+	}
+	return LandlockAddPathBeneathRule(r.fd, &LandlockPathBeneathAttr{
+		Allowed_access: access,
+		Parent_fd:      int32(fd),
+	}, 0)
+}
+
+// AddNetPort allows access to the TCP port. Accesses not handled by the
+// ruleset are dropped from access.
+func (r *LandlockRuleset) AddNetPort(port uint16, access uint64) error {
+	access &= r.attr.Access_net
+	if access == 0 {
+		return nil
+	}
+	return LandlockAddNetPortRule(r.fd, &LandlockNetPortAttr{
+		Allowed_access: access,
+		Port:           uint64(port),
+	}, 0)
+}
+
+// RestrictSelf sets PR_SET_NO_NEW_PRIVS and enforces the ruleset on the
+// calling thread. Both only apply to the calling thread; threads created
+// afterwards inherit them. Callers should use runtime.LockOSThread to
+// control which thread is restricted.
+func (r *LandlockRuleset) RestrictSelf() error {
+	if err := Prctl(PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return err
+	}
+	if r.fd < 0 {
+		return nil
+	}
+	return LandlockRestrictSelf(r.fd, 0)
+}
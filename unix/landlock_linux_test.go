@@ -0,0 +1,88 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestLandlockRuleset(t *testing.T) {
+	abi, err := unix.LandlockGetABIVersion()
+	if err == unix.ENOSYS || err == unix.EOPNOTSUPP {
+		t.Skipf("landlock not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("LandlockGetABIVersion: %v", err)
+	}
+
+	allowed := t.TempDir()
+	denied := t.TempDir()
+	for _, dir := range []string{allowed, denied} {
+		if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rs, err := unix.NewLandlockRuleset(unix.LandlockRulesetAttr{
+		// Handle every access right, including ones unknown to the
+		// running kernel, which must be dropped.
+		Access_fs:  ^uint64(0),
+		Access_net: unix.LANDLOCK_ACCESS_NET_BIND_TCP,
+	})
+	if err != nil {
+		t.Fatalf("NewLandlockRuleset: %v", err)
+	}
+	defer rs.Close()
+	if rs.ABI() != abi {
+		t.Errorf("unexpected ABI version: got %d, want %d", rs.ABI(), abi)
+	}
+	if h := rs.Handled(); h.Access_fs == ^uint64(0) || h.Access_fs&unix.LANDLOCK_ACCESS_FS_READ_FILE == 0 {
+		t.Errorf("unexpected handled filesystem accesses: %#x", h.Access_fs)
+	}
+	if h := rs.Handled(); abi < 4 && h.Access_net != 0 {
+		t.Errorf("network accesses handled on ABI %d: %#x", abi, h.Access_net)
+	}
+
+	if err := rs.AddPath(allowed, unix.LANDLOCK_ACCESS_FS_READ_FILE|unix.LANDLOCK_ACCESS_FS_READ_DIR); err != nil {
+		t.Fatalf("AddPath(dir): %v", err)
+	}
+	// Directory-only rights must be dropped for a regular file.
+	if err := rs.AddPath(filepath.Join(allowed, "file"), unix.LANDLOCK_ACCESS_FS_READ_FILE|unix.LANDLOCK_ACCESS_FS_MAKE_DIR); err != nil {
+		t.Fatalf("AddPath(file): %v", err)
+	}
+	if err := rs.AddNetPort(8080, unix.LANDLOCK_ACCESS_NET_BIND_TCP); err != nil {
+		t.Fatalf("AddNetPort: %v", err)
+	}
+
+	// Restrict a dedicated thread, which is terminated when the goroutine
+	// exits without unlocking it.
+	errc := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if err := rs.RestrictSelf(); err != nil {
+			errc <- err
+			return
+		}
+		fd, err := unix.Open(filepath.Join(allowed, "file"), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			errc <- err
+			return
+		}
+		unix.Close(fd)
+		_, err = unix.Open(filepath.Join(denied, "file"), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		errc <- err
+	}()
+	if err := <-errc; err != unix.EACCES {
+		t.Fatalf("unexpected result opening a denied file: got %v, want %v", err, unix.EACCES)
+	}
+}
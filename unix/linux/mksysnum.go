@@ -36,15 +36,16 @@ func plusBuildTags() string {
 	return fmt.Sprintf("%s,%s", goarch, goos)
 }
 
-func format(name string, num int, offset int) string {
+// format returns the SYS_* constant of the system call name and its entry
+// in the sysnames table.
+func format(name string, num int, offset int) (string, string) {
 	if num > 999 {
 		// ignore deprecated syscalls that are no longer implemented
 		// https://git.kernel.org/cgit/linux/kernel/git/torvalds/linux.git/tree/include/uapi/asm-generic/unistd.h?id=refs/heads/master#n716
-		return ""
+		return "", ""
 	}
-	name = strings.ToUpper(name)
 	num = num + offset
-	return fmt.Sprintf("	SYS_%s = %d;\n", name, num)
+	return fmt.Sprintf("	SYS_%s = %d;\n", strings.ToUpper(name), num), fmt.Sprintf("	{%q, %d},\n", name, num)
 }
 
 func checkErr(err error) {
@@ -100,7 +101,12 @@ func main() {
 		fmt.Fprintf(os.Stderr, "can't run %s", cc)
 		os.Exit(1)
 	}
-	text := ""
+	text, names := "", ""
+	add := func(name string, num int, offset int) {
+		c, n := format(name, num, offset)
+		text += c
+		names += n
+	}
 	s := bufio.NewScanner(strings.NewReader(string(cmd)))
 	var offset, prev int
 	for s.Scan() {
@@ -118,24 +124,24 @@ func main() {
 		} else if t.Match(`^#define __NR_(\w+)\s+([0-9]+)`) {
 			prev, err = strconv.Atoi(t.sub[2])
 			checkErr(err)
-			text += format(t.sub[1], prev, offset)
+			add(t.sub[1], prev, offset)
 		} else if t.Match(`^#define __NR3264_(\w+)\s+([0-9]+)`) {
 			prev, err = strconv.Atoi(t.sub[2])
 			checkErr(err)
-			text += format(t.sub[1], prev, offset)
+			add(t.sub[1], prev, offset)
 		} else if t.Match(`^#define __NR_(\w+)\s+\(\w+\+\s*([0-9]+)\)`) {
 			r2, err := strconv.Atoi(t.sub[2])
 			checkErr(err)
-			text += format(t.sub[1], prev+r2, offset)
+			add(t.sub[1], prev+r2, offset)
 		} else if t.Match(`^#define __NR_(\w+)\s+\(__NR_(?:SYSCALL_BASE|Linux) \+ ([0-9]+)`) {
 			r2, err := strconv.Atoi(t.sub[2])
 			checkErr(err)
-			text += format(t.sub[1], r2, offset)
+			add(t.sub[1], r2, offset)
 		}
 	}
 	err = s.Err()
 	checkErr(err)
-	fmt.Printf(template, cmdLine(), goBuildTags(), plusBuildTags(), text)
+	fmt.Printf(template, cmdLine(), goBuildTags(), plusBuildTags(), text, names)
 }
 
 const template = `// %s
@@ -147,4 +153,12 @@ const template = `// %s
 package unix
 
 const(
-%s)`
+%s)
+
+// sysnames maps the names of the system calls in the kernel to their
+// numbers, for SeccompSyscallNumber.
+var sysnames = []struct {
+	name string
+	num  int
+}{
+%s}`
@@ -102,6 +102,7 @@ struct termios2 {
 #include <linux/filter.h>
 #include <linux/fs.h>
 #include <linux/fsverity.h>
+#include <linux/futex.h>
 #include <linux/genetlink.h>
 #include <linux/hdreg.h>
 #include <linux/hidraw.h>
@@ -113,12 +114,15 @@ struct termios2 {
 #include <linux/if_pppox.h>
 #include <linux/if_tun.h>
 #include <linux/if_xdp.h>
+#include <linux/inet_diag.h>
+#include <linux/io_uring.h>
 #include <linux/ipc.h>
 #include <linux/kcm.h>
 #include <linux/keyctl.h>
 #include <linux/landlock.h>
 #include <linux/loop.h>
 #include <linux/lwtunnel.h>
+#include <linux/mempolicy.h>
 #include <linux/mpls_iptunnel.h>
 #include <linux/ncsi.h>
 #include <linux/net_namespace.h>
@@ -133,16 +137,22 @@ struct termios2 {
 #include <linux/nfc.h>
 #include <linux/nl80211.h>
 #include <linux/openat2.h>
+#include <linux/packet_diag.h>
 #include <linux/perf_event.h>
 #include <linux/pps.h>
 #include <linux/random.h>
 #include <linux/rtc.h>
 #include <linux/rtnetlink.h>
+#include <linux/sched.h>
 #include <linux/shm.h>
+#include <linux/sock_diag.h>
 #include <linux/socket.h>
 #include <linux/stat.h>
 #include <linux/taskstats.h>
 #include <linux/tipc.h>
+#include <linux/unix_diag.h>
+#include <linux/userfaultfd.h>
+#include <linux/veth.h>
 #include <linux/virtio_net.h>
 #include <linux/vm_sockets.h>
 #include <linux/watchdog.h>
@@ -284,6 +294,38 @@ struct my_sockaddr_nfc_llcp {
 	size_t service_name_len;
 };
 
+// copied from /usr/include/linux/pidfd.h, which conflicts with fcntl.h.
+struct pidfd_info {
+	__u64 mask;
+	__u64 cgroupid;
+	__u32 pid;
+	__u32 tgid;
+	__u32 ppid;
+	__u32 ruid;
+	__u32 rgid;
+	__u32 euid;
+	__u32 egid;
+	__u32 suid;
+	__u32 sgid;
+	__u32 fsuid;
+	__u32 fsgid;
+	__s32 exit_code;
+};
+
+// copied from /usr/include/linux/sched/types.h, which conflicts with sched.h.
+struct sched_attr {
+	__u32 size;
+	__u32 sched_policy;
+	__u64 sched_flags;
+	__s32 sched_nice;
+	__u32 sched_priority;
+	__u64 sched_runtime;
+	__u64 sched_deadline;
+	__u64 sched_period;
+	__u32 sched_util_min;
+	__u32 sched_util_max;
+};
+
 #ifdef __ARM_EABI__
 typedef struct user_regs PtraceRegs;
 #elif defined(__aarch64__) || defined(__loongarch64)
@@ -426,6 +468,225 @@ struct my_can_bittiming_const {
 	__u32 brp_max;
 	__u32 brp_inc;
 };
+
+// io_uring_sqe_go is io_uring_sqe from <linux/io_uring.h> with the unions
+// collapsed to their most common member, so that godefs produces named
+// fields instead of opaque byte arrays.
+struct io_uring_sqe_go {
+	__u8 opcode;
+	__u8 flags;
+	__u16 ioprio;
+	__s32 fd;
+
+	// union {
+	//   __u64 off;
+	//   __u64 addr2;
+	//   ...
+	// };
+	__u64 off;
+
+	// union {
+	//   __u64 addr;
+	//   __u64 splice_off_in;
+	// };
+	__u64 addr;
+
+	__u32 len;
+
+	// union {
+	//   __kernel_rwf_t rw_flags;
+	//   __u32 fsync_flags;
+	//   __u16 poll_events;
+	//   ...
+	// };
+	__u32 op_flags;
+
+	__u64 user_data;
+
+	// union {
+	//   __u16 buf_index;
+	//   __u16 buf_group;
+	// };
+	__u16 buf_index;
+
+	__u16 personality;
+
+	// union {
+	//   __s32 splice_fd_in;
+	//   __u32 file_index;
+	//   ...
+	// };
+	__s32 splice_fd_in;
+
+	__u64 addr3;
+	__u64 __pad2[1];
+};
+
+// bpf_insn_go is bpf_insn from <linux/bpf.h> with the dst_reg and src_reg
+// bit fields merged into a single byte.
+struct bpf_insn_go {
+	__u8 code;
+	__u8 regs;
+	__s16 off;
+	__s32 imm;
+};
+
+// bpf_map_info_go is bpf_map_info from <linux/bpf.h> with an unsigned name,
+// which keeps its definition the same on all architectures.
+struct bpf_map_info_go {
+	__u32 type;
+	__u32 id;
+	__u32 key_size;
+	__u32 value_size;
+	__u32 max_entries;
+	__u32 map_flags;
+	__u8 name[BPF_OBJ_NAME_LEN];
+	__u32 ifindex;
+	__u32 btf_vmlinux_value_type_id;
+	__u64 netns_dev;
+	__u64 netns_ino;
+	__u32 btf_id;
+	__u32 btf_key_type_id;
+	__u32 btf_value_type_id;
+	__u32 btf_vmlinux_id;
+	__u64 map_extra;
+} __attribute__((aligned(8)));
+
+// The bpf_*_attr_go structures are the anonymous structures of union
+// bpf_attr from <linux/bpf.h> used by the respective bpf(2) commands.
+struct bpf_map_create_attr_go {
+	__u32 map_type;
+	__u32 key_size;
+	__u32 value_size;
+	__u32 max_entries;
+	__u32 map_flags;
+	__u32 inner_map_fd;
+	__u32 numa_node;
+	__u8 map_name[BPF_OBJ_NAME_LEN];
+	__u32 map_ifindex;
+	__u32 btf_fd;
+	__u32 btf_key_type_id;
+	__u32 btf_value_type_id;
+	__u32 btf_vmlinux_value_type_id;
+	__aligned_u64 map_extra;
+};
+
+struct bpf_map_elem_attr_go {
+	__u32 map_fd;
+	__aligned_u64 key;
+
+	// union {
+	//   __aligned_u64 value;
+	//   __aligned_u64 next_key;
+	// };
+	__aligned_u64 value;
+
+	__u64 flags;
+};
+
+struct bpf_prog_load_attr_go {
+	__u32 prog_type;
+	__u32 insn_cnt;
+	__aligned_u64 insns;
+	__aligned_u64 license;
+	__u32 log_level;
+	__u32 log_size;
+	__aligned_u64 log_buf;
+	__u32 kern_version;
+	__u32 prog_flags;
+	__u8 prog_name[BPF_OBJ_NAME_LEN];
+	__u32 prog_ifindex;
+	__u32 expected_attach_type;
+	__u32 prog_btf_fd;
+	__u32 func_info_rec_size;
+	__aligned_u64 func_info;
+	__u32 func_info_cnt;
+	__u32 line_info_rec_size;
+	__aligned_u64 line_info;
+	__u32 line_info_cnt;
+	__u32 attach_btf_id;
+
+	// union {
+	//   __u32 attach_prog_fd;
+	//   __u32 attach_btf_obj_fd;
+	// };
+	__u32 attach_prog_fd;
+
+	__u32 core_relo_cnt;
+	__aligned_u64 fd_array;
+	__aligned_u64 core_relos;
+	__u32 core_relo_rec_size;
+	__u32 log_true_size;
+};
+
+struct bpf_obj_attr_go {
+	__aligned_u64 pathname;
+	__u32 bpf_fd;
+	__u32 file_flags;
+};
+
+struct bpf_prog_attach_attr_go {
+	// union {
+	//   __u32 target_fd;
+	//   __u32 target_ifindex;
+	// };
+	__u32 target_fd;
+
+	__u32 attach_bpf_fd;
+	__u32 attach_type;
+	__u32 attach_flags;
+	__u32 replace_bpf_fd;
+};
+
+struct bpf_prog_test_run_attr_go {
+	__u32 prog_fd;
+	__u32 retval;
+	__u32 data_size_in;
+	__u32 data_size_out;
+	__aligned_u64 data_in;
+	__aligned_u64 data_out;
+	__u32 repeat;
+	__u32 duration;
+	__u32 ctx_size_in;
+	__u32 ctx_size_out;
+	__aligned_u64 ctx_in;
+	__aligned_u64 ctx_out;
+	__u32 flags;
+	__u32 cpu;
+	__u32 batch_size;
+};
+
+struct bpf_obj_get_info_attr_go {
+	__u32 bpf_fd;
+	__u32 info_len;
+	__aligned_u64 info;
+};
+
+struct bpf_link_create_attr_go {
+	// union {
+	//   __u32 prog_fd;
+	//   __u32 map_fd;
+	// };
+	__u32 prog_fd;
+
+	// union {
+	//   __u32 target_fd;
+	//   __u32 target_ifindex;
+	// };
+	__u32 target_fd;
+
+	__u32 attach_type;
+	__u32 flags;
+
+	// union {
+	//   __u32 target_btf_id;
+	//   struct { __aligned_u64 iter_info; __u32 iter_info_len; };
+	//   struct { __u64 bpf_cookie; } perf_event;
+	//   ...
+	// };
+	__u32 target_btf_id;
+	__u64 __pad[3];
+};
 */
 import "C"
 
@@ -800,6 +1061,7 @@ const (
 	RTA_IP_PROTO       = C.RTA_IP_PROTO
 	RTA_SPORT          = C.RTA_SPORT
 	RTA_DPORT          = C.RTA_DPORT
+	RTA_NH_ID          = C.RTA_NH_ID
 	RTN_UNSPEC         = C.RTN_UNSPEC
 	RTN_UNICAST        = C.RTN_UNICAST
 	RTN_LOCAL          = C.RTN_LOCAL
@@ -941,12 +1203,31 @@ const (
 	FSPICK_EMPTY_PATH       = C.FSPICK_EMPTY_PATH
 
 	FSMOUNT_CLOEXEC = C.FSMOUNT_CLOEXEC
+
+	FSCONFIG_SET_FLAG        = C.FSCONFIG_SET_FLAG
+	FSCONFIG_SET_STRING      = C.FSCONFIG_SET_STRING
+	FSCONFIG_SET_BINARY      = C.FSCONFIG_SET_BINARY
+	FSCONFIG_SET_PATH        = C.FSCONFIG_SET_PATH
+	FSCONFIG_SET_PATH_EMPTY  = C.FSCONFIG_SET_PATH_EMPTY
+	FSCONFIG_SET_FD          = C.FSCONFIG_SET_FD
+	FSCONFIG_CMD_CREATE      = C.FSCONFIG_CMD_CREATE
+	FSCONFIG_CMD_RECONFIGURE = C.FSCONFIG_CMD_RECONFIGURE
 )
 
 type OpenHow C.struct_open_how
 
 const SizeofOpenHow = C.sizeof_struct_open_how
 
+type CloneArgs C.struct_clone_args
+
+const SizeofCloneArgs = C.sizeof_struct_clone_args
+
+type SchedAttr C.struct_sched_attr
+
+const SizeofSchedAttr = C.sizeof_struct_sched_attr
+
+type SchedParam C.struct_sched_param
+
 const (
 	RESOLVE_BENEATH       = C.RESOLVE_BENEATH
 	RESOLVE_IN_ROOT       = C.RESOLVE_IN_ROOT
@@ -1637,6 +1918,13 @@ const (
 	IFLA_MACVLAN_MACADDR                       = C.IFLA_MACVLAN_MACADDR
 	IFLA_MACVLAN_MACADDR_DATA                  = C.IFLA_MACVLAN_MACADDR_DATA
 	IFLA_MACVLAN_MACADDR_COUNT                 = C.IFLA_MACVLAN_MACADDR_COUNT
+	MACVLAN_MODE_PRIVATE                       = C.MACVLAN_MODE_PRIVATE
+	MACVLAN_MODE_VEPA                          = C.MACVLAN_MODE_VEPA
+	MACVLAN_MODE_BRIDGE                        = C.MACVLAN_MODE_BRIDGE
+	MACVLAN_MODE_PASSTHRU                      = C.MACVLAN_MODE_PASSTHRU
+	MACVLAN_MODE_SOURCE                        = C.MACVLAN_MODE_SOURCE
+	VETH_INFO_UNSPEC                           = C.VETH_INFO_UNSPEC
+	VETH_INFO_PEER                             = C.VETH_INFO_PEER
 	IFLA_VRF_UNSPEC                            = C.IFLA_VRF_UNSPEC
 	IFLA_VRF_TABLE                             = C.IFLA_VRF_TABLE
 	IFLA_VRF_PORT_UNSPEC                       = C.IFLA_VRF_PORT_UNSPEC
@@ -2899,6 +3187,7 @@ const (
 
 type LoopInfo C.struct_loop_info
 type LoopInfo64 C.struct_loop_info64
+type LoopConfig C.struct_loop_config
 
 // AF_TIPC
 
@@ -3302,6 +3591,129 @@ const (
 	NHA_ENCAP      = C.NHA_ENCAP
 	NHA_GROUPS     = C.NHA_GROUPS
 	NHA_MASTER     = C.NHA_MASTER
+	NHA_FDB        = C.NHA_FDB
+)
+
+const (
+	SizeofNhmsg      = C.sizeof_struct_nhmsg
+	SizeofNexthopGrp = C.sizeof_struct_nexthop_grp
+)
+
+const (
+	NEXTHOP_GRP_TYPE_MPATH = C.NEXTHOP_GRP_TYPE_MPATH
+	NEXTHOP_GRP_TYPE_RES   = C.NEXTHOP_GRP_TYPE_RES
+)
+
+// sock_diag
+
+type SockDiagReq C.struct_sock_diag_req
+
+type InetDiagSockID C.struct_inet_diag_sockid
+
+type InetDiagReqV2 C.struct_inet_diag_req_v2
+
+type InetDiagMsg C.struct_inet_diag_msg
+
+type InetDiagMeminfo C.struct_inet_diag_meminfo
+
+type TCPVegasInfo C.struct_tcpvegas_info
+
+type TCPDctcpInfo C.struct_tcp_dctcp_info
+
+type TCPBBRInfo C.struct_tcp_bbr_info
+
+type UnixDiagReq C.struct_unix_diag_req
+
+type UnixDiagMsg C.struct_unix_diag_msg
+
+type UnixDiagVfs C.struct_unix_diag_vfs
+
+type UnixDiagRQlen C.struct_unix_diag_rqlen
+
+type PacketDiagReq C.struct_packet_diag_req
+
+type PacketDiagMsg C.struct_packet_diag_msg
+
+type PacketDiagInfo C.struct_packet_diag_info
+
+type PacketDiagMclist C.struct_packet_diag_mclist
+
+type PacketDiagRing C.struct_packet_diag_ring
+
+const (
+	SizeofSockDiagReq      = C.sizeof_struct_sock_diag_req
+	SizeofInetDiagSockID   = C.sizeof_struct_inet_diag_sockid
+	SizeofInetDiagReqV2    = C.sizeof_struct_inet_diag_req_v2
+	SizeofInetDiagMsg      = C.sizeof_struct_inet_diag_msg
+	SizeofInetDiagMeminfo  = C.sizeof_struct_inet_diag_meminfo
+	SizeofTCPVegasInfo     = C.sizeof_struct_tcpvegas_info
+	SizeofTCPDctcpInfo     = C.sizeof_struct_tcp_dctcp_info
+	SizeofTCPBBRInfo       = C.sizeof_struct_tcp_bbr_info
+	SizeofUnixDiagReq      = C.sizeof_struct_unix_diag_req
+	SizeofUnixDiagMsg      = C.sizeof_struct_unix_diag_msg
+	SizeofUnixDiagVfs      = C.sizeof_struct_unix_diag_vfs
+	SizeofUnixDiagRQlen    = C.sizeof_struct_unix_diag_rqlen
+	SizeofPacketDiagReq    = C.sizeof_struct_packet_diag_req
+	SizeofPacketDiagMsg    = C.sizeof_struct_packet_diag_msg
+	SizeofPacketDiagInfo   = C.sizeof_struct_packet_diag_info
+	SizeofPacketDiagMclist = C.sizeof_struct_packet_diag_mclist
+	SizeofPacketDiagRing   = C.sizeof_struct_packet_diag_ring
+)
+
+const (
+	INET_DIAG_REQ_NONE            = C.INET_DIAG_REQ_NONE
+	INET_DIAG_REQ_BYTECODE        = C.INET_DIAG_REQ_BYTECODE
+	INET_DIAG_REQ_SK_BPF_STORAGES = C.INET_DIAG_REQ_SK_BPF_STORAGES
+	INET_DIAG_REQ_PROTOCOL        = C.INET_DIAG_REQ_PROTOCOL
+	INET_DIAG_NONE                = C.INET_DIAG_NONE
+	INET_DIAG_MEMINFO             = C.INET_DIAG_MEMINFO
+	INET_DIAG_INFO                = C.INET_DIAG_INFO
+	INET_DIAG_VEGASINFO           = C.INET_DIAG_VEGASINFO
+	INET_DIAG_CONG                = C.INET_DIAG_CONG
+	INET_DIAG_TOS                 = C.INET_DIAG_TOS
+	INET_DIAG_TCLASS              = C.INET_DIAG_TCLASS
+	INET_DIAG_SKMEMINFO           = C.INET_DIAG_SKMEMINFO
+	INET_DIAG_SHUTDOWN            = C.INET_DIAG_SHUTDOWN
+	INET_DIAG_DCTCPINFO           = C.INET_DIAG_DCTCPINFO
+	INET_DIAG_PROTOCOL            = C.INET_DIAG_PROTOCOL
+	INET_DIAG_SKV6ONLY            = C.INET_DIAG_SKV6ONLY
+	INET_DIAG_LOCALS              = C.INET_DIAG_LOCALS
+	INET_DIAG_PEERS               = C.INET_DIAG_PEERS
+	INET_DIAG_PAD                 = C.INET_DIAG_PAD
+	INET_DIAG_MARK                = C.INET_DIAG_MARK
+	INET_DIAG_BBRINFO             = C.INET_DIAG_BBRINFO
+	INET_DIAG_CLASS_ID            = C.INET_DIAG_CLASS_ID
+	INET_DIAG_MD5SIG              = C.INET_DIAG_MD5SIG
+	INET_DIAG_ULP_INFO            = C.INET_DIAG_ULP_INFO
+	INET_DIAG_SK_BPF_STORAGES     = C.INET_DIAG_SK_BPF_STORAGES
+	INET_DIAG_CGROUP_ID           = C.INET_DIAG_CGROUP_ID
+	INET_DIAG_SOCKOPT             = C.INET_DIAG_SOCKOPT
+	SK_MEMINFO_RMEM_ALLOC         = C.SK_MEMINFO_RMEM_ALLOC
+	SK_MEMINFO_RCVBUF             = C.SK_MEMINFO_RCVBUF
+	SK_MEMINFO_WMEM_ALLOC         = C.SK_MEMINFO_WMEM_ALLOC
+	SK_MEMINFO_SNDBUF             = C.SK_MEMINFO_SNDBUF
+	SK_MEMINFO_FWD_ALLOC          = C.SK_MEMINFO_FWD_ALLOC
+	SK_MEMINFO_WMEM_QUEUED        = C.SK_MEMINFO_WMEM_QUEUED
+	SK_MEMINFO_OPTMEM             = C.SK_MEMINFO_OPTMEM
+	SK_MEMINFO_BACKLOG            = C.SK_MEMINFO_BACKLOG
+	SK_MEMINFO_DROPS              = C.SK_MEMINFO_DROPS
+	SK_MEMINFO_VARS               = C.SK_MEMINFO_VARS
+	UNIX_DIAG_NAME                = C.UNIX_DIAG_NAME
+	UNIX_DIAG_VFS                 = C.UNIX_DIAG_VFS
+	UNIX_DIAG_PEER                = C.UNIX_DIAG_PEER
+	UNIX_DIAG_ICONS               = C.UNIX_DIAG_ICONS
+	UNIX_DIAG_RQLEN               = C.UNIX_DIAG_RQLEN
+	UNIX_DIAG_MEMINFO             = C.UNIX_DIAG_MEMINFO
+	UNIX_DIAG_SHUTDOWN            = C.UNIX_DIAG_SHUTDOWN
+	UNIX_DIAG_UID                 = C.UNIX_DIAG_UID
+	PACKET_DIAG_INFO              = C.PACKET_DIAG_INFO
+	PACKET_DIAG_MCLIST            = C.PACKET_DIAG_MCLIST
+	PACKET_DIAG_RX_RING           = C.PACKET_DIAG_RX_RING
+	PACKET_DIAG_TX_RING           = C.PACKET_DIAG_TX_RING
+	PACKET_DIAG_FANOUT            = C.PACKET_DIAG_FANOUT
+	PACKET_DIAG_UID               = C.PACKET_DIAG_UID
+	PACKET_DIAG_MEMINFO           = C.PACKET_DIAG_MEMINFO
+	PACKET_DIAG_FILTER            = C.PACKET_DIAG_FILTER
 )
 
 // raw CAN sockets
@@ -3855,6 +4267,55 @@ const SPEED_UNKNOWN = C.SPEED_UNKNOWN
 
 type EthtoolDrvinfo C.struct_ethtool_drvinfo
 
+type (
+	EthtoolLinkSettings     C.struct_ethtool_link_settings
+	EthtoolRingparam        C.struct_ethtool_ringparam
+	EthtoolChannels         C.struct_ethtool_channels
+	EthtoolCoalesce         C.struct_ethtool_coalesce
+	EthtoolSsetInfo         C.struct_ethtool_sset_info
+	EthtoolGstrings         C.struct_ethtool_gstrings
+	EthtoolStats            C.struct_ethtool_stats
+	EthtoolGfeatures        C.struct_ethtool_gfeatures
+	EthtoolSfeatures        C.struct_ethtool_sfeatures
+	EthtoolGetFeaturesBlock C.struct_ethtool_get_features_block
+	EthtoolSetFeaturesBlock C.struct_ethtool_set_features_block
+)
+
+const (
+	SizeofEthtoolLinkSettings     = C.sizeof_struct_ethtool_link_settings
+	SizeofEthtoolSsetInfo         = C.sizeof_struct_ethtool_sset_info
+	SizeofEthtoolGstrings         = C.sizeof_struct_ethtool_gstrings
+	SizeofEthtoolStats            = C.sizeof_struct_ethtool_stats
+	SizeofEthtoolGfeatures        = C.sizeof_struct_ethtool_gfeatures
+	SizeofEthtoolSfeatures        = C.sizeof_struct_ethtool_sfeatures
+	SizeofEthtoolGetFeaturesBlock = C.sizeof_struct_ethtool_get_features_block
+	SizeofEthtoolSetFeaturesBlock = C.sizeof_struct_ethtool_set_features_block
+)
+
+const (
+	ETH_SS_TEST             = C.ETH_SS_TEST
+	ETH_SS_STATS            = C.ETH_SS_STATS
+	ETH_SS_PRIV_FLAGS       = C.ETH_SS_PRIV_FLAGS
+	ETH_SS_NTUPLE_FILTERS   = C.ETH_SS_NTUPLE_FILTERS
+	ETH_SS_FEATURES         = C.ETH_SS_FEATURES
+	ETH_SS_RSS_HASH_FUNCS   = C.ETH_SS_RSS_HASH_FUNCS
+	ETH_SS_TUNABLES         = C.ETH_SS_TUNABLES
+	ETH_SS_PHY_STATS        = C.ETH_SS_PHY_STATS
+	ETH_SS_PHY_TUNABLES     = C.ETH_SS_PHY_TUNABLES
+	ETH_SS_LINK_MODES       = C.ETH_SS_LINK_MODES
+	ETH_SS_MSG_CLASSES      = C.ETH_SS_MSG_CLASSES
+	ETH_SS_WOL_MODES        = C.ETH_SS_WOL_MODES
+	ETH_SS_SOF_TIMESTAMPING = C.ETH_SS_SOF_TIMESTAMPING
+	ETH_SS_TS_TX_TYPES      = C.ETH_SS_TS_TX_TYPES
+	ETH_SS_TS_RX_FILTERS    = C.ETH_SS_TS_RX_FILTERS
+	ETH_SS_UDP_TUNNEL_TYPES = C.ETH_SS_UDP_TUNNEL_TYPES
+	ETH_SS_STATS_STD        = C.ETH_SS_STATS_STD
+	ETH_SS_STATS_ETH_PHY    = C.ETH_SS_STATS_ETH_PHY
+	ETH_SS_STATS_ETH_MAC    = C.ETH_SS_STATS_ETH_MAC
+	ETH_SS_STATS_ETH_CTRL   = C.ETH_SS_STATS_ETH_CTRL
+	ETH_SS_STATS_RMON       = C.ETH_SS_STATS_RMON
+)
+
 type (
 	HIDRawReportDescriptor C.struct_hidraw_report_descriptor
 	HIDRawDevInfo          C.struct_hidraw_devinfo
@@ -3869,9 +4330,12 @@ const (
 // Netlink extended acknowledgement TLVs.
 
 const (
-	NLMSGERR_ATTR_MSG    = C.NLMSGERR_ATTR_MSG
-	NLMSGERR_ATTR_OFFS   = C.NLMSGERR_ATTR_OFFS
-	NLMSGERR_ATTR_COOKIE = C.NLMSGERR_ATTR_COOKIE
+	NLMSGERR_ATTR_MSG       = C.NLMSGERR_ATTR_MSG
+	NLMSGERR_ATTR_OFFS      = C.NLMSGERR_ATTR_OFFS
+	NLMSGERR_ATTR_COOKIE    = C.NLMSGERR_ATTR_COOKIE
+	NLMSGERR_ATTR_POLICY    = C.NLMSGERR_ATTR_POLICY
+	NLMSGERR_ATTR_MISS_TYPE = C.NLMSGERR_ATTR_MISS_TYPE
+	NLMSGERR_ATTR_MISS_NEST = C.NLMSGERR_ATTR_MISS_NEST
 )
 
 // MTD
@@ -3981,8 +4445,11 @@ type LandlockRulesetAttr C.struct_landlock_ruleset_attr
 
 type LandlockPathBeneathAttr C.struct_landlock_path_beneath_attr
 
+type LandlockNetPortAttr C.struct_landlock_net_port_attr
+
 const (
 	LANDLOCK_RULE_PATH_BENEATH = C.LANDLOCK_RULE_PATH_BENEATH
+	LANDLOCK_RULE_NET_PORT     = C.LANDLOCK_RULE_NET_PORT
 )
 
 // pidfd flags.
@@ -3991,6 +4458,54 @@ const (
 	PIDFD_NONBLOCK = C.O_NONBLOCK
 )
 
+type PidfdInfo C.struct_pidfd_info
+
+const SizeofPidfdInfo = C.sizeof_struct_pidfd_info
+
+// futex
+
+type FutexWaitv C.struct_futex_waitv
+
+const SizeofFutexWaitv = C.sizeof_struct_futex_waitv
+
+// userfaultfd
+
+type UffdMsg C.struct_uffd_msg
+
+type UffdioAPI C.struct_uffdio_api
+
+type UffdioRange C.struct_uffdio_range
+
+type UffdioRegister C.struct_uffdio_register
+
+type UffdioCopy C.struct_uffdio_copy
+
+type UffdioZeropage C.struct_uffdio_zeropage
+
+type UffdioWriteprotect C.struct_uffdio_writeprotect
+
+type UffdioContinue C.struct_uffdio_continue
+
+type UffdioPoison C.struct_uffdio_poison
+
+type UffdioMove C.struct_uffdio_move
+
+const (
+	SizeofUffdMsg = C.sizeof_struct_uffd_msg
+)
+
+// NUMA memory policies
+
+const (
+	MPOL_DEFAULT             = C.MPOL_DEFAULT
+	MPOL_PREFERRED           = C.MPOL_PREFERRED
+	MPOL_BIND                = C.MPOL_BIND
+	MPOL_INTERLEAVE          = C.MPOL_INTERLEAVE
+	MPOL_LOCAL               = C.MPOL_LOCAL
+	MPOL_PREFERRED_MANY      = C.MPOL_PREFERRED_MANY
+	MPOL_WEIGHTED_INTERLEAVE = C.MPOL_WEIGHTED_INTERLEAVE
+)
+
 // shm
 
 type SysvIpcPerm C.struct_ipc64_perm
@@ -5690,3 +6205,162 @@ const (
 	VIRTIO_NET_HDR_GSO_TCPV6 = C.VIRTIO_NET_HDR_GSO_TCPV6
 	VIRTIO_NET_HDR_GSO_ECN   = C.VIRTIO_NET_HDR_GSO_ECN
 )
+
+// io_uring
+
+type IoUringParams C.struct_io_uring_params
+
+type IoSqringOffsets C.struct_io_sqring_offsets
+
+type IoCqringOffsets C.struct_io_cqring_offsets
+
+type IoUringSqe C.struct_io_uring_sqe_go
+
+type IoUringCqe C.struct_io_uring_cqe
+
+const (
+	SizeofIoUringParams = C.sizeof_struct_io_uring_params
+	SizeofIoUringSqe    = C.sizeof_struct_io_uring_sqe_go
+	SizeofIoUringCqe    = C.sizeof_struct_io_uring_cqe
+)
+
+// generated by:
+// perl -nlE '/^\s*(IORING_OP_\w+)/ && say "$1 = C.$1"' include/uapi/linux/io_uring.h
+const (
+	IORING_OP_NOP             = C.IORING_OP_NOP
+	IORING_OP_READV           = C.IORING_OP_READV
+	IORING_OP_WRITEV          = C.IORING_OP_WRITEV
+	IORING_OP_FSYNC           = C.IORING_OP_FSYNC
+	IORING_OP_READ_FIXED      = C.IORING_OP_READ_FIXED
+	IORING_OP_WRITE_FIXED     = C.IORING_OP_WRITE_FIXED
+	IORING_OP_POLL_ADD        = C.IORING_OP_POLL_ADD
+	IORING_OP_POLL_REMOVE     = C.IORING_OP_POLL_REMOVE
+	IORING_OP_SYNC_FILE_RANGE = C.IORING_OP_SYNC_FILE_RANGE
+	IORING_OP_SENDMSG         = C.IORING_OP_SENDMSG
+	IORING_OP_RECVMSG         = C.IORING_OP_RECVMSG
+	IORING_OP_TIMEOUT         = C.IORING_OP_TIMEOUT
+	IORING_OP_TIMEOUT_REMOVE  = C.IORING_OP_TIMEOUT_REMOVE
+	IORING_OP_ACCEPT          = C.IORING_OP_ACCEPT
+	IORING_OP_ASYNC_CANCEL    = C.IORING_OP_ASYNC_CANCEL
+	IORING_OP_LINK_TIMEOUT    = C.IORING_OP_LINK_TIMEOUT
+	IORING_OP_CONNECT         = C.IORING_OP_CONNECT
+	IORING_OP_FALLOCATE       = C.IORING_OP_FALLOCATE
+	IORING_OP_OPENAT          = C.IORING_OP_OPENAT
+	IORING_OP_CLOSE           = C.IORING_OP_CLOSE
+	IORING_OP_FILES_UPDATE    = C.IORING_OP_FILES_UPDATE
+	IORING_OP_STATX           = C.IORING_OP_STATX
+	IORING_OP_READ            = C.IORING_OP_READ
+	IORING_OP_WRITE           = C.IORING_OP_WRITE
+	IORING_OP_FADVISE         = C.IORING_OP_FADVISE
+	IORING_OP_MADVISE         = C.IORING_OP_MADVISE
+	IORING_OP_SEND            = C.IORING_OP_SEND
+	IORING_OP_RECV            = C.IORING_OP_RECV
+	IORING_OP_OPENAT2         = C.IORING_OP_OPENAT2
+	IORING_OP_EPOLL_CTL       = C.IORING_OP_EPOLL_CTL
+	IORING_OP_SPLICE          = C.IORING_OP_SPLICE
+	IORING_OP_PROVIDE_BUFFERS = C.IORING_OP_PROVIDE_BUFFERS
+	IORING_OP_REMOVE_BUFFERS  = C.IORING_OP_REMOVE_BUFFERS
+	IORING_OP_TEE             = C.IORING_OP_TEE
+	IORING_OP_SHUTDOWN        = C.IORING_OP_SHUTDOWN
+	IORING_OP_RENAMEAT        = C.IORING_OP_RENAMEAT
+	IORING_OP_UNLINKAT        = C.IORING_OP_UNLINKAT
+	IORING_OP_MKDIRAT         = C.IORING_OP_MKDIRAT
+	IORING_OP_SYMLINKAT       = C.IORING_OP_SYMLINKAT
+	IORING_OP_LINKAT          = C.IORING_OP_LINKAT
+	IORING_OP_MSG_RING        = C.IORING_OP_MSG_RING
+	IORING_OP_FSETXATTR       = C.IORING_OP_FSETXATTR
+	IORING_OP_SETXATTR        = C.IORING_OP_SETXATTR
+	IORING_OP_FGETXATTR       = C.IORING_OP_FGETXATTR
+	IORING_OP_GETXATTR        = C.IORING_OP_GETXATTR
+	IORING_OP_SOCKET          = C.IORING_OP_SOCKET
+	IORING_OP_URING_CMD       = C.IORING_OP_URING_CMD
+	IORING_OP_SEND_ZC         = C.IORING_OP_SEND_ZC
+	IORING_OP_SENDMSG_ZC      = C.IORING_OP_SENDMSG_ZC
+	IORING_OP_LAST            = C.IORING_OP_LAST
+)
+
+// generated by:
+// perl -nlE '/^\s*((UN)?REGISTER_\w+)/ && say "IORING_$1 = C.IORING_$1"' include/uapi/linux/io_uring.h
+const (
+	IORING_REGISTER_BUFFERS          = C.IORING_REGISTER_BUFFERS
+	IORING_UNREGISTER_BUFFERS        = C.IORING_UNREGISTER_BUFFERS
+	IORING_REGISTER_FILES            = C.IORING_REGISTER_FILES
+	IORING_UNREGISTER_FILES          = C.IORING_UNREGISTER_FILES
+	IORING_REGISTER_EVENTFD          = C.IORING_REGISTER_EVENTFD
+	IORING_UNREGISTER_EVENTFD        = C.IORING_UNREGISTER_EVENTFD
+	IORING_REGISTER_FILES_UPDATE     = C.IORING_REGISTER_FILES_UPDATE
+	IORING_REGISTER_EVENTFD_ASYNC    = C.IORING_REGISTER_EVENTFD_ASYNC
+	IORING_REGISTER_PROBE            = C.IORING_REGISTER_PROBE
+	IORING_REGISTER_PERSONALITY      = C.IORING_REGISTER_PERSONALITY
+	IORING_UNREGISTER_PERSONALITY    = C.IORING_UNREGISTER_PERSONALITY
+	IORING_REGISTER_RESTRICTIONS     = C.IORING_REGISTER_RESTRICTIONS
+	IORING_REGISTER_ENABLE_RINGS     = C.IORING_REGISTER_ENABLE_RINGS
+	IORING_REGISTER_FILES2           = C.IORING_REGISTER_FILES2
+	IORING_REGISTER_FILES_UPDATE2    = C.IORING_REGISTER_FILES_UPDATE2
+	IORING_REGISTER_BUFFERS2         = C.IORING_REGISTER_BUFFERS2
+	IORING_REGISTER_BUFFERS_UPDATE   = C.IORING_REGISTER_BUFFERS_UPDATE
+	IORING_REGISTER_IOWQ_AFF         = C.IORING_REGISTER_IOWQ_AFF
+	IORING_UNREGISTER_IOWQ_AFF       = C.IORING_UNREGISTER_IOWQ_AFF
+	IORING_REGISTER_IOWQ_MAX_WORKERS = C.IORING_REGISTER_IOWQ_MAX_WORKERS
+	IORING_REGISTER_RING_FDS         = C.IORING_REGISTER_RING_FDS
+	IORING_UNREGISTER_RING_FDS       = C.IORING_UNREGISTER_RING_FDS
+	IORING_REGISTER_PBUF_RING        = C.IORING_REGISTER_PBUF_RING
+	IORING_UNREGISTER_PBUF_RING      = C.IORING_UNREGISTER_PBUF_RING
+	IORING_REGISTER_SYNC_CANCEL      = C.IORING_REGISTER_SYNC_CANCEL
+	IORING_REGISTER_FILE_ALLOC_RANGE = C.IORING_REGISTER_FILE_ALLOC_RANGE
+	IORING_REGISTER_LAST             = C.IORING_REGISTER_LAST
+)
+
+// seccomp
+
+type SeccompData C.struct_seccomp_data
+
+type SeccompNotifSizes C.struct_seccomp_notif_sizes
+
+type SeccompNotif C.struct_seccomp_notif
+
+type SeccompNotifResp C.struct_seccomp_notif_resp
+
+type SeccompNotifAddfd C.struct_seccomp_notif_addfd
+
+const (
+	SizeofSeccompData       = C.sizeof_struct_seccomp_data
+	SizeofSeccompNotif      = C.sizeof_struct_seccomp_notif
+	SizeofSeccompNotifResp  = C.sizeof_struct_seccomp_notif_resp
+	SizeofSeccompNotifAddfd = C.sizeof_struct_seccomp_notif_addfd
+)
+
+// bpf
+
+type BpfInsn C.struct_bpf_insn_go
+
+type BpfMapCreateAttr C.struct_bpf_map_create_attr_go
+
+type BpfMapElemAttr C.struct_bpf_map_elem_attr_go
+
+type BpfProgLoadAttr C.struct_bpf_prog_load_attr_go
+
+type BpfObjAttr C.struct_bpf_obj_attr_go
+
+type BpfProgAttachAttr C.struct_bpf_prog_attach_attr_go
+
+type BpfProgTestRunAttr C.struct_bpf_prog_test_run_attr_go
+
+type BpfObjGetInfoAttr C.struct_bpf_obj_get_info_attr_go
+
+type BpfLinkCreateAttr C.struct_bpf_link_create_attr_go
+
+type BpfMapInfo C.struct_bpf_map_info_go
+
+const (
+	SizeofBpfInsn            = C.sizeof_struct_bpf_insn_go
+	SizeofBpfMapCreateAttr   = C.sizeof_struct_bpf_map_create_attr_go
+	SizeofBpfMapElemAttr     = C.sizeof_struct_bpf_map_elem_attr_go
+	SizeofBpfProgLoadAttr    = C.sizeof_struct_bpf_prog_load_attr_go
+	SizeofBpfObjAttr         = C.sizeof_struct_bpf_obj_attr_go
+	SizeofBpfProgAttachAttr  = C.sizeof_struct_bpf_prog_attach_attr_go
+	SizeofBpfProgTestRunAttr = C.sizeof_struct_bpf_prog_test_run_attr_go
+	SizeofBpfObjGetInfoAttr  = C.sizeof_struct_bpf_obj_get_info_attr_go
+	SizeofBpfLinkCreateAttr  = C.sizeof_struct_bpf_link_create_attr_go
+	SizeofBpfMapInfo         = C.sizeof_struct_bpf_map_info_go
+)
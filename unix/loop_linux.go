@@ -0,0 +1,198 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"strconv"
+	"time"
+)
+
+const loopControlPath = "/dev/loop-control"
+
+// A LoopDevice is an open loop block device.
+type LoopDevice struct {
+	fd    int
+	index int
+}
+
+// LoopDevicePath returns the path of the loop device with the given index,
+// such as /dev/loop0.
+func LoopDevicePath(index int) string {
+	return "/dev/loop" + strconv.Itoa(index)
+}
+
+// OpenLoopDevice opens the loop device with the given index with the given
+// open flags, such as O_RDWR.
+func OpenLoopDevice(index, flags int) (*LoopDevice, error) {
+	fd, err := Open(LoopDevicePath(index), flags|O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &LoopDevice{fd: fd, index: index}, nil
+}
+
+// Fd returns the file descriptor of the loop device.
+func (d *LoopDevice) Fd() int { return d.fd }
+
+// Index returns the index of the loop device.
+func (d *LoopDevice) Index() int { return d.index }
+
+// Path returns the path of the loop device.
+func (d *LoopDevice) Path() string { return LoopDevicePath(d.index) }
+
+// Close closes the loop device. A device attached with LO_FLAGS_AUTOCLEAR
+// is detached when its last file descriptor is closed.
+func (d *LoopDevice) Close() error { return Close(d.fd) }
+
+// loopAttachRetries bounds the attempts to attach to a free loop device,
+// which another process may have taken since LOOP_CTL_GET_FREE.
+const loopAttachRetries = 16
+
+// LoopAttach attaches the backing file open as cfg.Fd to a free loop
+// device, allocated with LOOP_CTL_GET_FREE, and configures it with
+// cfg.Info and cfg.Block_size. The flags of cfg.Info may include
+// LO_FLAGS_READ_ONLY, LO_FLAGS_AUTOCLEAR, LO_FLAGS_PARTSCAN and
+// LO_FLAGS_DIRECT_IO. The device is configured with LOOP_CONFIGURE, or on
+// kernels older than Linux 5.8 with LOOP_SET_FD, LOOP_SET_STATUS64,
+// LOOP_SET_BLOCK_SIZE and LOOP_SET_DIRECT_IO, which is not atomic.
+//
+// With LO_FLAGS_AUTOCLEAR, the device is detached when the returned
+// LoopDevice and any other file descriptors of it are closed.
+func LoopAttach(cfg *LoopConfig) (*LoopDevice, error) {
+	ctl, err := Open(loopControlPath, O_RDWR|O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer Close(ctl)
+
+	mode := O_RDWR
+	if cfg.Info.Flags&LO_FLAGS_READ_ONLY != 0 {
+		mode = O_RDONLY
+	}
+	for i := 0; ; i++ {
+		index, err := IoctlLoopCtlGetFree(ctl)
+		if err != nil {
+			return nil, err
+		}
+		d, err := OpenLoopDevice(index, mode)
+		if err != nil {
+			return nil, err
+		}
+		err = d.configure(cfg)
+		if err == nil {
+			return d, nil
+		}
+		d.Close()
+		// EBUSY means that the device was attached by someone else.
+		if err != EBUSY || i == loopAttachRetries {
+			return nil, err
+		}
+	}
+}
+
+func (d *LoopDevice) configure(cfg *LoopConfig) error {
+	err := IoctlLoopConfigure(d.fd, cfg)
+	// Kernels without LOOP_CONFIGURE fail with EINVAL before looking at the
+	// configuration.
+	if err != EINVAL && err != ENOTTY {
+		return err
+	}
+	if err := IoctlLoopSetFd(d.fd, int(cfg.Fd)); err != nil {
+		return err
+	}
+	if err := d.configureAttached(cfg); err != nil {
+		IoctlLoopClrFd(d.fd)
+		return err
+	}
+	return nil
+}
+
+// configureAttached applies the configuration which LOOP_SET_FD does not.
+func (d *LoopDevice) configureAttached(cfg *LoopConfig) error {
+	info := cfg.Info
+	// The read-only flag is set by LOOP_SET_FD from the file mode, and
+	// direct I/O has its own ioctl.
+	info.Flags &^= LO_FLAGS_READ_ONLY | LO_FLAGS_DIRECT_IO
+	if err := IoctlLoopSetStatus64(d.fd, &info); err != nil {
+		return err
+	}
+	if cfg.Block_size != 0 {
+		if err := IoctlLoopSetBlockSize(d.fd, cfg.Block_size); err != nil {
+			return err
+		}
+	}
+	if cfg.Info.Flags&LO_FLAGS_DIRECT_IO != 0 {
+		return IoctlLoopSetDirectIO(d.fd, true)
+	}
+	return nil
+}
+
+// Status returns the status of the loop device (LOOP_GET_STATUS64). It
+// fails with ENXIO if no backing file is attached.
+func (d *LoopDevice) Status() (*LoopInfo64, error) {
+	return IoctlLoopGetStatus64(d.fd)
+}
+
+// SetStatus changes the offset, size limit, file name and the
+// LO_FLAGS_AUTOCLEAR and LO_FLAGS_PARTSCAN flags of the loop device
+// (LOOP_SET_STATUS64).
+func (d *LoopDevice) SetStatus(info *LoopInfo64) error {
+	return IoctlLoopSetStatus64(d.fd, info)
+}
+
+// SetCapacity makes the loop device reread the size of its backing file
+// after it was resized (LOOP_SET_CAPACITY).
+func (d *LoopDevice) SetCapacity() error {
+	return IoctlLoopSetCapacity(d.fd)
+}
+
+// SetBlockSize sets the logical block size of the loop device, a power of
+// two between 512 and the page size (LOOP_SET_BLOCK_SIZE).
+func (d *LoopDevice) SetBlockSize(size uint32) error {
+	return IoctlLoopSetBlockSize(d.fd, size)
+}
+
+// SetDirectIO enables or disables direct I/O on the backing file
+// (LOOP_SET_DIRECT_IO). Enabling it fails with EINVAL if the backing file
+// system does not support direct I/O.
+func (d *LoopDevice) SetDirectIO(on bool) error {
+	return IoctlLoopSetDirectIO(d.fd, on)
+}
+
+// Detach detaches the backing file from the loop device (LOOP_CLR_FD).
+// While the device is briefly held open by others, such as udev probing
+// it after a change, LOOP_CLR_FD fails with EBUSY; Detach retries for up to
+// timeout and then falls back to setting LO_FLAGS_AUTOCLEAR, so that the
+// device is detached when last closed, and returns EBUSY. Detaching a
+// device without a backing file returns ENXIO.
+func (d *LoopDevice) Detach(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	delay := time.Millisecond
+	for {
+		err := IoctlLoopClrFd(d.fd)
+		if err != EBUSY {
+			return err
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(delay)
+		if delay < 100*time.Millisecond {
+			delay *= 2
+		}
+	}
+	info, err := d.Status()
+	if err != nil {
+		return err
+	}
+	info.Flags |= LO_FLAGS_AUTOCLEAR
+	if err := d.SetStatus(info); err != nil {
+		return err
+	}
+	return EBUSY
+}
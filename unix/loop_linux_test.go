@@ -0,0 +1,116 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func loopBackingFile(t *testing.T, size int64) *os.File {
+	f, err := os.Create(filepath.Join(t.TempDir(), "backing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	if err := f.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func loopAttach(t *testing.T, cfg *unix.LoopConfig) *unix.LoopDevice {
+	d, err := unix.LoopAttach(cfg)
+	if err != nil {
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			t.Skipf("LoopAttach: %v", err)
+		}
+		t.Fatalf("LoopAttach: %v", err)
+	}
+	return d
+}
+
+func loopSize(t *testing.T, d *unix.LoopDevice) int64 {
+	size, err := unix.Seek(d.Fd(), 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return size
+}
+
+func TestLoopAttach(t *testing.T) {
+	f := loopBackingFile(t, 1<<20)
+	cfg := &unix.LoopConfig{Fd: uint32(f.Fd()), Block_size: 4096}
+	cfg.Info.Flags = unix.LO_FLAGS_READ_ONLY
+	cfg.Info.Offset = 4096
+	copy(cfg.Info.File_name[:], "backing")
+	d := loopAttach(t, cfg)
+	defer d.Close()
+
+	info, err := d.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if info.Flags&unix.LO_FLAGS_READ_ONLY == 0 || info.Offset != 4096 || unix.ByteSliceToString(info.File_name[:]) != "backing" {
+		t.Errorf("got flags %#x, offset %d, name %q", info.Flags, info.Offset, info.File_name[:])
+	}
+	if got, want := loopSize(t, d), int64(1<<20-4096); got != want {
+		t.Errorf("got size %d, want %d", got, want)
+	}
+	if bs, err := unix.IoctlGetInt(d.Fd(), unix.BLKSSZGET); err != nil || bs != 4096 {
+		t.Errorf("got block size %d, %v", bs, err)
+	}
+
+	// Grow the backing file.
+	if err := f.Truncate(2 << 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetCapacity(); err != nil {
+		t.Fatalf("SetCapacity: %v", err)
+	}
+	if got, want := loopSize(t, d), int64(2<<20-4096); got != want {
+		t.Errorf("after SetCapacity: got size %d, want %d", got, want)
+	}
+	if err := d.SetBlockSize(512); err != nil {
+		t.Fatalf("SetBlockSize: %v", err)
+	}
+
+	if err := d.Detach(time.Second); err != nil {
+		t.Fatalf("Detach: %v", err)
+	}
+	if _, err := d.Status(); err != unix.ENXIO {
+		t.Errorf("Status after Detach: got %v, want %v", err, unix.ENXIO)
+	}
+	if err := d.Detach(time.Second); err != unix.ENXIO {
+		t.Errorf("second Detach: got %v, want %v", err, unix.ENXIO)
+	}
+}
+
+func TestLoopAutoclear(t *testing.T) {
+	f := loopBackingFile(t, 1<<20)
+	cfg := &unix.LoopConfig{Fd: uint32(f.Fd())}
+	cfg.Info.Flags = unix.LO_FLAGS_AUTOCLEAR
+	d := loopAttach(t, cfg)
+	index := d.Index()
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := unix.OpenLoopDevice(index, unix.O_RDONLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if _, err := d.Status(); err != unix.ENXIO {
+		t.Errorf("Status after Close: got %v, want %v", err, unix.ENXIO)
+	}
+}
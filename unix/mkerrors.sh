@@ -222,6 +222,7 @@ struct ltchars {
 #include <linux/fs.h>
 #include <linux/fscrypt.h>
 #include <linux/fsverity.h>
+#include <linux/futex.h>
 #include <linux/genetlink.h>
 #include <linux/hdreg.h>
 #include <linux/hidraw.h>
@@ -234,7 +235,9 @@ struct ltchars {
 #include <linux/if_tun.h>
 #include <linux/if_packet.h>
 #include <linux/if_xdp.h>
+#include <linux/inet_diag.h>
 #include <linux/input.h>
+#include <linux/io_uring.h>
 #include <linux/kcm.h>
 #include <linux/kexec.h>
 #include <linux/keyctl.h>
@@ -243,6 +246,7 @@ struct ltchars {
 #include <linux/lwtunnel.h>
 #include <linux/magic.h>
 #include <linux/memfd.h>
+#include <linux/mempolicy.h>
 #include <linux/module.h>
 #include <linux/mount.h>
 #include <linux/netfilter/nfnetlink.h>
@@ -250,7 +254,9 @@ struct ltchars {
 #include <linux/net_namespace.h>
 #include <linux/nfc.h>
 #include <linux/nsfs.h>
+#include <linux/packet_diag.h>
 #include <linux/perf_event.h>
+#include <linux/pidfd.h>
 #include <linux/pps.h>
 #include <linux/ptrace.h>
 #include <linux/random.h>
@@ -258,11 +264,15 @@ struct ltchars {
 #include <linux/rtc.h>
 #include <linux/rtnetlink.h>
 #include <linux/sched.h>
+#include <linux/sched/types.h>
 #include <linux/seccomp.h>
 #include <linux/serial.h>
+#include <linux/sock_diag.h>
 #include <linux/sockios.h>
 #include <linux/taskstats.h>
 #include <linux/tipc.h>
+#include <linux/unix_diag.h>
+#include <linux/userfaultfd.h>
 #include <linux/vm_sockets.h>
 #include <linux/wait.h>
 #include <linux/watchdog.h>
@@ -513,10 +523,13 @@ ccflags="$@"
 		$2 ~ /^TC[IO](ON|OFF)$/ ||
 		$2 ~ /^IN_/ ||
 		$2 ~ /^KCM/ ||
+		$2 ~ /^IORING_(SETUP|ENTER|FEAT|OFF|SQ|CQ|CQE|FSYNC)_/ ||
+		$2 ~ /^IOSQE_[A-Z_]+$/ ||
 		$2 ~ /^LANDLOCK_/ ||
 		$2 ~ /^LOCK_(SH|EX|NB|UN)$/ ||
 		$2 ~ /^LO_(KEY|NAME)_SIZE$/ ||
-		$2 ~ /^LOOP_(CLR|CTL|GET|SET)_/ ||
+		$2 ~ /^LOOP_(CLR|CTL|GET|SET|CHANGE)_/ ||
+		$2 ~ /^LOOP_CONFIGURE/ ||
 		$2 ~ /^(AF|SOCK|SO|SOL|IPPROTO|IP|IPV6|TCP|MCAST|EVFILT|NOTE|SHUT|PROT|MAP|MFD|T?PACKET|MSG|SCM|MCL|DT|MADV|PR|LOCAL|TCPOPT)_/ ||
 		$2 ~ /^NFC_(GENL|PROTO|COMM|RF|SE|DIRECTION|LLCP|SOCKPROTO)_/ ||
 		$2 ~ /^NFC_.*_(MAX)?SIZE$/ ||
@@ -557,6 +570,13 @@ ccflags="$@"
 		$2 ~ /^RLIMIT_(AS|CORE|CPU|DATA|FSIZE|LOCKS|MEMLOCK|MSGQUEUE|NICE|NOFILE|NPROC|RSS|RTPRIO|RTTIME|SIGPENDING|STACK)|RLIM_INFINITY/ ||
 		$2 ~ /^PRIO_(PROCESS|PGRP|USER)/ ||
 		$2 ~ /^CLONE_[A-Z_]+/ ||
+		$2 ~ /^CLD_/ ||
+		$2 ~ /^MREMAP_/ ||
+		$2 ~ /^MPOL_(F_(STATIC_NODES|RELATIVE_NODES|NUMA_BALANCING|NODE|ADDR|MEMS_ALLOWED)|MF_(STRICT|MOVE|MOVE_ALL))$/ ||
+		$2 ~ /^SCHED_(NORMAL|FIFO|RR|BATCH|IDLE|DEADLINE|RESET_ON_FORK|FLAG_|ATTR_SIZE_)/ ||
+		$2 ~ /^FUTEX2?_/ ||
+		$2 !~ /^UFFD_API_/ &&
+		$2 ~ /^(UFFD(IO)?_|USERFAULTFD_)/ ||
 		$2 !~ /^(BPF_TIMEVAL|BPF_FIB_LOOKUP_[A-Z]+)$/ &&
 		$2 ~ /^(BPF|DLT)_/ ||
 		$2 ~ /^AUDIT_/ ||
@@ -578,8 +598,10 @@ ccflags="$@"
 		$2 ~ /^KEY_(SPEC|REQKEY_DEFL)_/ ||
 		$2 ~ /^KEYCTL_/ ||
 		$2 ~ /^PERF_/ ||
-		$2 ~ /^SECCOMP_MODE_/ ||
+		$2 ~ /^SECCOMP_(MODE|SET_MODE|GET|FILTER_FLAG|RET|USER_NOTIF|ADDFD|IOCTL)_/ ||
 		$2 ~ /^SEEK_/ ||
+		$2 ~ /^(UDIAG_SHOW|PDI|TCPDIAG|DCCPDIAG)_/ ||
+		$2 == "INET_DIAG_NOCOOKIE" ||
 		$2 ~ /^SPLICE_/ ||
 		$2 ~ /^SYNC_FILE_RANGE_/ ||
 		$2 !~ /IOC_MAGIC/ &&
@@ -606,11 +628,14 @@ ccflags="$@"
 		$2 !~  "DEVLINK_RELOAD_LIMITS_VALID_MASK" &&
 		$2 ~ /^DEVLINK_/ ||
 		$2 ~ /^ETHTOOL_/ ||
+		$2 == "ETH_GSTRING_LEN" ||
+		$2 ~ /^(DUPLEX|AUTONEG)_/ ||
 		$2 ~ /^LWTUNNEL_IP/ ||
 		$2 ~ /^ITIMER_/ ||
 		$2 !~ "WMESGLEN" &&
 		$2 ~ /^W[A-Z0-9]+$/ ||
 		$2 ~ /^P_/ ||
+		$2 ~ /^PIDFD_(GET_INFO|INFO_|SELF|SIGNAL_|THREAD$)/ ||
 		$2 ~/^PPPIOC/ ||
 		$2 ~ /^FAN_|FANOTIFY_/ ||
 		$2 == "HID_MAX_DESCRIPTOR_SIZE" ||
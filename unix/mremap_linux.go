@@ -0,0 +1,106 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "unsafe"
+
+// mremapMmapper is an mmapper which can also remap its mappings.
+type mremapMmapper struct {
+	mmapper
+	mremap func(oldaddr uintptr, oldlength uintptr, newlength uintptr, flags int, newaddr uintptr) (xaddr uintptr, err error)
+}
+
+// mmapAt is like Mmap, but passes addr to the kernel as the address of the
+// mapping.
+func (m *mremapMmapper) mmapAt(addr uintptr, fd int, offset int64, length int, prot int, flags int) ([]byte, error) {
+	if length <= 0 {
+		return nil, EINVAL
+	}
+	xaddr, errno := m.mmap(addr, uintptr(length), prot, flags, fd, offset)
+	if errno != nil {
+		return nil, errno
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	return m.add(xaddr, length), nil
+}
+
+func (m *mremapMmapper) Mremap(oldData []byte, newAddr uintptr, newLength int, flags int) ([]byte, error) {
+	if newLength <= 0 || len(oldData) == 0 || len(oldData) != cap(oldData) {
+		return nil, EINVAL
+	}
+
+	// Find the base of the mapping.
+	pOld := &oldData[cap(oldData)-1]
+	m.Lock()
+	defer m.Unlock()
+	bOld := m.active[pOld]
+	if bOld == nil || &bOld[0] != &oldData[0] {
+		return nil, EINVAL
+	}
+
+	// MREMAP_FIXED replaces any mapping in the new range. A mapping only
+	// partly replaced could no longer be unmapped with Munmap, so refuse
+	// to split it.
+	pagesize := uintptr(Getpagesize())
+	pageEnd := func(start uintptr, length int) uintptr {
+		return (start + uintptr(length) + pagesize - 1) &^ (pagesize - 1)
+	}
+	var replaced []*byte
+	if flags&MREMAP_FIXED != 0 {
+		end := pageEnd(newAddr, newLength)
+		for p, b := range m.active {
+			start := uintptr(unsafe.Pointer(&b[0]))
+			if start >= end || newAddr >= pageEnd(start, len(b)) {
+				continue
+			}
+			if start < newAddr || pageEnd(start, len(b)) > end {
+				return nil, EINVAL
+			}
+			replaced = append(replaced, p)
+		}
+	}
+
+	xaddr, errno := m.mremap(uintptr(unsafe.Pointer(&bOld[0])), uintptr(len(bOld)), uintptr(newLength), flags, newAddr)
+	if errno != nil {
+		return nil, errno
+	}
+
+	// Update m: the old mapping is gone unless MREMAP_DONTUNMAP is set.
+	if flags&MREMAP_DONTUNMAP == 0 {
+		delete(m.active, pOld)
+	}
+	for _, p := range replaced {
+		delete(m.active, p)
+	}
+	return m.add(xaddr, newLength), nil
+}
+
+// Mremap resizes the mapping oldData, returned by Mmap or a previous Mremap,
+// to newLength bytes, and returns the new mapping (mremap(2)). If flags
+// includes MREMAP_MAYMOVE, the mapping may be moved to a new address if it
+// cannot be resized in place, which invalidates oldData. If flags also
+// includes MREMAP_DONTUNMAP, the mapping is moved and oldData remains
+// mapped, but its pages are moved to the new mapping, and must be unmapped
+// with Munmap separately.
+func Mremap(oldData []byte, newLength int, flags int) ([]byte, error) {
+	if flags&MREMAP_FIXED != 0 {
+		return nil, EINVAL
+	}
+	return mapper.Mremap(oldData, 0, newLength, flags)
+}
+
+// MremapFixed is like Mremap with MREMAP_MAYMOVE and MREMAP_FIXED, which
+// moves the mapping oldData to the page-aligned address newAddr, replacing
+// any mapping in the new range, including mappings returned by Mmap, which
+// must not be used afterwards. It returns EINVAL if the new range covers
+// only part of a mapping returned by Mmap or Mremap.
+func MremapFixed(oldData []byte, newAddr uintptr, newLength int, flags int) ([]byte, error) {
+	return mapper.Mremap(oldData, newAddr, newLength, flags|MREMAP_MAYMOVE|MREMAP_FIXED)
+}
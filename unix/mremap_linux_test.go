@@ -0,0 +1,109 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func mmapPages(t *testing.T, n int) []byte {
+	b, err := unix.Mmap(-1, 0, n*unix.Getpagesize(), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestMremap(t *testing.T) {
+	pagesize := unix.Getpagesize()
+	b := mmapPages(t, 1)
+	b[0] = 'a'
+
+	if _, err := unix.Mremap(b, 2*pagesize, unix.MREMAP_FIXED); err != unix.EINVAL {
+		t.Errorf("Mremap with MREMAP_FIXED: got %v, want %v", err, unix.EINVAL)
+	}
+	if _, err := unix.Mremap(b[:1], pagesize, 0); err != unix.EINVAL {
+		t.Errorf("Mremap of a partial mapping: got %v, want %v", err, unix.EINVAL)
+	}
+
+	bb, err := unix.Mremap(b, 3*pagesize, unix.MREMAP_MAYMOVE)
+	if err != nil {
+		t.Fatalf("Mremap: %v", err)
+	}
+	if len(bb) != 3*pagesize || bb[0] != 'a' {
+		t.Errorf("Mremap: got %d bytes starting with %q", len(bb), bb[0])
+	}
+	bb[len(bb)-1] = 'z'
+	if err := unix.Munmap(b); err != unix.EINVAL {
+		t.Errorf("Munmap of the old mapping: got %v, want %v", err, unix.EINVAL)
+	}
+
+	moved, err := unix.Mremap(bb, len(bb), unix.MREMAP_MAYMOVE|unix.MREMAP_DONTUNMAP)
+	if err == nil {
+		if moved[0] != 'a' || moved[len(moved)-1] != 'z' {
+			t.Errorf("Mremap with MREMAP_DONTUNMAP: got %q...%q", moved[0], moved[len(moved)-1])
+		}
+		// The old mapping remains, without its pages.
+		if bb[0] != 0 {
+			t.Errorf("got %q in the old mapping", bb[0])
+		}
+		if err := unix.Munmap(bb); err != nil {
+			t.Errorf("Munmap of the old mapping: %v", err)
+		}
+		bb = moved
+	} else {
+		t.Logf("Mremap with MREMAP_DONTUNMAP: %v", err)
+	}
+	if err := unix.Munmap(bb); err != nil {
+		t.Errorf("Munmap: %v", err)
+	}
+}
+
+func TestMremapFixed(t *testing.T) {
+	target := mmapPages(t, 1)
+	src := mmapPages(t, 1)
+	src[0] = 'a'
+
+	addr := uintptr(unsafe.Pointer(&target[0]))
+	b, err := unix.MremapFixed(src, addr, len(src), 0)
+	if err != nil {
+		t.Fatalf("MremapFixed: %v", err)
+	}
+	if uintptr(unsafe.Pointer(&b[0])) != addr || b[0] != 'a' {
+		t.Errorf("MremapFixed: got %q at %p, want 'a' at %#x", b[0], &b[0], addr)
+	}
+	// The replaced mapping and the moved mapping are both forgotten.
+	for _, old := range [][]byte{src, target[:1]} {
+		if err := unix.Munmap(old); err != unix.EINVAL {
+			t.Errorf("Munmap of a replaced mapping: got %v, want %v", err, unix.EINVAL)
+		}
+	}
+	if err := unix.Munmap(b); err != nil {
+		t.Errorf("Munmap: %v", err)
+	}
+}
+
+func TestMremapFixedPartialOverlap(t *testing.T) {
+	pagesize := unix.Getpagesize()
+	target := mmapPages(t, 2)
+	src := mmapPages(t, 1)
+
+	// Replacing the second page of target would split it.
+	addr := uintptr(unsafe.Pointer(&target[pagesize]))
+	if _, err := unix.MremapFixed(src, addr, len(src), 0); err != unix.EINVAL {
+		t.Errorf("MremapFixed over part of a mapping: got %v, want %v", err, unix.EINVAL)
+	}
+	for _, b := range [][]byte{target, src} {
+		if err := unix.Munmap(b); err != nil {
+			t.Errorf("Munmap: %v", err)
+		}
+	}
+}
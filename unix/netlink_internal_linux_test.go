@@ -0,0 +1,80 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "testing"
+
+func Test_parseNetlinkError(t *testing.T) {
+	code := func(errno Errno) []byte {
+		b := make([]byte, 4)
+		nativeEndian.PutUint32(b, uint32(-int32(errno)))
+		return b
+	}
+	var attrs NetlinkAttrEncoder
+	attrs.String(NLMSGERR_ATTR_MSG, "dump failed")
+	attrs.Uint32(NLMSGERR_ATTR_OFFS, 20)
+
+	// The header of the request, capped, or the complete request.
+	req := NetlinkMessage{Header: NlMsghdr{Type: NLMSG_MIN_TYPE}, Data: make([]byte, 8)}
+	capped := req.Marshal()[:SizeofNlMsghdr]
+	full := req.Marshal()
+
+	tests := []struct {
+		name  string
+		typ   uint16
+		flags uint16
+		data  []byte
+		want  NetlinkError
+	}{
+		{
+			name:  "done",
+			typ:   NLMSG_DONE,
+			flags: NLM_F_MULTI | NLM_F_ACK_TLVS,
+			data:  append(code(EOPNOTSUPP), attrs.Bytes()...),
+			want:  NetlinkError{Errno: EOPNOTSUPP, Msg: "dump failed", Offset: 20},
+		},
+		{
+			name:  "done without tlvs",
+			typ:   NLMSG_DONE,
+			flags: NLM_F_MULTI,
+			data:  code(EOPNOTSUPP),
+			want:  NetlinkError{Errno: EOPNOTSUPP},
+		},
+		{
+			name:  "error capped",
+			typ:   NLMSG_ERROR,
+			flags: NLM_F_CAPPED | NLM_F_ACK_TLVS,
+			data:  append(append(code(EINVAL), capped...), attrs.Bytes()...),
+			want:  NetlinkError{Errno: EINVAL, Msg: "dump failed", Offset: 20},
+		},
+		{
+			name:  "error with request",
+			typ:   NLMSG_ERROR,
+			flags: NLM_F_ACK_TLVS,
+			data:  append(append(code(EINVAL), full...), attrs.Bytes()...),
+			want:  NetlinkError{Errno: EINVAL, Msg: "dump failed", Offset: 20},
+		},
+	}
+	for _, tt := range tests {
+		m := &NetlinkMessage{Header: NlMsghdr{Type: tt.typ, Flags: tt.flags}, Data: tt.data}
+		err := parseNetlinkError(m)
+		nerr, ok := err.(*NetlinkError)
+		if !ok {
+			t.Errorf("%s: got %v, want a *NetlinkError", tt.name, err)
+			continue
+		}
+		if *nerr != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, *nerr, tt.want)
+		}
+	}
+
+	ack := &NetlinkMessage{Header: NlMsghdr{Type: NLMSG_DONE, Flags: NLM_F_MULTI}, Data: code(0)}
+	if err := parseNetlinkError(ack); err != nil {
+		t.Errorf("done without error: got %v, want nil", err)
+	}
+}
@@ -0,0 +1,563 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// nativeEndian is the byte order of the host, used by netlink for message
+// headers and most attributes.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	if isBigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}()
+
+// nlaTypeMask masks the NLA_F_NESTED and NLA_F_NET_BYTEORDER flags out of
+// the type of a netlink attribute.
+const nlaTypeMask = ^uint16(NLA_F_NESTED | NLA_F_NET_BYTEORDER)
+
+func nlmAlignOf(msglen int) int {
+	return (msglen + NLMSG_ALIGNTO - 1) & ^(NLMSG_ALIGNTO - 1)
+}
+
+func nlaAlignOf(attrlen int) int {
+	return (attrlen + NLA_ALIGNTO - 1) & ^(NLA_ALIGNTO - 1)
+}
+
+// A NetlinkMessage is a netlink message: a header followed by a payload,
+// which usually consists of a family-specific fixed header and netlink
+// attributes.
+type NetlinkMessage struct {
+	Header NlMsghdr
+	Data   []byte
+}
+
+// Marshal returns the message in wire format. The Len field of the header
+// is set to the length of the message.
+func (m *NetlinkMessage) Marshal() []byte {
+	m.Header.Len = uint32(NLMSG_HDRLEN + len(m.Data))
+	b := make([]byte, nlmAlignOf(int(m.Header.Len)))
+	*(*NlMsghdr)(unsafe.Pointer(&b[0])) = m.Header
+	copy(b[NLMSG_HDRLEN:], m.Data)
+	return b
+}
+
+// ParseNetlinkMessages parses the netlink messages in b, such as a datagram
+// received from a netlink socket. The payloads of the returned messages
+// refer to b.
+func ParseNetlinkMessages(b []byte) ([]NetlinkMessage, error) {
+	var msgs []NetlinkMessage
+	for len(b) >= NLMSG_HDRLEN {
+		h := *(*NlMsghdr)(unsafe.Pointer(&b[0]))
+		if int(h.Len) < NLMSG_HDRLEN || int(h.Len) > len(b) {
+			return nil, EINVAL
+		}
+		msgs = append(msgs, NetlinkMessage{Header: h, Data: b[NLMSG_HDRLEN:h.Len:h.Len]})
+		n := nlmAlignOf(int(h.Len))
+		if n > len(b) {
+			break
+		}
+		b = b[n:]
+	}
+	return msgs, nil
+}
+
+// A NetlinkError is an error reported by the kernel in an NLMSG_ERROR
+// message, or in the NLMSG_DONE message ending a dump, including the
+// extended acknowledgement attributes if the kernel supports them.
+type NetlinkError struct {
+	Errno Errno
+
+	// Msg is the NLMSGERR_ATTR_MSG error message, if any.
+	Msg string
+
+	// Offset is the NLMSGERR_ATTR_OFFS offset of the offending attribute
+	// in the request, including its netlink header, or 0 if unknown.
+	Offset uint32
+
+	// MissingType is the NLMSGERR_ATTR_MISS_TYPE type of a required
+	// attribute missing from the request, or 0 if unknown.
+	MissingType uint16
+}
+
+func (e *NetlinkError) Error() string {
+	if e.Msg == "" {
+		return "netlink: " + e.Errno.Error()
+	}
+	return "netlink: " + e.Msg + ": " + e.Errno.Error()
+}
+
+func (e *NetlinkError) Unwrap() error { return e.Errno }
+
+// parseNetlinkError parses the payload of an NLMSG_ERROR message, or of an
+// NLMSG_DONE message ending a dump, which starts with an error code. It
+// returns nil for acknowledgements.
+func parseNetlinkError(m *NetlinkMessage) error {
+	if len(m.Data) < 4 {
+		return EINVAL
+	}
+	code := int32(nativeEndian.Uint32(m.Data))
+	if code == 0 {
+		return nil
+	}
+	e := &NetlinkError{Errno: Errno(-code)}
+	if m.Header.Flags&NLM_F_ACK_TLVS == 0 {
+		return e
+	}
+	// In NLMSG_DONE, the attributes follow the error code. In NLMSG_ERROR,
+	// the header of the request follows it, and the rest of the request
+	// too unless NLM_F_CAPPED is set.
+	off := 4
+	if m.Header.Type == NLMSG_ERROR {
+		if len(m.Data) < SizeofNlMsgerr {
+			return e
+		}
+		off = SizeofNlMsgerr
+		if m.Header.Flags&NLM_F_CAPPED == 0 {
+			req := (*NlMsghdr)(unsafe.Pointer(&m.Data[4]))
+			off = 4 + nlmAlignOf(int(req.Len))
+		}
+		if off > len(m.Data) {
+			return e
+		}
+	}
+	d := NewNetlinkAttrDecoder(m.Data[off:])
+	for d.Next() {
+		switch d.Type() {
+		case NLMSGERR_ATTR_MSG:
+			e.Msg = d.String()
+		case NLMSGERR_ATTR_OFFS:
+			e.Offset = d.Uint32()
+		case NLMSGERR_ATTR_MISS_TYPE:
+			e.MissingType = uint16(d.Uint32())
+		}
+	}
+	return e
+}
+
+// A NetlinkConn is a netlink socket of a single netlink family, such as
+// NETLINK_ROUTE, which tracks the sequence numbers of requests.
+type NetlinkConn struct {
+	fd     int
+	portID uint32
+	seq    uint32
+
+	// mu serializes Execute, so that concurrent requests do not receive
+	// each other's replies.
+	mu sync.Mutex
+}
+
+// NewNetlinkConn opens a netlink socket of the given family and binds it to
+// a port ID assigned by the kernel. Extended acknowledgements are enabled
+// if the kernel supports them.
+func NewNetlinkConn(family int) (*NetlinkConn, error) {
+	fd, err := Socket(AF_NETLINK, SOCK_RAW|SOCK_CLOEXEC, family)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newNetlinkConn(fd)
+	if err != nil {
+		Close(fd)
+		return nil, err
+	}
+	return c, nil
+}
+
+func newNetlinkConn(fd int) (*NetlinkConn, error) {
+	if err := Bind(fd, &SockaddrNetlink{Family: AF_NETLINK}); err != nil {
+		return nil, err
+	}
+	sa, err := Getsockname(fd)
+	if err != nil {
+		return nil, err
+	}
+	nsa, ok := sa.(*SockaddrNetlink)
+	if !ok {
+		return nil, EINVAL
+	}
+	// Both options are best effort: they are unknown to older kernels.
+	SetsockoptInt(fd, SOL_NETLINK, NETLINK_EXT_ACK, 1)
+	SetsockoptInt(fd, SOL_NETLINK, NETLINK_CAP_ACK, 1)
+	return &NetlinkConn{fd: fd, portID: nsa.Pid, seq: uint32(Getpid())}, nil
+}
+
+// Fd returns the socket file descriptor.
+func (c *NetlinkConn) Fd() int { return c.fd }
+
+// PortID returns the netlink port ID the socket is bound to.
+func (c *NetlinkConn) PortID() uint32 { return c.portID }
+
+// Close closes the socket.
+func (c *NetlinkConn) Close() error {
+	return Close(c.fd)
+}
+
+// JoinGroup subscribes the socket to the multicast group, such as
+// RTNLGRP_LINK, so that Receive returns the notifications sent to it.
+func (c *NetlinkConn) JoinGroup(group int) error {
+	return SetsockoptInt(c.fd, SOL_NETLINK, NETLINK_ADD_MEMBERSHIP, group)
+}
+
+// LeaveGroup unsubscribes the socket from the multicast group.
+func (c *NetlinkConn) LeaveGroup(group int) error {
+	return SetsockoptInt(c.fd, SOL_NETLINK, NETLINK_DROP_MEMBERSHIP, group)
+}
+
+// Send sends the message to the kernel. If the sequence number of the
+// message is 0, Send assigns the next sequence number to it, which is
+// returned.
+func (c *NetlinkConn) Send(m *NetlinkMessage) (seq uint32, err error) {
+	if m.Header.Seq == 0 {
+		m.Header.Seq = atomic.AddUint32(&c.seq, 1)
+	}
+	m.Header.Pid = c.portID
+	if err := Sendto(c.fd, m.Marshal(), 0, &SockaddrNetlink{Family: AF_NETLINK}); err != nil {
+		return 0, err
+	}
+	return m.Header.Seq, nil
+}
+
+// Receive receives a datagram from the socket and returns the messages in
+// it. Datagrams which were not sent by the kernel are discarded.
+func (c *NetlinkConn) Receive() ([]NetlinkMessage, error) {
+	for {
+		// Peek at the datagram to allocate a buffer large enough for it.
+		n, _, err := Recvfrom(c.fd, nil, MSG_PEEK|MSG_TRUNC)
+		if err != nil {
+			if err == EINTR {
+				continue
+			}
+			return nil, err
+		}
+		b := make([]byte, nlmAlignOf(n))
+		n, from, err := Recvfrom(c.fd, b, 0)
+		if err != nil {
+			if err == EINTR {
+				continue
+			}
+			return nil, err
+		}
+		if sa, ok := from.(*SockaddrNetlink); !ok || sa.Pid != 0 {
+			continue
+		}
+		return ParseNetlinkMessages(b[:n])
+	}
+}
+
+// Execute sends the request m with NLM_F_REQUEST and NLM_F_ACK set, and
+// returns the replies to it. For dump requests, which have NLM_F_DUMP set,
+// the parts of the multipart reply are collected until NLMSG_DONE. Other
+// requests are complete when the kernel acknowledges them.
+//
+// If the kernel reports an error, Execute returns a *NetlinkError. If a
+// dump was interrupted by a change of the dumped objects, Execute returns
+// the replies along with EINTR and the dump should be retried.
+//
+// Messages which are not replies to the request, such as multicast
+// notifications, are discarded.
+func (c *NetlinkConn) Execute(m NetlinkMessage) ([]NetlinkMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m.Header.Flags |= NLM_F_REQUEST | NLM_F_ACK
+	m.Header.Seq = 0
+	seq, err := c.Send(&m)
+	if err != nil {
+		return nil, err
+	}
+	dump := m.Header.Flags&NLM_F_DUMP == NLM_F_DUMP
+
+	var replies []NetlinkMessage
+	var interrupted bool
+	for {
+		msgs, err := c.Receive()
+		if err != nil {
+			return nil, err
+		}
+		for i := range msgs {
+			reply := &msgs[i]
+			if reply.Header.Seq != seq || reply.Header.Pid != c.portID {
+				continue
+			}
+			if reply.Header.Flags&NLM_F_DUMP_INTR != 0 {
+				interrupted = true
+			}
+			switch reply.Header.Type {
+			case NLMSG_NOOP:
+				continue
+			case NLMSG_ERROR:
+				if err := parseNetlinkError(reply); err != nil {
+					return nil, err
+				}
+				return replies, nil
+			case NLMSG_DONE:
+				if len(reply.Data) >= 4 {
+					if err := parseNetlinkError(reply); err != nil {
+						return nil, err
+					}
+				}
+				if dump {
+					if interrupted {
+						return replies, EINTR
+					}
+					return replies, nil
+				}
+				continue
+			}
+			replies = append(replies, *reply)
+		}
+	}
+}
+
+// A NetlinkAttr is a netlink attribute. Type includes the NLA_F_NESTED and
+// NLA_F_NET_BYTEORDER flags.
+type NetlinkAttr struct {
+	Type  uint16
+	Value []byte
+}
+
+// ParseNetlinkAttrs parses the netlink attributes in b. The values of the
+// returned attributes refer to b.
+func ParseNetlinkAttrs(b []byte) ([]NetlinkAttr, error) {
+	var attrs []NetlinkAttr
+	d := NewNetlinkAttrDecoder(b)
+	for d.Next() {
+		attrs = append(attrs, NetlinkAttr{Type: d.typ, Value: d.Value()})
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// A NetlinkAttrEncoder builds a sequence of netlink attributes, which may
+// be nested. The zero value is an empty sequence ready to use.
+//
+// Integers are encoded in native byte order.
+type NetlinkAttrEncoder struct {
+	b     []byte
+	nests []int // offsets of the open nested attributes
+}
+
+// Bytes returns the encoded attributes. All nested attributes must have
+// been closed with EndNested.
+func (e *NetlinkAttrEncoder) Bytes() []byte {
+	if len(e.nests) != 0 {
+		panic("unix: unterminated nested netlink attribute")
+	}
+	return e.b
+}
+
+// Len returns the length of the encoded attributes.
+func (e *NetlinkAttrEncoder) Len() int { return len(e.b) }
+
+func (e *NetlinkAttrEncoder) header(typ uint16, n int) []byte {
+	off := len(e.b)
+	e.b = append(e.b, make([]byte, nlaAlignOf(NLA_HDRLEN+n))...)
+	nla := (*NlAttr)(unsafe.Pointer(&e.b[off]))
+	nla.Len = uint16(NLA_HDRLEN + n)
+	nla.Type = typ
+	return e.b[off+NLA_HDRLEN : off+NLA_HDRLEN+n]
+}
+
+// Attr appends an attribute with the given raw value.
+func (e *NetlinkAttrEncoder) Attr(typ uint16, value []byte) {
+	copy(e.header(typ, len(value)), value)
+}
+
+// Flag appends an attribute without value.
+func (e *NetlinkAttrEncoder) Flag(typ uint16) {
+	e.header(typ, 0)
+}
+
+// Uint8 appends an attribute with an 8-bit value.
+func (e *NetlinkAttrEncoder) Uint8(typ uint16, v uint8) {
+	e.header(typ, 1)[0] = v
+}
+
+// Uint16 appends an attribute with a 16-bit value.
+func (e *NetlinkAttrEncoder) Uint16(typ uint16, v uint16) {
+	nativeEndian.PutUint16(e.header(typ, 2), v)
+}
+
+// Uint32 appends an attribute with a 32-bit value.
+func (e *NetlinkAttrEncoder) Uint32(typ uint16, v uint32) {
+	nativeEndian.PutUint32(e.header(typ, 4), v)
+}
+
+// Uint64 appends an attribute with a 64-bit value.
+func (e *NetlinkAttrEncoder) Uint64(typ uint16, v uint64) {
+	nativeEndian.PutUint64(e.header(typ, 8), v)
+}
+
+// Int32 appends an attribute with a signed 32-bit value.
+func (e *NetlinkAttrEncoder) Int32(typ uint16, v int32) {
+	e.Uint32(typ, uint32(v))
+}
+
+// Uint16BE appends an attribute with a 16-bit value in network byte
+// order, such as a port number. NLA_F_NET_BYTEORDER is not set, as most
+// families reject attributes with unknown flags.
+func (e *NetlinkAttrEncoder) Uint16BE(typ uint16, v uint16) {
+	binary.BigEndian.PutUint16(e.header(typ, 2), v)
+}
+
+// Uint32BE appends an attribute with a 32-bit value in network byte order.
+func (e *NetlinkAttrEncoder) Uint32BE(typ uint16, v uint32) {
+	binary.BigEndian.PutUint32(e.header(typ, 4), v)
+}
+
+// String appends an attribute with a NUL-terminated string value.
+func (e *NetlinkAttrEncoder) String(typ uint16, s string) {
+	copy(e.header(typ, len(s)+1), s)
+}
+
+// Nested starts a nested attribute, to which the attributes appended until
+// the matching call to EndNested belong. NLA_F_NESTED is set in its type.
+func (e *NetlinkAttrEncoder) Nested(typ uint16) {
+	e.nests = append(e.nests, len(e.b))
+	e.header(typ|NLA_F_NESTED, 0)
+}
+
+// EndNested ends the innermost nested attribute.
+func (e *NetlinkAttrEncoder) EndNested() {
+	off := e.nests[len(e.nests)-1]
+	e.nests = e.nests[:len(e.nests)-1]
+	nla := (*NlAttr)(unsafe.Pointer(&e.b[off]))
+	nla.Len = uint16(len(e.b) - off)
+}
+
+// A NetlinkAttrDecoder iterates over a sequence of netlink attributes:
+//
+//	d := unix.NewNetlinkAttrDecoder(b)
+//	for d.Next() {
+//		switch d.Type() {
+//		case unix.IFLA_MTU:
+//			mtu = d.Uint32()
+//		case unix.IFLA_IFNAME:
+//			name = d.String()
+//		}
+//	}
+//	if err := d.Err(); err != nil {
+//		...
+//	}
+//
+// Integers are decoded in native byte order. If an attribute is malformed
+// or a value has the wrong length for the requested type, iteration stops
+// and Err returns EINVAL.
+type NetlinkAttrDecoder struct {
+	b     []byte
+	typ   uint16
+	value []byte
+	err   error
+}
+
+// NewNetlinkAttrDecoder returns a decoder for the attributes in b.
+func NewNetlinkAttrDecoder(b []byte) *NetlinkAttrDecoder {
+	return &NetlinkAttrDecoder{b: b}
+}
+
+// Next advances to the next attribute and reports whether there is one.
+func (d *NetlinkAttrDecoder) Next() bool {
+	if d.err != nil || len(d.b) < NLA_HDRLEN {
+		return false
+	}
+	nla := (*NlAttr)(unsafe.Pointer(&d.b[0]))
+	if int(nla.Len) < NLA_HDRLEN || int(nla.Len) > len(d.b) {
+		d.err = EINVAL
+		return false
+	}
+	d.typ = nla.Type
+	d.value = d.b[NLA_HDRLEN:nla.Len:nla.Len]
+	if n := nlaAlignOf(int(nla.Len)); n < len(d.b) {
+		d.b = d.b[n:]
+	} else {
+		d.b = nil
+	}
+	return true
+}
+
+// Err returns the first error encountered while decoding.
+func (d *NetlinkAttrDecoder) Err() error { return d.err }
+
+// Type returns the type of the current attribute without the NLA_F_NESTED
+// and NLA_F_NET_BYTEORDER flags.
+func (d *NetlinkAttrDecoder) Type() uint16 { return d.typ & nlaTypeMask }
+
+// NetByteOrder reports whether NLA_F_NET_BYTEORDER is set on the current
+// attribute.
+func (d *NetlinkAttrDecoder) NetByteOrder() bool { return d.typ&NLA_F_NET_BYTEORDER != 0 }
+
+// Value returns the raw value of the current attribute.
+func (d *NetlinkAttrDecoder) Value() []byte { return d.value }
+
+func (d *NetlinkAttrDecoder) fixed(n int) []byte {
+	if len(d.value) != n {
+		if d.err == nil {
+			d.err = EINVAL
+		}
+		return make([]byte, n)
+	}
+	return d.value
+}
+
+// Uint8 returns the value of the current attribute as an 8-bit integer.
+func (d *NetlinkAttrDecoder) Uint8() uint8 {
+	return d.fixed(1)[0]
+}
+
+// Uint16 returns the value of the current attribute as a 16-bit integer.
+func (d *NetlinkAttrDecoder) Uint16() uint16 {
+	return nativeEndian.Uint16(d.fixed(2))
+}
+
+// Uint32 returns the value of the current attribute as a 32-bit integer.
+func (d *NetlinkAttrDecoder) Uint32() uint32 {
+	return nativeEndian.Uint32(d.fixed(4))
+}
+
+// Uint64 returns the value of the current attribute as a 64-bit integer.
+func (d *NetlinkAttrDecoder) Uint64() uint64 {
+	return nativeEndian.Uint64(d.fixed(8))
+}
+
+// Int32 returns the value of the current attribute as a signed 32-bit
+// integer.
+func (d *NetlinkAttrDecoder) Int32() int32 {
+	return int32(d.Uint32())
+}
+
+// Uint16BE returns the value of the current attribute as a 16-bit integer
+// in network byte order.
+func (d *NetlinkAttrDecoder) Uint16BE() uint16 {
+	return binary.BigEndian.Uint16(d.fixed(2))
+}
+
+// Uint32BE returns the value of the current attribute as a 32-bit integer
+// in network byte order.
+func (d *NetlinkAttrDecoder) Uint32BE() uint32 {
+	return binary.BigEndian.Uint32(d.fixed(4))
+}
+
+// String returns the value of the current attribute as a string, without
+// the terminating NUL byte if any.
+func (d *NetlinkAttrDecoder) String() string {
+	return string(d.value[:clen(d.value)])
+}
+
+// Nested returns a decoder for the attributes nested in the current
+// attribute.
+func (d *NetlinkAttrDecoder) Nested() *NetlinkAttrDecoder {
+	return NewNetlinkAttrDecoder(d.value)
+}
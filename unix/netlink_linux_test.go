@@ -0,0 +1,185 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestNetlinkAttrs(t *testing.T) {
+	var e unix.NetlinkAttrEncoder
+	e.String(1, "abc")
+	e.Nested(2)
+	e.Uint8(3, 0x42)
+	e.Nested(4)
+	e.Uint64(5, 0x0102030405060708)
+	e.EndNested()
+	e.Flag(6)
+	e.EndNested()
+	e.Uint16BE(7, 0x1234)
+	b := e.Bytes()
+
+	if len(b)%unix.NLA_ALIGNTO != 0 {
+		t.Fatalf("encoded length %d is not aligned", len(b))
+	}
+	// "abc\0" fits exactly, so the next attribute starts at offset 8.
+	if got := *(*unix.NlAttr)(unsafe.Pointer(&b[8])); got.Type != 2|unix.NLA_F_NESTED || got.Len != 4+8+4+12+4 {
+		t.Fatalf("unexpected nested attribute header %+v", got)
+	}
+
+	d := unix.NewNetlinkAttrDecoder(b)
+	var types []uint16
+	for d.Next() {
+		types = append(types, d.Type())
+		switch d.Type() {
+		case 1:
+			if got := d.String(); got != "abc" {
+				t.Errorf("attribute 1: got %q, want %q", got, "abc")
+			}
+		case 2:
+			n := d.Nested()
+			for n.Next() {
+				types = append(types, n.Type())
+				switch n.Type() {
+				case 3:
+					if got := n.Uint8(); got != 0x42 {
+						t.Errorf("attribute 3: got %#x", got)
+					}
+				case 4:
+					nn := n.Nested()
+					for nn.Next() {
+						types = append(types, nn.Type())
+						if got := nn.Uint64(); got != 0x0102030405060708 {
+							t.Errorf("attribute 5: got %#x", got)
+						}
+					}
+				case 6:
+					if len(n.Value()) != 0 {
+						t.Errorf("attribute 6: unexpected value %x", n.Value())
+					}
+				}
+			}
+			if err := n.Err(); err != nil {
+				t.Fatal(err)
+			}
+		case 7:
+			if got := d.Uint16BE(); got != 0x1234 {
+				t.Errorf("attribute 7: got %#x", got)
+			}
+			if !bytes.Equal(d.Value(), []byte{0x12, 0x34}) {
+				t.Errorf("attribute 7: got bytes %x", d.Value())
+			}
+		}
+	}
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint16{1, 2, 3, 4, 5, 6, 7}; !equalUint16s(types, want) {
+		t.Errorf("got attribute types %v, want %v", types, want)
+	}
+
+	// Wrong value sizes and truncated attributes are reported.
+	d = unix.NewNetlinkAttrDecoder(b)
+	d.Next()
+	d.Uint16()
+	if d.Err() != unix.EINVAL {
+		t.Errorf("Uint16 of 4-byte value: got error %v, want EINVAL", d.Err())
+	}
+	if _, err := unix.ParseNetlinkAttrs(b[:len(b)-9]); err != unix.EINVAL {
+		t.Errorf("ParseNetlinkAttrs of truncated attributes: got error %v, want EINVAL", err)
+	}
+}
+
+func equalUint16s(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNetlinkMessages(t *testing.T) {
+	m1 := unix.NetlinkMessage{Header: unix.NlMsghdr{Type: 1, Seq: 7}, Data: []byte{1, 2, 3}}
+	m2 := unix.NetlinkMessage{Header: unix.NlMsghdr{Type: 2, Seq: 8}, Data: []byte{4, 5, 6, 7, 8}}
+	b := append(m1.Marshal(), m2.Marshal()...)
+	msgs, err := unix.ParseNetlinkMessages(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	for i, want := range []unix.NetlinkMessage{m1, m2} {
+		if msgs[i].Header != want.Header || !bytes.Equal(msgs[i].Data, want.Data) {
+			t.Errorf("message %d: got %+v, want %+v", i, msgs[i], want)
+		}
+	}
+}
+
+func TestNetlinkConn(t *testing.T) {
+	c, err := unix.NewNetlinkConn(unix.NETLINK_ROUTE)
+	if err != nil {
+		t.Skipf("NewNetlinkConn: %v", err)
+	}
+	defer c.Close()
+	if c.PortID() == 0 {
+		t.Error("port ID is 0")
+	}
+
+	ifi := make([]byte, unix.SizeofIfInfomsg)
+	ifi[0] = unix.AF_UNSPEC
+	msgs, err := c.Execute(unix.NetlinkMessage{
+		Header: unix.NlMsghdr{Type: unix.RTM_GETLINK, Flags: unix.NLM_F_DUMP},
+		Data:   ifi,
+	})
+	if err != nil {
+		t.Fatalf("RTM_GETLINK dump: %v", err)
+	}
+	var found bool
+	for _, m := range msgs {
+		if m.Header.Type != unix.RTM_NEWLINK || len(m.Data) < unix.SizeofIfInfomsg {
+			t.Fatalf("unexpected reply %+v", m.Header)
+		}
+		d := unix.NewNetlinkAttrDecoder(m.Data[unix.SizeofIfInfomsg:])
+		for d.Next() {
+			if d.Type() == unix.IFLA_IFNAME && d.String() == "lo" {
+				found = true
+			}
+		}
+		if err := d.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !found {
+		t.Error("loopback interface not found in dump")
+	}
+
+	// Requesting a nonexistent interface fails.
+	info := (*unix.IfInfomsg)(unsafe.Pointer(&ifi[0]))
+	info.Index = 0x7ffffff0
+	_, err = c.Execute(unix.NetlinkMessage{
+		Header: unix.NlMsghdr{Type: unix.RTM_GETLINK},
+		Data:   ifi,
+	})
+	var nerr *unix.NetlinkError
+	if !errors.As(err, &nerr) {
+		t.Fatalf("RTM_GETLINK of nonexistent interface: got error %v, want *NetlinkError", err)
+	}
+	if !errors.Is(err, unix.ENODEV) {
+		t.Errorf("RTM_GETLINK of nonexistent interface: got error %v, want ENODEV", err)
+	}
+}
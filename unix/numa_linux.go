@@ -0,0 +1,265 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// NodeSet represents a set of NUMA nodes, a nodemask, of arbitrary size.
+// The zero value is an empty set, which grows as nodes are added.
+type NodeSet struct {
+	bits DynamicCPUSet
+}
+
+// NewNodeSet returns a NodeSet containing nodes.
+func NewNodeSet(nodes ...int) *NodeSet {
+	s := new(NodeSet)
+	for _, node := range nodes {
+		s.Set(node)
+	}
+	return s
+}
+
+// Zero clears the set s, so that it contains no nodes.
+func (s *NodeSet) Zero() { s.bits.Zero() }
+
+// Set adds node to the set s.
+func (s *NodeSet) Set(node int) { s.bits.Set(node) }
+
+// Clear removes node from the set s.
+func (s *NodeSet) Clear(node int) { s.bits.Clear(node) }
+
+// IsSet reports whether node is in the set s.
+func (s *NodeSet) IsSet(node int) bool { return s.bits.IsSet(node) }
+
+// Count returns the number of nodes in the set s.
+func (s *NodeSet) Count() int { return s.bits.Count() }
+
+// Nodes returns the nodes in the set s, in increasing order.
+func (s *NodeSet) Nodes() []int { return s.bits.CPUs() }
+
+// Equal reports whether the sets s and t contain the same nodes.
+func (s *NodeSet) Equal(t *NodeSet) bool { return s.bits.Equal(&t.bits) }
+
+// String formats the set s in the kernel's list format, such as "0-1,3",
+// which is used by /sys/devices/system/node/online and the cpuset.mems file
+// of cgroups.
+func (s *NodeSet) String() string { return s.bits.String() }
+
+// ParseNodeList parses a set of nodes in the kernel's list format, such as
+// "0-1,3". See ParseCPUList.
+func ParseNodeList(list string) (*NodeSet, error) {
+	cpus, err := ParseCPUList(list)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeSet{bits: *cpus}, nil
+}
+
+// nodemask returns the nodemask of the set s and its maxnode argument.
+// The kernel ignores the last of maxnode bits.
+func (s *NodeSet) nodemask() (*cpuMask, uintptr) {
+	if s == nil || len(s.bits.mask) == 0 {
+		return nil, 0
+	}
+	return &s.bits.mask[0], uintptr(len(s.bits.mask)*_NCPUBITS + 1)
+}
+
+// Mbind sets the NUMA memory policy of the memory b, which must be page
+// aligned, such as returned by Mmap (mbind(2)). The mode is one of the
+// MPOL_* policies, optionally or'ed with MPOL_F_STATIC_NODES,
+// MPOL_F_RELATIVE_NODES or MPOL_F_NUMA_BALANCING, and nodes is the set of
+// nodes of the policy, or nil for MPOL_DEFAULT and MPOL_LOCAL. Flags may
+// include MPOL_MF_STRICT, MPOL_MF_MOVE and MPOL_MF_MOVE_ALL to check or
+// move the pages already allocated.
+func Mbind(b []byte, mode int, nodes *NodeSet, flags int) error {
+	var addr uintptr
+	if len(b) > 0 {
+		addr = uintptr(unsafe.Pointer(&b[0]))
+	}
+	mask, maxnode := nodes.nodemask()
+	return mbind(addr, uintptr(len(b)), mode, mask, maxnode, flags)
+}
+
+// SetMempolicy sets the default NUMA memory policy of the calling thread,
+// and of the threads it creates (set_mempolicy(2)), with the mode and nodes
+// as for Mbind. The policy applies to a single thread, so the goroutine
+// should be locked to its thread with runtime.LockOSThread.
+func SetMempolicy(mode int, nodes *NodeSet) error {
+	mask, maxnode := nodes.nodemask()
+	return setMempolicy(mode, mask, maxnode)
+}
+
+// maxNodeSetWords bounds the size of the nodemask GetMempolicy tries, the
+// limit of the kernel.
+const maxNodeSetWords = 4096 * 8 / _NCPUBITS
+
+// GetMempolicy returns the NUMA memory policy mode and nodes of the calling
+// thread, or of the memory at addr if flags includes MPOL_F_ADDR
+// (get_mempolicy(2)). If flags includes MPOL_F_MEMS_ALLOWED, the nodes are
+// the nodes the thread is allowed to use. If flags includes MPOL_F_NODE
+// and MPOL_F_ADDR, the returned mode is the node of the page at addr,
+// allocated if needed.
+func GetMempolicy(addr uintptr, flags int) (int, *NodeSet, error) {
+	nodes := new(NodeSet)
+	n := 1024 / _NCPUBITS
+	for {
+		var mode int32
+		nodes.bits.mask = make([]cpuMask, n)
+		err := getMempolicy(&mode, &nodes.bits.mask[0], uintptr(n*_NCPUBITS), addr, flags)
+		if err == nil {
+			return int(mode), nodes, nil
+		}
+		if err != EINVAL || n >= maxNodeSetWords {
+			return -1, nil, err
+		}
+		n *= 2
+	}
+}
+
+// MigratePages moves the pages of the process pid on the nodes from to the
+// nodes to (migrate_pages(2)), and returns the number of pages which could
+// not be moved. If pid is 0 the calling process is used. It returns
+// EINVAL if from or to is nil, or if both are empty.
+func MigratePages(pid int, from, to *NodeSet) (int, error) {
+	if from == nil || to == nil || from.Count() == 0 && to.Count() == 0 {
+		return -1, EINVAL
+	}
+	// The kernel uses the same maxnode for both nodemasks.
+	n := len(from.bits.mask)
+	if len(to.bits.mask) > n {
+		n = len(to.bits.mask)
+	}
+	oldNodes := make([]cpuMask, n)
+	copy(oldNodes, from.bits.mask)
+	newNodes := make([]cpuMask, n)
+	copy(newNodes, to.bits.mask)
+	return migratePages(pid, uintptr(n*_NCPUBITS+1), &oldNodes[0], &newNodes[0])
+}
+
+// MovePages moves the pages of the process pid at the addresses pages to
+// the nodes at the same indices of nodes (move_pages(2)), and returns for
+// each page the node it is on, or a negative errno value, such as -ENOENT
+// for a page which is not present. If nodes is nil, the pages are not
+// moved, only queried. If pid is 0 the calling process is used. Flags may
+// include MPOL_MF_MOVE_ALL to also move the pages shared with other
+// processes.
+func MovePages(pid int, pages []uintptr, nodes []int, flags int) ([]int, error) {
+	if len(pages) == 0 {
+		return nil, nil
+	}
+	var pnodes *int32
+	if nodes != nil {
+		if len(nodes) != len(pages) {
+			return nil, EINVAL
+		}
+		n := make([]int32, len(nodes))
+		for i, node := range nodes {
+			n[i] = int32(node)
+		}
+		pnodes = &n[0]
+	}
+	status := make([]int32, len(pages))
+	if err := movePages(pid, uintptr(len(pages)), &pages[0], pnodes, &status[0], flags); err != nil {
+		return nil, err
+	}
+	s := make([]int, len(status))
+	for i, st := range status {
+		s[i] = int(st)
+	}
+	return s, nil
+}
+
+// A NUMANode describes a NUMA node of the system, as listed under
+// /sys/devices/system/node.
+type NUMANode struct {
+	ID        int
+	CPUs      *DynamicCPUSet
+	Distances map[int]int // relative distances to the online nodes, 10 to itself
+}
+
+// readFile reads the small file at path, such as a file of sysfs or procfs.
+func readFile(path string) ([]byte, error) {
+	fd, err := Open(path, O_RDONLY|O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer Close(fd)
+	b := make([]byte, 0, 4096)
+	for {
+		if len(b) == cap(b) {
+			b = append(b, 0)[:len(b)]
+		}
+		n, err := Read(fd, b[len(b):cap(b)])
+		if err == EINTR {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return b, nil
+		}
+		b = b[:len(b)+n]
+	}
+}
+
+const sysfsNodeDir = "/sys/devices/system/node/"
+
+// OnlineNUMANodes returns the set of online NUMA nodes.
+func OnlineNUMANodes() (*NodeSet, error) {
+	b, err := readFile(sysfsNodeDir + "online")
+	if err != nil {
+		return nil, err
+	}
+	return ParseNodeList(string(b))
+}
+
+// ReadNUMATopology returns the online NUMA nodes of the system, with their
+// CPUs and distances, in increasing order of ID.
+func ReadNUMATopology() ([]NUMANode, error) {
+	online, err := OnlineNUMANodes()
+	if err != nil {
+		return nil, err
+	}
+	ids := online.Nodes()
+	nodes := make([]NUMANode, 0, len(ids))
+	for _, id := range ids {
+		dir := sysfsNodeDir + "node" + strconv.Itoa(id) + "/"
+		b, err := readFile(dir + "cpulist")
+		if err != nil {
+			return nil, err
+		}
+		cpus, err := ParseCPUList(string(b))
+		if err != nil {
+			return nil, err
+		}
+		b, err = readFile(dir + "distance")
+		if err != nil {
+			return nil, err
+		}
+		// The distances are listed in the order of the online nodes.
+		fields := strings.Fields(string(b))
+		if len(fields) != len(ids) {
+			return nil, EINVAL
+		}
+		distances := make(map[int]int, len(fields))
+		for i, f := range fields {
+			d, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, EINVAL
+			}
+			distances[ids[i]] = d
+		}
+		nodes = append(nodes, NUMANode{ID: id, CPUs: cpus, Distances: distances})
+	}
+	return nodes, nil
+}
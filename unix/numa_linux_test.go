@@ -0,0 +1,128 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestNodeSet(t *testing.T) {
+	s, err := unix.ParseNodeList("0-1,3\n")
+	if err != nil {
+		t.Fatalf("ParseNodeList: %v", err)
+	}
+	if !s.Equal(unix.NewNodeSet(0, 1, 3)) || s.Count() != 3 || s.String() != "0-1,3" {
+		t.Errorf("ParseNodeList: got %v", s.Nodes())
+	}
+	s.Clear(1)
+	if s.IsSet(1) || s.String() != "0,3" {
+		t.Errorf("Clear: got %v", s)
+	}
+}
+
+func TestNUMATopology(t *testing.T) {
+	nodes, err := unix.ReadNUMATopology()
+	if err != nil {
+		t.Skipf("ReadNUMATopology: %v", err)
+	}
+	if len(nodes) == 0 {
+		t.Fatal("no online nodes")
+	}
+	for _, n := range nodes {
+		if d := n.Distances[n.ID]; d != 10 {
+			t.Errorf("node %d: got distance %d to itself, want 10", n.ID, d)
+		}
+		if len(n.Distances) != len(nodes) {
+			t.Errorf("node %d: got %d distances for %d nodes", n.ID, len(n.Distances), len(nodes))
+		}
+	}
+}
+
+// firstNode returns the first NUMA node the calling thread may use.
+func firstNode(t *testing.T) int {
+	_, allowed, err := unix.GetMempolicy(0, unix.MPOL_F_MEMS_ALLOWED)
+	if err == unix.ENOSYS {
+		t.Skip("NUMA not supported")
+	}
+	if err != nil {
+		t.Fatalf("GetMempolicy(MPOL_F_MEMS_ALLOWED): %v", err)
+	}
+	nodes := allowed.Nodes()
+	if len(nodes) == 0 {
+		t.Fatal("no allowed nodes")
+	}
+	return nodes[0]
+}
+
+func TestMbind(t *testing.T) {
+	node := firstNode(t)
+	pagesize := unix.Getpagesize()
+	mem, err := unix.Mmap(-1, 0, 2*pagesize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Munmap(mem)
+	addr := uintptr(unsafe.Pointer(&mem[0]))
+
+	if err := unix.Mbind(mem, unix.MPOL_BIND, unix.NewNodeSet(node), unix.MPOL_MF_STRICT); err != nil {
+		t.Fatalf("Mbind: %v", err)
+	}
+	mode, nodes, err := unix.GetMempolicy(addr, unix.MPOL_F_ADDR)
+	if err != nil {
+		t.Fatalf("GetMempolicy(MPOL_F_ADDR): %v", err)
+	}
+	if mode != unix.MPOL_BIND || !nodes.Equal(unix.NewNodeSet(node)) {
+		t.Errorf("GetMempolicy(MPOL_F_ADDR): got %d, %v, want %d, %d", mode, nodes, unix.MPOL_BIND, node)
+	}
+
+	mem[0] = 1
+	status, err := unix.MovePages(0, []uintptr{addr, addr + uintptr(pagesize)}, nil, 0)
+	if err != nil {
+		t.Fatalf("MovePages: %v", err)
+	}
+	if status[0] != node || status[1] != -int(unix.ENOENT) {
+		t.Errorf("MovePages: got %v, want [%d %d]", status, node, -int(unix.ENOENT))
+	}
+	if status, err := unix.MovePages(0, []uintptr{addr}, []int{node}, unix.MPOL_MF_MOVE); err != nil || status[0] != node {
+		t.Errorf("MovePages to node %d: got %v, %v", node, status, err)
+	}
+	if _, err := unix.MovePages(0, []uintptr{addr}, []int{node, node}, 0); err != unix.EINVAL {
+		t.Errorf("MovePages with mismatched nodes: got %v, want %v", err, unix.EINVAL)
+	}
+	if n, err := unix.MigratePages(0, unix.NewNodeSet(node), unix.NewNodeSet(node)); err != nil || n != 0 {
+		t.Errorf("MigratePages: got %d, %v", n, err)
+	}
+	for _, sets := range [][2]*unix.NodeSet{{nil, unix.NewNodeSet(node)}, {unix.NewNodeSet(node), nil}, {unix.NewNodeSet(), unix.NewNodeSet()}} {
+		if _, err := unix.MigratePages(0, sets[0], sets[1]); err != unix.EINVAL {
+			t.Errorf("MigratePages(%v, %v): got %v, want %v", sets[0], sets[1], err, unix.EINVAL)
+		}
+	}
+}
+
+func TestSetMempolicy(t *testing.T) {
+	node := firstNode(t)
+	inThrowawayThread(func() {
+		if err := unix.SetMempolicy(unix.MPOL_PREFERRED, unix.NewNodeSet(node)); err != nil {
+			t.Errorf("SetMempolicy: %v", err)
+			return
+		}
+		mode, nodes, err := unix.GetMempolicy(0, 0)
+		if err != nil || mode != unix.MPOL_PREFERRED || !nodes.Equal(unix.NewNodeSet(node)) {
+			t.Errorf("GetMempolicy: got %d, %v, %v", mode, nodes, err)
+		}
+		if err := unix.SetMempolicy(unix.MPOL_DEFAULT, nil); err != nil {
+			t.Errorf("SetMempolicy(MPOL_DEFAULT): %v", err)
+		}
+		if mode, _, err := unix.GetMempolicy(0, 0); err != nil || mode != unix.MPOL_DEFAULT {
+			t.Errorf("GetMempolicy after reset: got %d, %v", mode, err)
+		}
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// A Process is a handle to a process referring to it by a pidfd, so that,
+// unlike a process ID, it cannot refer to another process once the process
+// exited and its ID is reused.
+//
+// Requires kernel >= 5.3 (5.4 for Wait, 6.13 for Info).
+type Process struct {
+	fd  int
+	pid int
+
+	once    sync.Once
+	done    chan struct{}
+	doneErr error
+	stop    int           // eventfd stopping the goroutine polling for exit
+	exit    chan struct{} // closed when the goroutine polling for exit returns
+}
+
+// NewProcess opens a pidfd referring to the process with the given ID
+// (pidfd_open(2)). Flags may include PIDFD_NONBLOCK and, to refer to a
+// thread rather than a thread group, PIDFD_THREAD.
+func NewProcess(pid, flags int) (*Process, error) {
+	fd, err := PidfdOpen(pid, flags)
+	if err != nil {
+		return nil, err
+	}
+	return NewProcessFromPidfd(fd, pid), nil
+}
+
+// NewProcessFromPidfd returns a Process for the pidfd fd referring to the
+// process with the given ID, such as returned by CloneExec. The Process
+// takes ownership of fd.
+func NewProcessFromPidfd(fd, pid int) *Process {
+	return &Process{fd: fd, pid: pid, stop: -1}
+}
+
+// Fd returns the pidfd of the process.
+func (p *Process) Fd() int { return p.fd }
+
+// Pid returns the process ID of the process.
+func (p *Process) Pid() int { return p.pid }
+
+// PollFd returns a PollFd for the pidfd, which becomes readable when the
+// process exits, for use with Poll.
+func (p *Process) PollFd() PollFd {
+	return PollFd{Fd: int32(p.fd), Events: POLLIN}
+}
+
+// Close closes the pidfd and stops the goroutine started by Done, if any.
+// It does not affect the process.
+func (p *Process) Close() error {
+	if p.exit != nil {
+		var one [8]byte
+		nativeEndian.PutUint64(one[:], 1)
+		Write(p.stop, one[:])
+		<-p.exit
+		Close(p.stop)
+	}
+	return Close(p.fd)
+}
+
+// Signal sends the signal sig to the process (pidfd_send_signal(2)).
+func (p *Process) Signal(sig Signal) error {
+	return PidfdSendSignal(p.fd, sig, nil, 0)
+}
+
+// Getfd returns a duplicate of the file descriptor targetfd of the process
+// (pidfd_getfd(2)), with the close-on-exec flag set. It requires the
+// permission to ptrace the process.
+func (p *Process) Getfd(targetfd int) (int, error) {
+	return PidfdGetfd(p.fd, targetfd, 0)
+}
+
+// Info returns information about the process (PIDFD_GET_INFO). Mask
+// selects the information to return in addition to the process IDs, as a
+// bitmask of PIDFD_INFO_CREDS, PIDFD_INFO_CGROUPID and PIDFD_INFO_EXIT;
+// the Mask field of the result reports the information available. The
+// exit code is available once the process exited and was reaped by its
+// parent.
+func (p *Process) Info(mask uint64) (*PidfdInfo, error) {
+	info := &PidfdInfo{Mask: mask}
+	if err := ioctlPtr(p.fd, PIDFD_GET_INFO, unsafe.Pointer(info)); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// FileHandle returns a pidfs file handle of the process, which remains
+// valid, and unique among the processes of the system, until reboot
+// (name_to_handle_at(2), kernel >= 6.14).
+func (p *Process) FileHandle() (FileHandle, error) {
+	h, _, err := NameToHandleAt(p.fd, "", AT_EMPTY_PATH)
+	return h, err
+}
+
+// OpenProcessByHandle opens a pidfd from a pidfs file handle returned by
+// Process.FileHandle (open_by_handle_at(2), kernel >= 6.14). Flags may
+// include PIDFD_NONBLOCK and PIDFD_THREAD. The process must be visible in
+// the PID namespace of the caller.
+func OpenProcessByHandle(handle FileHandle, flags int) (*Process, error) {
+	// Any pidfd identifies the pidfs mount.
+	self, err := PidfdOpen(Getpid(), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer Close(self)
+	fd, err := OpenByHandleAt(self, handle, flags)
+	if err != nil {
+		return nil, err
+	}
+	p := NewProcessFromPidfd(fd, 0)
+	info, err := p.Info(0)
+	if err != nil {
+		Close(fd)
+		return nil, err
+	}
+	p.pid = int(info.Pid)
+	return p, nil
+}
+
+// A ProcessState is a state change of a process reported by Process.Wait.
+type ProcessState struct {
+	Pid int
+	Uid int // real user ID of the process
+	// Code is CLD_EXITED, CLD_KILLED or CLD_DUMPED if the process
+	// terminated, or CLD_STOPPED, CLD_TRAPPED or CLD_CONTINUED.
+	Code int
+	// Status is the exit status if the process exited, or the signal
+	// which caused the state change otherwise.
+	Status int
+	Rusage Rusage // resource usage, if the process terminated
+}
+
+// Exited reports whether the process exited normally.
+func (s *ProcessState) Exited() bool { return s.Code == CLD_EXITED }
+
+// Success reports whether the process exited with status 0.
+func (s *ProcessState) Success() bool { return s.Exited() && s.Status == 0 }
+
+// ExitStatus returns the exit status of the process, or -1 if it did not
+// exit normally.
+func (s *ProcessState) ExitStatus() int {
+	if !s.Exited() {
+		return -1
+	}
+	return s.Status
+}
+
+// Signaled reports whether the process was terminated by a signal.
+func (s *ProcessState) Signaled() bool { return s.Code == CLD_KILLED || s.Code == CLD_DUMPED }
+
+// CoreDump reports whether the process was terminated by a signal and
+// dumped core.
+func (s *ProcessState) CoreDump() bool { return s.Code == CLD_DUMPED }
+
+// Stopped reports whether the process was stopped by a signal or by
+// ptrace.
+func (s *ProcessState) Stopped() bool { return s.Code == CLD_STOPPED || s.Code == CLD_TRAPPED }
+
+// Continued reports whether the process was resumed by SIGCONT.
+func (s *ProcessState) Continued() bool { return s.Code == CLD_CONTINUED }
+
+// Signal returns the signal which terminated, stopped or resumed the
+// process, or -1 if it exited normally.
+func (s *ProcessState) Signal() Signal {
+	if s.Exited() {
+		return -1
+	}
+	return Signal(s.Status)
+}
+
+// Wait waits for the process, which must be a child of the caller, to
+// change state (waitid(2) with P_PIDFD) and reaps it if it terminated.
+// WEXITED is implied; options may also include WSTOPPED, WCONTINUED,
+// WNOWAIT and WNOHANG. With WNOHANG, or for a pidfd opened with
+// PIDFD_NONBLOCK, Wait returns a nil ProcessState if the process did not
+// change state.
+func (p *Process) Wait(options int) (*ProcessState, error) {
+	var info Siginfo
+	s := new(ProcessState)
+	for {
+		err := Waitid(P_PIDFD, p.fd, &info, options|WEXITED, &s.Rusage)
+		if err == EAGAIN {
+			return nil, nil
+		}
+		if err != EINTR {
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	// The union of siginfo_t follows si_signo, si_errno and si_code, aligned
+	// to the pointer size. For SIGCHLD, it starts with si_pid, si_uid and
+	// si_status.
+	off := 3 * 4
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		off = 4 * 4
+	}
+	b := (*[unsafe.Sizeof(info)]byte)(unsafe.Pointer(&info))[off:]
+	s.Pid = int(int32(nativeEndian.Uint32(b)))
+	if s.Pid == 0 {
+		// WNOHANG and no state change.
+		return nil, nil
+	}
+	s.Uid = int(nativeEndian.Uint32(b[4:]))
+	s.Status = int(int32(nativeEndian.Uint32(b[8:])))
+	s.Code = int(info.Code)
+	return s, nil
+}
+
+// Done returns a channel which is closed when the process exits. The first
+// call starts a goroutine polling the pidfd, which blocks a thread until
+// the process exits or Close is called. The channel is not closed if Close
+// is called first.
+func (p *Process) Done() (<-chan struct{}, error) {
+	p.once.Do(func() {
+		p.stop, p.doneErr = Eventfd(0, EFD_CLOEXEC)
+		if p.doneErr != nil {
+			return
+		}
+		p.done = make(chan struct{})
+		p.exit = make(chan struct{})
+		go p.poll()
+	})
+	return p.done, p.doneErr
+}
+
+func (p *Process) poll() {
+	defer close(p.exit)
+	fds := []PollFd{p.PollFd(), {Fd: int32(p.stop), Events: POLLIN}}
+	for {
+		_, err := Poll(fds, -1)
+		if err == EINTR {
+			continue
+		}
+		if err != nil || fds[1].Revents != 0 {
+			return
+		}
+		if fds[0].Revents != 0 {
+			close(p.done)
+			return
+		}
+	}
+}
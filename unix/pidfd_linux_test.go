@@ -0,0 +1,167 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux && !sparc64
+// +build linux,!sparc64
+
+package unix_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func startProcess(t *testing.T, script string, files ...int) *unix.Process {
+	attr := &unix.CloneExecAttr{Files: files}
+	pid, pidfd, err := unix.CloneExec("/bin/sh", []string{"sh", "-c", script}, attr)
+	if err != nil {
+		t.Skipf("CloneExec: %v", err)
+	}
+	return unix.NewProcessFromPidfd(pidfd, pid)
+}
+
+func TestProcessWait(t *testing.T) {
+	var stdin [2]int
+	if err := unix.Pipe2(stdin[:], unix.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(stdin[1])
+	p := startProcess(t, "read x; exit 7", stdin[0])
+	unix.Close(stdin[0])
+	defer p.Close()
+
+	if s, err := p.Wait(unix.WNOHANG); err != nil || s != nil {
+		t.Errorf("Wait(WNOHANG) before exit: got %+v, %v", s, err)
+	}
+	done, err := p.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+		t.Fatal("Done before exit")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// The process exits once it reads a line.
+	if _, err := unix.Write(stdin[1], []byte("\n")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Done not closed after exit")
+	}
+	s, err := p.Wait(0)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if s.Pid != p.Pid() || !s.Exited() || s.ExitStatus() != 7 || s.Signaled() || s.Uid != os.Getuid() {
+		t.Errorf("got %+v", s)
+	}
+	if _, err := p.Wait(0); err != unix.ECHILD {
+		t.Errorf("Wait after reaping: got %v, want %v", err, unix.ECHILD)
+	}
+}
+
+func TestProcessInfo(t *testing.T) {
+	p := startProcess(t, "exec sleep 60")
+	defer p.Close()
+
+	info, err := p.Info(unix.PIDFD_INFO_CREDS | unix.PIDFD_INFO_CGROUPID)
+	if err == unix.ENOTTY || err == unix.EINVAL {
+		t.Skipf("Info: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if int(info.Pid) != p.Pid() || int(info.Ppid) != os.Getpid() || info.Mask&unix.PIDFD_INFO_CREDS == 0 || int(info.Ruid) != os.Getuid() {
+		t.Errorf("got %+v", info)
+	}
+
+	if h, err := p.FileHandle(); err == nil {
+		q, err := unix.OpenProcessByHandle(h, 0)
+		if err != nil {
+			t.Errorf("OpenProcessByHandle: %v", err)
+		} else {
+			if q.Pid() != p.Pid() {
+				t.Errorf("OpenProcessByHandle: got pid %d, want %d", q.Pid(), p.Pid())
+			}
+			q.Close()
+		}
+	}
+
+	if err := p.Signal(unix.SIGKILL); err != nil {
+		t.Fatalf("Signal: %v", err)
+	}
+	s, err := p.Wait(0)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if !s.Signaled() || s.Signal() != unix.SIGKILL || s.ExitStatus() != -1 {
+		t.Errorf("got %+v", s)
+	}
+
+	info, err = p.Info(unix.PIDFD_INFO_EXIT)
+	if err != nil {
+		t.Fatalf("Info after exit: %v", err)
+	}
+	if info.Mask&unix.PIDFD_INFO_EXIT != 0 && info.Exit_code != int32(unix.SIGKILL) {
+		t.Errorf("after exit: got %+v", info)
+	}
+}
+
+func TestProcessGetfd(t *testing.T) {
+	p, err := unix.NewProcess(os.Getpid(), 0)
+	if err != nil {
+		t.Skipf("NewProcess: %v", err)
+	}
+	// Close must stop the goroutine polling for exit.
+	if _, err := p.Done(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	fd, err := p.Getfd(p.Fd())
+	if err != nil {
+		t.Skipf("Getfd: %v", err)
+	}
+	defer unix.Close(fd)
+	var st1, st2 unix.Stat_t
+	if err := unix.Fstat(fd, &st1); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Fstat(p.Fd(), &st2); err != nil {
+		t.Fatal(err)
+	}
+	if st1.Ino != st2.Ino {
+		t.Errorf("got inode %d, want %d", st1.Ino, st2.Ino)
+	}
+}
+
+func TestProcessMrelease(t *testing.T) {
+	p := startProcess(t, "exec sleep 60")
+	defer p.Close()
+
+	err := unix.ProcessMrelease(p.Fd(), 0)
+	if err == unix.ENOSYS {
+		t.Skip("process_mrelease not supported")
+	}
+	if err != unix.EINVAL {
+		t.Errorf("ProcessMrelease of a live process: got %v, want %v", err, unix.EINVAL)
+	}
+	if err := p.Signal(unix.SIGKILL); err != nil {
+		t.Fatalf("Signal: %v", err)
+	}
+	// The memory may already be released by the exiting process.
+	if err := unix.ProcessMrelease(p.Fd(), 0); err != nil && err != unix.ESRCH {
+		t.Errorf("ProcessMrelease: %v", err)
+	}
+	if _, err := p.Wait(0); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_processMadviseAll(t *testing.T) {
+	const limit = 10 << 12 // bytes advised per call, like MAX_RW_COUNT
+
+	// advise advises up to limit bytes of the ranges of a batch, and
+	// records the ranges it advised, merging adjacent ones.
+	var advised []RemoteIovec
+	calls := 0
+	advise := func(batch []RemoteIovec) (int, error) {
+		calls++
+		if len(batch) > maxProcessMadviseIovecs {
+			t.Fatalf("got %d ranges, want at most %d", len(batch), maxProcessMadviseIovecs)
+		}
+		n := 0
+		for _, iov := range batch {
+			if n+iov.Len > limit {
+				iov.Len = limit - n
+			}
+			if iov.Len == 0 {
+				continue
+			}
+			if last := len(advised) - 1; last >= 0 && advised[last].Base+uintptr(advised[last].Len) == iov.Base {
+				advised[last].Len += iov.Len
+			} else {
+				advised = append(advised, iov)
+			}
+			n += iov.Len
+		}
+		return n, nil
+	}
+
+	iovs := []RemoteIovec{
+		{Base: 0x100000, Len: 25 << 12}, // split across three calls
+		{Base: 0x200000, Len: 0},
+		{Base: 0x300000, Len: 3 << 12},
+		{Base: 0x400000, Len: 2 << 12},
+	}
+	orig := append([]RemoteIovec(nil), iovs...)
+	n, err := processMadviseAll(iovs, advise)
+	if err != nil || n != 30<<12 {
+		t.Errorf("got %d, %v, want %d", n, err, 30<<12)
+	}
+	want := []RemoteIovec{orig[0], orig[2], orig[3]}
+	if !reflect.DeepEqual(advised, want) {
+		t.Errorf("advised %+v, want %+v", advised, want)
+	}
+	if calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+	if !reflect.DeepEqual(iovs, orig) {
+		t.Errorf("iovs modified: got %+v, want %+v", iovs, orig)
+	}
+
+	// More ranges than a call accepts.
+	advised, calls = nil, 0
+	many := make([]RemoteIovec, 2*maxProcessMadviseIovecs+1)
+	for i := range many {
+		many[i] = RemoteIovec{Base: uintptr(i) << 16, Len: 1}
+	}
+	if n, err := processMadviseAll(many, advise); err != nil || n != len(many) {
+		t.Errorf("got %d, %v, want %d", n, err, len(many))
+	}
+	if calls != 3 || !reflect.DeepEqual(advised, many) {
+		t.Errorf("got %d calls, advised %d ranges, want 3 calls and %d ranges", calls, len(advised), len(many))
+	}
+
+	// A call which fails after the first one, such as on a range which
+	// cannot be advised, or which makes no progress, stops the loop.
+	for _, tt := range []struct {
+		ret int
+		err error
+	}{
+		{0, EINVAL},
+		{0, nil},
+	} {
+		calls = 0
+		n, err := processMadviseAll(iovs, func(batch []RemoteIovec) (int, error) {
+			calls++
+			if calls > 1 {
+				return tt.ret, tt.err
+			}
+			return batch[0].Len, nil
+		})
+		if n != iovs[0].Len || err != tt.err || calls != 2 {
+			t.Errorf("got %d, %v after %d calls, want %d, %v after 2 calls", n, err, calls, iovs[0].Len, tt.err)
+		}
+	}
+}
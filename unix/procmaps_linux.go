@@ -0,0 +1,192 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// A MapsEntry describes a memory mapping of a process, as a line of
+// /proc/[pid]/maps.
+type MapsEntry struct {
+	Start    uintptr
+	End      uintptr
+	Perms    string // such as "r-xp": read, write, execute, and private or shared
+	Offset   uint64 // offset in the mapped file
+	Dev      uint64 // device of the mapped file, as returned by Mkdev
+	Inode    uint64 // inode of the mapped file
+	Pathname string // mapped file, pseudo-path such as "[heap]", or empty if anonymous
+}
+
+// Readable reports whether the mapping is readable.
+func (e *MapsEntry) Readable() bool { return len(e.Perms) > 0 && e.Perms[0] == 'r' }
+
+// Writable reports whether the mapping is writable.
+func (e *MapsEntry) Writable() bool { return len(e.Perms) > 1 && e.Perms[1] == 'w' }
+
+// Executable reports whether the mapping is executable.
+func (e *MapsEntry) Executable() bool { return len(e.Perms) > 2 && e.Perms[2] == 'x' }
+
+// Shared reports whether the mapping is shared rather than private.
+func (e *MapsEntry) Shared() bool { return len(e.Perms) > 3 && e.Perms[3] == 's' }
+
+// Anonymous reports whether the mapping is not backed by a file, such as
+// the heap or a stack.
+func (e *MapsEntry) Anonymous() bool { return e.Inode == 0 }
+
+// ParseProcMaps parses the contents of a /proc/[pid]/maps file.
+func ParseProcMaps(data []byte) ([]MapsEntry, error) {
+	var entries []MapsEntry
+	for len(data) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			line, data = data, nil
+		}
+		if len(line) == 0 {
+			continue
+		}
+		e, err := parseMapsLine(string(line))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// parseMapsLine parses a line such as
+//
+//	7f0c1c000000-7f0c1c021000 r-xp 00000000 fd:01 1835010    /usr/lib/libc.so.6
+func parseMapsLine(line string) (MapsEntry, error) {
+	var e MapsEntry
+	var fields [5]string
+	rest := line
+	for i := range fields {
+		rest = strings.TrimLeft(rest, " ")
+		j := strings.IndexByte(rest, ' ')
+		if j < 0 {
+			j = len(rest)
+		}
+		fields[i], rest = rest[:j], rest[j:]
+	}
+	// The pathname is padded to align it, and may contain spaces.
+	if len(rest) > 0 {
+		e.Pathname = strings.TrimLeft(rest[1:], " ")
+	}
+
+	i := strings.IndexByte(fields[0], '-')
+	if i < 0 {
+		return e, EINVAL
+	}
+	start, err1 := strconv.ParseUint(fields[0][:i], 16, 64)
+	end, err2 := strconv.ParseUint(fields[0][i+1:], 16, 64)
+	offset, err3 := strconv.ParseUint(fields[2], 16, 64)
+	inode, err4 := strconv.ParseUint(fields[4], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || len(fields[1]) != 4 {
+		return e, EINVAL
+	}
+	i = strings.IndexByte(fields[3], ':')
+	if i < 0 {
+		return e, EINVAL
+	}
+	major, err1 := strconv.ParseUint(fields[3][:i], 16, 32)
+	minor, err2 := strconv.ParseUint(fields[3][i+1:], 16, 32)
+	if err1 != nil || err2 != nil {
+		return e, EINVAL
+	}
+	e.Start = uintptr(start)
+	e.End = uintptr(end)
+	e.Perms = fields[1]
+	e.Offset = offset
+	e.Dev = Mkdev(uint32(major), uint32(minor))
+	e.Inode = inode
+	return e, nil
+}
+
+// ReadProcMaps returns the memory mappings of the process pid, or of the
+// calling process if pid is 0, from /proc/[pid]/maps.
+func ReadProcMaps(pid int) ([]MapsEntry, error) {
+	path := "/proc/self/maps"
+	if pid != 0 {
+		path = "/proc/" + strconv.Itoa(pid) + "/maps"
+	}
+	b, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProcMaps(b)
+}
+
+// MapsIovecs returns the address ranges of the mappings in entries for
+// which keep returns true, or of all the mappings if keep is nil, for use
+// with ProcessMadvise or ProcessVMReadv.
+func MapsIovecs(entries []MapsEntry, keep func(e *MapsEntry) bool) []RemoteIovec {
+	var iovs []RemoteIovec
+	for i := range entries {
+		e := &entries[i]
+		if keep != nil && !keep(e) {
+			continue
+		}
+		iovs = append(iovs, RemoteIovec{Base: e.Start, Len: int(e.End - e.Start)})
+	}
+	return iovs
+}
+
+// maxProcessMadviseIovecs is UIO_MAXIOV, the maximum number of ranges of a
+// single process_madvise call.
+const maxProcessMadviseIovecs = 1024
+
+// ProcessMadviseAll is like ProcessMadvise, but advises all of iovs with as
+// many calls as needed: the kernel limits a call to 1024 ranges and to
+// MAX_RW_COUNT bytes, about 2 GiB, and a call stops early at a range it
+// cannot advise. It returns the number of bytes advised, and stops at the
+// first error or when a call advises nothing.
+func ProcessMadviseAll(pidfd int, iovs []RemoteIovec, advice int) (int, error) {
+	return processMadviseAll(iovs, func(batch []RemoteIovec) (int, error) {
+		return ProcessMadvise(pidfd, batch, advice, 0)
+	})
+}
+
+// processMadviseAll calls advise with batches of iovs, resuming after the
+// bytes advised by each call, until all ranges are advised.
+func processMadviseAll(iovs []RemoteIovec, advise func(batch []RemoteIovec) (int, error)) (int, error) {
+	// The partially advised range is trimmed in a copy of iovs.
+	iovs = append([]RemoteIovec(nil), iovs...)
+	total := 0
+	for len(iovs) > 0 {
+		if iovs[0].Len == 0 {
+			iovs = iovs[1:]
+			continue
+		}
+		batch := iovs
+		if len(batch) > maxProcessMadviseIovecs {
+			batch = batch[:maxProcessMadviseIovecs]
+		}
+		n, err := advise(batch)
+		if err != nil {
+			return total, err
+		}
+		if n <= 0 {
+			return total, nil
+		}
+		total += n
+		for len(iovs) > 0 && n >= iovs[0].Len {
+			n -= iovs[0].Len
+			iovs = iovs[1:]
+		}
+		if n > 0 {
+			iovs[0].Base += uintptr(n)
+			iovs[0].Len -= n
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"os"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseProcMaps(t *testing.T) {
+	maps := `55d0a000-55d0c000 r--p 00000000 fd:01 1835010                    /usr/bin/cat
+56e6b000-56e8c000 rw-p 00000000 00:00 0                          [heap]
+7f3e0000-7f3e1000 rw-s 00001000 00:05 42                         /memfd:a b (deleted)
+` + "bf1e4000-bf1e6000 rw-p 00000000 00:00 0 \n"
+	entries, err := unix.ParseProcMaps([]byte(maps))
+	if err != nil {
+		t.Fatalf("ParseProcMaps: %v", err)
+	}
+	want := []unix.MapsEntry{
+		{0x55d0a000, 0x55d0c000, "r--p", 0, unix.Mkdev(0xfd, 1), 1835010, "/usr/bin/cat"},
+		{0x56e6b000, 0x56e8c000, "rw-p", 0, 0, 0, "[heap]"},
+		{0x7f3e0000, 0x7f3e1000, "rw-s", 0x1000, unix.Mkdev(0, 5), 42, "/memfd:a b (deleted)"},
+		{0xbf1e4000, 0xbf1e6000, "rw-p", 0, 0, 0, ""},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+	if e := entries[2]; !e.Readable() || !e.Writable() || e.Executable() || !e.Shared() || e.Anonymous() {
+		t.Errorf("got wrong permissions for %+v", e)
+	}
+	if !entries[1].Anonymous() {
+		t.Errorf("got %+v not anonymous", entries[1])
+	}
+
+	for _, bad := range []string{"0-1 r--p 0 0:0", "x-1 r--p 0 0:0 0", "0-1 r--p 0 00 0", "0-1 r- 0 0:0 0"} {
+		if _, err := unix.ParseProcMaps([]byte(bad)); err == nil {
+			t.Errorf("ParseProcMaps(%q): no error", bad)
+		}
+	}
+}
+
+func TestProcessMadvise(t *testing.T) {
+	pagesize := unix.Getpagesize()
+	mem, err := unix.Mmap(-1, 0, 4*pagesize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Munmap(mem)
+	for i := range mem {
+		mem[i] = 1
+	}
+	start := uintptr(unsafe.Pointer(&mem[0]))
+
+	entries, err := unix.ReadProcMaps(os.Getpid())
+	if err != nil {
+		t.Fatalf("ReadProcMaps: %v", err)
+	}
+	iovs := unix.MapsIovecs(entries, func(e *unix.MapsEntry) bool {
+		return e.Start <= start && start < e.End
+	})
+	if len(iovs) != 1 || iovs[0].Len < len(mem) {
+		t.Fatalf("got %+v for mapping at %#x", iovs, start)
+	}
+	// Only advise our mapping, which may have been merged with others.
+	iovs[0] = unix.RemoteIovec{Base: start, Len: len(mem)}
+
+	pidfd, err := unix.PidfdOpen(os.Getpid(), 0)
+	if err != nil {
+		t.Skipf("PidfdOpen: %v", err)
+	}
+	defer unix.Close(pidfd)
+	n, err := unix.ProcessMadvise(pidfd, iovs, unix.MADV_COLD, 0)
+	if err == unix.ENOSYS || err == unix.EPERM {
+		t.Skipf("ProcessMadvise: %v", err)
+	}
+	if err != nil || n != len(mem) {
+		t.Fatalf("ProcessMadvise: got %d, %v, want %d", n, err, len(mem))
+	}
+
+	many := make([]unix.RemoteIovec, 1500)
+	for i := range many {
+		many[i] = unix.RemoteIovec{Base: start + uintptr(i%4*pagesize), Len: pagesize}
+	}
+	if n, err := unix.ProcessMadviseAll(pidfd, many, unix.MADV_PAGEOUT); err != nil || n != len(many)*pagesize {
+		t.Errorf("ProcessMadviseAll: got %d, %v, want %d", n, err, len(many)*pagesize)
+	}
+	if mem[0] != 1 {
+		t.Errorf("got %d after MADV_PAGEOUT, want 1", mem[0])
+	}
+
+	// A single call advises at most MAX_RW_COUNT bytes, about 2 GiB, so a
+	// larger range takes several calls.
+	if unsafe.Sizeof(uintptr(0)) < 8 {
+		return
+	}
+	gib := 1 << 30
+	large := 3 * gib
+	reserved, err := unix.Mmap(-1, 0, large, unix.PROT_NONE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS|unix.MAP_NORESERVE)
+	if err != nil {
+		t.Skipf("Mmap(%d): %v", large, err)
+	}
+	defer unix.Munmap(reserved)
+	large1 := []unix.RemoteIovec{{Base: uintptr(unsafe.Pointer(&reserved[0])), Len: large}}
+	if n, err := unix.ProcessMadviseAll(pidfd, large1, unix.MADV_COLD); err != nil || n != large {
+		t.Errorf("ProcessMadviseAll of %d bytes: got %d, %v", large, n, err)
+	}
+}
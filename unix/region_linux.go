@@ -0,0 +1,209 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"io"
+	"math/bits"
+	"unsafe"
+)
+
+// HugePageFlags returns the Mmap flags for a mapping backed by huge pages of
+// pageSize bytes, a power of two such as 2<<20, which must be supported by
+// the system: MAP_HUGETLB with the size encoded with MAP_HUGE_SHIFT. If
+// pageSize is 0, the default huge page size is used.
+func HugePageFlags(pageSize int) int {
+	if pageSize <= 0 {
+		return MAP_HUGETLB
+	}
+	return MAP_HUGETLB | (bits.Len(uint(pageSize))-1)&MAP_HUGE_MASK<<MAP_HUGE_SHIFT
+}
+
+// A Region is a mapping of memory, such as of a file, which can be resized
+// and whose page-aligned ranges can be synced, advised and locked. It
+// implements io.ReaderAt and io.WriterAt. A Region is not safe for
+// concurrent use while it is resized or unmapped.
+type Region struct {
+	data     []byte
+	pagesize int
+}
+
+// MmapRegion maps length bytes of the file fd at offset, or anonymous memory
+// if flags includes MAP_ANONYMOUS, into a Region, like Mmap.
+func MmapRegion(fd int, offset int64, length int, prot int, flags int) (*Region, error) {
+	return MmapRegionAt(0, fd, offset, length, prot, flags)
+}
+
+// MmapRegionAt is like MmapRegion, but places the mapping at the page-aligned
+// address addr if possible. If flags includes MAP_FIXED_NOREPLACE, the
+// mapping is placed exactly at addr, and MmapRegionAt returns EEXIST if
+// addr is already in use. MAP_FIXED, which replaces existing mappings, is
+// not permitted.
+func MmapRegionAt(addr uintptr, fd int, offset int64, length int, prot int, flags int) (*Region, error) {
+	if flags&MAP_FIXED != 0 {
+		return nil, EINVAL
+	}
+	b, err := mapper.mmapAt(addr, fd, offset, length, prot, flags)
+	if err != nil {
+		return nil, err
+	}
+	r := &Region{data: b, pagesize: Getpagesize()}
+	if flags&MAP_HUGETLB != 0 {
+		if shift := flags >> MAP_HUGE_SHIFT & MAP_HUGE_MASK; shift != 0 {
+			r.pagesize = 1 << uint(shift)
+		} else {
+			r.pagesize = 0
+		}
+	}
+	// Kernels before 4.17 treat MAP_FIXED_NOREPLACE as a hint.
+	if flags&MAP_FIXED_NOREPLACE != 0 && r.Addr() != addr {
+		r.Unmap()
+		return nil, EEXIST
+	}
+	return r, nil
+}
+
+// Bytes returns the memory of the region r, which is invalidated by Resize
+// and Unmap.
+func (r *Region) Bytes() []byte { return r.data }
+
+// Len returns the length of the region r.
+func (r *Region) Len() int { return len(r.data) }
+
+// Addr returns the address of the region r.
+func (r *Region) Addr() uintptr {
+	if len(r.data) == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&r.data[0]))
+}
+
+// Resize resizes the region r to newLength bytes with Mremap. Flags may
+// include MREMAP_MAYMOVE to permit moving the region to another address if
+// it cannot be resized in place. For a file mapping, the pages beyond the
+// end of the file must not be accessed.
+func (r *Region) Resize(newLength int, flags int) error {
+	if flags&(MREMAP_FIXED|MREMAP_DONTUNMAP) != 0 {
+		return EINVAL
+	}
+	b, err := Mremap(r.data, newLength, flags)
+	if err != nil {
+		return err
+	}
+	r.data = b
+	return nil
+}
+
+// pages returns the memory of the region r from off to off+n, extended to
+// page boundaries.
+func (r *Region) pages(off, n int) ([]byte, error) {
+	if off < 0 || n < 0 || off > len(r.data)-n {
+		return nil, EINVAL
+	}
+	pagesize := r.pagesize
+	if pagesize == 0 {
+		// The default huge page size is not known; the mapping is aligned
+		// to it, and the kernel checks the alignment of the ranges.
+		return r.data[off : off+n], nil
+	}
+	start := off &^ (pagesize - 1)
+	end := (off + n + pagesize - 1) &^ (pagesize - 1)
+	if end > len(r.data) {
+		end = len(r.data)
+	}
+	return r.data[start:end], nil
+}
+
+// Sync flushes the pages of the region r covering the n bytes at off to the
+// mapped file with Msync. Flags is MS_SYNC or MS_ASYNC, optionally or'ed
+// with MS_INVALIDATE.
+func (r *Region) Sync(off, n int, flags int) error {
+	b, err := r.pages(off, n)
+	if err != nil {
+		return err
+	}
+	return Msync(b, flags)
+}
+
+// Advise gives the advice, one of the MADV_* constants, for the pages of the
+// region r covering the n bytes at off, with Madvise.
+func (r *Region) Advise(off, n int, advice int) error {
+	b, err := r.pages(off, n)
+	if err != nil {
+		return err
+	}
+	return Madvise(b, advice)
+}
+
+// Lock locks the pages of the region r covering the n bytes at off in
+// memory, with Mlock.
+func (r *Region) Lock(off, n int) error {
+	b, err := r.pages(off, n)
+	if err != nil {
+		return err
+	}
+	return Mlock(b)
+}
+
+// Unlock unlocks the pages of the region r covering the n bytes at off,
+// with Munlock.
+func (r *Region) Unlock(off, n int) error {
+	b, err := r.pages(off, n)
+	if err != nil {
+		return err
+	}
+	return Munlock(b)
+}
+
+// Protect sets the protection of the pages of the region r covering the n
+// bytes at off to prot, with Mprotect.
+func (r *Region) Protect(off, n int, prot int) error {
+	b, err := r.pages(off, n)
+	if err != nil {
+		return err
+	}
+	return Mprotect(b, prot)
+}
+
+// ReadAt implements io.ReaderAt, reading from the memory of the region r.
+func (r *Region) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, EINVAL
+	}
+	if off >= int64(len(r.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt implements io.WriterAt, writing to the memory of the region r,
+// which must be writable. It returns io.ErrShortWrite if p extends beyond
+// the end of the region.
+func (r *Region) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off > int64(len(r.data)) {
+		return 0, EINVAL
+	}
+	n := copy(r.data[off:], p)
+	if n < len(p) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// Unmap unmaps the region r with Munmap.
+func (r *Region) Unmap() error {
+	if err := Munmap(r.data); err != nil {
+		return err
+	}
+	r.data = nil
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestRegion(t *testing.T) {
+	pagesize := unix.Getpagesize()
+	fd, err := unix.Open(filepath.Join(t.TempDir(), "log"), unix.O_RDWR|unix.O_CREAT|unix.O_CLOEXEC, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fd)
+	if err := unix.Ftruncate(fd, int64(pagesize)); err != nil {
+		t.Fatal(err)
+	}
+	r, err := unix.MmapRegion(fd, 0, pagesize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		t.Fatalf("MmapRegion: %v", err)
+	}
+	defer r.Unmap()
+
+	if n, err := r.WriteAt([]byte("hello"), 10); err != nil || n != 5 {
+		t.Fatalf("WriteAt: got %d, %v", n, err)
+	}
+	if err := r.Sync(10, 5, unix.MS_SYNC); err != nil {
+		t.Errorf("Sync: %v", err)
+	}
+	buf := make([]byte, 5)
+	if _, err := unix.Pread(fd, buf, 10); err != nil || string(buf) != "hello" {
+		t.Errorf("Pread after Sync: got %q, %v", buf, err)
+	}
+
+	// Grow the file and the region.
+	if err := unix.Ftruncate(fd, int64(3*pagesize)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Resize(3*pagesize, unix.MREMAP_MAYMOVE); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	if r.Len() != 3*pagesize || !bytes.Equal(r.Bytes()[10:15], []byte("hello")) {
+		t.Errorf("Resize: got %d bytes", r.Len())
+	}
+	off := int64(2*pagesize + 5)
+	if _, err := r.WriteAt([]byte("world"), off); err != nil {
+		t.Fatalf("WriteAt: %v", err)
+	}
+	if _, err := unix.Pread(fd, buf, off); err != nil || string(buf) != "world" {
+		t.Errorf("Pread after Resize: got %q, %v", buf, err)
+	}
+
+	if n, err := r.ReadAt(buf, off); err != nil || n != 5 || string(buf) != "world" {
+		t.Errorf("ReadAt: got %d, %q, %v", n, buf, err)
+	}
+	if n, err := r.ReadAt(buf, int64(r.Len()-2)); err != io.EOF || n != 2 {
+		t.Errorf("ReadAt at the end: got %d, %v, want 2, %v", n, err, io.EOF)
+	}
+	if n, err := r.WriteAt([]byte("abc"), int64(r.Len()-1)); err != io.ErrShortWrite || n != 1 {
+		t.Errorf("WriteAt at the end: got %d, %v, want 1, %v", n, err, io.ErrShortWrite)
+	}
+
+	if err := r.Advise(pagesize+1, 10, unix.MADV_WILLNEED); err != nil {
+		t.Errorf("Advise: %v", err)
+	}
+	if err := r.Advise(1, r.Len(), unix.MADV_WILLNEED); err != unix.EINVAL {
+		t.Errorf("Advise beyond the end: got %v, want %v", err, unix.EINVAL)
+	}
+	if err := r.Lock(1, 1); err != nil {
+		t.Logf("Lock: %v", err)
+	} else if err := r.Unlock(1, 1); err != nil {
+		t.Errorf("Unlock: %v", err)
+	}
+	if err := r.Protect(0, 1, unix.PROT_READ); err != nil {
+		t.Errorf("Protect: %v", err)
+	}
+}
+
+func TestMmapRegionAt(t *testing.T) {
+	pagesize := unix.Getpagesize()
+	prot := unix.PROT_READ | unix.PROT_WRITE
+	flags := unix.MAP_PRIVATE | unix.MAP_ANONYMOUS
+
+	if _, err := unix.MmapRegionAt(0, -1, 0, pagesize, prot, flags|unix.MAP_FIXED); err != unix.EINVAL {
+		t.Errorf("MmapRegionAt with MAP_FIXED: got %v, want %v", err, unix.EINVAL)
+	}
+	r, err := unix.MmapRegion(-1, 0, pagesize, prot, flags)
+	if err != nil {
+		t.Fatalf("MmapRegion: %v", err)
+	}
+	addr := r.Addr()
+	if _, err := unix.MmapRegionAt(addr, -1, 0, pagesize, prot, flags|unix.MAP_FIXED_NOREPLACE); err != unix.EEXIST {
+		t.Errorf("MmapRegionAt over a mapping: got %v, want %v", err, unix.EEXIST)
+	}
+	if err := r.Unmap(); err != nil {
+		t.Fatalf("Unmap: %v", err)
+	}
+	r, err = unix.MmapRegionAt(addr, -1, 0, pagesize, prot, flags|unix.MAP_FIXED_NOREPLACE)
+	if err != nil {
+		t.Fatalf("MmapRegionAt: %v", err)
+	}
+	if r.Addr() != addr {
+		t.Errorf("MmapRegionAt: got %#x, want %#x", r.Addr(), addr)
+	}
+	r.Unmap()
+}
+
+func TestHugePageFlags(t *testing.T) {
+	if got, want := unix.HugePageFlags(2<<20), unix.MAP_HUGETLB|21<<unix.MAP_HUGE_SHIFT; got != want {
+		t.Errorf("HugePageFlags(2MB): got %#x, want %#x", got, want)
+	}
+	if got := unix.HugePageFlags(0); got != unix.MAP_HUGETLB {
+		t.Errorf("HugePageFlags(0): got %#x, want %#x", got, unix.MAP_HUGETLB)
+	}
+
+	const size = 2 << 20
+	r, err := unix.MmapRegion(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS|unix.HugePageFlags(size))
+	if err != nil {
+		t.Skipf("MmapRegion with huge pages: %v", err)
+	}
+	defer r.Unmap()
+	if err := r.Advise(1, 1, unix.MADV_DONTNEED); err != nil {
+		t.Errorf("Advise: %v", err)
+	}
+}
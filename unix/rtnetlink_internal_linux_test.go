@@ -0,0 +1,47 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "testing"
+
+func TestRtnlVxlan_encode(t *testing.T) {
+	attrs := func(v *RtnlVxlan) map[uint16][]byte {
+		var e NetlinkAttrEncoder
+		v.encode(&e)
+		m := make(map[uint16][]byte)
+		d := NewNetlinkAttrDecoder(e.Bytes())
+		for d.Next() {
+			m[d.Type()] = d.Value()
+		}
+		if err := d.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	// The zero value leaves learning to the kernel default.
+	m := attrs(&RtnlVxlan{})
+	if _, ok := m[IFLA_VXLAN_LEARNING]; ok {
+		t.Errorf("zero RtnlVxlan encodes IFLA_VXLAN_LEARNING")
+	}
+	if len(m) != 1 {
+		t.Errorf("zero RtnlVxlan: got attributes %v, want IFLA_VXLAN_ID only", m)
+	}
+
+	m = attrs(&RtnlVxlan{VNI: 42, NoLearning: true})
+	if v, ok := m[IFLA_VXLAN_LEARNING]; !ok || len(v) != 1 || v[0] != 0 {
+		t.Errorf("NoLearning: got IFLA_VXLAN_LEARNING %v, %v, want [0]", v, ok)
+	}
+
+	var v RtnlVxlan
+	var e NetlinkAttrEncoder
+	e.Uint8(IFLA_VXLAN_LEARNING, 1)
+	if v.decode(NewNetlinkAttrDecoder(e.Bytes())); v.NoLearning {
+		t.Errorf("decode of IFLA_VXLAN_LEARNING 1: got NoLearning")
+	}
+}
@@ -0,0 +1,1110 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "unsafe"
+
+// A RtnlConn is a NETLINK_ROUTE socket with typed operations on links,
+// addresses, routes, nexthop objects and neighbors.
+//
+// A connection used to receive multicast notifications with Subscribe and
+// ReceiveEvents should not be used for requests, as Execute discards the
+// notifications which arrive while it waits for a reply.
+type RtnlConn struct {
+	*NetlinkConn
+}
+
+// NewRtnlConn opens a NETLINK_ROUTE socket in the network namespace of the
+// calling thread.
+func NewRtnlConn() (*RtnlConn, error) {
+	c, err := NewNetlinkConn(NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	// Strict checking makes the kernel filter dumps by the fields of the
+	// request header. It is unknown to older kernels, which then return
+	// everything.
+	SetsockoptInt(c.fd, SOL_NETLINK, NETLINK_GET_STRICT_CHK, 1)
+	return &RtnlConn{c}, nil
+}
+
+// structBytes returns the memory of the struct at p, which has the given
+// size, as a byte slice.
+func structBytes(p unsafe.Pointer, size int) []byte {
+	return unsafe.Slice((*byte)(p), size)
+}
+
+func rtnlMessage(typ, flags uint16, hdr []byte, attrs *NetlinkAttrEncoder) NetlinkMessage {
+	data := append([]byte(nil), hdr...)
+	if attrs != nil {
+		data = append(data, attrs.Bytes()...)
+	}
+	return NetlinkMessage{Header: NlMsghdr{Type: typ, Flags: flags}, Data: data}
+}
+
+// dump executes a dump request, retrying it if it was interrupted.
+func (c *RtnlConn) dump(m NetlinkMessage) ([]NetlinkMessage, error) {
+	m.Header.Flags |= NLM_F_DUMP
+	for i := 0; ; i++ {
+		msgs, err := c.Execute(m)
+		if err == EINTR && i < 10 {
+			continue
+		}
+		return msgs, err
+	}
+}
+
+func familyOf(addr []byte) uint8 {
+	switch len(addr) {
+	case 4:
+		return AF_INET
+	case 16:
+		return AF_INET6
+	}
+	return AF_UNSPEC
+}
+
+// RtnlLinkData is the kind-specific configuration of a link, nested in
+// IFLA_INFO_DATA. It is implemented by RtnlVeth, RtnlBridge, RtnlDummy,
+// RtnlVlan, RtnlMacvlan and RtnlVxlan.
+type RtnlLinkData interface {
+	// Kind returns the IFLA_INFO_KIND of the link, such as "veth".
+	Kind() string
+
+	encode(e *NetlinkAttrEncoder)
+	decode(d *NetlinkAttrDecoder)
+}
+
+// A RtnlLink is a network interface, as sent and received in RTM_NEWLINK
+// messages. Zero fields are not sent to the kernel.
+type RtnlLink struct {
+	Info         IfInfomsg
+	Name         string // IFLA_IFNAME
+	MTU          uint32 // IFLA_MTU
+	TxQueueLen   uint32 // IFLA_TXQLEN
+	HardwareAddr []byte // IFLA_ADDRESS
+	Master       int32  // IFLA_MASTER, the index of the controlling bridge
+	ParentIndex  int32  // IFLA_LINK, such as the link of a VLAN or the peer of a veth
+	OperState    uint8  // IFLA_OPERSTATE, received only
+
+	// NetNsFd, if greater than 0, is a file descriptor of the network
+	// namespace to create or move the link in (IFLA_NET_NS_FD).
+	NetNsFd int
+
+	// Kind is the IFLA_INFO_KIND of received links. It is ignored when
+	// sending, where the kind of Data is used.
+	Kind string
+
+	// Data is the kind-specific configuration. Received links have Data
+	// set if their kind is one of the implementations of RtnlLinkData.
+	Data RtnlLinkData
+
+	// Attrs holds all attributes of received links.
+	Attrs []NetlinkAttr
+}
+
+func (l *RtnlLink) encode(e *NetlinkAttrEncoder) {
+	if l.Name != "" {
+		e.String(IFLA_IFNAME, l.Name)
+	}
+	if l.MTU != 0 {
+		e.Uint32(IFLA_MTU, l.MTU)
+	}
+	if l.TxQueueLen != 0 {
+		e.Uint32(IFLA_TXQLEN, l.TxQueueLen)
+	}
+	if l.HardwareAddr != nil {
+		e.Attr(IFLA_ADDRESS, l.HardwareAddr)
+	}
+	if l.Master != 0 {
+		e.Uint32(IFLA_MASTER, uint32(l.Master))
+	}
+	if l.ParentIndex != 0 {
+		e.Uint32(IFLA_LINK, uint32(l.ParentIndex))
+	}
+	if l.NetNsFd > 0 {
+		e.Uint32(IFLA_NET_NS_FD, uint32(l.NetNsFd))
+	}
+	if l.Data != nil {
+		e.Nested(IFLA_LINKINFO)
+		e.String(IFLA_INFO_KIND, l.Data.Kind())
+		e.Nested(IFLA_INFO_DATA)
+		l.Data.encode(e)
+		e.EndNested()
+		e.EndNested()
+	}
+}
+
+func (l *RtnlLink) message(typ, flags uint16) NetlinkMessage {
+	var e NetlinkAttrEncoder
+	l.encode(&e)
+	return rtnlMessage(typ, flags, structBytes(unsafe.Pointer(&l.Info), SizeofIfInfomsg), &e)
+}
+
+func parseRtnlLink(m *NetlinkMessage) (*RtnlLink, error) {
+	if len(m.Data) < SizeofIfInfomsg {
+		return nil, EINVAL
+	}
+	l := &RtnlLink{Info: *(*IfInfomsg)(unsafe.Pointer(&m.Data[0]))}
+	var err error
+	l.Attrs, err = ParseNetlinkAttrs(m.Data[SizeofIfInfomsg:])
+	if err != nil {
+		return nil, err
+	}
+	d := NewNetlinkAttrDecoder(m.Data[SizeofIfInfomsg:])
+	for d.Next() {
+		switch d.Type() {
+		case IFLA_IFNAME:
+			l.Name = d.String()
+		case IFLA_MTU:
+			l.MTU = d.Uint32()
+		case IFLA_TXQLEN:
+			l.TxQueueLen = d.Uint32()
+		case IFLA_ADDRESS:
+			l.HardwareAddr = d.Value()
+		case IFLA_MASTER:
+			l.Master = d.Int32()
+		case IFLA_LINK:
+			l.ParentIndex = d.Int32()
+		case IFLA_OPERSTATE:
+			l.OperState = d.Uint8()
+		case IFLA_LINKINFO:
+			var data []byte
+			info := d.Nested()
+			for info.Next() {
+				switch info.Type() {
+				case IFLA_INFO_KIND:
+					l.Kind = info.String()
+				case IFLA_INFO_DATA:
+					data = info.Value()
+				}
+			}
+			if err := info.Err(); err != nil {
+				return nil, err
+			}
+			if l.Data = newRtnlLinkData(l.Kind); l.Data != nil {
+				dd := NewNetlinkAttrDecoder(data)
+				l.Data.decode(dd)
+				if err := dd.Err(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return l, d.Err()
+}
+
+func newRtnlLinkData(kind string) RtnlLinkData {
+	switch kind {
+	case "veth":
+		return new(RtnlVeth)
+	case "bridge":
+		return new(RtnlBridge)
+	case "dummy":
+		return new(RtnlDummy)
+	case "vlan":
+		return new(RtnlVlan)
+	case "macvlan":
+		return new(RtnlMacvlan)
+	case "vxlan":
+		return new(RtnlVxlan)
+	}
+	return nil
+}
+
+// RtnlVeth configures a veth pair. Only the peer is configured in
+// IFLA_INFO_DATA, and it is not reported back by the kernel: the index of
+// the peer of a received veth link is its ParentIndex.
+type RtnlVeth struct {
+	PeerName         string
+	PeerHardwareAddr []byte
+	PeerNetNsFd      int // if greater than 0, the network namespace of the peer
+}
+
+func (*RtnlVeth) Kind() string { return "veth" }
+
+func (v *RtnlVeth) encode(e *NetlinkAttrEncoder) {
+	peer := RtnlLink{Name: v.PeerName, HardwareAddr: v.PeerHardwareAddr, NetNsFd: v.PeerNetNsFd}
+	e.Nested(VETH_INFO_PEER)
+	// The payload of VETH_INFO_PEER is an ifinfomsg followed by the
+	// attributes of the peer.
+	var hdr IfInfomsg
+	e.b = append(e.b, structBytes(unsafe.Pointer(&hdr), SizeofIfInfomsg)...)
+	peer.encode(e)
+	e.EndNested()
+}
+
+func (v *RtnlVeth) decode(d *NetlinkAttrDecoder) {}
+
+// RtnlBridge configures a bridge.
+type RtnlBridge struct {
+	VlanFiltering bool   // IFLA_BR_VLAN_FILTERING
+	STP           bool   // IFLA_BR_STP_STATE
+	AgeingTime    uint32 // IFLA_BR_AGEING_TIME, in centiseconds
+}
+
+func (*RtnlBridge) Kind() string { return "bridge" }
+
+func (b *RtnlBridge) encode(e *NetlinkAttrEncoder) {
+	if b.VlanFiltering {
+		e.Uint8(IFLA_BR_VLAN_FILTERING, 1)
+	}
+	if b.STP {
+		e.Uint32(IFLA_BR_STP_STATE, 1)
+	}
+	if b.AgeingTime != 0 {
+		e.Uint32(IFLA_BR_AGEING_TIME, b.AgeingTime)
+	}
+}
+
+func (b *RtnlBridge) decode(d *NetlinkAttrDecoder) {
+	for d.Next() {
+		switch d.Type() {
+		case IFLA_BR_VLAN_FILTERING:
+			b.VlanFiltering = d.Uint8() != 0
+		case IFLA_BR_STP_STATE:
+			b.STP = d.Uint32() != 0
+		case IFLA_BR_AGEING_TIME:
+			b.AgeingTime = d.Uint32()
+		}
+	}
+}
+
+// RtnlDummy configures a dummy link, which has no configuration.
+type RtnlDummy struct{}
+
+func (*RtnlDummy) Kind() string { return "dummy" }
+
+func (*RtnlDummy) encode(e *NetlinkAttrEncoder) {}
+
+func (*RtnlDummy) decode(d *NetlinkAttrDecoder) {}
+
+// RtnlVlan configures a VLAN on the link given by the ParentIndex of the
+// RtnlLink.
+type RtnlVlan struct {
+	ID       uint16 // IFLA_VLAN_ID
+	Protocol uint16 // IFLA_VLAN_PROTOCOL, ETH_P_8021Q or ETH_P_8021AD; 0 means ETH_P_8021Q
+}
+
+func (*RtnlVlan) Kind() string { return "vlan" }
+
+func (v *RtnlVlan) encode(e *NetlinkAttrEncoder) {
+	e.Uint16(IFLA_VLAN_ID, v.ID)
+	if v.Protocol != 0 {
+		e.Uint16BE(IFLA_VLAN_PROTOCOL, v.Protocol)
+	}
+}
+
+func (v *RtnlVlan) decode(d *NetlinkAttrDecoder) {
+	for d.Next() {
+		switch d.Type() {
+		case IFLA_VLAN_ID:
+			v.ID = d.Uint16()
+		case IFLA_VLAN_PROTOCOL:
+			v.Protocol = d.Uint16BE()
+		}
+	}
+}
+
+// RtnlMacvlan configures a macvlan on the link given by the ParentIndex of
+// the RtnlLink.
+type RtnlMacvlan struct {
+	Mode uint32 // IFLA_MACVLAN_MODE, such as MACVLAN_MODE_BRIDGE; 0 means MACVLAN_MODE_VEPA
+}
+
+func (*RtnlMacvlan) Kind() string { return "macvlan" }
+
+func (m *RtnlMacvlan) encode(e *NetlinkAttrEncoder) {
+	if m.Mode != 0 {
+		e.Uint32(IFLA_MACVLAN_MODE, m.Mode)
+	}
+}
+
+func (m *RtnlMacvlan) decode(d *NetlinkAttrDecoder) {
+	for d.Next() {
+		if d.Type() == IFLA_MACVLAN_MODE {
+			m.Mode = d.Uint32()
+		}
+	}
+}
+
+// RtnlVxlan configures a VXLAN tunnel endpoint.
+type RtnlVxlan struct {
+	VNI        uint32 // IFLA_VXLAN_ID
+	Group      []byte // IFLA_VXLAN_GROUP or IFLA_VXLAN_GROUP6, the multicast group or remote address
+	Local      []byte // IFLA_VXLAN_LOCAL or IFLA_VXLAN_LOCAL6
+	DevIndex   uint32 // IFLA_VXLAN_LINK, the link of the underlay
+	Port       uint16 // IFLA_VXLAN_PORT, the UDP destination port; 0 means the kernel default
+	TTL        uint8  // IFLA_VXLAN_TTL
+	NoLearning bool   // IFLA_VXLAN_LEARNING of 0, disabling the learning enabled by default
+}
+
+func (*RtnlVxlan) Kind() string { return "vxlan" }
+
+func (v *RtnlVxlan) encode(e *NetlinkAttrEncoder) {
+	e.Uint32(IFLA_VXLAN_ID, v.VNI)
+	if len(v.Group) == 16 {
+		e.Attr(IFLA_VXLAN_GROUP6, v.Group)
+	} else if v.Group != nil {
+		e.Attr(IFLA_VXLAN_GROUP, v.Group)
+	}
+	if len(v.Local) == 16 {
+		e.Attr(IFLA_VXLAN_LOCAL6, v.Local)
+	} else if v.Local != nil {
+		e.Attr(IFLA_VXLAN_LOCAL, v.Local)
+	}
+	if v.DevIndex != 0 {
+		e.Uint32(IFLA_VXLAN_LINK, v.DevIndex)
+	}
+	if v.Port != 0 {
+		e.Uint16BE(IFLA_VXLAN_PORT, v.Port)
+	}
+	if v.TTL != 0 {
+		e.Uint8(IFLA_VXLAN_TTL, v.TTL)
+	}
+	if v.NoLearning {
+		e.Uint8(IFLA_VXLAN_LEARNING, 0)
+	}
+}
+
+func (v *RtnlVxlan) decode(d *NetlinkAttrDecoder) {
+	for d.Next() {
+		switch d.Type() {
+		case IFLA_VXLAN_ID:
+			v.VNI = d.Uint32()
+		case IFLA_VXLAN_GROUP, IFLA_VXLAN_GROUP6:
+			v.Group = d.Value()
+		case IFLA_VXLAN_LOCAL, IFLA_VXLAN_LOCAL6:
+			v.Local = d.Value()
+		case IFLA_VXLAN_LINK:
+			v.DevIndex = d.Uint32()
+		case IFLA_VXLAN_PORT:
+			v.Port = d.Uint16BE()
+		case IFLA_VXLAN_TTL:
+			v.TTL = d.Uint8()
+		case IFLA_VXLAN_LEARNING:
+			v.NoLearning = d.Uint8() == 0
+		}
+	}
+}
+
+// LinkAdd creates the link l. Its kind is given by l.Data, which must not
+// be nil.
+func (c *RtnlConn) LinkAdd(l *RtnlLink) error {
+	if l.Data == nil {
+		return EINVAL
+	}
+	_, err := c.Execute(l.message(RTM_NEWLINK, NLM_F_CREATE|NLM_F_EXCL))
+	return err
+}
+
+// LinkSet changes the link identified by l.Info.Index, or by l.Name if the
+// index is 0. The flags in l.Info.Change are set to their values in
+// l.Info.Flags, and the other nonzero fields of l are applied.
+func (c *RtnlConn) LinkSet(l *RtnlLink) error {
+	_, err := c.Execute(l.message(RTM_NEWLINK, 0))
+	return err
+}
+
+// LinkSetUp sets IFF_UP on the link with the given index.
+func (c *RtnlConn) LinkSetUp(index int) error {
+	return c.LinkSet(&RtnlLink{Info: IfInfomsg{Index: int32(index), Flags: IFF_UP, Change: IFF_UP}})
+}
+
+// LinkSetDown clears IFF_UP on the link with the given index.
+func (c *RtnlConn) LinkSetDown(index int) error {
+	return c.LinkSet(&RtnlLink{Info: IfInfomsg{Index: int32(index), Change: IFF_UP}})
+}
+
+// LinkSetMaster enslaves the link with the given index to the bridge or
+// bond with index master. A master index of 0 releases the link.
+func (c *RtnlConn) LinkSetMaster(index, master int) error {
+	l := RtnlLink{Info: IfInfomsg{Index: int32(index)}}
+	var e NetlinkAttrEncoder
+	e.Uint32(IFLA_MASTER, uint32(master))
+	_, err := c.Execute(rtnlMessage(RTM_NEWLINK, 0, structBytes(unsafe.Pointer(&l.Info), SizeofIfInfomsg), &e))
+	return err
+}
+
+// LinkSetNetNsFd moves the link with the given index to the network
+// namespace referred to by nsfd.
+func (c *RtnlConn) LinkSetNetNsFd(index, nsfd int) error {
+	return c.LinkSet(&RtnlLink{Info: IfInfomsg{Index: int32(index)}, NetNsFd: nsfd})
+}
+
+// LinkDel deletes the link with the given index.
+func (c *RtnlConn) LinkDel(index int) error {
+	l := RtnlLink{Info: IfInfomsg{Index: int32(index)}}
+	_, err := c.Execute(l.message(RTM_DELLINK, 0))
+	return err
+}
+
+// LinkGet returns the link with the given index.
+func (c *RtnlConn) LinkGet(index int) (*RtnlLink, error) {
+	l := RtnlLink{Info: IfInfomsg{Index: int32(index)}}
+	return c.linkGet(&l)
+}
+
+// LinkGetByName returns the link with the given name.
+func (c *RtnlConn) LinkGetByName(name string) (*RtnlLink, error) {
+	l := RtnlLink{Name: name}
+	return c.linkGet(&l)
+}
+
+func (c *RtnlConn) linkGet(l *RtnlLink) (*RtnlLink, error) {
+	msgs, err := c.Execute(l.message(RTM_GETLINK, 0))
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) != 1 {
+		return nil, EINVAL
+	}
+	return parseRtnlLink(&msgs[0])
+}
+
+// Links returns all links.
+func (c *RtnlConn) Links() ([]RtnlLink, error) {
+	var l RtnlLink
+	msgs, err := c.dump(l.message(RTM_GETLINK, 0))
+	if err != nil {
+		return nil, err
+	}
+	links := make([]RtnlLink, 0, len(msgs))
+	for i := range msgs {
+		l, err := parseRtnlLink(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *l)
+	}
+	return links, nil
+}
+
+// A RtnlAddr is an interface address, as sent and received in RTM_NEWADDR
+// messages. Zero fields are not sent to the kernel.
+type RtnlAddr struct {
+	Info      IfAddrmsg
+	Address   []byte // IFA_ADDRESS, the peer address on point-to-point links
+	Local     []byte // IFA_LOCAL
+	Broadcast []byte // IFA_BROADCAST
+	Label     string // IFA_LABEL
+	Flags     uint32 // IFA_FLAGS, such as IFA_F_NODAD
+	Cacheinfo IfaCacheinfo
+}
+
+func (a *RtnlAddr) message(typ, flags uint16) NetlinkMessage {
+	info := a.Info
+	if info.Family == AF_UNSPEC {
+		info.Family = familyOf(a.Address)
+	}
+	var e NetlinkAttrEncoder
+	local := a.Local
+	if local == nil && info.Family == AF_INET {
+		// Like ip(8), use the address as local address on IPv4, where
+		// it is required.
+		local = a.Address
+	}
+	if local != nil {
+		e.Attr(IFA_LOCAL, local)
+	}
+	if a.Address != nil {
+		e.Attr(IFA_ADDRESS, a.Address)
+	}
+	if a.Broadcast != nil {
+		e.Attr(IFA_BROADCAST, a.Broadcast)
+	}
+	if a.Label != "" {
+		e.String(IFA_LABEL, a.Label)
+	}
+	if a.Flags != 0 {
+		e.Uint32(IFA_FLAGS, a.Flags)
+	}
+	if a.Cacheinfo != (IfaCacheinfo{}) {
+		e.Attr(IFA_CACHEINFO, structBytes(unsafe.Pointer(&a.Cacheinfo), SizeofIfaCacheinfo))
+	}
+	return rtnlMessage(typ, flags, structBytes(unsafe.Pointer(&info), SizeofIfAddrmsg), &e)
+}
+
+func parseRtnlAddr(m *NetlinkMessage) (*RtnlAddr, error) {
+	if len(m.Data) < SizeofIfAddrmsg {
+		return nil, EINVAL
+	}
+	a := &RtnlAddr{Info: *(*IfAddrmsg)(unsafe.Pointer(&m.Data[0]))}
+	a.Flags = uint32(a.Info.Flags)
+	d := NewNetlinkAttrDecoder(m.Data[SizeofIfAddrmsg:])
+	for d.Next() {
+		switch d.Type() {
+		case IFA_ADDRESS:
+			a.Address = d.Value()
+		case IFA_LOCAL:
+			a.Local = d.Value()
+		case IFA_BROADCAST:
+			a.Broadcast = d.Value()
+		case IFA_LABEL:
+			a.Label = d.String()
+		case IFA_FLAGS:
+			a.Flags = d.Uint32()
+		case IFA_CACHEINFO:
+			if len(d.Value()) == SizeofIfaCacheinfo {
+				a.Cacheinfo = *(*IfaCacheinfo)(unsafe.Pointer(&d.Value()[0]))
+			}
+		}
+	}
+	return a, d.Err()
+}
+
+// AddrAdd adds the address a to the link with index a.Info.Index. The
+// family is derived from the length of a.Address if a.Info.Family is 0.
+func (c *RtnlConn) AddrAdd(a *RtnlAddr) error {
+	_, err := c.Execute(a.message(RTM_NEWADDR, NLM_F_CREATE|NLM_F_EXCL))
+	return err
+}
+
+// AddrReplace adds the address a, or changes it if it exists.
+func (c *RtnlConn) AddrReplace(a *RtnlAddr) error {
+	_, err := c.Execute(a.message(RTM_NEWADDR, NLM_F_CREATE|NLM_F_REPLACE))
+	return err
+}
+
+// AddrDel deletes the address a.
+func (c *RtnlConn) AddrDel(a *RtnlAddr) error {
+	_, err := c.Execute(a.message(RTM_DELADDR, 0))
+	return err
+}
+
+// Addrs returns the addresses of the given family, or of all families if
+// family is AF_UNSPEC, on the link with the given index, or on all links if
+// index is 0.
+func (c *RtnlConn) Addrs(family, index int) ([]RtnlAddr, error) {
+	req := RtnlAddr{Info: IfAddrmsg{Family: uint8(family), Index: uint32(index)}}
+	msgs, err := c.dump(req.message(RTM_GETADDR, 0))
+	if err != nil {
+		return nil, err
+	}
+	var addrs []RtnlAddr
+	for i := range msgs {
+		a, err := parseRtnlAddr(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		// Without strict checking, the kernel ignores the index.
+		if index != 0 && a.Info.Index != uint32(index) {
+			continue
+		}
+		addrs = append(addrs, *a)
+	}
+	return addrs, nil
+}
+
+// A RtnlRouteNexthop is a nexthop of a multipath route, in an rtnexthop
+// structure of RTA_MULTIPATH.
+type RtnlRouteNexthop struct {
+	Index   int32  // the output link
+	Weight  uint8  // the weight of the nexthop; 0 means 1
+	Flags   uint8  // RTNH_F_* flags
+	Gateway []byte // RTA_GATEWAY
+}
+
+// A RtnlRoute is a route, as sent and received in RTM_NEWROUTE messages.
+// Zero fields are not sent to the kernel.
+type RtnlRoute struct {
+	Info      RtMsg
+	Dst       []byte // RTA_DST, whose prefix length is Info.Dst_len
+	Src       []byte // RTA_SRC, whose prefix length is Info.Src_len
+	Gateway   []byte // RTA_GATEWAY
+	PrefSrc   []byte // RTA_PREFSRC
+	OutIndex  int32  // RTA_OIF
+	Priority  uint32 // RTA_PRIORITY, the metric
+	Table     uint32 // RTA_TABLE, which takes precedence over Info.Table
+	NexthopID uint32 // RTA_NH_ID, a nexthop object
+
+	// Multipath holds the nexthops of a multipath route (RTA_MULTIPATH).
+	Multipath []RtnlRouteNexthop
+}
+
+func (r *RtnlRoute) message(typ, flags uint16) NetlinkMessage {
+	var e NetlinkAttrEncoder
+	if r.Dst != nil {
+		e.Attr(RTA_DST, r.Dst)
+	}
+	if r.Src != nil {
+		e.Attr(RTA_SRC, r.Src)
+	}
+	if r.Gateway != nil {
+		e.Attr(RTA_GATEWAY, r.Gateway)
+	}
+	if r.PrefSrc != nil {
+		e.Attr(RTA_PREFSRC, r.PrefSrc)
+	}
+	if r.OutIndex != 0 {
+		e.Uint32(RTA_OIF, uint32(r.OutIndex))
+	}
+	if r.Priority != 0 {
+		e.Uint32(RTA_PRIORITY, r.Priority)
+	}
+	if r.Table != 0 {
+		e.Uint32(RTA_TABLE, r.Table)
+	}
+	if r.NexthopID != 0 {
+		e.Uint32(RTA_NH_ID, r.NexthopID)
+	}
+	if len(r.Multipath) > 0 {
+		e.Attr(RTA_MULTIPATH, encodeRtnlMultipath(r.Multipath))
+	}
+	return rtnlMessage(typ, flags, structBytes(unsafe.Pointer(&r.Info), SizeofRtMsg), &e)
+}
+
+func encodeRtnlMultipath(nhs []RtnlRouteNexthop) []byte {
+	var b []byte
+	for _, nh := range nhs {
+		var attrs NetlinkAttrEncoder
+		if nh.Gateway != nil {
+			attrs.Attr(RTA_GATEWAY, nh.Gateway)
+		}
+		rtnh := RtNexthop{
+			Len:     uint16(SizeofRtNexthop + attrs.Len()),
+			Flags:   nh.Flags,
+			Ifindex: nh.Index,
+		}
+		if nh.Weight > 0 {
+			rtnh.Hops = nh.Weight - 1
+		}
+		b = append(b, structBytes(unsafe.Pointer(&rtnh), SizeofRtNexthop)...)
+		b = append(b, attrs.Bytes()...)
+	}
+	return b
+}
+
+func decodeRtnlMultipath(b []byte) ([]RtnlRouteNexthop, error) {
+	var nhs []RtnlRouteNexthop
+	for len(b) >= SizeofRtNexthop {
+		rtnh := *(*RtNexthop)(unsafe.Pointer(&b[0]))
+		if int(rtnh.Len) < SizeofRtNexthop || int(rtnh.Len) > len(b) {
+			return nil, EINVAL
+		}
+		nh := RtnlRouteNexthop{Index: rtnh.Ifindex, Weight: rtnh.Hops + 1, Flags: rtnh.Flags}
+		d := NewNetlinkAttrDecoder(b[SizeofRtNexthop:rtnh.Len])
+		for d.Next() {
+			if d.Type() == RTA_GATEWAY {
+				nh.Gateway = d.Value()
+			}
+		}
+		if err := d.Err(); err != nil {
+			return nil, err
+		}
+		nhs = append(nhs, nh)
+		n := nlaAlignOf(int(rtnh.Len))
+		if n > len(b) {
+			break
+		}
+		b = b[n:]
+	}
+	return nhs, nil
+}
+
+func parseRtnlRoute(m *NetlinkMessage) (*RtnlRoute, error) {
+	if len(m.Data) < SizeofRtMsg {
+		return nil, EINVAL
+	}
+	r := &RtnlRoute{Info: *(*RtMsg)(unsafe.Pointer(&m.Data[0]))}
+	d := NewNetlinkAttrDecoder(m.Data[SizeofRtMsg:])
+	for d.Next() {
+		switch d.Type() {
+		case RTA_DST:
+			r.Dst = d.Value()
+		case RTA_SRC:
+			r.Src = d.Value()
+		case RTA_GATEWAY:
+			r.Gateway = d.Value()
+		case RTA_PREFSRC:
+			r.PrefSrc = d.Value()
+		case RTA_OIF:
+			r.OutIndex = d.Int32()
+		case RTA_PRIORITY:
+			r.Priority = d.Uint32()
+		case RTA_TABLE:
+			r.Table = d.Uint32()
+		case RTA_NH_ID:
+			r.NexthopID = d.Uint32()
+		case RTA_MULTIPATH:
+			var err error
+			if r.Multipath, err = decodeRtnlMultipath(d.Value()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, d.Err()
+}
+
+// withDefaults returns a copy of r with the defaults of ip(8) filled in for
+// creating routes: the main table, the boot protocol, the unicast type, the
+// family of the destination or gateway, and link scope for unicast routes
+// without gateway.
+func (r *RtnlRoute) withDefaults() *RtnlRoute {
+	rr := *r
+	if rr.Info.Table == RT_TABLE_UNSPEC && rr.Table == 0 {
+		rr.Info.Table = RT_TABLE_MAIN
+	}
+	if rr.Info.Protocol == RTPROT_UNSPEC {
+		rr.Info.Protocol = RTPROT_BOOT
+	}
+	if rr.Info.Type == RTN_UNSPEC {
+		rr.Info.Type = RTN_UNICAST
+	}
+	if rr.Info.Family == AF_UNSPEC {
+		rr.Info.Family = familyOf(rr.Dst)
+		if rr.Info.Family == AF_UNSPEC {
+			rr.Info.Family = familyOf(rr.Gateway)
+		}
+		if rr.Info.Family == AF_UNSPEC && len(rr.Multipath) > 0 {
+			rr.Info.Family = familyOf(rr.Multipath[0].Gateway)
+		}
+	}
+	if rr.Info.Type == RTN_UNICAST && rr.Info.Scope == RT_SCOPE_UNIVERSE &&
+		rr.Gateway == nil && rr.Multipath == nil && rr.NexthopID == 0 {
+		rr.Info.Scope = RT_SCOPE_LINK
+	}
+	return &rr
+}
+
+// RouteAdd adds the route r. Unset fields of r.Info are filled in like
+// ip(8) does: see RtnlRoute for details.
+func (c *RtnlConn) RouteAdd(r *RtnlRoute) error {
+	_, err := c.Execute(r.withDefaults().message(RTM_NEWROUTE, NLM_F_CREATE|NLM_F_EXCL))
+	return err
+}
+
+// RouteReplace adds the route r, or replaces the route with the same
+// destination, table, type, priority and TOS.
+func (c *RtnlConn) RouteReplace(r *RtnlRoute) error {
+	_, err := c.Execute(r.withDefaults().message(RTM_NEWROUTE, NLM_F_CREATE|NLM_F_REPLACE))
+	return err
+}
+
+// RouteDel deletes the route r. The kernel deletes the first route
+// matching the nonzero fields of r.
+func (c *RtnlConn) RouteDel(r *RtnlRoute) error {
+	rr := *r
+	if rr.Info.Family == AF_UNSPEC {
+		rr.Info.Family = familyOf(rr.Dst)
+	}
+	if rr.Info.Scope == RT_SCOPE_UNIVERSE {
+		rr.Info.Scope = RT_SCOPE_NOWHERE
+	}
+	_, err := c.Execute(rr.message(RTM_DELROUTE, 0))
+	return err
+}
+
+// Routes returns the routes of the given family in all tables.
+func (c *RtnlConn) Routes(family int) ([]RtnlRoute, error) {
+	req := RtnlRoute{Info: RtMsg{Family: uint8(family)}}
+	msgs, err := c.dump(req.message(RTM_GETROUTE, 0))
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]RtnlRoute, 0, len(msgs))
+	for i := range msgs {
+		r, err := parseRtnlRoute(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, *r)
+	}
+	return routes, nil
+}
+
+// RouteGet returns the route the kernel selects for packets to dst.
+func (c *RtnlConn) RouteGet(dst []byte) (*RtnlRoute, error) {
+	req := RtnlRoute{Info: RtMsg{Family: familyOf(dst), Dst_len: uint8(8 * len(dst))}, Dst: dst}
+	msgs, err := c.Execute(req.message(RTM_GETROUTE, 0))
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) != 1 {
+		return nil, EINVAL
+	}
+	return parseRtnlRoute(&msgs[0])
+}
+
+// A RtnlNexthop is a nexthop object, as sent and received in
+// RTM_NEWNEXTHOP messages, which routes refer to by ID. It is either a
+// single nexthop, given by OutIndex and Gateway or Blackhole, or a group
+// of nexthop objects.
+type RtnlNexthop struct {
+	Info      Nhmsg
+	ID        uint32       // NHA_ID; 0 lets the kernel choose when adding
+	OutIndex  uint32       // NHA_OIF
+	Gateway   []byte       // NHA_GATEWAY
+	Blackhole bool         // NHA_BLACKHOLE
+	FDB       bool         // NHA_FDB
+	Group     []NexthopGrp // NHA_GROUP, whose weights are one less than the actual weight
+	GroupType uint16       // NHA_GROUP_TYPE, such as NEXTHOP_GRP_TYPE_MPATH
+}
+
+func (nh *RtnlNexthop) message(typ, flags uint16) NetlinkMessage {
+	info := nh.Info
+	if info.Family == AF_UNSPEC {
+		info.Family = familyOf(nh.Gateway)
+	}
+	if info.Protocol == RTPROT_UNSPEC && typ == RTM_NEWNEXTHOP {
+		info.Protocol = RTPROT_BOOT
+	}
+	var e NetlinkAttrEncoder
+	if nh.ID != 0 {
+		e.Uint32(NHA_ID, nh.ID)
+	}
+	if nh.OutIndex != 0 {
+		e.Uint32(NHA_OIF, nh.OutIndex)
+	}
+	if nh.Gateway != nil {
+		e.Attr(NHA_GATEWAY, nh.Gateway)
+	}
+	if nh.Blackhole {
+		e.Flag(NHA_BLACKHOLE)
+	}
+	if nh.FDB {
+		e.Flag(NHA_FDB)
+	}
+	if len(nh.Group) > 0 {
+		e.Attr(NHA_GROUP, structBytes(unsafe.Pointer(&nh.Group[0]), len(nh.Group)*SizeofNexthopGrp))
+		e.Uint16(NHA_GROUP_TYPE, nh.GroupType)
+	}
+	return rtnlMessage(typ, flags, structBytes(unsafe.Pointer(&info), SizeofNhmsg), &e)
+}
+
+func parseRtnlNexthop(m *NetlinkMessage) (*RtnlNexthop, error) {
+	if len(m.Data) < SizeofNhmsg {
+		return nil, EINVAL
+	}
+	nh := &RtnlNexthop{Info: *(*Nhmsg)(unsafe.Pointer(&m.Data[0]))}
+	d := NewNetlinkAttrDecoder(m.Data[SizeofNhmsg:])
+	for d.Next() {
+		switch d.Type() {
+		case NHA_ID:
+			nh.ID = d.Uint32()
+		case NHA_OIF:
+			nh.OutIndex = d.Uint32()
+		case NHA_GATEWAY:
+			nh.Gateway = d.Value()
+		case NHA_BLACKHOLE:
+			nh.Blackhole = true
+		case NHA_FDB:
+			nh.FDB = true
+		case NHA_GROUP:
+			v := d.Value()
+			if len(v) < SizeofNexthopGrp {
+				break
+			}
+			nh.Group = make([]NexthopGrp, len(v)/SizeofNexthopGrp)
+			copy(structBytes(unsafe.Pointer(&nh.Group[0]), len(nh.Group)*SizeofNexthopGrp), v)
+		case NHA_GROUP_TYPE:
+			nh.GroupType = d.Uint16()
+		}
+	}
+	return nh, d.Err()
+}
+
+// NexthopAdd adds the nexthop object nh.
+func (c *RtnlConn) NexthopAdd(nh *RtnlNexthop) error {
+	_, err := c.Execute(nh.message(RTM_NEWNEXTHOP, NLM_F_CREATE|NLM_F_EXCL))
+	return err
+}
+
+// NexthopReplace adds the nexthop object nh, or replaces the one with the
+// same ID. Routes using it are updated.
+func (c *RtnlConn) NexthopReplace(nh *RtnlNexthop) error {
+	_, err := c.Execute(nh.message(RTM_NEWNEXTHOP, NLM_F_CREATE|NLM_F_REPLACE))
+	return err
+}
+
+// NexthopDel deletes the nexthop object with the given ID, and the routes
+// using it.
+func (c *RtnlConn) NexthopDel(id uint32) error {
+	nh := RtnlNexthop{ID: id}
+	_, err := c.Execute(nh.message(RTM_DELNEXTHOP, 0))
+	return err
+}
+
+// Nexthops returns all nexthop objects.
+func (c *RtnlConn) Nexthops() ([]RtnlNexthop, error) {
+	var req RtnlNexthop
+	msgs, err := c.dump(req.message(RTM_GETNEXTHOP, 0))
+	if err != nil {
+		return nil, err
+	}
+	nhs := make([]RtnlNexthop, 0, len(msgs))
+	for i := range msgs {
+		nh, err := parseRtnlNexthop(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		nhs = append(nhs, *nh)
+	}
+	return nhs, nil
+}
+
+// A RtnlNeigh is a neighbor table entry, as sent and received in
+// RTM_NEWNEIGH messages, such as an ARP entry or a bridge forwarding
+// database entry. Zero fields are not sent to the kernel.
+type RtnlNeigh struct {
+	Info     NdMsg
+	Dst      []byte // NDA_DST, the protocol address
+	LinkAddr []byte // NDA_LLADDR, the link-layer address
+	Vlan     uint16 // NDA_VLAN
+	Master   uint32 // NDA_MASTER
+}
+
+func (n *RtnlNeigh) message(typ, flags uint16) NetlinkMessage {
+	info := n.Info
+	if info.Family == AF_UNSPEC {
+		info.Family = familyOf(n.Dst)
+	}
+	var e NetlinkAttrEncoder
+	if n.Dst != nil {
+		e.Attr(NDA_DST, n.Dst)
+	}
+	if n.LinkAddr != nil {
+		e.Attr(NDA_LLADDR, n.LinkAddr)
+	}
+	if n.Vlan != 0 {
+		e.Uint16(NDA_VLAN, n.Vlan)
+	}
+	if n.Master != 0 {
+		e.Uint32(NDA_MASTER, n.Master)
+	}
+	return rtnlMessage(typ, flags, structBytes(unsafe.Pointer(&info), SizeofNdMsg), &e)
+}
+
+func parseRtnlNeigh(m *NetlinkMessage) (*RtnlNeigh, error) {
+	if len(m.Data) < SizeofNdMsg {
+		return nil, EINVAL
+	}
+	n := &RtnlNeigh{Info: *(*NdMsg)(unsafe.Pointer(&m.Data[0]))}
+	d := NewNetlinkAttrDecoder(m.Data[SizeofNdMsg:])
+	for d.Next() {
+		switch d.Type() {
+		case NDA_DST:
+			n.Dst = d.Value()
+		case NDA_LLADDR:
+			n.LinkAddr = d.Value()
+		case NDA_VLAN:
+			n.Vlan = d.Uint16()
+		case NDA_MASTER:
+			n.Master = d.Uint32()
+		}
+	}
+	return n, d.Err()
+}
+
+// NeighAdd adds the neighbor entry n on the link with index
+// n.Info.Ifindex. n.Info.State is usually NUD_PERMANENT.
+func (c *RtnlConn) NeighAdd(n *RtnlNeigh) error {
+	_, err := c.Execute(n.message(RTM_NEWNEIGH, NLM_F_CREATE|NLM_F_EXCL))
+	return err
+}
+
+// NeighSet adds the neighbor entry n, or replaces the existing one.
+func (c *RtnlConn) NeighSet(n *RtnlNeigh) error {
+	_, err := c.Execute(n.message(RTM_NEWNEIGH, NLM_F_CREATE|NLM_F_REPLACE))
+	return err
+}
+
+// NeighDel deletes the neighbor entry n.
+func (c *RtnlConn) NeighDel(n *RtnlNeigh) error {
+	_, err := c.Execute(n.message(RTM_DELNEIGH, 0))
+	return err
+}
+
+// Neighs returns the neighbor entries of the given family, or of all
+// families if family is AF_UNSPEC, on the link with the given index, or on
+// all links if index is 0.
+func (c *RtnlConn) Neighs(family, index int) ([]RtnlNeigh, error) {
+	// Dumps are filtered by NDA_IFINDEX rather than by the header, which
+	// must otherwise be zero with strict checking.
+	info := NdMsg{Family: uint8(family)}
+	var e NetlinkAttrEncoder
+	if index != 0 {
+		e.Uint32(NDA_IFINDEX, uint32(index))
+	}
+	msgs, err := c.dump(rtnlMessage(RTM_GETNEIGH, 0, structBytes(unsafe.Pointer(&info), SizeofNdMsg), &e))
+	if err != nil {
+		return nil, err
+	}
+	var neighs []RtnlNeigh
+	for i := range msgs {
+		n, err := parseRtnlNeigh(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		if index != 0 && n.Info.Ifindex != int32(index) {
+			continue
+		}
+		neighs = append(neighs, *n)
+	}
+	return neighs, nil
+}
+
+// A RtnlEvent is a notification of a change received from an RTNLGRP_*
+// multicast group. Exactly one of its pointer fields is set, according to
+// Type.
+type RtnlEvent struct {
+	Type    uint16 // such as RTM_NEWLINK or RTM_DELROUTE
+	Link    *RtnlLink
+	Addr    *RtnlAddr
+	Route   *RtnlRoute
+	Neigh   *RtnlNeigh
+	Nexthop *RtnlNexthop
+}
+
+// Subscribe joins the multicast groups, such as RTNLGRP_LINK and
+// RTNLGRP_IPV4_ROUTE, whose notifications are returned by ReceiveEvents.
+func (c *RtnlConn) Subscribe(groups ...int) error {
+	for _, g := range groups {
+		if err := c.JoinGroup(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReceiveEvents waits for notifications and returns the ones received in a
+// single datagram. Notifications of other types than links, addresses,
+// routes, neighbors and nexthop objects are skipped. If the socket receive
+// buffer overflowed, ReceiveEvents returns ENOBUFS and notifications have
+// been lost, so the state should be dumped again.
+func (c *RtnlConn) ReceiveEvents() ([]RtnlEvent, error) {
+	msgs, err := c.Receive()
+	if err != nil {
+		return nil, err
+	}
+	var events []RtnlEvent
+	for i := range msgs {
+		m := &msgs[i]
+		ev := RtnlEvent{Type: m.Header.Type}
+		var err error
+		switch m.Header.Type {
+		case RTM_NEWLINK, RTM_DELLINK:
+			ev.Link, err = parseRtnlLink(m)
+		case RTM_NEWADDR, RTM_DELADDR:
+			ev.Addr, err = parseRtnlAddr(m)
+		case RTM_NEWROUTE, RTM_DELROUTE:
+			ev.Route, err = parseRtnlRoute(m)
+		case RTM_NEWNEIGH, RTM_DELNEIGH:
+			ev.Neigh, err = parseRtnlNeigh(m)
+		case RTM_NEWNEXTHOP, RTM_DELNEXTHOP:
+			ev.Nexthop, err = parseRtnlNexthop(m)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
@@ -0,0 +1,327 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// inNewNetNs runs f on a thread in a new network namespace. The thread is
+// discarded afterwards.
+func inNewNetNs(t *testing.T, f func()) {
+	errc := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// The thread is not unlocked, so it exits with the goroutine.
+		runtime.LockOSThread()
+		err := unix.Unshare(unix.CLONE_NEWNET)
+		errc <- err
+		if err == nil {
+			f()
+		}
+	}()
+	if err := <-errc; err != nil {
+		t.Skipf("Unshare(CLONE_NEWNET): %v", err)
+	}
+	<-done
+}
+
+func TestRtnetlink(t *testing.T) {
+	inNewNetNs(t, func() {
+		c, err := unix.NewRtnlConn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		ev, err := unix.NewRtnlConn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ev.Close()
+		if err := ev.Subscribe(unix.RTNLGRP_LINK); err != nil {
+			t.Fatal(err)
+		}
+		// Fail instead of hanging if the notification does not arrive.
+		tv := unix.NsecToTimeval(int64(5 * time.Second))
+		if err := unix.SetsockoptTimeval(ev.Fd(), unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+			t.Fatal(err)
+		}
+
+		lo, err := c.LinkGetByName("lo")
+		if err != nil {
+			t.Fatalf("LinkGetByName(lo): %v", err)
+		}
+		if err := c.LinkSetUp(int(lo.Info.Index)); err != nil {
+			t.Fatalf("LinkSetUp(lo): %v", err)
+		}
+
+		// Links.
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "veth0", MTU: 1400, Data: &unix.RtnlVeth{PeerName: "veth1"}}); err != nil {
+			t.Fatalf("LinkAdd(veth): %v", err)
+		}
+		veth, err := c.LinkGetByName("veth0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if veth.Kind != "veth" || veth.MTU != 1400 {
+			t.Errorf("veth0: got kind %q, MTU %d", veth.Kind, veth.MTU)
+		}
+		vethIndex := int(veth.Info.Index)
+		peer, err := c.LinkGet(int(veth.ParentIndex))
+		if err != nil || peer.Name != "veth1" {
+			t.Fatalf("veth peer: got %+v, %v", peer, err)
+		}
+		for _, index := range []int{vethIndex, int(peer.Info.Index)} {
+			if err := c.LinkSetUp(index); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if l, err := c.LinkGet(vethIndex); err != nil || l.Info.Flags&unix.IFF_UP == 0 {
+			t.Errorf("LinkGet(veth0) after LinkSetUp: %+v, %v", l, err)
+		}
+
+		var sawVeth bool
+		for !sawVeth {
+			events, err := ev.ReceiveEvents()
+			if err != nil {
+				t.Fatalf("ReceiveEvents: %v", err)
+			}
+			for _, e := range events {
+				if e.Type == unix.RTM_NEWLINK && e.Link.Name == "veth0" {
+					sawVeth = true
+				}
+			}
+		}
+
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "br0", Data: &unix.RtnlBridge{STP: true, AgeingTime: 1000}}); err != nil {
+			t.Fatalf("LinkAdd(bridge): %v", err)
+		}
+		br, err := c.LinkGetByName("br0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, ok := br.Data.(*unix.RtnlBridge); !ok || !b.STP || b.AgeingTime != 1000 {
+			t.Errorf("br0: got data %+v", br.Data)
+		}
+		if err := c.LinkSetMaster(int(peer.Info.Index), int(br.Info.Index)); err != nil {
+			t.Fatalf("LinkSetMaster: %v", err)
+		}
+		if l, err := c.LinkGet(int(peer.Info.Index)); err != nil || l.Master != br.Info.Index {
+			t.Errorf("LinkGet(veth1) after LinkSetMaster: %+v, %v", l, err)
+		}
+
+		// The other kinds may not be built into the kernel.
+		unsupported := func(kind string, err error) bool {
+			if errors.Is(err, unix.EOPNOTSUPP) {
+				t.Logf("%s links not supported: %v", kind, err)
+				return true
+			}
+			return false
+		}
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "dummy0", Data: &unix.RtnlDummy{}}); unsupported("dummy", err) {
+		} else if err != nil {
+			t.Errorf("LinkAdd(dummy): %v", err)
+		} else if l, err := c.LinkGetByName("dummy0"); err != nil || l.Kind != "dummy" {
+			t.Errorf("dummy0: got %+v, %v", l, err)
+		}
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "veth0.10", ParentIndex: int32(vethIndex), Data: &unix.RtnlVlan{ID: 10}}); unsupported("vlan", err) {
+		} else if err != nil {
+			t.Errorf("LinkAdd(vlan): %v", err)
+		} else if l, err := c.LinkGetByName("veth0.10"); err != nil {
+			t.Error(err)
+		} else if v, ok := l.Data.(*unix.RtnlVlan); !ok || v.ID != 10 || v.Protocol != unix.ETH_P_8021Q {
+			t.Errorf("veth0.10: got data %+v", l.Data)
+		}
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "mv0", ParentIndex: int32(vethIndex), Data: &unix.RtnlMacvlan{Mode: unix.MACVLAN_MODE_BRIDGE}}); unsupported("macvlan", err) {
+		} else if err != nil {
+			t.Errorf("LinkAdd(macvlan): %v", err)
+		} else if l, err := c.LinkGetByName("mv0"); err != nil {
+			t.Error(err)
+		} else if m, ok := l.Data.(*unix.RtnlMacvlan); !ok || m.Mode != unix.MACVLAN_MODE_BRIDGE {
+			t.Errorf("mv0: got data %+v", l.Data)
+		}
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "vx0", Data: &unix.RtnlVxlan{VNI: 42, Port: 4789, DevIndex: uint32(vethIndex)}}); unsupported("vxlan", err) {
+		} else if err != nil {
+			t.Errorf("LinkAdd(vxlan): %v", err)
+		} else if l, err := c.LinkGetByName("vx0"); err != nil {
+			t.Error(err)
+		} else if v, ok := l.Data.(*unix.RtnlVxlan); !ok || v.VNI != 42 || v.Port != 4789 || v.NoLearning {
+			t.Errorf("vx0: got data %+v", l.Data)
+		}
+		if err := c.LinkAdd(&unix.RtnlLink{Name: "vx1", Data: &unix.RtnlVxlan{VNI: 43, DevIndex: uint32(vethIndex), NoLearning: true}}); unsupported("vxlan", err) {
+		} else if err != nil {
+			t.Errorf("LinkAdd(vxlan): %v", err)
+		} else if l, err := c.LinkGetByName("vx1"); err != nil {
+			t.Error(err)
+		} else if v, ok := l.Data.(*unix.RtnlVxlan); !ok || v.VNI != 43 || !v.NoLearning {
+			t.Errorf("vx1: got data %+v", l.Data)
+		}
+
+		links, err := c.Links()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := map[string]bool{}
+		for _, l := range links {
+			names[l.Name] = true
+		}
+		for _, name := range []string{"lo", "veth0", "veth1", "br0"} {
+			if !names[name] {
+				t.Errorf("link %s missing from Links", name)
+			}
+		}
+
+		// Addresses.
+		addr := &unix.RtnlAddr{
+			Info:    unix.IfAddrmsg{Prefixlen: 24, Index: uint32(vethIndex)},
+			Address: []byte{10, 1, 2, 3},
+			Flags:   unix.IFA_F_NOPREFIXROUTE,
+		}
+		if err := c.AddrAdd(addr); err != nil {
+			t.Fatalf("AddrAdd: %v", err)
+		}
+		if err := c.AddrAdd(addr); !errors.Is(err, unix.EEXIST) {
+			t.Errorf("AddrAdd of existing address: got %v, want EEXIST", err)
+		}
+		addrs, err := c.Addrs(unix.AF_INET, vethIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(addrs) != 1 || !bytes.Equal(addrs[0].Local, addr.Address) || addrs[0].Info.Prefixlen != 24 || addrs[0].Flags&unix.IFA_F_NOPREFIXROUTE == 0 {
+			t.Errorf("Addrs: got %+v", addrs)
+		}
+
+		// Routes.
+		if err := c.RouteAdd(&unix.RtnlRoute{
+			Info:     unix.RtMsg{Dst_len: 24},
+			Dst:      []byte{10, 1, 2, 0},
+			OutIndex: int32(vethIndex),
+		}); err != nil {
+			t.Fatalf("RouteAdd(link route): %v", err)
+		}
+		if err := c.RouteAdd(&unix.RtnlRoute{
+			Info:     unix.RtMsg{Dst_len: 16},
+			Dst:      []byte{10, 9, 0, 0},
+			Gateway:  []byte{10, 1, 2, 1},
+			Priority: 50,
+		}); err != nil {
+			t.Fatalf("RouteAdd(gateway route): %v", err)
+		}
+		if err := c.RouteAdd(&unix.RtnlRoute{
+			Info: unix.RtMsg{Dst_len: 16},
+			Dst:  []byte{10, 8, 0, 0},
+			Multipath: []unix.RtnlRouteNexthop{
+				{Index: int32(vethIndex), Gateway: []byte{10, 1, 2, 1}},
+				{Index: int32(vethIndex), Gateway: []byte{10, 1, 2, 2}, Weight: 3},
+			},
+		}); err != nil {
+			t.Fatalf("RouteAdd(multipath route): %v", err)
+		}
+		r, err := c.RouteGet([]byte{10, 9, 1, 1})
+		if err != nil {
+			t.Fatalf("RouteGet: %v", err)
+		}
+		if !bytes.Equal(r.Gateway, []byte{10, 1, 2, 1}) || r.OutIndex != int32(vethIndex) {
+			t.Errorf("RouteGet: got %+v", r)
+		}
+		routes, err := c.Routes(unix.AF_INET)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sawMultipath bool
+		for _, r := range routes {
+			if bytes.Equal(r.Dst, []byte{10, 8, 0, 0}) {
+				sawMultipath = true
+				if len(r.Multipath) != 2 || r.Multipath[1].Weight != 3 || !bytes.Equal(r.Multipath[1].Gateway, []byte{10, 1, 2, 2}) {
+					t.Errorf("multipath route: got nexthops %+v", r.Multipath)
+				}
+			}
+		}
+		if !sawMultipath {
+			t.Error("multipath route missing from Routes")
+		}
+		if err := c.RouteDel(&unix.RtnlRoute{Info: unix.RtMsg{Dst_len: 16, Table: unix.RT_TABLE_MAIN}, Dst: []byte{10, 9, 0, 0}}); err != nil {
+			t.Errorf("RouteDel: %v", err)
+		}
+
+		// Nexthop objects.
+		err = c.NexthopAdd(&unix.RtnlNexthop{ID: 1, OutIndex: uint32(vethIndex), Gateway: []byte{10, 1, 2, 1}})
+		if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EINVAL) {
+			t.Logf("nexthop objects not supported: %v", err)
+		} else if err != nil {
+			t.Errorf("NexthopAdd: %v", err)
+		} else {
+			if err := c.NexthopAdd(&unix.RtnlNexthop{ID: 2, OutIndex: uint32(vethIndex), Gateway: []byte{10, 1, 2, 2}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.NexthopAdd(&unix.RtnlNexthop{ID: 10, Group: []unix.NexthopGrp{{Id: 1}, {Id: 2, Weight: 1}}}); err != nil {
+				t.Fatalf("NexthopAdd(group): %v", err)
+			}
+			if err := c.RouteAdd(&unix.RtnlRoute{Info: unix.RtMsg{Family: unix.AF_INET, Dst_len: 16}, Dst: []byte{10, 7, 0, 0}, NexthopID: 10}); err != nil {
+				t.Errorf("RouteAdd(nexthop route): %v", err)
+			}
+			nhs, err := c.Nexthops()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var sawGroup bool
+			for _, nh := range nhs {
+				if nh.ID == 10 {
+					sawGroup = true
+					if len(nh.Group) != 2 || nh.Group[1].Id != 2 || nh.Group[1].Weight != 1 {
+						t.Errorf("nexthop group: got %+v", nh.Group)
+					}
+				}
+			}
+			if !sawGroup {
+				t.Error("nexthop group missing from Nexthops")
+			}
+			if err := c.NexthopDel(10); err != nil {
+				t.Errorf("NexthopDel: %v", err)
+			}
+		}
+
+		// Neighbors.
+		neigh := &unix.RtnlNeigh{
+			Info:     unix.NdMsg{Ifindex: int32(vethIndex), State: unix.NUD_PERMANENT},
+			Dst:      []byte{10, 1, 2, 1},
+			LinkAddr: []byte{0x02, 0, 0, 0, 0, 1},
+		}
+		if err := c.NeighAdd(neigh); err != nil {
+			t.Fatalf("NeighAdd: %v", err)
+		}
+		neighs, err := c.Neighs(unix.AF_INET, vethIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(neighs) != 1 || !bytes.Equal(neighs[0].LinkAddr, neigh.LinkAddr) || neighs[0].Info.State != unix.NUD_PERMANENT {
+			t.Errorf("Neighs: got %+v", neighs)
+		}
+		if err := c.NeighDel(neigh); err != nil {
+			t.Errorf("NeighDel: %v", err)
+		}
+
+		if err := c.AddrDel(addr); err != nil {
+			t.Errorf("AddrDel: %v", err)
+		}
+		if err := c.LinkDel(vethIndex); err != nil {
+			t.Errorf("LinkDel: %v", err)
+		}
+		if _, err := c.LinkGet(vethIndex); !errors.Is(err, unix.ENODEV) {
+			t.Errorf("LinkGet of deleted link: got %v, want ENODEV", err)
+		}
+	})
+}
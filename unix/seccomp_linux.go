@@ -0,0 +1,505 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// Offsets of the fields of SeccompData, as loaded by a seccomp-BPF program.
+const (
+	seccompDataNr   = uint32(unsafe.Offsetof(SeccompData{}.Nr))
+	seccompDataArch = uint32(unsafe.Offsetof(SeccompData{}.Arch))
+	seccompDataArgs = uint32(unsafe.Offsetof(SeccompData{}.Args))
+)
+
+// __X32_SYSCALL_BIT from <asm/unistd.h>; x32 system calls share
+// AUDIT_ARCH_X86_64 with amd64 and are told apart by this bit.
+const x32SyscallBit = 0x40000000
+
+// SeccompNativeArch returns the AUDIT_ARCH_* value which the kernel reports
+// in SeccompData.Arch for system calls made by the running program.
+func SeccompNativeArch() uint32 {
+	switch runtime.GOARCH {
+	case "386":
+		return AUDIT_ARCH_I386
+	case "amd64":
+		return AUDIT_ARCH_X86_64
+	case "arm":
+		return AUDIT_ARCH_ARM
+	case "arm64":
+		return AUDIT_ARCH_AARCH64
+	case "loong64":
+		return AUDIT_ARCH_LOONGARCH64
+	case "mips":
+		return AUDIT_ARCH_MIPS
+	case "mipsle":
+		return AUDIT_ARCH_MIPSEL
+	case "mips64":
+		return AUDIT_ARCH_MIPS64
+	case "mips64le":
+		return AUDIT_ARCH_MIPSEL64
+	case "ppc":
+		return AUDIT_ARCH_PPC
+	case "ppc64":
+		return AUDIT_ARCH_PPC64
+	case "ppc64le":
+		return AUDIT_ARCH_PPC64LE
+	case "riscv64":
+		return AUDIT_ARCH_RISCV64
+	case "s390x":
+		return AUDIT_ARCH_S390X
+	case "sparc64":
+		return AUDIT_ARCH_SPARC64
+	}
+	return 0
+}
+
+// SeccompRetErrno returns the SECCOMP_RET_ERRNO action which fails the
+// system call with errno.
+func SeccompRetErrno(errno Errno) uint32 {
+	return SECCOMP_RET_ERRNO | uint32(errno)&SECCOMP_RET_DATA
+}
+
+// SeccompRetTrace returns the SECCOMP_RET_TRACE action which notifies a
+// ptrace tracer, passing it data as the event message.
+func SeccompRetTrace(data uint16) uint32 {
+	return SECCOMP_RET_TRACE | uint32(data)
+}
+
+// A SeccompCmpOp is a comparison applied to a system call argument by a
+// SeccompArg. All comparisons are unsigned and operate on the full 64-bit
+// argument value.
+type SeccompCmpOp int
+
+const (
+	SeccompCmpEQ       SeccompCmpOp = iota // argument == Value
+	SeccompCmpNE                           // argument != Value
+	SeccompCmpLT                           // argument < Value
+	SeccompCmpLE                           // argument <= Value
+	SeccompCmpGT                           // argument > Value
+	SeccompCmpGE                           // argument >= Value
+	SeccompCmpMaskedEQ                     // argument & Mask == Value
+)
+
+// A SeccompArg is a condition on the system call argument with the given
+// index, which must be in the range [0, 5].
+type SeccompArg struct {
+	Index int
+	Op    SeccompCmpOp
+	Value uint64
+	Mask  uint64 // only used by SeccompCmpMaskedEQ
+}
+
+// A SeccompRule applies Action to invocations of the system call Syscall,
+// one of the SYS_* constants or a number returned by SeccompSyscallNumber,
+// for which all conditions in Args hold.
+type SeccompRule struct {
+	Syscall int
+	Args    []SeccompArg
+	Action  uint32
+}
+
+// SeccompSyscallNumber returns the number of the system call with the given
+// name in the kernel, such as "openat", on the architecture of the running
+// program. It returns ENOSYS if the architecture has no such system call,
+// so that a policy listing system calls by name can skip those missing on
+// some architectures.
+func SeccompSyscallNumber(name string) (int, error) {
+	for _, s := range sysnames {
+		if s.name == name {
+			return s.num, nil
+		}
+	}
+	return -1, ENOSYS
+}
+
+// A SeccompFilter is a seccomp-BPF policy. Rules are evaluated in order and
+// the action of the first matching rule is taken; DefaultAction is taken if
+// no rule matches. Actions are SECCOMP_RET_* values, optionally combined
+// with data as done by SeccompRetErrno and SeccompRetTrace.
+//
+// System calls made with an architecture other than Arch, or with
+// SeccompNativeArch if Arch is 0, kill the process, as do x32 system calls
+// made on amd64.
+type SeccompFilter struct {
+	Arch          uint32
+	DefaultAction uint32
+	Rules         []SeccompRule
+}
+
+// A seccompInsn is an instruction of a SeccompRule block whose true or false
+// branch may jump to the end of the block, which is only known once the
+// whole block has been built.
+type seccompInsn struct {
+	SockFilter
+	jtFail, jfFail bool
+}
+
+func bpfStmt(code uint16, k uint32) SockFilter {
+	return SockFilter{Code: code, K: k}
+}
+
+func bpfJump(code uint16, k uint32, jt, jf uint8) SockFilter {
+	return SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
+
+// Compile compiles the filter to a seccomp-BPF program.
+func (f *SeccompFilter) Compile() ([]SockFilter, error) {
+	arch := f.Arch
+	if arch == 0 {
+		arch = SeccompNativeArch()
+	}
+
+	prog := []SockFilter{
+		bpfStmt(BPF_LD|BPF_W|BPF_ABS, seccompDataArch),
+		bpfJump(BPF_JMP|BPF_JEQ|BPF_K, arch, 1, 0),
+		bpfStmt(BPF_RET|BPF_K, SECCOMP_RET_KILL_PROCESS),
+	}
+	if arch == AUDIT_ARCH_X86_64 {
+		prog = append(prog,
+			bpfStmt(BPF_LD|BPF_W|BPF_ABS, seccompDataNr),
+			bpfJump(BPF_JMP|BPF_JGE|BPF_K, x32SyscallBit, 0, 1),
+			bpfStmt(BPF_RET|BPF_K, SECCOMP_RET_KILL_PROCESS),
+		)
+	}
+
+	for _, r := range f.Rules {
+		block, err := r.compile()
+		if err != nil {
+			return nil, err
+		}
+		prog = append(prog, block...)
+	}
+	prog = append(prog, bpfStmt(BPF_RET|BPF_K, f.DefaultAction))
+
+	if len(prog) > BPF_MAXINSNS {
+		return nil, EINVAL
+	}
+	return prog, nil
+}
+
+// compile compiles r to a self-contained block of instructions which either
+// returns r.Action or falls through to the instruction following it.
+func (r *SeccompRule) compile() ([]SockFilter, error) {
+	insns := []seccompInsn{
+		{SockFilter: bpfStmt(BPF_LD|BPF_W|BPF_ABS, seccompDataNr)},
+		{SockFilter: bpfStmt(BPF_JMP|BPF_JEQ|BPF_K, uint32(r.Syscall)), jfFail: true},
+	}
+	for _, a := range r.Args {
+		check, err := a.compile()
+		if err != nil {
+			return nil, err
+		}
+		insns = append(insns, check...)
+	}
+	insns = append(insns, seccompInsn{SockFilter: bpfStmt(BPF_RET|BPF_K, r.Action)})
+
+	block := make([]SockFilter, len(insns))
+	for i, in := range insns {
+		fail := len(insns) - i - 1
+		if (in.jtFail || in.jfFail) && fail > 0xff {
+			return nil, EINVAL
+		}
+		if in.jtFail {
+			in.Jt = uint8(fail)
+		}
+		if in.jfFail {
+			in.Jf = uint8(fail)
+		}
+		block[i] = in.SockFilter
+	}
+	return block, nil
+}
+
+// compile compiles a to instructions which fall through if the condition
+// holds and jump to the end of the enclosing rule otherwise.
+func (a *SeccompArg) compile() ([]seccompInsn, error) {
+	if a.Index < 0 || a.Index > 5 {
+		return nil, EINVAL
+	}
+	lo := seccompDataArgs + 8*uint32(a.Index)
+	hi := lo + 4
+	if isBigEndian {
+		lo, hi = hi, lo
+	}
+	vlo, vhi := uint32(a.Value), uint32(a.Value>>32)
+
+	load := func(off uint32) seccompInsn {
+		return seccompInsn{SockFilter: bpfStmt(BPF_LD|BPF_W|BPF_ABS, off)}
+	}
+	jump := func(op uint16, k uint32, jt, jf uint8) seccompInsn {
+		return seccompInsn{SockFilter: bpfJump(BPF_JMP|op|BPF_K, k, jt, jf)}
+	}
+	jumpFail := func(op uint16, k uint32, onTrue bool) seccompInsn {
+		return seccompInsn{SockFilter: bpfStmt(BPF_JMP|op|BPF_K, k), jtFail: onTrue, jfFail: !onTrue}
+	}
+
+	// Each 64-bit comparison is split into a comparison of the upper half
+	// and, if that does not decide it, one of the lower half. Jumps with a
+	// fixed offset skip the remaining instructions of the check.
+	switch a.Op {
+	case SeccompCmpEQ:
+		return []seccompInsn{
+			load(hi),
+			jumpFail(BPF_JEQ, vhi, false),
+			load(lo),
+			jumpFail(BPF_JEQ, vlo, false),
+		}, nil
+	case SeccompCmpNE:
+		return []seccompInsn{
+			load(hi),
+			jump(BPF_JEQ, vhi, 0, 2),
+			load(lo),
+			jumpFail(BPF_JEQ, vlo, true),
+		}, nil
+	case SeccompCmpGT, SeccompCmpGE:
+		op := uint16(BPF_JGT)
+		if a.Op == SeccompCmpGE {
+			op = BPF_JGE
+		}
+		return []seccompInsn{
+			load(hi),
+			jump(BPF_JGT, vhi, 3, 0),
+			jumpFail(BPF_JEQ, vhi, false),
+			load(lo),
+			jumpFail(op, vlo, false),
+		}, nil
+	case SeccompCmpLT, SeccompCmpLE:
+		// a < v is !(a >= v) and a <= v is !(a > v).
+		op := uint16(BPF_JGE)
+		if a.Op == SeccompCmpLE {
+			op = BPF_JGT
+		}
+		return []seccompInsn{
+			load(hi),
+			jumpFail(BPF_JGT, vhi, true),
+			jump(BPF_JEQ, vhi, 0, 2),
+			load(lo),
+			jumpFail(op, vlo, true),
+		}, nil
+	case SeccompCmpMaskedEQ:
+		mlo, mhi := uint32(a.Mask), uint32(a.Mask>>32)
+		return []seccompInsn{
+			load(hi),
+			{SockFilter: bpfStmt(BPF_ALU|BPF_AND|BPF_K, mhi)},
+			jumpFail(BPF_JEQ, vhi&mhi, false),
+			load(lo),
+			{SockFilter: bpfStmt(BPF_ALU|BPF_AND|BPF_K, mlo)},
+			jumpFail(BPF_JEQ, vlo&mlo, false),
+		}, nil
+	}
+	return nil, EINVAL
+}
+
+// Install compiles the filter and installs it for the calling thread with
+// SeccompSetModeFilter, after setting PR_SET_NO_NEW_PRIVS. flags is a
+// combination of SECCOMP_FILTER_FLAG_* values; SECCOMP_FILTER_FLAG_TSYNC
+// installs the filter for all threads of the process. The returned fd is
+// only valid if flags contains SECCOMP_FILTER_FLAG_NEW_LISTENER.
+func (f *SeccompFilter) Install(flags int) (fd int, err error) {
+	prog, err := f.Compile()
+	if err != nil {
+		return -1, err
+	}
+	if err := Prctl(PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return -1, err
+	}
+	fd, err = SeccompSetModeFilter(&SockFprog{
+		Len:    uint16(len(prog)),
+		Filter: &prog[0],
+	}, flags)
+	runtime.KeepAlive(prog)
+	return fd, err
+}
+
+// A SeccompListener receives the seccomp user notifications generated by
+// filters returning SECCOMP_RET_USER_NOTIF, which were installed with
+// SECCOMP_FILTER_FLAG_NEW_LISTENER. While a notification is pending, the
+// target is blocked in the system call until the supervisor responds.
+type SeccompListener struct {
+	fd int
+}
+
+// NewSeccompListener returns a SeccompListener for the listener file
+// descriptor fd returned by SeccompSetModeFilter or SeccompFilter.Install.
+// The listener takes ownership of fd.
+func NewSeccompListener(fd int) *SeccompListener {
+	return &SeccompListener{fd: fd}
+}
+
+// Fd returns the listener file descriptor.
+func (l *SeccompListener) Fd() int { return l.fd }
+
+// Close closes the listener file descriptor. Targets blocked in a system
+// call waiting for a response fail it with ENOSYS.
+func (l *SeccompListener) Close() error {
+	return Close(l.fd)
+}
+
+// Receive blocks until a notification is available and returns it. If the
+// target of the notification was interrupted by a signal before it could be
+// received, the notification is skipped.
+func (l *SeccompListener) Receive() (*SeccompRequest, error) {
+	for {
+		notif, err := IoctlSeccompNotifRecv(l.fd)
+		switch err {
+		case nil:
+			return &SeccompRequest{Notif: *notif, fd: l.fd}, nil
+		case EINTR, ENOENT:
+			continue
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Serve receives notifications and passes them to handler, which must
+// respond to them, until no filter attached to the listener is in use
+// anymore or an error occurs. If handler returns an error, Serve returns it.
+func (l *SeccompListener) Serve(handler func(req *SeccompRequest) error) error {
+	fds := []PollFd{{Fd: int32(l.fd), Events: POLLIN}}
+	for {
+		if _, err := Poll(fds, -1); err != nil {
+			if err == EINTR {
+				continue
+			}
+			return err
+		}
+		if fds[0].Revents&POLLIN == 0 && fds[0].Revents&POLLHUP != 0 {
+			// All tasks using the filter have exited.
+			return nil
+		}
+		req, err := l.Receive()
+		if err != nil {
+			return err
+		}
+		if err := handler(req); err != nil {
+			return err
+		}
+	}
+}
+
+// A SeccompRequest is a system call of a target intercepted by a seccomp
+// filter and received by a SeccompListener.
+//
+// The memory and file descriptors of the target may change between the
+// notification and the response. Values read from the target with
+// ReadMemory and OpenPidfd are only meaningful once Valid reports that the
+// target is still blocked in the system call.
+type SeccompRequest struct {
+	Notif SeccompNotif
+	fd    int
+}
+
+// Valid reports whether the target is still blocked in the system call,
+// which means that its PID has not been reused.
+func (r *SeccompRequest) Valid() bool {
+	return IoctlSeccompNotifIDValid(r.fd, r.Notif.Id) == nil
+}
+
+// ReadMemory reads len(b) bytes at addr from the address space of the
+// target using ProcessVMReadv.
+func (r *SeccompRequest) ReadMemory(addr uint64, b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	local := []Iovec{{Base: &b[0]}}
+	local[0].SetLen(len(b))
+	remote := []RemoteIovec{{Base: uintptr(addr), Len: len(b)}}
+	n, err := ProcessVMReadv(int(r.Notif.Pid), local, remote, 0)
+	if err != nil {
+		return n, err
+	}
+	if !r.Valid() {
+		return 0, ENOENT
+	}
+	return n, nil
+}
+
+// ReadString reads a NUL-terminated string of at most max bytes at addr
+// from the address space of the target, such as a path argument.
+func (r *SeccompRequest) ReadString(addr uint64, max int) (string, error) {
+	b := make([]byte, max)
+	n, err := r.ReadMemory(addr, b)
+	if err != nil && n == 0 {
+		return "", err
+	}
+	b = b[:n]
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i]), nil
+		}
+	}
+	return "", ENAMETOOLONG
+}
+
+// OpenPidfd returns a pidfd referring to the target, which can be used with
+// PidfdGetfd and PidfdSendSignal without the risk of PID reuse.
+func (r *SeccompRequest) OpenPidfd() (int, error) {
+	pidfd, err := PidfdOpen(int(r.Notif.Pid), 0)
+	if err != nil {
+		return -1, err
+	}
+	if !r.Valid() {
+		Close(pidfd)
+		return -1, ENOENT
+	}
+	return pidfd, nil
+}
+
+// Continue lets the kernel execute the system call as if it had been
+// allowed by the filter. As the arguments may have been changed by the
+// target since they were inspected, Continue must not be used to implement
+// security policies.
+func (r *SeccompRequest) Continue() error {
+	return IoctlSeccompNotifSend(r.fd, &SeccompNotifResp{
+		Id:    r.Notif.Id,
+		Flags: SECCOMP_USER_NOTIF_FLAG_CONTINUE,
+	})
+}
+
+// Return makes the system call return val in the target.
+func (r *SeccompRequest) Return(val int64) error {
+	return IoctlSeccompNotifSend(r.fd, &SeccompNotifResp{
+		Id:  r.Notif.Id,
+		Val: val,
+	})
+}
+
+// Fail makes the system call fail with errno in the target.
+func (r *SeccompRequest) Fail(errno Errno) error {
+	return IoctlSeccompNotifSend(r.fd, &SeccompNotifResp{
+		Id:    r.Notif.Id,
+		Error: -int32(errno),
+	})
+}
+
+// AddFd installs a duplicate of fd, a file descriptor of the supervisor,
+// into the target and returns its number in the target. flags may contain
+// O_CLOEXEC. The system call still needs to be responded to.
+func (r *SeccompRequest) AddFd(fd int, flags int) (int, error) {
+	return IoctlSeccompNotifAddfd(r.fd, &SeccompNotifAddfd{
+		Id:          r.Notif.Id,
+		Srcfd:       uint32(fd),
+		Newfd_flags: uint32(flags),
+	})
+}
+
+// ReturnFd installs a duplicate of fd into the target like AddFd and
+// atomically makes the system call return the new file descriptor number,
+// as needed to emulate system calls such as openat or accept.
+func (r *SeccompRequest) ReturnFd(fd int, flags int) (int, error) {
+	return IoctlSeccompNotifAddfd(r.fd, &SeccompNotifAddfd{
+		Id:          r.Notif.Id,
+		Flags:       SECCOMP_ADDFD_FLAG_SEND,
+		Srcfd:       uint32(fd),
+		Newfd_flags: uint32(flags),
+	})
+}
@@ -0,0 +1,312 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestSeccompGetActionAvail(t *testing.T) {
+	err := unix.SeccompGetActionAvail(unix.SECCOMP_RET_ALLOW)
+	if err == unix.ENOSYS || err == unix.EINVAL {
+		t.Skipf("seccomp not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("SeccompGetActionAvail(SECCOMP_RET_ALLOW): %v", err)
+	}
+	if err := unix.SeccompGetActionAvail(0x12340000); err != unix.EOPNOTSUPP {
+		t.Fatalf("unexpected result for unknown action: got %v, want %v", err, unix.EOPNOTSUPP)
+	}
+}
+
+func TestSeccompFilterCompile(t *testing.T) {
+	f := unix.SeccompFilter{
+		DefaultAction: unix.SECCOMP_RET_ALLOW,
+		Rules: []unix.SeccompRule{{
+			Syscall: unix.SYS_GETPRIORITY,
+			Args:    []unix.SeccompArg{{Index: 6}},
+			Action:  unix.SECCOMP_RET_KILL_PROCESS,
+		}},
+	}
+	if _, err := f.Compile(); err != unix.EINVAL {
+		t.Errorf("unexpected error for argument index 6: got %v, want %v", err, unix.EINVAL)
+	}
+
+	f.Rules[0].Args = make([]unix.SeccompArg, 100)
+	if _, err := f.Compile(); err != unix.EINVAL {
+		t.Errorf("unexpected error for out of range jump: got %v, want %v", err, unix.EINVAL)
+	}
+
+	f.Rules[0].Args = nil
+	prog, err := f.Compile()
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if last := prog[len(prog)-1]; last.Code != unix.BPF_RET|unix.BPF_K || last.K != unix.SECCOMP_RET_ALLOW {
+		t.Errorf("program does not end with the default action: %+v", last)
+	}
+}
+
+func TestSeccompSyscallNumber(t *testing.T) {
+	for name, want := range map[string]int{
+		"getpid":      unix.SYS_GETPID,
+		"openat":      unix.SYS_OPENAT,
+		"getpriority": unix.SYS_GETPRIORITY,
+	} {
+		got, err := unix.SeccompSyscallNumber(name)
+		if err != nil || got != want {
+			t.Errorf("SeccompSyscallNumber(%q) = %d, %v; want %d", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"", "no_such_syscall", "GETPID", "SYS_GETPID"} {
+		if _, err := unix.SeccompSyscallNumber(name); err != unix.ENOSYS {
+			t.Errorf("SeccompSyscallNumber(%q): got %v, want %v", name, err, unix.ENOSYS)
+		}
+	}
+
+	// A rule on a resolved name compiles like a rule on the number.
+	nr, err := unix.SeccompSyscallNumber("getpriority")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compile := func(syscall int) []unix.SockFilter {
+		f := unix.SeccompFilter{
+			DefaultAction: unix.SECCOMP_RET_ALLOW,
+			Rules:         []unix.SeccompRule{{Syscall: syscall, Action: unix.SECCOMP_RET_KILL_PROCESS}},
+		}
+		prog, err := f.Compile()
+		if err != nil {
+			t.Fatalf("Compile: %v", err)
+		}
+		return prog
+	}
+	if byName, byNumber := compile(nr), compile(unix.SYS_GETPRIORITY); !reflect.DeepEqual(byName, byNumber) {
+		t.Errorf("programs differ:\n%v\n%v", byName, byNumber)
+	}
+}
+
+func TestSeccompFilterInstall(t *testing.T) {
+	if err := unix.SeccompGetActionAvail(unix.SECCOMP_RET_ERRNO); err != nil {
+		t.Skipf("seccomp not available: %v", err)
+	}
+
+	const big = 1 << 40
+	tests := []struct {
+		arg   unix.SeccompArg
+		match []uint64
+		miss  []uint64
+	}{
+		{unix.SeccompArg{Op: unix.SeccompCmpEQ, Value: 7}, []uint64{7}, []uint64{6, 8, big + 7}},
+		{unix.SeccompArg{Op: unix.SeccompCmpNE, Value: 7}, []uint64{6, big + 7}, []uint64{7}},
+		{unix.SeccompArg{Op: unix.SeccompCmpLT, Value: big}, []uint64{0, big - 1}, []uint64{big, big + 1}},
+		{unix.SeccompArg{Op: unix.SeccompCmpLE, Value: big}, []uint64{0, big}, []uint64{big + 1, 2 * big}},
+		{unix.SeccompArg{Op: unix.SeccompCmpGT, Value: big}, []uint64{big + 1, 2 * big}, []uint64{1, big}},
+		{unix.SeccompArg{Op: unix.SeccompCmpGE, Value: big}, []uint64{big, 2 * big}, []uint64{1, big - 1}},
+		{unix.SeccompArg{Op: unix.SeccompCmpMaskedEQ, Mask: 0xf0, Value: 0x30}, []uint64{0x30, 0x3f, big + 0x31}, []uint64{0x40, 0x0f}},
+	}
+	for _, tt := range tests {
+		f := unix.SeccompFilter{
+			DefaultAction: unix.SECCOMP_RET_ALLOW,
+			Rules: []unix.SeccompRule{{
+				// getpriority(PRIO_PGRP, who): the rule on PRIO_PROCESS
+				// must never match.
+				Syscall: unix.SYS_GETPRIORITY,
+				Args: []unix.SeccompArg{
+					{Index: 0, Op: unix.SeccompCmpEQ, Value: unix.PRIO_PROCESS},
+				},
+				Action: unix.SECCOMP_RET_KILL_PROCESS,
+			}, {
+				Syscall: unix.SYS_GETPRIORITY,
+				Args: []unix.SeccompArg{
+					{Index: 0, Op: unix.SeccompCmpEQ, Value: unix.PRIO_PGRP},
+					{Index: 1, Op: tt.arg.Op, Value: tt.arg.Value, Mask: tt.arg.Mask},
+				},
+				Action: unix.SeccompRetErrno(unix.EXFULL),
+			}},
+		}
+
+		errc := make(chan error, 1)
+		results := make(chan uint64, len(tt.match)+len(tt.miss))
+		go func() {
+			// The filter stays installed on this thread, which is
+			// terminated when the goroutine exits while locked to it.
+			runtime.LockOSThread()
+			if _, err := f.Install(0); err != nil {
+				errc <- err
+				return
+			}
+			for _, who := range append(tt.match, tt.miss...) {
+				_, _, e := unix.Syscall(unix.SYS_GETPRIORITY, unix.PRIO_PGRP, uintptr(who), 0)
+				if e == unix.EXFULL {
+					results <- who
+				}
+			}
+			close(results)
+			errc <- nil
+		}()
+		if err := <-errc; err != nil {
+			t.Fatalf("Install: %v", err)
+		}
+
+		matched := make(map[uint64]bool)
+		for who := range results {
+			matched[who] = true
+		}
+		for _, who := range tt.match {
+			if uint64(uintptr(who)) != who {
+				// The upper half is lost on 32-bit platforms.
+				continue
+			}
+			if !matched[who] {
+				t.Errorf("op %d value %#x: argument %#x did not match", tt.arg.Op, tt.arg.Value, who)
+			}
+		}
+		for _, who := range tt.miss {
+			if uint64(uintptr(who)) != who {
+				continue
+			}
+			if matched[who] {
+				t.Errorf("op %d value %#x: argument %#x unexpectedly matched", tt.arg.Op, tt.arg.Value, who)
+			}
+		}
+	}
+}
+
+func TestSeccompListener(t *testing.T) {
+	if err := unix.SeccompGetActionAvail(unix.SECCOMP_RET_USER_NOTIF); err != nil {
+		t.Skipf("seccomp user notifications not available: %v", err)
+	}
+
+	f := unix.SeccompFilter{
+		DefaultAction: unix.SECCOMP_RET_ALLOW,
+		Rules: []unix.SeccompRule{{
+			Syscall: unix.SYS_GETPRIORITY,
+			Args: []unix.SeccompArg{
+				{Index: 0, Op: unix.SeccompCmpEQ, Value: unix.PRIO_PGRP},
+			},
+			Action: unix.SECCOMP_RET_USER_NOTIF,
+		}},
+	}
+
+	type result struct {
+		tid int
+		r1  uintptr
+		err unix.Errno
+	}
+	fdc := make(chan int, 1)
+	errc := make(chan error, 1)
+	results := make(chan result, 2)
+	arg := []byte("argument\x00")
+	go func() {
+		// The filter stays installed on this thread, which is terminated
+		// when the goroutine exits while locked to it.
+		runtime.LockOSThread()
+		fd, err := f.Install(unix.SECCOMP_FILTER_FLAG_NEW_LISTENER)
+		if err != nil {
+			errc <- err
+			return
+		}
+		fdc <- fd
+		tid := unix.Gettid()
+		r1, _, e := unix.Syscall(unix.SYS_GETPRIORITY, unix.PRIO_PGRP, uintptr(unsafe.Pointer(&arg[0])), 0)
+		results <- result{tid, r1, e}
+		r1, _, e = unix.Syscall(unix.SYS_GETPRIORITY, unix.PRIO_PGRP, 0, 0)
+		results <- result{tid, r1, e}
+		runtime.KeepAlive(arg)
+	}()
+	var l *unix.SeccompListener
+	select {
+	case err := <-errc:
+		t.Fatalf("Install: %v", err)
+	case fd := <-fdc:
+		l = unix.NewSeccompListener(fd)
+	}
+	defer l.Close()
+
+	req, err := l.Receive()
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if req.Notif.Data.Nr != unix.SYS_GETPRIORITY {
+		t.Errorf("unexpected system call: got %d, want %d", req.Notif.Data.Nr, unix.SYS_GETPRIORITY)
+	}
+	if !req.Valid() {
+		t.Error("request is not valid")
+	}
+	s, err := req.ReadString(req.Notif.Data.Args[1], 64)
+	if err != nil {
+		t.Errorf("ReadString: %v", err)
+	} else if s != "argument" {
+		t.Errorf("unexpected argument read: got %q, want %q", s, "argument")
+	}
+	if err := req.Return(42); err != nil {
+		t.Fatalf("Return: %v", err)
+	}
+	res := <-results
+	if res.err != 0 || res.r1 != 42 {
+		t.Errorf("unexpected result: got %d, %v, want 42", res.r1, res.err)
+	}
+	if int(req.Notif.Pid) != res.tid {
+		t.Errorf("unexpected target: got %d, want %d", req.Notif.Pid, res.tid)
+	}
+
+	req, err = l.Receive()
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	var p [2]int
+	if err := unix.Pipe2(p[:], unix.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(p[0])
+	defer unix.Close(p[1])
+	newfd, err := req.ReturnFd(p[1], unix.O_CLOEXEC)
+	if err == unix.ENOTTY || err == unix.EINVAL {
+		req.Fail(unix.ENOSYS)
+		t.Skipf("SECCOMP_ADDFD_FLAG_SEND not supported: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("ReturnFd: %v", err)
+	}
+	res = <-results
+	if res.err != 0 || int(res.r1) != newfd {
+		t.Fatalf("unexpected result: got %d, %v, want %d", res.r1, res.err, newfd)
+	}
+	defer unix.Close(newfd)
+	if _, err := unix.Write(newfd, []byte("x")); err != nil {
+		t.Fatalf("writing to injected fd: %v", err)
+	}
+	var b [1]byte
+	if n, err := unix.Read(p[0], b[:]); err != nil || n != 1 || b[0] != 'x' {
+		t.Fatalf("unexpected data read from pipe: %d, %v", n, err)
+	}
+}
+
+func TestSeccompStructSizes(t *testing.T) {
+	if got := unsafe.Sizeof(unix.SeccompData{}); got != unix.SizeofSeccompData {
+		t.Errorf("unexpected SeccompData size: got %d, want %d", got, unix.SizeofSeccompData)
+	}
+	if got := unsafe.Sizeof(unix.SeccompNotif{}); got != unix.SizeofSeccompNotif {
+		t.Errorf("unexpected SeccompNotif size: got %d, want %d", got, unix.SizeofSeccompNotif)
+	}
+	if got := unsafe.Sizeof(unix.SeccompNotifResp{}); got != unix.SizeofSeccompNotifResp {
+		t.Errorf("unexpected SeccompNotifResp size: got %d, want %d", got, unix.SizeofSeccompNotifResp)
+	}
+	sizes, err := unix.SeccompGetNotifSizes()
+	if err != nil {
+		t.Skipf("SeccompGetNotifSizes: %v", err)
+	}
+	if int(sizes.Seccomp_notif) < unix.SizeofSeccompNotif || int(sizes.Seccomp_data) != unix.SizeofSeccompData {
+		t.Errorf("unexpected kernel structure sizes: %+v", sizes)
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+// A SockDiagConn is a NETLINK_SOCK_DIAG socket, which lists the sockets of
+// the network namespace it was opened in, like ss(8).
+type SockDiagConn struct {
+	*NetlinkConn
+}
+
+// NewSockDiagConn opens a NETLINK_SOCK_DIAG socket.
+func NewSockDiagConn() (*SockDiagConn, error) {
+	c, err := NewNetlinkConn(NETLINK_SOCK_DIAG)
+	if err != nil {
+		return nil, err
+	}
+	return &SockDiagConn{c}, nil
+}
+
+// copyStruct copies b into the struct of the given size at p. Structures
+// sent by the kernel may be shorter or longer than the Go type, such as
+// tcp_info, and are not necessarily aligned in attributes.
+func copyStruct(p unsafe.Pointer, size int, b []byte) {
+	copy(structBytes(p, size), b)
+}
+
+func parseUint32s(b []byte) []uint32 {
+	v := make([]uint32, len(b)/4)
+	for i := range v {
+		v[i] = nativeEndian.Uint32(b[4*i:])
+	}
+	return v
+}
+
+func (c *SockDiagConn) dump(typ uint16, req []byte) ([]NetlinkMessage, error) {
+	m := NetlinkMessage{
+		Header: NlMsghdr{Type: typ, Flags: NLM_F_DUMP},
+		Data:   append([]byte(nil), req...),
+	}
+	for i := 0; ; i++ {
+		msgs, err := c.Execute(m)
+		if err == EINTR && i < 10 {
+			continue
+		}
+		return msgs, err
+	}
+}
+
+// InetDiagExt returns the bit of the Idiag_ext field of InetDiagReqV2
+// requesting the INET_DIAG_* attribute attr, such as INET_DIAG_INFO.
+func InetDiagExt(attr int) uint8 {
+	return 1 << (attr - 1)
+}
+
+// An InetDiagSocket is an IPv4 or IPv6 socket, as listed by InetSockets.
+// The pointer fields are nil if the attribute was not requested or is not
+// supported by the protocol or the congestion control algorithm.
+type InetDiagSocket struct {
+	Msg InetDiagMsg
+
+	Meminfo   *InetDiagMeminfo // INET_DIAG_MEMINFO
+	SkMeminfo []uint32         // INET_DIAG_SKMEMINFO, indexed by SK_MEMINFO_*
+	TCPInfo   *TCPInfo         // INET_DIAG_INFO, for TCP sockets
+	Cong      string           // INET_DIAG_CONG, the congestion control algorithm
+	Vegas     *TCPVegasInfo    // INET_DIAG_VEGASINFO
+	Dctcp     *TCPDctcpInfo    // INET_DIAG_DCTCPINFO
+	BBR       *TCPBBRInfo      // INET_DIAG_BBRINFO
+	Shutdown  uint8            // INET_DIAG_SHUTDOWN, a mask of RCV_SHUTDOWN and SEND_SHUTDOWN
+	Protocol  uint8            // INET_DIAG_PROTOCOL, or the protocol of the request
+	Mark      uint32           // INET_DIAG_MARK, for privileged callers
+	CgroupID  uint64           // INET_DIAG_CGROUP_ID
+
+	// Attrs holds all attributes of the socket.
+	Attrs []NetlinkAttr
+}
+
+func inetDiagAddr(family uint8, a *[4]uint32) []byte {
+	b := structBytes(unsafe.Pointer(&a[0]), 16)
+	if family == AF_INET {
+		return append([]byte(nil), b[:4]...)
+	}
+	return append([]byte(nil), b...)
+}
+
+// LocalAddr returns the local address of the socket, 4 bytes long for
+// AF_INET and 16 bytes long for AF_INET6.
+func (s *InetDiagSocket) LocalAddr() []byte {
+	return inetDiagAddr(s.Msg.Family, &s.Msg.Id.Src)
+}
+
+// RemoteAddr returns the remote address of the socket.
+func (s *InetDiagSocket) RemoteAddr() []byte {
+	return inetDiagAddr(s.Msg.Family, &s.Msg.Id.Dst)
+}
+
+// LocalPort returns the local port of the socket in host byte order.
+func (s *InetDiagSocket) LocalPort() uint16 {
+	return ntohs(s.Msg.Id.Sport)
+}
+
+// RemotePort returns the remote port of the socket in host byte order.
+func (s *InetDiagSocket) RemotePort() uint16 {
+	return ntohs(s.Msg.Id.Dport)
+}
+
+// ntohs converts a port in network byte order, as stored in a uint16 in
+// native byte order, to host byte order.
+func ntohs(port uint16) uint16 {
+	var b [2]byte
+	nativeEndian.PutUint16(b[:], port)
+	return binary.BigEndian.Uint16(b[:])
+}
+
+func parseInetDiagSocket(m *NetlinkMessage, protocol uint8) (*InetDiagSocket, error) {
+	if len(m.Data) < SizeofInetDiagMsg {
+		return nil, EINVAL
+	}
+	s := &InetDiagSocket{Protocol: protocol}
+	copyStruct(unsafe.Pointer(&s.Msg), SizeofInetDiagMsg, m.Data)
+	attrs := m.Data[nlaAlignOf(SizeofInetDiagMsg):]
+	var err error
+	if s.Attrs, err = ParseNetlinkAttrs(attrs); err != nil {
+		return nil, err
+	}
+	for _, a := range s.Attrs {
+		v := a.Value
+		switch a.Type & nlaTypeMask {
+		case INET_DIAG_MEMINFO:
+			s.Meminfo = new(InetDiagMeminfo)
+			copyStruct(unsafe.Pointer(s.Meminfo), SizeofInetDiagMeminfo, v)
+		case INET_DIAG_SKMEMINFO:
+			s.SkMeminfo = parseUint32s(v)
+		case INET_DIAG_INFO:
+			// Only TCP sockets have a tcp_info structure.
+			if protocol == IPPROTO_TCP {
+				s.TCPInfo = new(TCPInfo)
+				copyStruct(unsafe.Pointer(s.TCPInfo), SizeofTCPInfo, v)
+			}
+		case INET_DIAG_CONG:
+			s.Cong = string(v[:clen(v)])
+		case INET_DIAG_VEGASINFO:
+			s.Vegas = new(TCPVegasInfo)
+			copyStruct(unsafe.Pointer(s.Vegas), SizeofTCPVegasInfo, v)
+		case INET_DIAG_DCTCPINFO:
+			s.Dctcp = new(TCPDctcpInfo)
+			copyStruct(unsafe.Pointer(s.Dctcp), SizeofTCPDctcpInfo, v)
+		case INET_DIAG_BBRINFO:
+			s.BBR = new(TCPBBRInfo)
+			copyStruct(unsafe.Pointer(s.BBR), SizeofTCPBBRInfo, v)
+		case INET_DIAG_SHUTDOWN:
+			if len(v) > 0 {
+				s.Shutdown = v[0]
+			}
+		case INET_DIAG_PROTOCOL:
+			if len(v) > 0 {
+				s.Protocol = v[0]
+			}
+		case INET_DIAG_MARK:
+			if len(v) == 4 {
+				s.Mark = nativeEndian.Uint32(v)
+			}
+		case INET_DIAG_CGROUP_ID:
+			if len(v) == 8 {
+				s.CgroupID = nativeEndian.Uint64(v)
+			}
+		}
+	}
+	return s, nil
+}
+
+// InetSockets returns the sockets matching req. Req.Sdiag_family is AF_INET
+// or AF_INET6, Req.Sdiag_protocol is the protocol, such as IPPROTO_TCP or
+// IPPROTO_UDP, Req.Idiag_states is a mask of the states to list, such as
+// 1<<TCP_ESTABLISHED, or 0xffffffff for all, and Req.Idiag_ext is a mask of
+// the attributes to return, built with InetDiagExt. The request is always
+// a dump: the kernel ignores Req.Id, including its cookie, except that TCP
+// and UDP sockets are only listed if their local and remote ports match
+// Req.Id.Sport and Req.Id.Dport, in network byte order, when these are not
+// 0.
+func (c *SockDiagConn) InetSockets(req *InetDiagReqV2) ([]InetDiagSocket, error) {
+	msgs, err := c.dump(SOCK_DIAG_BY_FAMILY, structBytes(unsafe.Pointer(req), SizeofInetDiagReqV2))
+	if err != nil {
+		return nil, err
+	}
+	socks := make([]InetDiagSocket, 0, len(msgs))
+	for i := range msgs {
+		s, err := parseInetDiagSocket(&msgs[i], req.Sdiag_protocol)
+		if err != nil {
+			return nil, err
+		}
+		socks = append(socks, *s)
+	}
+	return socks, nil
+}
+
+// DestroyInetSocket closes the socket s, as listed by InetSockets, with
+// SOCK_DESTROY. Operations on the socket then fail with ECONNABORTED. It
+// requires CAP_NET_ADMIN in the user namespace of the network namespace
+// and a kernel built with CONFIG_INET_DIAG_DESTROY.
+func (c *SockDiagConn) DestroyInetSocket(s *InetDiagSocket) error {
+	req := InetDiagReqV2{
+		Sdiag_family:   s.Msg.Family,
+		Sdiag_protocol: s.Protocol,
+		Idiag_states:   0xffffffff,
+		Id:             s.Msg.Id,
+	}
+	_, err := c.Execute(NetlinkMessage{
+		Header: NlMsghdr{Type: SOCK_DESTROY},
+		Data:   append([]byte(nil), structBytes(unsafe.Pointer(&req), SizeofInetDiagReqV2)...),
+	})
+	return err
+}
+
+// A UnixDiagSocket is a Unix domain socket, as listed by UnixSockets. The
+// fields other than Msg are set according to the UDIAG_SHOW_* flags of the
+// request.
+type UnixDiagSocket struct {
+	Msg UnixDiagMsg
+
+	Name     string         // UNIX_DIAG_NAME, the bound path, with a leading '@' for abstract names
+	Vfs      *UnixDiagVfs   // UNIX_DIAG_VFS, the inode and device of a bound path
+	Peer     uint32         // UNIX_DIAG_PEER, the inode of the peer socket, or 0
+	Icons    []uint32       // UNIX_DIAG_ICONS, the inodes of pending connections of a listener
+	RQlen    *UnixDiagRQlen // UNIX_DIAG_RQLEN
+	Meminfo  []uint32       // UNIX_DIAG_MEMINFO, indexed by SK_MEMINFO_*
+	Shutdown uint8          // UNIX_DIAG_SHUTDOWN
+	Uid      uint32         // UNIX_DIAG_UID
+
+	// Attrs holds all attributes of the socket.
+	Attrs []NetlinkAttr
+}
+
+func parseUnixDiagSocket(m *NetlinkMessage) (*UnixDiagSocket, error) {
+	if len(m.Data) < SizeofUnixDiagMsg {
+		return nil, EINVAL
+	}
+	s := new(UnixDiagSocket)
+	copyStruct(unsafe.Pointer(&s.Msg), SizeofUnixDiagMsg, m.Data)
+	var err error
+	if s.Attrs, err = ParseNetlinkAttrs(m.Data[SizeofUnixDiagMsg:]); err != nil {
+		return nil, err
+	}
+	for _, a := range s.Attrs {
+		v := a.Value
+		switch a.Type & nlaTypeMask {
+		case UNIX_DIAG_NAME:
+			if len(v) > 0 && v[0] == 0 {
+				s.Name = "@" + string(v[1:])
+			} else {
+				s.Name = string(v[:clen(v)])
+			}
+		case UNIX_DIAG_VFS:
+			s.Vfs = new(UnixDiagVfs)
+			copyStruct(unsafe.Pointer(s.Vfs), SizeofUnixDiagVfs, v)
+		case UNIX_DIAG_PEER:
+			if len(v) == 4 {
+				s.Peer = nativeEndian.Uint32(v)
+			}
+		case UNIX_DIAG_ICONS:
+			s.Icons = parseUint32s(v)
+		case UNIX_DIAG_RQLEN:
+			s.RQlen = new(UnixDiagRQlen)
+			copyStruct(unsafe.Pointer(s.RQlen), SizeofUnixDiagRQlen, v)
+		case UNIX_DIAG_MEMINFO:
+			s.Meminfo = parseUint32s(v)
+		case UNIX_DIAG_SHUTDOWN:
+			if len(v) > 0 {
+				s.Shutdown = v[0]
+			}
+		case UNIX_DIAG_UID:
+			if len(v) == 4 {
+				s.Uid = nativeEndian.Uint32(v)
+			}
+		}
+	}
+	return s, nil
+}
+
+// UnixSockets returns the Unix domain sockets in the given states, a mask
+// such as 1<<TCP_LISTEN|1<<TCP_ESTABLISHED or 0xffffffff for all, with the
+// information selected by show, a mask of UDIAG_SHOW_* flags.
+func (c *SockDiagConn) UnixSockets(states, show uint32) ([]UnixDiagSocket, error) {
+	req := UnixDiagReq{
+		Sdiag_family: AF_UNIX,
+		Udiag_states: states,
+		Udiag_show:   show,
+		Udiag_cookie: [2]uint32{INET_DIAG_NOCOOKIE, INET_DIAG_NOCOOKIE},
+	}
+	msgs, err := c.dump(SOCK_DIAG_BY_FAMILY, structBytes(unsafe.Pointer(&req), SizeofUnixDiagReq))
+	if err != nil {
+		return nil, err
+	}
+	socks := make([]UnixDiagSocket, 0, len(msgs))
+	for i := range msgs {
+		s, err := parseUnixDiagSocket(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		socks = append(socks, *s)
+	}
+	return socks, nil
+}
+
+// A PacketDiagSocket is an AF_PACKET socket, as listed by PacketSockets.
+// The fields other than Msg are set according to the PACKET_SHOW_* flags
+// of the request.
+type PacketDiagSocket struct {
+	Msg PacketDiagMsg
+
+	Info    *PacketDiagInfo    // PACKET_DIAG_INFO
+	Mclist  []PacketDiagMclist // PACKET_DIAG_MCLIST
+	RxRing  *PacketDiagRing    // PACKET_DIAG_RX_RING
+	TxRing  *PacketDiagRing    // PACKET_DIAG_TX_RING
+	Fanout  uint32             // PACKET_DIAG_FANOUT, the group ID and type as for PACKET_FANOUT
+	Uid     uint32             // PACKET_DIAG_UID
+	Meminfo []uint32           // PACKET_DIAG_MEMINFO, indexed by SK_MEMINFO_*
+	Filter  []SockFilter       // PACKET_DIAG_FILTER, the attached classic BPF filter
+
+	// Attrs holds all attributes of the socket.
+	Attrs []NetlinkAttr
+}
+
+func parsePacketDiagSocket(m *NetlinkMessage) (*PacketDiagSocket, error) {
+	if len(m.Data) < SizeofPacketDiagMsg {
+		return nil, EINVAL
+	}
+	s := new(PacketDiagSocket)
+	copyStruct(unsafe.Pointer(&s.Msg), SizeofPacketDiagMsg, m.Data)
+	var err error
+	if s.Attrs, err = ParseNetlinkAttrs(m.Data[SizeofPacketDiagMsg:]); err != nil {
+		return nil, err
+	}
+	for _, a := range s.Attrs {
+		v := a.Value
+		switch a.Type & nlaTypeMask {
+		case PACKET_DIAG_INFO:
+			s.Info = new(PacketDiagInfo)
+			copyStruct(unsafe.Pointer(s.Info), SizeofPacketDiagInfo, v)
+		case PACKET_DIAG_MCLIST:
+			s.Mclist = make([]PacketDiagMclist, len(v)/SizeofPacketDiagMclist)
+			for i := range s.Mclist {
+				copyStruct(unsafe.Pointer(&s.Mclist[i]), SizeofPacketDiagMclist, v[i*SizeofPacketDiagMclist:])
+			}
+		case PACKET_DIAG_RX_RING:
+			s.RxRing = new(PacketDiagRing)
+			copyStruct(unsafe.Pointer(s.RxRing), SizeofPacketDiagRing, v)
+		case PACKET_DIAG_TX_RING:
+			s.TxRing = new(PacketDiagRing)
+			copyStruct(unsafe.Pointer(s.TxRing), SizeofPacketDiagRing, v)
+		case PACKET_DIAG_FANOUT:
+			if len(v) == 4 {
+				s.Fanout = nativeEndian.Uint32(v)
+			}
+		case PACKET_DIAG_UID:
+			if len(v) == 4 {
+				s.Uid = nativeEndian.Uint32(v)
+			}
+		case PACKET_DIAG_MEMINFO:
+			s.Meminfo = parseUint32s(v)
+		case PACKET_DIAG_FILTER:
+			s.Filter = make([]SockFilter, len(v)/SizeofSockFilter)
+			for i := range s.Filter {
+				copyStruct(unsafe.Pointer(&s.Filter[i]), SizeofSockFilter, v[i*SizeofSockFilter:])
+			}
+		}
+	}
+	return s, nil
+}
+
+// PacketSockets returns the AF_PACKET sockets with the information selected
+// by show, a mask of PACKET_SHOW_* flags.
+func (c *SockDiagConn) PacketSockets(show uint32) ([]PacketDiagSocket, error) {
+	req := PacketDiagReq{
+		Sdiag_family: AF_PACKET,
+		Pdiag_show:   show,
+		Pdiag_cookie: [2]uint32{INET_DIAG_NOCOOKIE, INET_DIAG_NOCOOKIE},
+	}
+	msgs, err := c.dump(SOCK_DIAG_BY_FAMILY, structBytes(unsafe.Pointer(&req), SizeofPacketDiagReq))
+	if err != nil {
+		return nil, err
+	}
+	socks := make([]PacketDiagSocket, 0, len(msgs))
+	for i := range msgs {
+		s, err := parsePacketDiagSocket(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		socks = append(socks, *s)
+	}
+	return socks, nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func socketInode(t *testing.T, fd int) uint32 {
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		t.Fatal(err)
+	}
+	return uint32(st.Ino)
+}
+
+func TestSockDiagInet(t *testing.T) {
+	inNewNetNs(t, func() {
+		rc, err := unix.NewRtnlConn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		lo, err := rc.LinkGetByName("lo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rc.LinkSetUp(int(lo.Info.Index)); err != nil {
+			t.Fatal(err)
+		}
+
+		ln, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unix.Close(ln)
+		if err := unix.Bind(ln, &unix.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := unix.Listen(ln, 1); err != nil {
+			t.Fatal(err)
+		}
+		sa, err := unix.Getsockname(ln)
+		if err != nil {
+			t.Fatal(err)
+		}
+		port := sa.(*unix.SockaddrInet4).Port
+
+		cl, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unix.Close(cl)
+		if err := unix.Connect(cl, sa); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := unix.NewSockDiagConn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		socks, err := c.InetSockets(&unix.InetDiagReqV2{
+			Sdiag_family:   unix.AF_INET,
+			Sdiag_protocol: unix.IPPROTO_TCP,
+			Idiag_ext:      unix.InetDiagExt(unix.INET_DIAG_INFO) | unix.InetDiagExt(unix.INET_DIAG_CONG),
+			Idiag_states:   1<<unix.BPF_TCP_LISTEN | 1<<unix.BPF_TCP_ESTABLISHED,
+		})
+		if err != nil {
+			t.Fatalf("InetSockets: %v", err)
+		}
+
+		var listener, client *unix.InetDiagSocket
+		for i := range socks {
+			s := &socks[i]
+			switch s.Msg.Inode {
+			case socketInode(t, ln):
+				listener = s
+			case socketInode(t, cl):
+				client = s
+			}
+		}
+		if listener == nil || client == nil {
+			t.Fatalf("sockets not found in %d sockets", len(socks))
+		}
+		if listener.Msg.State != unix.BPF_TCP_LISTEN || int(listener.LocalPort()) != port {
+			t.Errorf("listener: got state %d, port %d", listener.Msg.State, listener.LocalPort())
+		}
+		if client.Msg.State != unix.BPF_TCP_ESTABLISHED || int(client.RemotePort()) != port {
+			t.Errorf("client: got state %d, remote port %d", client.Msg.State, client.RemotePort())
+		}
+		if !bytes.Equal(client.RemoteAddr(), []byte{127, 0, 0, 1}) {
+			t.Errorf("client: got remote address %v", client.RemoteAddr())
+		}
+		if client.TCPInfo == nil || client.TCPInfo.State != unix.BPF_TCP_ESTABLISHED {
+			t.Errorf("client: got TCP info %+v", client.TCPInfo)
+		}
+		if client.Cong == "" {
+			t.Error("client: no congestion control algorithm")
+		}
+
+		// The ports in Req.Id filter the dump, and the cookie of the
+		// listener does not select it.
+		socks, err = c.InetSockets(&unix.InetDiagReqV2{
+			Sdiag_family:   unix.AF_INET,
+			Sdiag_protocol: unix.IPPROTO_TCP,
+			Idiag_states:   1<<unix.BPF_TCP_LISTEN | 1<<unix.BPF_TCP_ESTABLISHED,
+			Id:             unix.InetDiagSockID{Dport: client.Msg.Id.Dport, Cookie: listener.Msg.Id.Cookie},
+		})
+		if err != nil {
+			t.Fatalf("InetSockets: %v", err)
+		}
+		found := false
+		for i := range socks {
+			s := &socks[i]
+			if int(s.RemotePort()) != port {
+				t.Errorf("socket with remote port %d listed", s.RemotePort())
+			}
+			found = found || s.Msg.Inode == client.Msg.Inode
+		}
+		if !found {
+			t.Errorf("client not found in %d sockets", len(socks))
+		}
+
+		err = c.DestroyInetSocket(client)
+		if errors.Is(err, unix.EOPNOTSUPP) {
+			t.Skip("SOCK_DESTROY not supported")
+		}
+		if err != nil {
+			t.Fatalf("DestroyInetSocket: %v", err)
+		}
+		if _, err := unix.Read(cl, make([]byte, 1)); err != unix.ECONNABORTED {
+			t.Errorf("Read after DestroyInetSocket: got %v, want %v", err, unix.ECONNABORTED)
+		}
+	})
+}
+
+func TestSockDiagUnix(t *testing.T) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fds[0])
+	defer unix.Close(fds[1])
+
+	ln, err := unix.Socket(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(ln)
+	path := filepath.Join(t.TempDir(), "sock")
+	if err := unix.Bind(ln, &unix.SockaddrUnix{Name: path}); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Listen(ln, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := unix.NewSockDiagConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	socks, err := c.UnixSockets(0xffffffff, unix.UDIAG_SHOW_NAME|unix.UDIAG_SHOW_VFS|unix.UDIAG_SHOW_PEER|unix.UDIAG_SHOW_RQLEN|unix.UDIAG_SHOW_UID)
+	if err != nil {
+		t.Fatalf("UnixSockets: %v", err)
+	}
+	ino0, ino1, inoLn := socketInode(t, fds[0]), socketInode(t, fds[1]), socketInode(t, ln)
+	found := 0
+	for _, s := range socks {
+		switch s.Msg.Ino {
+		case ino0:
+			found++
+			if s.Peer != ino1 {
+				t.Errorf("socketpair: got peer %d, want %d", s.Peer, ino1)
+			}
+			if s.Uid != uint32(unix.Getuid()) {
+				t.Errorf("socketpair: got uid %d, want %d", s.Uid, unix.Getuid())
+			}
+		case inoLn:
+			found++
+			if s.Name != path {
+				t.Errorf("listener: got name %q, want %q", s.Name, path)
+			}
+			if s.Msg.State != unix.BPF_TCP_LISTEN || s.Vfs == nil || s.RQlen == nil {
+				t.Errorf("listener: got state %d, vfs %+v, rqlen %+v", s.Msg.State, s.Vfs, s.RQlen)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("found %d of 2 sockets in %d sockets", found, len(socks))
+	}
+}
+
+func TestSockDiagPacket(t *testing.T) {
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Skipf("Socket(AF_PACKET): %v", err)
+	}
+	defer unix.Close(fd)
+
+	c, err := unix.NewSockDiagConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	socks, err := c.PacketSockets(unix.PACKET_SHOW_INFO | unix.PACKET_SHOW_MEMINFO)
+	if errors.Is(err, unix.ENOENT) {
+		t.Skip("packet_diag not available")
+	}
+	if err != nil {
+		t.Fatalf("PacketSockets: %v", err)
+	}
+	ino := socketInode(t, fd)
+	for _, s := range socks {
+		if s.Msg.Ino == ino {
+			if s.Info == nil || s.Msg.Type != unix.SOCK_RAW {
+				t.Errorf("got type %d, info %+v", s.Msg.Type, s.Info)
+			}
+			if len(s.Meminfo) < unix.SK_MEMINFO_VARS-1 {
+				t.Errorf("got meminfo %v", s.Meminfo)
+			}
+			return
+		}
+	}
+	t.Errorf("socket not found in %d sockets", len(socks))
+}
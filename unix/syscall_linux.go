@@ -13,6 +13,7 @@ package unix
 
 import (
 	"encoding/binary"
+	"runtime"
 	"strconv"
 	"syscall"
 	"time"
@@ -1783,6 +1784,106 @@ func MountSetattr(dirfd int, pathname string, flags uint, attr *MountAttr) error
 	return mountSetattr(dirfd, pathname, flags, attr, unsafe.Sizeof(*attr))
 }
 
+// fsconfigCommon is a common helper for most of the fsconfig(2) wrappers.
+func fsconfigCommon(fd int, cmd uint, key string, value *byte, aux int) error {
+	var keyp *byte
+	if key != "" {
+		var err error
+		if keyp, err = BytePtrFromString(key); err != nil {
+			return err
+		}
+	}
+	return fsconfig(fd, cmd, keyp, value, aux)
+}
+
+// FsconfigSetFlag is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_SET_FLAG.
+//
+// fd is the filesystem context to act upon.
+// key the parameter key to set.
+func FsconfigSetFlag(fd int, key string) error {
+	return fsconfigCommon(fd, FSCONFIG_SET_FLAG, key, nil, 0)
+}
+
+// FsconfigSetString is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_SET_STRING.
+//
+// fd is the filesystem context to act upon.
+// key the parameter key to set.
+// value is the parameter value to set.
+func FsconfigSetString(fd int, key string, value string) error {
+	valuep, err := BytePtrFromString(value)
+	if err != nil {
+		return err
+	}
+	return fsconfigCommon(fd, FSCONFIG_SET_STRING, key, valuep, 0)
+}
+
+// FsconfigSetBinary is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_SET_BINARY.
+//
+// fd is the filesystem context to act upon.
+// key the parameter key to set.
+// value is the parameter value to set.
+func FsconfigSetBinary(fd int, key string, value []byte) error {
+	if len(value) == 0 {
+		return EINVAL
+	}
+	return fsconfigCommon(fd, FSCONFIG_SET_BINARY, key, &value[0], len(value))
+}
+
+// FsconfigSetPath is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_SET_PATH.
+//
+// fd is the filesystem context to act upon.
+// key the parameter key to set.
+// path is a non-empty path for specified key.
+// atfd is a file descriptor at which to start lookup from or AT_FDCWD.
+func FsconfigSetPath(fd int, key string, path string, atfd int) error {
+	pathp, err := BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	return fsconfigCommon(fd, FSCONFIG_SET_PATH, key, pathp, atfd)
+}
+
+// FsconfigSetPathEmpty is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_SET_PATH_EMPTY. The same as
+// FsconfigSetPath but with AT_EMPTY_PATH implied.
+func FsconfigSetPathEmpty(fd int, key string, path string, atfd int) error {
+	pathp, err := BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	return fsconfigCommon(fd, FSCONFIG_SET_PATH_EMPTY, key, pathp, atfd)
+}
+
+// FsconfigSetFd is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_SET_FD.
+//
+// fd is the filesystem context to act upon.
+// key the parameter key to set.
+// value is a file descriptor to be assigned to specified key.
+func FsconfigSetFd(fd int, key string, value int) error {
+	return fsconfigCommon(fd, FSCONFIG_SET_FD, key, nil, value)
+}
+
+// FsconfigCreate is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_CMD_CREATE.
+//
+// fd is the filesystem context to act upon.
+func FsconfigCreate(fd int) error {
+	return fsconfig(fd, FSCONFIG_CMD_CREATE, nil, nil, 0)
+}
+
+// FsconfigReconfigure is equivalent to fsconfig(2) called
+// with cmd == FSCONFIG_CMD_RECONFIGURE.
+//
+// fd is the filesystem context to act upon.
+func FsconfigReconfigure(fd int) error {
+	return fsconfig(fd, FSCONFIG_CMD_RECONFIGURE, nil, nil, 0)
+}
+
 func Sendfile(outfd int, infd int, offset *int64, count int) (written int, err error) {
 	if raceenabled {
 		raceReleaseMerge(unsafe.Pointer(&ioSync))
@@ -1838,6 +1939,7 @@ func Dup2(oldfd, newfd int) error {
 //sys	Fsmount(fd int, flags int, mountAttrs int) (fsfd int, err error)
 //sys	Fsopen(fsName string, flags int) (fd int, err error)
 //sys	Fspick(dirfd int, pathName string, flags int) (fd int, err error)
+//sys	fsconfig(fd int, cmd uint, key *byte, value *byte, aux int) (err error)
 //sys	Getdents(fd int, buf []byte) (n int, err error) = SYS_GETDENTS64
 //sysnb	Getpgid(pid int) (pgid int, err error)
 
@@ -2105,11 +2207,15 @@ func writevRacedetect(iovecs []Iovec, n int) {
 
 // mmap varies by architecture; see syscall_linux_*.go.
 //sys	munmap(addr uintptr, length uintptr) (err error)
+//sys	mremap(oldaddr uintptr, oldlength uintptr, newlength uintptr, flags int, newaddr uintptr) (xaddr uintptr, err error)
 
-var mapper = &mmapper{
-	active: make(map[*byte][]byte),
-	mmap:   mmap,
-	munmap: munmap,
+var mapper = &mremapMmapper{
+	mmapper: mmapper{
+		active: make(map[*byte][]byte),
+		mmap:   mmap,
+		munmap: munmap,
+	},
+	mremap: mremap,
 }
 
 func Mmap(fd int, offset int64, length int, prot int, flags int) (data []byte, err error) {
@@ -2336,9 +2442,9 @@ func Klogset(typ int, arg int) (err error) {
 }
 
 // RemoteIovec is Iovec with the pointer replaced with an integer.
-// It is used for ProcessVMReadv and ProcessVMWritev, where the pointer
-// refers to a location in a different process' address space, which
-// would confuse the Go garbage collector.
+// It is used for ProcessVMReadv, ProcessVMWritev and ProcessMadvise,
+// where the pointer refers to a location in a different process' address
+// space, which would confuse the Go garbage collector.
 type RemoteIovec struct {
 	Base uintptr
 	Len  int
@@ -2346,11 +2452,75 @@ type RemoteIovec struct {
 
 //sys	ProcessVMReadv(pid int, localIov []Iovec, remoteIov []RemoteIovec, flags uint) (n int, err error) = SYS_PROCESS_VM_READV
 //sys	ProcessVMWritev(pid int, localIov []Iovec, remoteIov []RemoteIovec, flags uint) (n int, err error) = SYS_PROCESS_VM_WRITEV
+//sys	ProcessMadvise(pidfd int, iovs []RemoteIovec, advice int, flags uint) (n int, err error) = SYS_PROCESS_MADVISE
+//sys	ProcessMrelease(pidfd int, flags uint) (err error) = SYS_PROCESS_MRELEASE
 
 //sys	PidfdOpen(pid int, flags int) (fd int, err error) = SYS_PIDFD_OPEN
 //sys	PidfdGetfd(pidfd int, targetfd int, flags int) (fd int, err error) = SYS_PIDFD_GETFD
 //sys	PidfdSendSignal(pidfd int, sig Signal, info *Siginfo, flags int) (err error) = SYS_PIDFD_SEND_SIGNAL
 
+//sys	futex(addr *uint32, op int, val uint32, timeout *Timespec, addr2 *uint32, val3 uint32) (n int, err error) = SYS_FUTEX
+//sys	futexVal2(addr *uint32, op int, val uint32, val2 uintptr, addr2 *uint32, val3 uint32) (n int, err error) = SYS_FUTEX
+//sys	futexWaitv(waiters *FutexWaitv, nrFutexes int, flags int, timeout *kernelTimespec, clockid int) (n int, err error) = SYS_FUTEX_WAITV
+
+//sys	Userfaultfd(flags int) (fd int, err error) = SYS_USERFAULTFD
+
+//sys	SchedSetscheduler(pid int, policy int, param *SchedParam) (err error) = SYS_SCHED_SETSCHEDULER
+//sys	SchedGetscheduler(pid int) (policy int, err error) = SYS_SCHED_GETSCHEDULER
+//sys	SchedSetparam(pid int, param *SchedParam) (err error) = SYS_SCHED_SETPARAM
+//sys	SchedGetparam(pid int, param *SchedParam) (err error) = SYS_SCHED_GETPARAM
+//sys	SchedGetPriorityMax(policy int) (prio int, err error) = SYS_SCHED_GET_PRIORITY_MAX
+//sys	SchedGetPriorityMin(policy int) (prio int, err error) = SYS_SCHED_GET_PRIORITY_MIN
+//sys	schedSetattr(pid int, attr *SchedAttr, flags uint) (err error) = SYS_SCHED_SETATTR
+//sys	schedGetattr(pid int, attr *SchedAttr, size uint, flags uint) (err error) = SYS_SCHED_GETATTR
+
+// SchedSetattr sets the scheduling policy and attributes of the thread pid,
+// or of the calling thread if pid is 0 (sched_setattr(2)). The policy applies
+// to a single thread, so a goroutine setting its own policy should be locked
+// to its thread with runtime.LockOSThread. The Size field of attr is set by
+// SchedSetattr. Flags must be 0.
+//
+// SchedAttr has no latency nice field: the latency nice proposal was never
+// merged into the kernel, where the EEVDF scheduler (Linux 6.6) superseded
+// it, and struct sched_attr has no such field.
+func SchedSetattr(pid int, attr *SchedAttr, flags uint) error {
+	if attr == nil {
+		return EINVAL
+	}
+	attr.Size = SizeofSchedAttr
+	return schedSetattr(pid, attr, flags)
+}
+
+// SchedGetattr returns the scheduling policy and attributes of the thread
+// pid, or of the calling thread if pid is 0 (sched_getattr(2)). Flags must
+// be 0.
+func SchedGetattr(pid int, flags uint) (*SchedAttr, error) {
+	attr := &SchedAttr{}
+	if err := schedGetattr(pid, attr, SizeofSchedAttr, flags); err != nil {
+		return nil, err
+	}
+	return attr, nil
+}
+
+//sys	getcpu(cpu *uint32, node *uint32) (err error) = SYS_GETCPU
+
+//sys	mbind(addr uintptr, length uintptr, mode int, nodemask *cpuMask, maxnode uintptr, flags int) (err error) = SYS_MBIND
+//sys	setMempolicy(mode int, nodemask *cpuMask, maxnode uintptr) (err error) = SYS_SET_MEMPOLICY
+//sys	getMempolicy(mode *int32, nodemask *cpuMask, maxnode uintptr, addr uintptr, flags int) (err error) = SYS_GET_MEMPOLICY
+//sys	migratePages(pid int, maxnode uintptr, oldNodes *cpuMask, newNodes *cpuMask) (n int, err error) = SYS_MIGRATE_PAGES
+//sys	movePages(pid int, count uintptr, pages *uintptr, nodes *int32, status *int32, flags int) (err error) = SYS_MOVE_PAGES
+
+// Getcpu returns the CPU and NUMA node on which the calling thread is
+// running (getcpu(2)). The result may be outdated as soon as it is returned,
+// unless the thread's CPU affinity is restricted to a single CPU.
+func Getcpu() (cpu, node int, err error) {
+	var c, n uint32
+	if err := getcpu(&c, &n); err != nil {
+		return -1, -1, err
+	}
+	return int(c), int(n), nil
+}
+
 //sys	shmat(id int, addr uintptr, flag int) (ret uintptr, err error)
 //sys	shmctl(id int, cmd int, buf *SysvShmDesc) (result int, err error)
 //sys	shmdt(addr uintptr) (err error)
@@ -2412,6 +2582,216 @@ func PthreadSigmask(how int, set, oldset *Sigset_t) error {
 	return rtSigprocmask(how, set, oldset, _C__NSIG/8)
 }
 
+// blockSignals locks the calling goroutine to its thread and blocks all
+// signals in the thread, which is then cloned into a child process with a
+// raw clone or clone3 system call. The child runs without the Go runtime,
+// so it must only make raw system calls and must not grow the stack, and
+// no signal handler of the Go runtime may run in it. The parent calls
+// restoreSignals with the returned signal mask after cloning.
+func blockSignals() (old Sigset_t) {
+	runtime.LockOSThread()
+	var all Sigset_t
+	for i := range all.Val {
+		all.Val[i] = ^all.Val[i]
+	}
+	// This cannot fail with valid arguments.
+	PthreadSigmask(SIG_SETMASK, &all, &old)
+	return old
+}
+
+// restoreSignals restores the signal mask old returned by blockSignals and
+// unlocks the calling goroutine from its thread.
+func restoreSignals(old *Sigset_t) {
+	PthreadSigmask(SIG_SETMASK, old, nil)
+	runtime.UnlockOSThread()
+}
+
+//sys	IoUringSetup(entries uint32, params *IoUringParams) (fd int, err error) = SYS_IO_URING_SETUP
+//sys	ioUringEnter(fd int, toSubmit uint32, minComplete uint32, flags uint32, arg unsafe.Pointer, argsz uintptr) (n int, err error) = SYS_IO_URING_ENTER
+
+// IoUringRegister wraps io_uring_register(2). It performs the registration
+// operation opcode, such as IORING_REGISTER_BUFFERS, on the io_uring
+// instance fd, with the nrArgs arguments that arg points to.
+//sys	IoUringRegister(fd int, opcode uint32, arg unsafe.Pointer, nrArgs uint32) (n int, err error) = SYS_IO_URING_REGISTER
+
+// IoUringEnter wraps io_uring_enter(2). It submits up to toSubmit entries
+// from the submission queue of the io_uring instance fd and, if flags
+// contains IORING_ENTER_GETEVENTS, waits for at least minComplete
+// completions. If sigset is not nil, it replaces the signal mask for the
+// duration of the call.
+func IoUringEnter(fd int, toSubmit, minComplete, flags uint32, sigset *Sigset_t) (n int, err error) {
+	var argsz uintptr
+	if sigset != nil {
+		argsz = _C__NSIG / 8
+	}
+	return ioUringEnter(fd, toSubmit, minComplete, flags, unsafe.Pointer(sigset), argsz)
+}
+
+//sys	landlockCreateRuleset(attr *LandlockRulesetAttr, size uintptr, flags int) (fd int, err error) = SYS_LANDLOCK_CREATE_RULESET
+//sys	landlockAddRule(rulesetFd int, ruleType int, ruleAttr unsafe.Pointer, flags int) (err error) = SYS_LANDLOCK_ADD_RULE
+//sys	LandlockRestrictSelf(rulesetFd int, flags int) (err error) = SYS_LANDLOCK_RESTRICT_SELF
+
+// LandlockCreateRuleset wraps landlock_create_ruleset(2) and returns a file
+// descriptor for a new ruleset handling the accesses set in attr.
+func LandlockCreateRuleset(attr *LandlockRulesetAttr, flags int) (fd int, err error) {
+	return landlockCreateRuleset(attr, unsafe.Sizeof(*attr), flags)
+}
+
+// LandlockGetABIVersion returns the highest Landlock ABI version supported
+// by the running kernel. It returns EOPNOTSUPP if Landlock is supported but
+// disabled, and ENOSYS if it is not supported at all.
+func LandlockGetABIVersion() (int, error) {
+	return landlockCreateRuleset(nil, 0, LANDLOCK_CREATE_RULESET_VERSION)
+}
+
+// LandlockAddPathBeneathRule adds a LANDLOCK_RULE_PATH_BENEATH rule to the
+// ruleset rulesetFd.
+func LandlockAddPathBeneathRule(rulesetFd int, attr *LandlockPathBeneathAttr, flags int) error {
+	return landlockAddRule(rulesetFd, LANDLOCK_RULE_PATH_BENEATH, unsafe.Pointer(attr), flags)
+}
+
+// LandlockAddNetPortRule adds a LANDLOCK_RULE_NET_PORT rule to the ruleset
+// rulesetFd.
+func LandlockAddNetPortRule(rulesetFd int, attr *LandlockNetPortAttr, flags int) error {
+	return landlockAddRule(rulesetFd, LANDLOCK_RULE_NET_PORT, unsafe.Pointer(attr), flags)
+}
+
+//sys	seccomp(op int, flags int, args unsafe.Pointer) (ret int, err error) = SYS_SECCOMP
+
+// SeccompSetModeFilter installs the seccomp-BPF program prog for the
+// calling thread using seccomp(2) with SECCOMP_SET_MODE_FILTER. flags is a
+// combination of SECCOMP_FILTER_FLAG_* values. If flags contains
+// SECCOMP_FILTER_FLAG_NEW_LISTENER, the returned fd is a seccomp user
+// notification file descriptor; otherwise it is 0.
+//
+// Unless the caller has CAP_SYS_ADMIN, PR_SET_NO_NEW_PRIVS must have been set
+// on the calling thread.
+func SeccompSetModeFilter(prog *SockFprog, flags int) (fd int, err error) {
+	return seccomp(SECCOMP_SET_MODE_FILTER, flags, unsafe.Pointer(prog))
+}
+
+// SeccompGetNotifSizes returns the sizes of the seccomp user notification
+// structures used by the running kernel.
+func SeccompGetNotifSizes() (*SeccompNotifSizes, error) {
+	var sizes SeccompNotifSizes
+	if _, err := seccomp(SECCOMP_GET_NOTIF_SIZES, 0, unsafe.Pointer(&sizes)); err != nil {
+		return nil, err
+	}
+	return &sizes, nil
+}
+
+// SeccompGetActionAvail reports whether the running kernel supports the
+// seccomp filter return action, one of the SECCOMP_RET_* values. It returns
+// nil if the action is supported and EOPNOTSUPP if it is not.
+func SeccompGetActionAvail(action uint32) error {
+	_, err := seccomp(SECCOMP_GET_ACTION_AVAIL, 0, unsafe.Pointer(&action))
+	return err
+}
+
+//sys	bpf(cmd int, attr unsafe.Pointer, size uintptr) (r int, err error) = SYS_BPF
+
+// BpfMapCreate creates a BPF map using bpf(2) with BPF_MAP_CREATE and
+// returns its file descriptor.
+func BpfMapCreate(attr *BpfMapCreateAttr) (fd int, err error) {
+	return bpf(BPF_MAP_CREATE, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+}
+
+// BpfMapLookupElem copies the value of the element at attr.Key in the map
+// attr.Map_fd to attr.Value using bpf(2) with BPF_MAP_LOOKUP_ELEM.
+func BpfMapLookupElem(attr *BpfMapElemAttr) error {
+	_, err := bpf(BPF_MAP_LOOKUP_ELEM, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfMapUpdateElem creates or updates the element at attr.Key in the map
+// attr.Map_fd using bpf(2) with BPF_MAP_UPDATE_ELEM. attr.Flags is one of
+// BPF_ANY, BPF_NOEXIST or BPF_EXIST, optionally with BPF_F_LOCK.
+func BpfMapUpdateElem(attr *BpfMapElemAttr) error {
+	_, err := bpf(BPF_MAP_UPDATE_ELEM, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfMapDeleteElem deletes the element at attr.Key from the map attr.Map_fd
+// using bpf(2) with BPF_MAP_DELETE_ELEM.
+func BpfMapDeleteElem(attr *BpfMapElemAttr) error {
+	_, err := bpf(BPF_MAP_DELETE_ELEM, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfMapGetNextKey copies the key following attr.Key in the map attr.Map_fd
+// to attr.Value, which holds the next_key member of the union, using
+// bpf(2) with BPF_MAP_GET_NEXT_KEY. If attr.Key is 0 or not found, the
+// first key is returned. ENOENT is returned after the last key.
+func BpfMapGetNextKey(attr *BpfMapElemAttr) error {
+	_, err := bpf(BPF_MAP_GET_NEXT_KEY, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfProgLoad loads a BPF program using bpf(2) with BPF_PROG_LOAD and
+// returns its file descriptor. If attr.Log_level is not 0, the verifier log
+// is written to attr.Log_buf.
+func BpfProgLoad(attr *BpfProgLoadAttr) (fd int, err error) {
+	return bpf(BPF_PROG_LOAD, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+}
+
+// BpfObjPin pins the BPF object attr.Bpf_fd to the path attr.Pathname on a
+// bpf filesystem using bpf(2) with BPF_OBJ_PIN.
+func BpfObjPin(attr *BpfObjAttr) error {
+	_, err := bpf(BPF_OBJ_PIN, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfObjGet opens the BPF object pinned at attr.Pathname using bpf(2) with
+// BPF_OBJ_GET and returns its file descriptor.
+func BpfObjGet(attr *BpfObjAttr) (fd int, err error) {
+	return bpf(BPF_OBJ_GET, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+}
+
+// BpfProgAttach attaches the program attr.Attach_bpf_fd to attr.Target_fd,
+// such as a cgroup directory, using bpf(2) with BPF_PROG_ATTACH.
+func BpfProgAttach(attr *BpfProgAttachAttr) error {
+	_, err := bpf(BPF_PROG_ATTACH, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfProgDetach detaches the program attr.Attach_bpf_fd from
+// attr.Target_fd using bpf(2) with BPF_PROG_DETACH.
+func BpfProgDetach(attr *BpfProgAttachAttr) error {
+	_, err := bpf(BPF_PROG_DETACH, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfProgTestRun runs the program attr.Prog_fd on the input attr.Data_in
+// using bpf(2) with BPF_PROG_TEST_RUN.
+func BpfProgTestRun(attr *BpfProgTestRunAttr) error {
+	_, err := bpf(BPF_PROG_TEST_RUN, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfObjGetInfoByFd copies information about the BPF object attr.Bpf_fd,
+// such as a BpfMapInfo, to attr.Info using bpf(2) with
+// BPF_OBJ_GET_INFO_BY_FD.
+func BpfObjGetInfoByFd(attr *BpfObjGetInfoAttr) error {
+	_, err := bpf(BPF_OBJ_GET_INFO_BY_FD, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	return err
+}
+
+// BpfLinkCreate attaches the program attr.Prog_fd to attr.Target_fd using
+// bpf(2) with BPF_LINK_CREATE and returns the file descriptor of the
+// resulting link. The program is detached when the last reference to the
+// link is closed, unless the link is pinned.
+func BpfLinkCreate(attr *BpfLinkCreateAttr) (fd int, err error) {
+	return bpf(BPF_LINK_CREATE, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+}
+
+// BpfLinkDetach detaches the link linkFd from its target without closing it
+// using bpf(2) with BPF_LINK_DETACH.
+func BpfLinkDetach(linkFd int) error {
+	attr := struct{ fd uint32 }{uint32(linkFd)}
+	_, err := bpf(BPF_LINK_DETACH, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
+
 /*
  * Unimplemented
  */
@@ -2426,9 +2806,7 @@ func PthreadSigmask(how int, set, oldset *Sigset_t) error {
 // EpollWaitOld
 // Execve
 // Fork
-// Futex
 // GetKernelSyms
-// GetMempolicy
 // GetRobustList
 // GetThreadArea
 // Getpmsg
@@ -2441,19 +2819,15 @@ func PthreadSigmask(how int, set, oldset *Sigset_t) error {
 // IoprioSet
 // KexecLoad
 // LookupDcookie
-// Mbind
-// MigratePages
 // Mincore
 // ModifyLdt
 // Mount
-// MovePages
 // MqGetsetattr
 // MqNotify
 // MqOpen
 // MqTimedreceive
 // MqTimedsend
 // MqUnlink
-// Mremap
 // Msgctl
 // Msgget
 // Msgrcv
@@ -2474,19 +2848,13 @@ func PthreadSigmask(how int, set, oldset *Sigset_t) error {
 // RtSigreturn
 // RtSigsuspend
 // RtSigtimedwait
-// SchedGetPriorityMax
-// SchedGetPriorityMin
-// SchedGetparam
-// SchedGetscheduler
 // SchedRrGetInterval
-// SchedSetparam
 // SchedYield
 // Security
 // Semctl
 // Semget
 // Semop
 // Semtimedop
-// SetMempolicy
 // SetRobustList
 // SetThreadArea
 // SetTidAddress
@@ -1173,3 +1173,237 @@ func TestReadvAllocate(t *testing.T) {
 		unix.Preadv2(fd, iovs, 0, 0)
 	})
 }
+
+// inThrowawayThread runs f in a goroutine locked to a thread that exits
+// afterwards, so that f can change the scheduling policy of its thread.
+func inThrowawayThread(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runtime.LockOSThread()
+		f()
+	}()
+	<-done
+}
+
+func TestSchedSetattr(t *testing.T) {
+	if err := unix.SchedSetattr(0, nil, 0); err != unix.EINVAL {
+		t.Errorf("SchedSetattr(nil): got %v, want %v", err, unix.EINVAL)
+	}
+	attr, err := unix.SchedGetattr(0, 0)
+	if err == unix.ENOSYS {
+		t.Skip("sched_getattr not supported")
+	}
+	if err != nil {
+		t.Fatalf("SchedGetattr: %v", err)
+	}
+	if attr.Size != unix.SizeofSchedAttr {
+		t.Errorf("SchedGetattr: got size %d, want %d", attr.Size, unix.SizeofSchedAttr)
+	}
+
+	inThrowawayThread(func() {
+		want := unix.SchedAttr{Policy: unix.SCHED_BATCH, Nice: attr.Nice + 1}
+		if err := unix.SchedSetattr(0, &want, 0); err != nil {
+			t.Errorf("SchedSetattr: %v", err)
+			return
+		}
+		got, err := unix.SchedGetattr(0, 0)
+		if err != nil {
+			t.Errorf("SchedGetattr: %v", err)
+			return
+		}
+		if got.Policy != want.Policy || got.Nice != want.Nice {
+			t.Errorf("SchedGetattr: got %+v, want %+v", got, want)
+		}
+		if prio, err := unix.Getpriority(unix.PRIO_PROCESS, 0); err != nil || 20-prio != int(want.Nice) {
+			t.Errorf("Getpriority: got %d, %v, want nice %d", prio, err, want.Nice)
+		}
+
+		clamp := unix.SchedAttr{
+			Flags:    unix.SCHED_FLAG_KEEP_ALL | unix.SCHED_FLAG_UTIL_CLAMP,
+			Util_min: 128,
+			Util_max: 512,
+		}
+		switch err := unix.SchedSetattr(0, &clamp, 0); err {
+		case nil:
+			got, err := unix.SchedGetattr(0, 0)
+			if err != nil || got.Policy != unix.SCHED_BATCH || got.Util_min != 128 || got.Util_max != 512 {
+				t.Errorf("SchedGetattr after clamping: got %+v, %v", got, err)
+			}
+		case unix.EOPNOTSUPP, unix.EINVAL:
+			t.Logf("utilization clamping not supported: %v", err)
+		default:
+			t.Errorf("SchedSetattr with SCHED_FLAG_UTIL_CLAMP: %v", err)
+		}
+	})
+
+	inThrowawayThread(func() {
+		dl := unix.SchedAttr{
+			Policy:   unix.SCHED_DEADLINE,
+			Runtime:  uint64(time.Millisecond),
+			Deadline: uint64(10 * time.Millisecond),
+			Period:   uint64(10 * time.Millisecond),
+		}
+		if err := unix.SchedSetattr(0, &dl, 0); err != nil {
+			t.Logf("SchedSetattr with SCHED_DEADLINE: %v", err)
+			return
+		}
+		got, err := unix.SchedGetattr(0, 0)
+		if err != nil || got.Policy != unix.SCHED_DEADLINE || got.Runtime != dl.Runtime || got.Period != dl.Period {
+			t.Errorf("SchedGetattr with SCHED_DEADLINE: got %+v, %v", got, err)
+		}
+	})
+}
+
+func TestSchedSetscheduler(t *testing.T) {
+	min, err := unix.SchedGetPriorityMin(unix.SCHED_FIFO)
+	if err != nil {
+		t.Fatalf("SchedGetPriorityMin: %v", err)
+	}
+	max, err := unix.SchedGetPriorityMax(unix.SCHED_FIFO)
+	if err != nil {
+		t.Fatalf("SchedGetPriorityMax: %v", err)
+	}
+	if min != 1 || max != 99 {
+		t.Errorf("got SCHED_FIFO priorities %d-%d, want 1-99", min, max)
+	}
+	if policy, err := unix.SchedGetscheduler(0); err != nil || policy != unix.SCHED_NORMAL {
+		t.Errorf("SchedGetscheduler: got %d, %v, want %d", policy, err, unix.SCHED_NORMAL)
+	}
+
+	inThrowawayThread(func() {
+		err := unix.SchedSetscheduler(0, unix.SCHED_FIFO|unix.SCHED_RESET_ON_FORK, &unix.SchedParam{Priority: int32(min)})
+		if err == unix.EPERM {
+			t.Log("SchedSetscheduler: not permitted")
+			return
+		}
+		if err != nil {
+			t.Errorf("SchedSetscheduler: %v", err)
+			return
+		}
+		if policy, err := unix.SchedGetscheduler(0); err != nil || policy != unix.SCHED_FIFO|unix.SCHED_RESET_ON_FORK {
+			t.Errorf("SchedGetscheduler: got %#x, %v", policy, err)
+		}
+		if err := unix.SchedSetparam(0, &unix.SchedParam{Priority: int32(min + 1)}); err != nil {
+			t.Errorf("SchedSetparam: %v", err)
+		}
+		var param unix.SchedParam
+		if err := unix.SchedGetparam(0, &param); err != nil || param.Priority != int32(min+1) {
+			t.Errorf("SchedGetparam: got %+v, %v", param, err)
+		}
+	})
+}
+
+func TestGetcpu(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	cpu, node, err := unix.Getcpu()
+	if err != nil {
+		t.Fatalf("Getcpu: %v", err)
+	}
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &set); err != nil {
+		t.Fatal(err)
+	}
+	if !set.IsSet(cpu) || node < 0 {
+		t.Errorf("Getcpu: got CPU %d, node %d, with affinity %v", cpu, node, set)
+	}
+}
+
+func TestDynamicCPUSet(t *testing.T) {
+	s, err := unix.ParseCPUList("0-3,8,10-11,2000\n")
+	if err != nil {
+		t.Fatalf("ParseCPUList: %v", err)
+	}
+	if got, want := s.String(), "0-3,8,10-11,2000"; got != want {
+		t.Errorf("String: got %q, want %q", got, want)
+	}
+	if s.Count() != 8 || !s.IsSet(2000) || s.IsSet(1999) {
+		t.Errorf("got CPUs %v", s.CPUs())
+	}
+	if got, want := s.Mask(), "10000,00000000,"; !strings.HasPrefix(got, want) || !strings.HasSuffix(got, ",00000d0f") {
+		t.Errorf("Mask: got %q, want %q...,00000d0f", got, want)
+	}
+	m, err := unix.ParseCPUMask(s.Mask())
+	if err != nil || !m.Equal(s) {
+		t.Errorf("ParseCPUMask(%q): got %v, %v", s.Mask(), m, err)
+	}
+	if m, err := unix.ParseCPUMask("ff,0000000f"); err != nil || m.String() != "0-3,32-39" {
+		t.Errorf("ParseCPUMask: got %v, %v", m, err)
+	}
+	if s, err := unix.ParseCPUList("0-15:2/4"); err != nil || s.String() != "0-1,4-5,8-9,12-13" {
+		t.Errorf("ParseCPUList with stride: got %v, %v", s, err)
+	}
+	for _, bad := range []string{"1-", "3-1", "a", "0-3:2", "0-3:5/4", ",", "-1"} {
+		if _, err := unix.ParseCPUList(bad); err == nil {
+			t.Errorf("ParseCPUList(%q): no error", bad)
+		}
+	}
+
+	a, b := unix.NewDynamicCPUSet(1, 2, 100), unix.NewDynamicCPUSet(2, 3)
+	for _, tt := range []struct {
+		name string
+		got  *unix.DynamicCPUSet
+		want string
+	}{
+		{"And", a.And(b), "2"},
+		{"AndNot", a.AndNot(b), "1,100"},
+		{"Or", a.Or(b), "1-3,100"},
+		{"Xor", a.Xor(b), "1,3,100"},
+	} {
+		if got := tt.got.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	a.Clear(100)
+	if !a.Equal(unix.NewDynamicCPUSet(1, 2)) {
+		t.Errorf("Clear: got %v", a)
+	}
+	var empty unix.DynamicCPUSet
+	if empty.String() != "" || empty.Mask() != "0" || empty.Count() != 0 {
+		t.Errorf("got %q, %q for the empty set", empty.String(), empty.Mask())
+	}
+}
+
+func TestSchedGetaffinityDynamic(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var fixed unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &fixed); err != nil {
+		t.Fatal(err)
+	}
+	var set unix.DynamicCPUSet
+	if err := unix.SchedGetaffinityDynamic(0, &set); err != nil {
+		t.Fatalf("SchedGetaffinityDynamic: %v", err)
+	}
+	if !set.Equal(fixed.DynamicCPUSet()) {
+		t.Errorf("got %v, want %v", &set, fixed.DynamicCPUSet())
+	}
+
+	online, err := ioutil.ReadFile("/sys/devices/system/cpu/online")
+	if err == nil {
+		all, err := unix.ParseCPUList(string(online))
+		if err != nil {
+			t.Fatalf("ParseCPUList(%q): %v", online, err)
+		}
+		if set.AndNot(all).Count() != 0 {
+			t.Errorf("affinity %v not within online CPUs %v", &set, all)
+		}
+	}
+
+	if err := unix.SchedSetaffinityDynamic(0, &unix.DynamicCPUSet{}); err != unix.EINVAL {
+		t.Errorf("SchedSetaffinityDynamic with the empty set: got %v, want %v", err, unix.EINVAL)
+	}
+	cpus := set.CPUs()
+	one := unix.NewDynamicCPUSet(cpus[len(cpus)-1])
+	if err := unix.SchedSetaffinityDynamic(0, one); err != nil {
+		t.Fatalf("SchedSetaffinityDynamic: %v", err)
+	}
+	defer unix.SchedSetaffinityDynamic(0, &set)
+	var got unix.DynamicCPUSet
+	if err := unix.SchedGetaffinityDynamic(0, &got); err != nil || !got.Equal(one) {
+		t.Errorf("SchedGetaffinityDynamic after set: got %v, %v, want %v", &got, err, one)
+	}
+}
@@ -114,15 +114,19 @@ func (m *mmapper) Mmap(fd int, offset int64, length int, prot int, flags int) (d
 		return nil, errno
 	}
 
-	// Use unsafe to convert addr into a []byte.
-	b := unsafe.Slice((*byte)(unsafe.Pointer(addr)), length)
-
 	// Register mapping in m and return it.
-	p := &b[cap(b)-1]
 	m.Lock()
 	defer m.Unlock()
-	m.active[p] = b
-	return b, nil
+	return m.add(addr, length), nil
+}
+
+// add registers the mapping of length bytes at addr in m, which must be
+// locked, and returns it.
+func (m *mmapper) add(addr uintptr, length int) []byte {
+	// Use unsafe to convert addr into a []byte.
+	b := unsafe.Slice((*byte)(unsafe.Pointer(addr)), length)
+	m.active[&b[cap(b)-1]] = b
+	return b
 }
 
 func (m *mmapper) Munmap(data []byte) (err error) {
@@ -0,0 +1,188 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "unsafe"
+
+// A TaskstatsConn queries per-task and per-cgroup accounting from the
+// TASKSTATS generic netlink family. The delay accounting fields of
+// Taskstats, such as Cpu_delay_total, Blkio_delay_total,
+// Swapin_delay_total and Freepages_delay_total (memory reclaim), are only
+// maintained if delay accounting is enabled with the delayacct boot
+// parameter or the kernel.task_delayacct sysctl.
+type TaskstatsConn struct {
+	*GenlConn
+	family *GenlFamily
+}
+
+// NewTaskstatsConn opens a generic netlink socket and resolves the
+// TASKSTATS family.
+func NewTaskstatsConn() (*TaskstatsConn, error) {
+	c, err := NewGenlConn()
+	if err != nil {
+		return nil, err
+	}
+	f, err := c.Family(TASKSTATS_GENL_NAME)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &TaskstatsConn{GenlConn: c, family: f}, nil
+}
+
+func (c *TaskstatsConn) request(cmd uint8, attrs *NetlinkAttrEncoder) ([]GenlMessage, error) {
+	return c.Request(c.family.ID, cmd, TASKSTATS_VERSION, 0, nil, attrs)
+}
+
+// parseTaskstats copies the taskstats structure in b, which is shorter
+// than Taskstats if sent by an older kernel and longer if sent by a newer
+// one, and may be unaligned.
+func parseTaskstats(b []byte) *Taskstats {
+	ts := new(Taskstats)
+	copy(structBytes(unsafe.Pointer(ts), int(unsafe.Sizeof(*ts))), b)
+	return ts
+}
+
+// A TaskExitStats holds the accounting of an exited task or thread group,
+// as received after registering with RegisterExitCPUs.
+type TaskExitStats struct {
+	// TGID reports whether the statistics are those of a whole thread
+	// group (TASKSTATS_TYPE_AGGR_TGID), sent when its last thread exits.
+	TGID  bool
+	ID    uint32 // the PID or TGID
+	Stats *Taskstats
+}
+
+// parseTaskstatsReply parses the TASKSTATS_TYPE_AGGR_PID and
+// TASKSTATS_TYPE_AGGR_TGID attributes of a TASKSTATS_CMD_NEW message.
+func parseTaskstatsReply(m *GenlMessage) ([]TaskExitStats, error) {
+	var stats []TaskExitStats
+	d := m.Attrs(0)
+	for d.Next() {
+		if d.Type() != TASKSTATS_TYPE_AGGR_PID && d.Type() != TASKSTATS_TYPE_AGGR_TGID {
+			continue
+		}
+		s := TaskExitStats{TGID: d.Type() == TASKSTATS_TYPE_AGGR_TGID}
+		nd := d.Nested()
+		for nd.Next() {
+			switch nd.Type() {
+			case TASKSTATS_TYPE_PID, TASKSTATS_TYPE_TGID:
+				s.ID = nd.Uint32()
+			case TASKSTATS_TYPE_STATS:
+				s.Stats = parseTaskstats(nd.Value())
+			}
+		}
+		if err := nd.Err(); err != nil {
+			return nil, err
+		}
+		if s.Stats != nil {
+			stats = append(stats, s)
+		}
+	}
+	return stats, d.Err()
+}
+
+func (c *TaskstatsConn) get(attr uint16, id int) (*Taskstats, error) {
+	var e NetlinkAttrEncoder
+	e.Uint32(attr, uint32(id))
+	msgs, err := c.request(TASKSTATS_CMD_GET, &e)
+	if err != nil {
+		return nil, err
+	}
+	for i := range msgs {
+		stats, err := parseTaskstatsReply(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(stats) > 0 {
+			return stats[0].Stats, nil
+		}
+	}
+	return nil, EINVAL
+}
+
+// PID returns the accounting of the task with the given PID
+// (TASKSTATS_CMD_ATTR_PID), in the PID namespace of the socket.
+func (c *TaskstatsConn) PID(pid int) (*Taskstats, error) {
+	return c.get(TASKSTATS_CMD_ATTR_PID, pid)
+}
+
+// TGID returns the accounting summed over the threads of the thread group
+// with the given TGID (TASKSTATS_CMD_ATTR_TGID), including its exited
+// threads.
+func (c *TaskstatsConn) TGID(tgid int) (*Taskstats, error) {
+	return c.get(TASKSTATS_CMD_ATTR_TGID, tgid)
+}
+
+// CgroupStats returns the number of tasks in each state in the cgroup
+// whose directory is open as fd (CGROUPSTATS_CMD_GET).
+func (c *TaskstatsConn) CgroupStats(fd int) (*CGroupStats, error) {
+	var e NetlinkAttrEncoder
+	e.Uint32(CGROUPSTATS_CMD_ATTR_FD, uint32(fd))
+	msgs, err := c.request(CGROUPSTATS_CMD_GET, &e)
+	if err != nil {
+		return nil, err
+	}
+	for i := range msgs {
+		d := msgs[i].Attrs(0)
+		for d.Next() {
+			if d.Type() == CGROUPSTATS_TYPE_CGROUP_STATS {
+				cs := new(CGroupStats)
+				copy(structBytes(unsafe.Pointer(cs), int(unsafe.Sizeof(*cs))), d.Value())
+				return cs, nil
+			}
+		}
+		if err := d.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, EINVAL
+}
+
+// RegisterExitCPUs registers the socket to receive the accounting of the
+// tasks exiting on the given CPUs, in cpulist format such as "0-3,8"
+// (TASKSTATS_CMD_ATTR_REGISTER_CPUMASK). The statistics are returned by
+// ReceiveExits. The socket should not be used for other requests, since
+// the notifications are discarded while waiting for replies.
+func (c *TaskstatsConn) RegisterExitCPUs(cpulist string) error {
+	var e NetlinkAttrEncoder
+	e.String(TASKSTATS_CMD_ATTR_REGISTER_CPUMASK, cpulist)
+	_, err := c.request(TASKSTATS_CMD_GET, &e)
+	return err
+}
+
+// DeregisterExitCPUs stops the notifications for the given CPUs
+// (TASKSTATS_CMD_ATTR_DEREGISTER_CPUMASK).
+func (c *TaskstatsConn) DeregisterExitCPUs(cpulist string) error {
+	var e NetlinkAttrEncoder
+	e.String(TASKSTATS_CMD_ATTR_DEREGISTER_CPUMASK, cpulist)
+	_, err := c.request(TASKSTATS_CMD_GET, &e)
+	return err
+}
+
+// ReceiveExits waits for the accounting of exited tasks and returns the
+// statistics received in a single datagram. If the socket receive buffer
+// overflowed, it returns ENOBUFS and statistics have been lost.
+func (c *TaskstatsConn) ReceiveExits() ([]TaskExitStats, error) {
+	msgs, err := c.ReceiveGenl()
+	if err != nil {
+		return nil, err
+	}
+	var stats []TaskExitStats
+	for i := range msgs {
+		if msgs[i].Family != c.family.ID || msgs[i].Header.Cmd != TASKSTATS_CMD_NEW {
+			continue
+		}
+		s, err := parseTaskstatsReply(&msgs[i])
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s...)
+	}
+	return stats, nil
+}
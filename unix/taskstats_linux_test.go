@@ -0,0 +1,140 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func newTaskstatsConn(t *testing.T) *unix.TaskstatsConn {
+	c, err := unix.NewTaskstatsConn()
+	if err != nil {
+		t.Skipf("NewTaskstatsConn: %v", err)
+	}
+	return c
+}
+
+func TestTaskstats(t *testing.T) {
+	c := newTaskstatsConn(t)
+	defer c.Close()
+
+	// Keep the thread whose statistics are requested.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	pid, tid := unix.Getpid(), unix.Gettid()
+	ts, err := c.PID(tid)
+	if err != nil {
+		if errors.Is(err, unix.EPERM) {
+			t.Skip("taskstats requires CAP_NET_ADMIN")
+		}
+		t.Fatalf("PID: %v", err)
+	}
+	if ts.Version == 0 || int(ts.Ac_pid) != tid || int(ts.Ac_ppid) != unix.Getppid() {
+		t.Errorf("PID: got version %d, pid %d, ppid %d", ts.Version, ts.Ac_pid, ts.Ac_ppid)
+	}
+
+	ts, err = c.TGID(pid)
+	if err != nil {
+		t.Fatalf("TGID: %v", err)
+	}
+	// Only the CPU and delay fields are summed over the thread group.
+	if ts.Version == 0 || ts.Cpu_run_real_total == 0 {
+		t.Errorf("TGID: got version %d, run time %d", ts.Version, ts.Cpu_run_real_total)
+	}
+
+	if _, err := c.PID(0x7ffffff0); !errors.Is(err, unix.ESRCH) {
+		t.Errorf("PID of nonexistent task: got error %v, want ESRCH", err)
+	}
+}
+
+func TestCgroupStats(t *testing.T) {
+	c := newTaskstatsConn(t)
+	defer c.Close()
+
+	// The cgroup of the process is listed in /proc/self/cgroup as
+	// hierarchy-ID:controllers:path; try the hierarchies in turn.
+	b, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		dir := "/sys/fs/cgroup"
+		if fields[1] != "" {
+			dir += "/" + strings.TrimPrefix(strings.Split(fields[1], ",")[0], "name=")
+		}
+		fd, err := unix.Open(dir+fields[2], unix.O_RDONLY|unix.O_DIRECTORY, 0)
+		if err != nil {
+			continue
+		}
+		cs, err := c.CgroupStats(fd)
+		unix.Close(fd)
+		if err != nil {
+			t.Logf("CgroupStats(%s): %v", dir+fields[2], err)
+			continue
+		}
+		// The calling thread is running.
+		if cs.Running+cs.Sleeping == 0 {
+			t.Errorf("CgroupStats(%s): got %+v", dir+fields[2], cs)
+		}
+		return
+	}
+	t.Skip("no cgroup supports CGROUPSTATS_CMD_GET")
+}
+
+func TestTaskstatsExit(t *testing.T) {
+	c := newTaskstatsConn(t)
+	defer c.Close()
+
+	b, err := os.ReadFile("/sys/devices/system/cpu/online")
+	if err != nil {
+		t.Skip(err)
+	}
+	cpus := strings.TrimSpace(string(b))
+	if err := c.RegisterExitCPUs(cpus); err != nil {
+		t.Skipf("RegisterExitCPUs(%s): %v", cpus, err)
+	}
+	defer c.DeregisterExitCPUs(cpus)
+
+	tv := unix.NsecToTimeval(int64(5 * time.Second))
+	if err := unix.SetsockoptTimeval(c.Fd(), unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", "exit 3")
+	if err := cmd.Run(); err == nil {
+		t.Fatal("child did not fail")
+	}
+	pid := uint32(cmd.Process.Pid)
+	for {
+		stats, err := c.ReceiveExits()
+		if err != nil {
+			t.Fatalf("ReceiveExits: %v", err)
+		}
+		for _, s := range stats {
+			if s.ID != pid || s.TGID {
+				continue
+			}
+			if s.Stats.Ac_pid != pid || s.Stats.Ac_exitcode != 3<<8 {
+				t.Errorf("exit stats: got pid %d, exit code %#x", s.Stats.Ac_pid, s.Stats.Ac_exitcode)
+			}
+			return
+		}
+	}
+}
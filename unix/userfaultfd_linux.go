@@ -0,0 +1,276 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix
+
+import "unsafe"
+
+// OpenUserfaultfd creates a userfaultfd through /dev/userfaultfd with the
+// USERFAULTFD_IOC_NEW operation (kernel >= 6.1), which is permitted to the
+// users with access to the device rather than depending on the
+// vm.unprivileged_userfaultfd sysctl as Userfaultfd does. Flags may include
+// O_CLOEXEC, O_NONBLOCK and UFFD_USER_MODE_ONLY.
+func OpenUserfaultfd(flags int) (int, error) {
+	dev, err := Open("/dev/userfaultfd", O_RDWR|O_CLOEXEC, 0)
+	if err != nil {
+		return -1, err
+	}
+	defer Close(dev)
+	fd, _, e1 := Syscall(SYS_IOCTL, uintptr(dev), USERFAULTFD_IOC_NEW, uintptr(flags))
+	if e1 != 0 {
+		return -1, errnoErr(e1)
+	}
+	return int(fd), nil
+}
+
+// ReadUffdMsgs reads the pending messages of the userfaultfd fd into msgs,
+// and returns the number of messages read.
+func ReadUffdMsgs(fd int, msgs []UffdMsg) (int, error) {
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	b := unsafe.Slice((*byte)(unsafe.Pointer(&msgs[0])), len(msgs)*SizeofUffdMsg)
+	n, err := Read(fd, b)
+	if err != nil {
+		return 0, err
+	}
+	return n / SizeofUffdMsg, nil
+}
+
+// UffdPagefault is the argument of a UFFD_EVENT_PAGEFAULT message.
+type UffdPagefault struct {
+	Flags   uint64 // UFFD_PAGEFAULT_FLAG_*
+	Address uint64 // faulting address, rounded down to the page unless UFFD_FEATURE_EXACT_ADDRESS
+	Ptid    uint32 // thread ID of the faulting thread, with UFFD_FEATURE_THREAD_ID
+}
+
+// UffdRemap is the argument of a UFFD_EVENT_REMAP message, reporting that
+// the range From of length Len was moved to To by mremap(2).
+type UffdRemap struct {
+	From uint64
+	To   uint64
+	Len  uint64
+}
+
+// UffdRemove is the argument of a UFFD_EVENT_REMOVE or UFFD_EVENT_UNMAP
+// message, reporting that the range from Start to End was discarded by
+// madvise(2) or unmapped.
+type UffdRemove struct {
+	Start uint64
+	End   uint64
+}
+
+// Pagefault decodes the argument of a UFFD_EVENT_PAGEFAULT message.
+func (m *UffdMsg) Pagefault() UffdPagefault {
+	return UffdPagefault{
+		Flags:   nativeEndian.Uint64(m.Arg[0:]),
+		Address: nativeEndian.Uint64(m.Arg[8:]),
+		Ptid:    nativeEndian.Uint32(m.Arg[16:]),
+	}
+}
+
+// Fork decodes the argument of a UFFD_EVENT_FORK message: a new
+// userfaultfd for the memory of the child process, which must be closed by
+// the caller.
+func (m *UffdMsg) Fork() int {
+	return int(nativeEndian.Uint32(m.Arg[0:]))
+}
+
+// Remap decodes the argument of a UFFD_EVENT_REMAP message.
+func (m *UffdMsg) Remap() UffdRemap {
+	return UffdRemap{
+		From: nativeEndian.Uint64(m.Arg[0:]),
+		To:   nativeEndian.Uint64(m.Arg[8:]),
+		Len:  nativeEndian.Uint64(m.Arg[16:]),
+	}
+}
+
+// Remove decodes the argument of a UFFD_EVENT_REMOVE or UFFD_EVENT_UNMAP
+// message.
+func (m *UffdMsg) Remove() UffdRemove {
+	return UffdRemove{
+		Start: nativeEndian.Uint64(m.Arg[0:]),
+		End:   nativeEndian.Uint64(m.Arg[8:]),
+	}
+}
+
+// A UffdPageSource fills page with the contents of the page at the
+// page-aligned address addr, to resolve a page fault.
+type UffdPageSource func(addr uintptr, page []byte) error
+
+// A UffdHandler resolves the missing page faults of the memory ranges
+// registered with a userfaultfd with the pages of a UffdPageSource.
+type UffdHandler struct {
+	// Events, if not nil, is called with the messages other than missing
+	// page faults: those enabled by the UFFD_FEATURE_EVENT_* features, and
+	// the write-protect and minor faults of memory registered with
+	// UFFDIO_REGISTER_MODE_WP or UFFDIO_REGISTER_MODE_MINOR, which Events
+	// must resolve with IoctlUffdioWriteprotect or IoctlUffdioContinue.
+	// Otherwise, the userfaultfds of UFFD_EVENT_FORK messages are closed.
+	Events func(m *UffdMsg)
+
+	fd     int
+	source UffdPageSource
+	page   []byte
+	stop   int
+}
+
+// NewUffdHandler returns a UffdHandler for the userfaultfd fd, which must
+// have been set up with IoctlUffdioAPI and should have been created with
+// O_NONBLOCK. The handler does not take ownership of fd.
+func NewUffdHandler(fd int, source UffdPageSource) (*UffdHandler, error) {
+	stop, err := Eventfd(0, EFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	return &UffdHandler{
+		fd:     fd,
+		source: source,
+		page:   make([]byte, Getpagesize()),
+		stop:   stop,
+	}, nil
+}
+
+func (h *UffdHandler) pageRange(b []byte) (UffdioRange, error) {
+	r := UffdioRange{Len: uint64(len(b))}
+	if len(b) > 0 {
+		r.Start = uint64(uintptr(unsafe.Pointer(&b[0])))
+	}
+	mask := uint64(len(h.page) - 1)
+	if r.Start&mask != 0 || r.Len&mask != 0 || r.Len == 0 {
+		return r, EINVAL
+	}
+	return r, nil
+}
+
+// Register registers the page-aligned memory b, such as returned by Mmap,
+// with the userfaultfd for missing page faults (UFFDIO_REGISTER_MODE_MISSING),
+// and for the UFFDIO_REGISTER_MODE_* modes in mode, and returns the ioctls
+// supported for it as a bitmask, with bit n set for the ioctl with number
+// n, such as UFFDIO_COPY&0xff. Registering with UFFDIO_REGISTER_MODE_WP or
+// UFFDIO_REGISTER_MODE_MINOR requires Events to resolve those faults.
+func (h *UffdHandler) Register(b []byte, mode uint64) (uint64, error) {
+	r, err := h.pageRange(b)
+	if err != nil {
+		return 0, err
+	}
+	if mode&(UFFDIO_REGISTER_MODE_WP|UFFDIO_REGISTER_MODE_MINOR) != 0 && h.Events == nil {
+		return 0, EINVAL
+	}
+	reg := UffdioRegister{Range: r, Mode: UFFDIO_REGISTER_MODE_MISSING | mode}
+	if err := IoctlUffdioRegister(h.fd, &reg); err != nil {
+		return 0, err
+	}
+	return reg.Ioctls, nil
+}
+
+// Unregister unregisters the page-aligned memory b from the userfaultfd.
+func (h *UffdHandler) Unregister(b []byte) error {
+	r, err := h.pageRange(b)
+	if err != nil {
+		return err
+	}
+	return IoctlUffdioUnregister(h.fd, &r)
+}
+
+// Serve handles the messages of the userfaultfd until Stop is called, and
+// then returns nil. If the page source or resolving a fault fails, Serve
+// returns the error, and the faulting thread remains blocked until the
+// fault is resolved, the memory is unregistered or the userfaultfd is
+// closed.
+func (h *UffdHandler) Serve() error {
+	fds := []PollFd{
+		{Fd: int32(h.fd), Events: POLLIN},
+		{Fd: int32(h.stop), Events: POLLIN},
+	}
+	msgs := make([]UffdMsg, 16)
+	for {
+		_, err := Poll(fds, -1)
+		if err == EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if fds[1].Revents != 0 {
+			var b [8]byte
+			Read(h.stop, b[:])
+			return nil
+		}
+		if fds[0].Revents&(POLLERR|POLLHUP|POLLNVAL) != 0 {
+			return EBADF
+		}
+		if fds[0].Revents == 0 {
+			continue
+		}
+		n, err := ReadUffdMsgs(h.fd, msgs)
+		if err == EAGAIN || err == EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for i := range msgs[:n] {
+			if err := h.handle(&msgs[i]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (h *UffdHandler) handle(m *UffdMsg) error {
+	var pf UffdPagefault
+	if m.Event == UFFD_EVENT_PAGEFAULT {
+		pf = m.Pagefault()
+	}
+	if m.Event != UFFD_EVENT_PAGEFAULT || pf.Flags&(UFFD_PAGEFAULT_FLAG_WP|UFFD_PAGEFAULT_FLAG_MINOR) != 0 {
+		if h.Events != nil {
+			h.Events(m)
+		} else if m.Event == UFFD_EVENT_FORK {
+			Close(m.Fork())
+		}
+		return nil
+	}
+	size := uint64(len(h.page))
+	addr := pf.Address &^ (size - 1)
+	if err := h.source(uintptr(addr), h.page); err != nil {
+		return err
+	}
+	c := UffdioCopy{
+		Dst: addr,
+		Src: uint64(uintptr(unsafe.Pointer(&h.page[0]))),
+		Len: size,
+	}
+	switch err := IoctlUffdioCopy(h.fd, &c); err {
+	case nil:
+		return nil
+	case EEXIST:
+		// The page was mapped since the fault, such as by resolving a
+		// fault of another thread; wake the faulting thread.
+		return IoctlUffdioWake(h.fd, &UffdioRange{Start: addr, Len: size})
+	case EAGAIN, ENOENT:
+		// The memory layout changed; the thread faults again if the page
+		// is still missing.
+		return nil
+	default:
+		return err
+	}
+}
+
+// Stop makes Serve return.
+func (h *UffdHandler) Stop() error {
+	var b [8]byte
+	nativeEndian.PutUint64(b[:], 1)
+	_, err := Write(h.stop, b[:])
+	return err
+}
+
+// Close releases the resources of the handler, which must not be serving.
+// It does not close the userfaultfd.
+func (h *UffdHandler) Close() error {
+	return Close(h.stop)
+}
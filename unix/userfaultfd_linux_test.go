@@ -0,0 +1,216 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package unix_test
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func newUserfaultfd(t *testing.T, features uint64) int {
+	// Not UFFD_USER_MODE_ONLY: the faults are resolved in system calls.
+	flags := unix.O_CLOEXEC | unix.O_NONBLOCK
+	fd, err := unix.Userfaultfd(flags)
+	if err != nil {
+		fd, err = unix.OpenUserfaultfd(flags)
+	}
+	if err != nil {
+		t.Skipf("userfaultfd: %v", err)
+	}
+	t.Cleanup(func() { unix.Close(fd) })
+	api := unix.UffdioAPI{Api: unix.UFFD_API, Features: features}
+	if err := unix.IoctlUffdioAPI(fd, &api); err != nil {
+		t.Skipf("UFFDIO_API: %v", err)
+	}
+	return fd
+}
+
+// readThrough reads b through a pipe, so that the page faults happen in a
+// system call rather than in Go code.
+func readThrough(t *testing.T, b []byte) []byte {
+	var p [2]int
+	if err := unix.Pipe2(p[:], unix.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(p[0])
+	defer unix.Close(p[1])
+	if _, err := unix.Write(p[1], b); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	buf := make([]byte, len(b))
+	if _, err := unix.Read(p[0], buf); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return buf
+}
+
+func TestUffdHandler(t *testing.T) {
+	fd := newUserfaultfd(t, unix.UFFD_FEATURE_EVENT_REMOVE)
+	pagesize := unix.Getpagesize()
+	mem, err := unix.Mmap(-1, 0, 2*pagesize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Munmap(mem)
+	base := uintptr(unsafe.Pointer(&mem[0]))
+
+	h, err := unix.NewUffdHandler(fd, func(addr uintptr, page []byte) error {
+		for i := range page {
+			page[i] = byte('a' + (addr-base)/uintptr(pagesize))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	removed := make(chan unix.UffdRemove, 1)
+	h.Events = func(m *unix.UffdMsg) {
+		if m.Event == unix.UFFD_EVENT_REMOVE {
+			removed <- m.Remove()
+		}
+	}
+	if _, err := h.Register(mem[1:], 0); err != unix.EINVAL {
+		t.Errorf("Register unaligned: got %v, want %v", err, unix.EINVAL)
+	}
+	ioctls, err := h.Register(mem, 0)
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if ioctls&(1<<(unix.UFFDIO_COPY&0xff)) == 0 {
+		t.Errorf("Register: got ioctls %#x without UFFDIO_COPY", ioctls)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- h.Serve() }()
+
+	got := readThrough(t, mem)
+	want := append(bytes.Repeat([]byte{'a'}, pagesize), bytes.Repeat([]byte{'b'}, pagesize)...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %q..., want %q...", got[:4], want[:4])
+	}
+
+	if err := unix.Madvise(mem, unix.MADV_DONTNEED); err != nil {
+		t.Fatalf("Madvise: %v", err)
+	}
+	if r := <-removed; r.Start != uint64(base) || r.End != uint64(base)+uint64(len(mem)) {
+		t.Errorf("got %+v for range %#x-%#x", r, base, base+uintptr(len(mem)))
+	}
+	// A zero page resolves the fault without the page source.
+	z := unix.UffdioZeropage{Range: unix.UffdioRange{Start: uint64(base), Len: uint64(pagesize)}}
+	if err := unix.IoctlUffdioZeropage(fd, &z); err != nil {
+		t.Fatalf("UFFDIO_ZEROPAGE: %v", err)
+	}
+	if z.Zeropage != int64(pagesize) {
+		t.Errorf("UFFDIO_ZEROPAGE: got %d, want %d", z.Zeropage, pagesize)
+	}
+	if got := readThrough(t, mem[:pagesize]); !bytes.Equal(got, make([]byte, pagesize)) {
+		t.Errorf("got %q... after UFFDIO_ZEROPAGE", got[:4])
+	}
+
+	if err := h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("Serve: %v", err)
+	}
+	if err := h.Unregister(mem); err != nil {
+		t.Errorf("Unregister: %v", err)
+	}
+	if err := unix.Madvise(mem, unix.MADV_DONTNEED); err != nil {
+		t.Fatalf("Madvise: %v", err)
+	}
+	if got := readThrough(t, mem); !bytes.Equal(got, make([]byte, len(mem))) {
+		t.Errorf("got %q... after Unregister", got[:4])
+	}
+}
+
+func TestUffdHandlerWriteprotect(t *testing.T) {
+	fd := newUserfaultfd(t, unix.UFFD_FEATURE_PAGEFAULT_FLAG_WP)
+	pagesize := unix.Getpagesize()
+	mem, err := unix.Mmap(-1, 0, pagesize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Munmap(mem)
+	mem[0] = 'a'
+	wp := unix.UffdioRange{Start: uint64(uintptr(unsafe.Pointer(&mem[0]))), Len: uint64(pagesize)}
+
+	h, err := unix.NewUffdHandler(fd, func(addr uintptr, page []byte) error {
+		return unix.EFAULT
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if _, err := h.Register(mem, unix.UFFDIO_REGISTER_MODE_WP); err != unix.EINVAL {
+		t.Errorf("Register write-protect without Events: got %v, want %v", err, unix.EINVAL)
+	}
+	faults := make(chan unix.UffdPagefault, 1)
+	h.Events = func(m *unix.UffdMsg) {
+		if m.Event != unix.UFFD_EVENT_PAGEFAULT {
+			return
+		}
+		faults <- m.Pagefault()
+		unix.IoctlUffdioWriteprotect(fd, &unix.UffdioWriteprotect{Range: wp})
+	}
+	if _, err := h.Register(mem, unix.UFFDIO_REGISTER_MODE_WP); err != nil {
+		t.Skipf("Register write-protect: %v", err)
+	}
+	defer h.Unregister(mem)
+	if err := unix.IoctlUffdioWriteprotect(fd, &unix.UffdioWriteprotect{Range: wp, Mode: unix.UFFDIO_WRITEPROTECT_MODE_WP}); err != nil {
+		t.Fatalf("UFFDIO_WRITEPROTECT: %v", err)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- h.Serve() }()
+
+	// Write to the page through a pipe, so that the fault happens in a
+	// system call.
+	var p [2]int
+	if err := unix.Pipe2(p[:], unix.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(p[0])
+	defer unix.Close(p[1])
+	if _, err := unix.Write(p[1], []byte{'b'}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unix.Read(p[0], mem[:1]); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if f := <-faults; f.Flags&unix.UFFD_PAGEFAULT_FLAG_WP == 0 || f.Address != wp.Start {
+		t.Errorf("got fault %+v, want a write-protect fault at %#x", f, wp.Start)
+	}
+	if mem[0] != 'b' {
+		t.Errorf("got %q, want 'b'", mem[0])
+	}
+
+	if err := h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("Serve: %v", err)
+	}
+}
+
+func TestOpenUserfaultfd(t *testing.T) {
+	fd, err := unix.OpenUserfaultfd(unix.O_CLOEXEC | unix.UFFD_USER_MODE_ONLY)
+	if err != nil {
+		t.Skipf("OpenUserfaultfd: %v", err)
+	}
+	defer unix.Close(fd)
+	api := unix.UffdioAPI{Api: unix.UFFD_API}
+	if err := unix.IoctlUffdioAPI(fd, &api); err != nil {
+		t.Fatalf("UFFDIO_API: %v", err)
+	}
+	if api.Ioctls&(1<<(unix.UFFDIO_REGISTER&0xff)) == 0 {
+		t.Errorf("UFFDIO_API: got ioctls %#x without UFFDIO_REGISTER", api.Ioctls)
+	}
+}
@@ -442,6 +442,8 @@ const (
 	AUDIT_WATCH_LIST                            = 0x3f1
 	AUDIT_WATCH_REM                             = 0x3f0
 	AUTOFS_SUPER_MAGIC                          = 0x187
+	AUTONEG_DISABLE                             = 0x0
+	AUTONEG_ENABLE                              = 0x1
 	B0                                          = 0x0
 	B110                                        = 0x3
 	B1200                                       = 0x9
@@ -705,6 +707,12 @@ const (
 	CGROUP2_SUPER_MAGIC                         = 0x63677270
 	CGROUP_SUPER_MAGIC                          = 0x27e0eb
 	CIFS_SUPER_MAGIC                            = 0xff534d42
+	CLD_CONTINUED                               = 0x6
+	CLD_DUMPED                                  = 0x3
+	CLD_EXITED                                  = 0x1
+	CLD_KILLED                                  = 0x2
+	CLD_STOPPED                                 = 0x5
+	CLD_TRAPPED                                 = 0x4
 	CLOCK_BOOTTIME                              = 0x7
 	CLOCK_BOOTTIME_ALARM                        = 0x9
 	CLOCK_DEFAULT                               = 0x0
@@ -767,6 +775,7 @@ const (
 	CSTOP                                       = 0x13
 	CSUSP                                       = 0x1a
 	DAXFS_MAGIC                                 = 0x64646178
+	DCCPDIAG_GETSOCK                            = 0x13
 	DEBUGFS_MAGIC                               = 0x64626720
 	DEVLINK_CMD_ESWITCH_MODE_GET                = 0x1d
 	DEVLINK_CMD_ESWITCH_MODE_SET                = 0x1e
@@ -839,6 +848,9 @@ const (
 	DT_SOCK                                     = 0xc
 	DT_UNKNOWN                                  = 0x0
 	DT_WHT                                      = 0xe
+	DUPLEX_FULL                                 = 0x1
+	DUPLEX_HALF                                 = 0x0
+	DUPLEX_UNKNOWN                              = 0xff
 	ECHO                                        = 0x8
 	ECRYPTFS_SUPER_MAGIC                        = 0xf15f
 	EFD_SEMAPHORE                               = 0x1
@@ -1031,6 +1043,7 @@ const (
 	ETHTOOL_SUFO                                = 0x22
 	ETHTOOL_SWOL                                = 0x6
 	ETHTOOL_TEST                                = 0x1a
+	ETH_GSTRING_LEN                             = 0x20
 	ETH_P_1588                                  = 0x88f7
 	ETH_P_8021AD                                = 0x88a8
 	ETH_P_8021AH                                = 0x88e7
@@ -1309,7 +1322,61 @@ const (
 	FS_VERITY_METADATA_TYPE_MERKLE_TREE         = 0x1
 	FS_VERITY_METADATA_TYPE_SIGNATURE           = 0x3
 	FUSE_SUPER_MAGIC                            = 0x65735546
+	FUTEX2_PRIVATE                              = 0x80
+	FUTEX2_SIZE_MASK                            = 0x3
+	FUTEX2_SIZE_U16                             = 0x1
+	FUTEX2_SIZE_U32                             = 0x2
+	FUTEX2_SIZE_U64                             = 0x3
+	FUTEX2_SIZE_U8                              = 0x0
 	FUTEXFS_SUPER_MAGIC                         = 0xbad1dea
+	FUTEX_32                                    = 0x2
+	FUTEX_BITSET_MATCH_ANY                      = 0xffffffff
+	FUTEX_CLOCK_REALTIME                        = 0x100
+	FUTEX_CMD_MASK                              = -0x181
+	FUTEX_CMP_REQUEUE                           = 0x4
+	FUTEX_CMP_REQUEUE_PI                        = 0xc
+	FUTEX_CMP_REQUEUE_PI_PRIVATE                = 0x8c
+	FUTEX_CMP_REQUEUE_PRIVATE                   = 0x84
+	FUTEX_FD                                    = 0x2
+	FUTEX_LOCK_PI                               = 0x6
+	FUTEX_LOCK_PI2                              = 0xd
+	FUTEX_LOCK_PI2_PRIVATE                      = 0x8d
+	FUTEX_LOCK_PI_PRIVATE                       = 0x86
+	FUTEX_OP_ADD                                = 0x1
+	FUTEX_OP_ANDN                               = 0x3
+	FUTEX_OP_CMP_EQ                             = 0x0
+	FUTEX_OP_CMP_GE                             = 0x5
+	FUTEX_OP_CMP_GT                             = 0x4
+	FUTEX_OP_CMP_LE                             = 0x3
+	FUTEX_OP_CMP_LT                             = 0x2
+	FUTEX_OP_CMP_NE                             = 0x1
+	FUTEX_OP_OPARG_SHIFT                        = 0x8
+	FUTEX_OP_OR                                 = 0x2
+	FUTEX_OP_SET                                = 0x0
+	FUTEX_OP_XOR                                = 0x4
+	FUTEX_OWNER_DIED                            = 0x40000000
+	FUTEX_PRIVATE_FLAG                          = 0x80
+	FUTEX_REQUEUE                               = 0x3
+	FUTEX_REQUEUE_PRIVATE                       = 0x83
+	FUTEX_TID_MASK                              = 0x3fffffff
+	FUTEX_TRYLOCK_PI                            = 0x8
+	FUTEX_TRYLOCK_PI_PRIVATE                    = 0x88
+	FUTEX_UNLOCK_PI                             = 0x7
+	FUTEX_UNLOCK_PI_PRIVATE                     = 0x87
+	FUTEX_WAIT                                  = 0x0
+	FUTEX_WAITERS                               = 0x80000000
+	FUTEX_WAITV_MAX                             = 0x80
+	FUTEX_WAIT_BITSET                           = 0x9
+	FUTEX_WAIT_BITSET_PRIVATE                   = 0x89
+	FUTEX_WAIT_PRIVATE                          = 0x80
+	FUTEX_WAIT_REQUEUE_PI                       = 0xb
+	FUTEX_WAIT_REQUEUE_PI_PRIVATE               = 0x8b
+	FUTEX_WAKE                                  = 0x1
+	FUTEX_WAKE_BITSET                           = 0xa
+	FUTEX_WAKE_BITSET_PRIVATE                   = 0x8a
+	FUTEX_WAKE_OP                               = 0x5
+	FUTEX_WAKE_OP_PRIVATE                       = 0x85
+	FUTEX_WAKE_PRIVATE                          = 0x81
 	F_ADD_SEALS                                 = 0x409
 	F_DUPFD                                     = 0x0
 	F_DUPFD_CLOEXEC                             = 0x406
@@ -1461,6 +1528,7 @@ const (
 	IGNCR                                       = 0x80
 	IGNPAR                                      = 0x4
 	IMAXBEL                                     = 0x2000
+	INET_DIAG_NOCOOKIE                          = 0xffffffff
 	INLCR                                       = 0x40
 	INPCK                                       = 0x10
 	IN_ACCESS                                   = 0x1
@@ -1500,6 +1568,58 @@ const (
 	IN_OPEN                                     = 0x20
 	IN_Q_OVERFLOW                               = 0x4000
 	IN_UNMOUNT                                  = 0x2000
+	IORING_CQE_BUFFER_SHIFT                     = 0x10
+	IORING_CQE_F_BUFFER                         = 0x1
+	IORING_CQE_F_MORE                           = 0x2
+	IORING_CQE_F_NOTIF                          = 0x8
+	IORING_CQE_F_SOCK_NONEMPTY                  = 0x4
+	IORING_CQ_EVENTFD_DISABLED                  = 0x1
+	IORING_ENTER_EXT_ARG                        = 0x8
+	IORING_ENTER_GETEVENTS                      = 0x1
+	IORING_ENTER_REGISTERED_RING                = 0x10
+	IORING_ENTER_SQ_WAIT                        = 0x4
+	IORING_ENTER_SQ_WAKEUP                      = 0x2
+	IORING_FEAT_CQE_SKIP                        = 0x800
+	IORING_FEAT_CUR_PERSONALITY                 = 0x10
+	IORING_FEAT_EXT_ARG                         = 0x100
+	IORING_FEAT_FAST_POLL                       = 0x20
+	IORING_FEAT_LINKED_FILE                     = 0x1000
+	IORING_FEAT_NATIVE_WORKERS                  = 0x200
+	IORING_FEAT_NODROP                          = 0x2
+	IORING_FEAT_POLL_32BITS                     = 0x40
+	IORING_FEAT_RSRC_TAGS                       = 0x400
+	IORING_FEAT_RW_CUR_POS                      = 0x8
+	IORING_FEAT_SINGLE_MMAP                     = 0x1
+	IORING_FEAT_SQPOLL_NONFIXED                 = 0x80
+	IORING_FEAT_SUBMIT_STABLE                   = 0x4
+	IORING_FSYNC_DATASYNC                       = 0x1
+	IORING_OFF_CQ_RING                          = 0x8000000
+	IORING_OFF_SQES                             = 0x10000000
+	IORING_OFF_SQ_RING                          = 0x0
+	IORING_SETUP_ATTACH_WQ                      = 0x20
+	IORING_SETUP_CLAMP                          = 0x10
+	IORING_SETUP_COOP_TASKRUN                   = 0x100
+	IORING_SETUP_CQE32                          = 0x800
+	IORING_SETUP_CQSIZE                         = 0x8
+	IORING_SETUP_DEFER_TASKRUN                  = 0x2000
+	IORING_SETUP_IOPOLL                         = 0x1
+	IORING_SETUP_R_DISABLED                     = 0x40
+	IORING_SETUP_SINGLE_ISSUER                  = 0x1000
+	IORING_SETUP_SQE128                         = 0x400
+	IORING_SETUP_SQPOLL                         = 0x2
+	IORING_SETUP_SQ_AFF                         = 0x4
+	IORING_SETUP_SUBMIT_ALL                     = 0x80
+	IORING_SETUP_TASKRUN_FLAG                   = 0x200
+	IORING_SQ_CQ_OVERFLOW                       = 0x2
+	IORING_SQ_NEED_WAKEUP                       = 0x1
+	IORING_SQ_TASKRUN                           = 0x4
+	IOSQE_ASYNC                                 = 0x10
+	IOSQE_BUFFER_SELECT                         = 0x20
+	IOSQE_CQE_SKIP_SUCCESS                      = 0x40
+	IOSQE_FIXED_FILE                            = 0x1
+	IOSQE_IO_DRAIN                              = 0x2
+	IOSQE_IO_HARDLINK                           = 0x8
+	IOSQE_IO_LINK                               = 0x4
 	IPPROTO_AH                                  = 0x33
 	IPPROTO_BEETPH                              = 0x5e
 	IPPROTO_COMP                                = 0x6c
@@ -1761,6 +1881,7 @@ const (
 	KEY_SPEC_USER_KEYRING                       = -0x4
 	KEY_SPEC_USER_SESSION_KEYRING               = -0x5
 	LANDLOCK_ACCESS_FS_EXECUTE                  = 0x1
+	LANDLOCK_ACCESS_FS_IOCTL_DEV                = 0x8000
 	LANDLOCK_ACCESS_FS_MAKE_BLOCK               = 0x800
 	LANDLOCK_ACCESS_FS_MAKE_CHAR                = 0x40
 	LANDLOCK_ACCESS_FS_MAKE_DIR                 = 0x80
@@ -1775,7 +1896,11 @@ const (
 	LANDLOCK_ACCESS_FS_REMOVE_FILE              = 0x20
 	LANDLOCK_ACCESS_FS_TRUNCATE                 = 0x4000
 	LANDLOCK_ACCESS_FS_WRITE_FILE               = 0x2
+	LANDLOCK_ACCESS_NET_BIND_TCP                = 0x1
+	LANDLOCK_ACCESS_NET_CONNECT_TCP             = 0x2
 	LANDLOCK_CREATE_RULESET_VERSION             = 0x1
+	LANDLOCK_SCOPE_ABSTRACT_UNIX_SOCKET         = 0x1
+	LANDLOCK_SCOPE_SIGNAL                       = 0x2
 	LINUX_REBOOT_CMD_CAD_OFF                    = 0x0
 	LINUX_REBOOT_CMD_CAD_ON                     = 0x89abcdef
 	LINUX_REBOOT_CMD_HALT                       = 0xcdef0123
@@ -1790,7 +1915,10 @@ const (
 	LOCK_NB                                     = 0x4
 	LOCK_SH                                     = 0x1
 	LOCK_UN                                     = 0x8
+	LOOP_CHANGE_FD                              = 0x4c06
 	LOOP_CLR_FD                                 = 0x4c01
+	LOOP_CONFIGURE                              = 0x4c0a
+	LOOP_CONFIGURE_SETTABLE_FLAGS               = 0x1d
 	LOOP_CTL_ADD                                = 0x4c80
 	LOOP_CTL_GET_FREE                           = 0x4c82
 	LOOP_CTL_REMOVE                             = 0x4c81
@@ -1898,6 +2026,18 @@ const (
 	MOUNT_ATTR_SIZE_VER0                        = 0x20
 	MOUNT_ATTR_STRICTATIME                      = 0x20
 	MOUNT_ATTR__ATIME                           = 0x70
+	MPOL_F_ADDR                                 = 0x2
+	MPOL_F_MEMS_ALLOWED                         = 0x4
+	MPOL_F_NODE                                 = 0x1
+	MPOL_F_NUMA_BALANCING                       = 0x2000
+	MPOL_F_RELATIVE_NODES                       = 0x4000
+	MPOL_F_STATIC_NODES                         = 0x8000
+	MPOL_MF_MOVE                                = 0x2
+	MPOL_MF_MOVE_ALL                            = 0x4
+	MPOL_MF_STRICT                              = 0x1
+	MREMAP_DONTUNMAP                            = 0x4
+	MREMAP_FIXED                                = 0x2
+	MREMAP_MAYMOVE                              = 0x1
 	MSDOS_SUPER_MAGIC                           = 0x4d44
 	MSG_BATCH                                   = 0x40000
 	MSG_CMSG_CLOEXEC                            = 0x40000000
@@ -2196,6 +2336,12 @@ const (
 	PACKET_RESERVE                              = 0xc
 	PACKET_ROLLOVER_STATS                       = 0x15
 	PACKET_RX_RING                              = 0x5
+	PACKET_SHOW_FANOUT                          = 0x8
+	PACKET_SHOW_FILTER                          = 0x20
+	PACKET_SHOW_INFO                            = 0x1
+	PACKET_SHOW_MCLIST                          = 0x2
+	PACKET_SHOW_MEMINFO                         = 0x10
+	PACKET_SHOW_RING_CFG                        = 0x4
 	PACKET_STATISTICS                           = 0x6
 	PACKET_TIMESTAMP                            = 0x11
 	PACKET_TX_HAS_OFF                           = 0x13
@@ -2213,6 +2359,11 @@ const (
 	PARITY_DEFAULT                              = 0x0
 	PARITY_NONE                                 = 0x1
 	PARMRK                                      = 0x8
+	PDI_AUXDATA                                 = 0x2
+	PDI_LOSS                                    = 0x10
+	PDI_ORIGDEV                                 = 0x4
+	PDI_RUNNING                                 = 0x1
+	PDI_VNETHDR                                 = 0x8
 	PERF_ATTR_SIZE_VER0                         = 0x40
 	PERF_ATTR_SIZE_VER1                         = 0x48
 	PERF_ATTR_SIZE_VER2                         = 0x50
@@ -2324,6 +2475,19 @@ const (
 	PERF_RECORD_MISC_USER                       = 0x2
 	PERF_SAMPLE_BRANCH_PLM_ALL                  = 0x7
 	PERF_SAMPLE_WEIGHT_TYPE                     = 0x1004000
+	PIDFD_GET_INFO                              = 0xc040ff0b
+	PIDFD_INFO_CGROUPID                         = 0x4
+	PIDFD_INFO_CREDS                            = 0x2
+	PIDFD_INFO_EXIT                             = 0x8
+	PIDFD_INFO_PID                              = 0x1
+	PIDFD_INFO_SIZE_VER0                        = 0x40
+	PIDFD_SELF                                  = -0x2710
+	PIDFD_SELF_PROCESS                          = -0x4e20
+	PIDFD_SELF_THREAD                           = -0x2710
+	PIDFD_SELF_THREAD_GROUP                     = -0x4e20
+	PIDFD_SIGNAL_PROCESS_GROUP                  = 0x4
+	PIDFD_SIGNAL_THREAD                         = 0x1
+	PIDFD_SIGNAL_THREAD_GROUP                   = 0x2
 	PIPEFS_MAGIC                                = 0x50495045
 	PPPIOCGNPMODE                               = 0xc008744c
 	PPPIOCNEWUNIT                               = 0xc004743e
@@ -2802,13 +2966,60 @@ const (
 	RWF_SUPPORTED                               = 0x1f
 	RWF_SYNC                                    = 0x4
 	RWF_WRITE_LIFE_NOT_SET                      = 0x0
+	SCHED_ATTR_SIZE_VER0                        = 0x30
+	SCHED_ATTR_SIZE_VER1                        = 0x38
+	SCHED_BATCH                                 = 0x3
+	SCHED_DEADLINE                              = 0x6
+	SCHED_FIFO                                  = 0x1
+	SCHED_FLAG_ALL                              = 0x7f
+	SCHED_FLAG_DL_OVERRUN                       = 0x4
+	SCHED_FLAG_KEEP_ALL                         = 0x18
+	SCHED_FLAG_KEEP_PARAMS                      = 0x10
+	SCHED_FLAG_KEEP_POLICY                      = 0x8
+	SCHED_FLAG_RECLAIM                          = 0x2
+	SCHED_FLAG_RESET_ON_FORK                    = 0x1
+	SCHED_FLAG_UTIL_CLAMP                       = 0x60
+	SCHED_FLAG_UTIL_CLAMP_MAX                   = 0x40
+	SCHED_FLAG_UTIL_CLAMP_MIN                   = 0x20
+	SCHED_IDLE                                  = 0x5
+	SCHED_NORMAL                                = 0x0
+	SCHED_RESET_ON_FORK                         = 0x40000000
+	SCHED_RR                                    = 0x2
 	SCM_CREDENTIALS                             = 0x2
 	SCM_RIGHTS                                  = 0x1
 	SCM_TIMESTAMP                               = 0x1d
 	SC_LOG_FLUSH                                = 0x100000
+	SECCOMP_ADDFD_FLAG_SEND                     = 0x2
+	SECCOMP_ADDFD_FLAG_SETFD                    = 0x1
+	SECCOMP_FILTER_FLAG_LOG                     = 0x2
+	SECCOMP_FILTER_FLAG_NEW_LISTENER            = 0x8
+	SECCOMP_FILTER_FLAG_SPEC_ALLOW              = 0x4
+	SECCOMP_FILTER_FLAG_TSYNC                   = 0x1
+	SECCOMP_FILTER_FLAG_TSYNC_ESRCH             = 0x10
+	SECCOMP_FILTER_FLAG_WAIT_KILLABLE_RECV      = 0x20
+	SECCOMP_GET_ACTION_AVAIL                    = 0x2
+	SECCOMP_GET_NOTIF_SIZES                     = 0x3
+	SECCOMP_IOCTL_NOTIF_RECV                    = 0xc0502100
+	SECCOMP_IOCTL_NOTIF_SEND                    = 0xc0182101
 	SECCOMP_MODE_DISABLED                       = 0x0
 	SECCOMP_MODE_FILTER                         = 0x2
 	SECCOMP_MODE_STRICT                         = 0x1
+	SECCOMP_RET_ACTION                          = 0x7fff0000
+	SECCOMP_RET_ACTION_FULL                     = 0xffff0000
+	SECCOMP_RET_ALLOW                           = 0x7fff0000
+	SECCOMP_RET_DATA                            = 0xffff
+	SECCOMP_RET_ERRNO                           = 0x50000
+	SECCOMP_RET_KILL                            = 0x0
+	SECCOMP_RET_KILL_PROCESS                    = 0x80000000
+	SECCOMP_RET_KILL_THREAD                     = 0x0
+	SECCOMP_RET_LOG                             = 0x7ffc0000
+	SECCOMP_RET_TRACE                           = 0x7ff00000
+	SECCOMP_RET_TRAP                            = 0x30000
+	SECCOMP_RET_USER_NOTIF                      = 0x7fc00000
+	SECCOMP_SET_MODE_FILTER                     = 0x1
+	SECCOMP_SET_MODE_STRICT                     = 0x0
+	SECCOMP_USER_NOTIF_FD_SYNC_WAKE_UP          = 0x1
+	SECCOMP_USER_NOTIF_FLAG_CONTINUE            = 0x1
 	SECRETMEM_MAGIC                             = 0x5345434d
 	SECURITYFS_MAGIC                            = 0x73636673
 	SEEK_CUR                                    = 0x1
@@ -2927,6 +3138,8 @@ const (
 	SOCKFS_MAGIC                                = 0x534f434b
 	SOCK_BUF_LOCK_MASK                          = 0x3
 	SOCK_DCCP                                   = 0x6
+	SOCK_DESTROY                                = 0x15
+	SOCK_DIAG_BY_FAMILY                         = 0x14
 	SOCK_IOC_TYPE                               = 0x89
 	SOCK_PACKET                                 = 0xa
 	SOCK_RAW                                    = 0x3
@@ -3079,6 +3292,7 @@ const (
 	TCOFLUSH                                    = 0x1
 	TCOOFF                                      = 0x0
 	TCOON                                       = 0x1
+	TCPDIAG_GETSOCK                             = 0x12
 	TCPOPT_EOL                                  = 0x0
 	TCPOPT_MAXSEG                               = 0x2
 	TCPOPT_NOP                                  = 0x1
@@ -3251,6 +3465,61 @@ const (
 	TRACEFS_MAGIC                               = 0x74726163
 	TS_COMM_LEN                                 = 0x20
 	UDF_SUPER_MAGIC                             = 0x15013346
+	UDIAG_SHOW_ICONS                            = 0x8
+	UDIAG_SHOW_MEMINFO                          = 0x20
+	UDIAG_SHOW_NAME                             = 0x1
+	UDIAG_SHOW_PEER                             = 0x4
+	UDIAG_SHOW_RQLEN                            = 0x10
+	UDIAG_SHOW_UID                              = 0x40
+	UDIAG_SHOW_VFS                              = 0x2
+	UFFDIO_API                                  = 0xc018aa3f
+	UFFDIO_CONTINUE                             = 0xc020aa07
+	UFFDIO_CONTINUE_MODE_DONTWAKE               = 0x1
+	UFFDIO_CONTINUE_MODE_WP                     = 0x2
+	UFFDIO_COPY                                 = 0xc028aa03
+	UFFDIO_COPY_MODE_DONTWAKE                   = 0x1
+	UFFDIO_COPY_MODE_WP                         = 0x2
+	UFFDIO_MOVE                                 = 0xc028aa05
+	UFFDIO_MOVE_MODE_ALLOW_SRC_HOLES            = 0x2
+	UFFDIO_MOVE_MODE_DONTWAKE                   = 0x1
+	UFFDIO_POISON                               = 0xc020aa08
+	UFFDIO_POISON_MODE_DONTWAKE                 = 0x1
+	UFFDIO_REGISTER                             = 0xc020aa00
+	UFFDIO_REGISTER_MODE_MINOR                  = 0x4
+	UFFDIO_REGISTER_MODE_MISSING                = 0x1
+	UFFDIO_REGISTER_MODE_WP                     = 0x2
+	UFFDIO_WRITEPROTECT                         = 0xc018aa06
+	UFFDIO_WRITEPROTECT_MODE_DONTWAKE           = 0x2
+	UFFDIO_WRITEPROTECT_MODE_WP                 = 0x1
+	UFFDIO_ZEROPAGE                             = 0xc020aa04
+	UFFDIO_ZEROPAGE_MODE_DONTWAKE               = 0x1
+	UFFD_API                                    = 0xaa
+	UFFD_EVENT_FORK                             = 0x13
+	UFFD_EVENT_PAGEFAULT                        = 0x12
+	UFFD_EVENT_REMAP                            = 0x14
+	UFFD_EVENT_REMOVE                           = 0x15
+	UFFD_EVENT_UNMAP                            = 0x16
+	UFFD_FEATURE_EVENT_FORK                     = 0x2
+	UFFD_FEATURE_EVENT_REMAP                    = 0x4
+	UFFD_FEATURE_EVENT_REMOVE                   = 0x8
+	UFFD_FEATURE_EVENT_UNMAP                    = 0x40
+	UFFD_FEATURE_EXACT_ADDRESS                  = 0x800
+	UFFD_FEATURE_MINOR_HUGETLBFS                = 0x200
+	UFFD_FEATURE_MINOR_SHMEM                    = 0x400
+	UFFD_FEATURE_MISSING_HUGETLBFS              = 0x10
+	UFFD_FEATURE_MISSING_SHMEM                  = 0x20
+	UFFD_FEATURE_MOVE                           = 0x10000
+	UFFD_FEATURE_PAGEFAULT_FLAG_WP              = 0x1
+	UFFD_FEATURE_POISON                         = 0x4000
+	UFFD_FEATURE_SIGBUS                         = 0x80
+	UFFD_FEATURE_THREAD_ID                      = 0x100
+	UFFD_FEATURE_WP_ASYNC                       = 0x8000
+	UFFD_FEATURE_WP_HUGETLBFS_SHMEM             = 0x1000
+	UFFD_FEATURE_WP_UNPOPULATED                 = 0x2000
+	UFFD_PAGEFAULT_FLAG_MINOR                   = 0x4
+	UFFD_PAGEFAULT_FLAG_WP                      = 0x2
+	UFFD_PAGEFAULT_FLAG_WRITE                   = 0x1
+	UFFD_USER_MODE_ONLY                         = 0x1
 	UMOUNT_NOFOLLOW                             = 0x8
 	USBDEVICE_SUPER_MAGIC                       = 0x9fa2
 	UTIME_NOW                                   = 0x3fffffff
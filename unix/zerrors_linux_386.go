@@ -188,6 +188,7 @@ const (
 	PERF_EVENT_IOC_SET_BPF           = 0x40042408
 	PERF_EVENT_IOC_SET_FILTER        = 0x40042406
 	PERF_EVENT_IOC_SET_OUTPUT        = 0x2405
+	PIDFD_THREAD                     = 0x80
 	PPPIOCATTACH                     = 0x4004743d
 	PPPIOCATTCHAN                    = 0x40047438
 	PPPIOCBRIDGECHAN                 = 0x40047435
@@ -273,6 +274,9 @@ const (
 	SCM_TIMESTAMPNS                  = 0x23
 	SCM_TXTIME                       = 0x3d
 	SCM_WIFI_STATUS                  = 0x29
+	SECCOMP_IOCTL_NOTIF_ADDFD        = 0x40182103
+	SECCOMP_IOCTL_NOTIF_ID_VALID     = 0x40082102
+	SECCOMP_IOCTL_NOTIF_SET_FLAGS    = 0x40082104
 	SFD_CLOEXEC                      = 0x80000
 	SFD_NONBLOCK                     = 0x800
 	SIOCATMARK                       = 0x8905
@@ -490,6 +494,9 @@ const (
 	UBI_IOCVOLCRBLK                  = 0x40804f07
 	UBI_IOCVOLRMBLK                  = 0x4f08
 	UBI_IOCVOLUP                     = 0x40084f00
+	UFFDIO_UNREGISTER                = 0x8010aa01
+	UFFDIO_WAKE                      = 0x8010aa02
+	USERFAULTFD_IOC_NEW              = 0xaa00
 	VDISCARD                         = 0xd
 	VEOF                             = 0x4
 	VEOL                             = 0xb
@@ -186,6 +186,7 @@ const (
 	PERF_EVENT_IOC_SET_BPF           = 0x80042408
 	PERF_EVENT_IOC_SET_FILTER        = 0x80082406
 	PERF_EVENT_IOC_SET_OUTPUT        = 0x20002405
+	PIDFD_THREAD                     = 0x400
 	PPPIOCATTACH                     = 0x8004743d
 	PPPIOCATTCHAN                    = 0x80047438
 	PPPIOCBRIDGECHAN                 = 0x80047435
@@ -273,6 +274,9 @@ const (
 	SCM_TIMESTAMPNS                  = 0x23
 	SCM_TXTIME                       = 0x3d
 	SCM_WIFI_STATUS                  = 0x29
+	SECCOMP_IOCTL_NOTIF_ADDFD        = 0x80182103
+	SECCOMP_IOCTL_NOTIF_ID_VALID     = 0x80082102
+	SECCOMP_IOCTL_NOTIF_SET_FLAGS    = 0x80082104
 	SFD_CLOEXEC                      = 0x80000
 	SFD_NONBLOCK                     = 0x80
 	SIOCATMARK                       = 0x40047307
@@ -492,6 +496,9 @@ const (
 	UBI_IOCVOLCRBLK                  = 0x80804f07
 	UBI_IOCVOLRMBLK                  = 0x20004f08
 	UBI_IOCVOLUP                     = 0x80084f00
+	UFFDIO_UNREGISTER                = 0x4010aa01
+	UFFDIO_WAKE                      = 0x4010aa02
+	USERFAULTFD_IOC_NEW              = 0x2000aa00
 	VDISCARD                         = 0xd
 	VEOF                             = 0x10
 	VEOL                             = 0x11
@@ -635,6 +635,7 @@ const (
 	RTA_IP_PROTO       = 0x1b
 	RTA_SPORT          = 0x1c
 	RTA_DPORT          = 0x1d
+	RTA_NH_ID          = 0x1e
 	RTN_UNSPEC         = 0x0
 	RTN_UNICAST        = 0x1
 	RTN_LOCAL          = 0x2
@@ -833,6 +834,15 @@ const (
 	FSPICK_EMPTY_PATH       = 0x8
 
 	FSMOUNT_CLOEXEC = 0x1
+
+	FSCONFIG_SET_FLAG        = 0x0
+	FSCONFIG_SET_STRING      = 0x1
+	FSCONFIG_SET_BINARY      = 0x2
+	FSCONFIG_SET_PATH        = 0x3
+	FSCONFIG_SET_PATH_EMPTY  = 0x4
+	FSCONFIG_SET_FD          = 0x5
+	FSCONFIG_CMD_CREATE      = 0x6
+	FSCONFIG_CMD_RECONFIGURE = 0x7
 )
 
 type OpenHow struct {
@@ -843,6 +853,41 @@ type OpenHow struct {
 
 const SizeofOpenHow = 0x18
 
+type CloneArgs struct {
+	Flags        uint64
+	Pidfd        uint64
+	Child_tid    uint64
+	Parent_tid   uint64
+	Exit_signal  uint64
+	Stack        uint64
+	Stack_size   uint64
+	Tls          uint64
+	Set_tid      uint64
+	Set_tid_size uint64
+	Cgroup       uint64
+}
+
+const SizeofCloneArgs = 0x58
+
+type SchedAttr struct {
+	Size     uint32
+	Policy   uint32
+	Flags    uint64
+	Nice     int32
+	Priority uint32
+	Runtime  uint64
+	Deadline uint64
+	Period   uint64
+	Util_min uint32
+	Util_max uint32
+}
+
+const SizeofSchedAttr = 0x38
+
+type SchedParam struct {
+	Priority int32
+}
+
 const (
 	RESOLVE_BENEATH       = 0x8
 	RESOLVE_IN_ROOT       = 0x10
@@ -1658,6 +1703,13 @@ const (
 	IFLA_MACVLAN_MACADDR                       = 0x4
 	IFLA_MACVLAN_MACADDR_DATA                  = 0x5
 	IFLA_MACVLAN_MACADDR_COUNT                 = 0x6
+	MACVLAN_MODE_PRIVATE                       = 0x1
+	MACVLAN_MODE_VEPA                          = 0x2
+	MACVLAN_MODE_BRIDGE                        = 0x4
+	MACVLAN_MODE_PASSTHRU                      = 0x8
+	MACVLAN_MODE_SOURCE                        = 0x10
+	VETH_INFO_UNSPEC                           = 0x0
+	VETH_INFO_PEER                             = 0x1
 	IFLA_VRF_UNSPEC                            = 0x0
 	IFLA_VRF_TABLE                             = 0x1
 	IFLA_VRF_PORT_UNSPEC                       = 0x0
@@ -2926,6 +2978,13 @@ type LoopInfo64 struct {
 	Init             [2]uint64
 }
 
+type LoopConfig struct {
+	Fd         uint32
+	Block_size uint32
+	Info       LoopInfo64
+	_          [8]uint64
+}
+
 type TIPCSocketAddr struct {
 	Ref  uint32
 	Node uint32
@@ -3360,6 +3419,232 @@ const (
 	NHA_ENCAP      = 0x8
 	NHA_GROUPS     = 0x9
 	NHA_MASTER     = 0xa
+	NHA_FDB        = 0xb
+)
+
+const (
+	SizeofNhmsg      = 0x8
+	SizeofNexthopGrp = 0x8
+)
+
+const (
+	NEXTHOP_GRP_TYPE_MPATH = 0x0
+	NEXTHOP_GRP_TYPE_RES   = 0x1
+)
+
+type SockDiagReq struct {
+	Family   uint8
+	Protocol uint8
+}
+
+type InetDiagSockID struct {
+	Sport  uint16
+	Dport  uint16
+	Src    [4]uint32
+	Dst    [4]uint32
+	If     uint32
+	Cookie [2]uint32
+}
+
+type InetDiagReqV2 struct {
+	Sdiag_family   uint8
+	Sdiag_protocol uint8
+	Idiag_ext      uint8
+	Pad            uint8
+	Idiag_states   uint32
+	Id             InetDiagSockID
+}
+
+type InetDiagMsg struct {
+	Family  uint8
+	State   uint8
+	Timer   uint8
+	Retrans uint8
+	Id      InetDiagSockID
+	Expires uint32
+	Rqueue  uint32
+	Wqueue  uint32
+	Uid     uint32
+	Inode   uint32
+}
+
+type InetDiagMeminfo struct {
+	Rmem uint32
+	Wmem uint32
+	Fmem uint32
+	Tmem uint32
+}
+
+type TCPVegasInfo struct {
+	Enabled uint32
+	Rttcnt  uint32
+	Rtt     uint32
+	Minrtt  uint32
+}
+
+type TCPDctcpInfo struct {
+	Enabled  uint16
+	Ce_state uint16
+	Alpha    uint32
+	Ab_ecn   uint32
+	Ab_tot   uint32
+}
+
+type TCPBBRInfo struct {
+	Bw_lo       uint32
+	Bw_hi       uint32
+	Min_rtt     uint32
+	Pacing_gain uint32
+	Cwnd_gain   uint32
+}
+
+type UnixDiagReq struct {
+	Sdiag_family   uint8
+	Sdiag_protocol uint8
+	Pad            uint16
+	Udiag_states   uint32
+	Udiag_ino      uint32
+	Udiag_show     uint32
+	Udiag_cookie   [2]uint32
+}
+
+type UnixDiagMsg struct {
+	Family uint8
+	Type   uint8
+	State  uint8
+	Pad    uint8
+	Ino    uint32
+	Cookie [2]uint32
+}
+
+type UnixDiagVfs struct {
+	Ino uint32
+	Dev uint32
+}
+
+type UnixDiagRQlen struct {
+	Rqueue uint32
+	Wqueue uint32
+}
+
+type PacketDiagReq struct {
+	Sdiag_family   uint8
+	Sdiag_protocol uint8
+	Pad            uint16
+	Pdiag_ino      uint32
+	Pdiag_show     uint32
+	Pdiag_cookie   [2]uint32
+}
+
+type PacketDiagMsg struct {
+	Family uint8
+	Type   uint8
+	Num    uint16
+	Ino    uint32
+	Cookie [2]uint32
+}
+
+type PacketDiagInfo struct {
+	Index       uint32
+	Version     uint32
+	Reserve     uint32
+	Copy_thresh uint32
+	Tstamp      uint32
+	Flags       uint32
+}
+
+type PacketDiagMclist struct {
+	Index uint32
+	Count uint32
+	Type  uint16
+	Alen  uint16
+	Addr  [32]uint8
+}
+
+type PacketDiagRing struct {
+	Block_size  uint32
+	Block_nr    uint32
+	Frame_size  uint32
+	Frame_nr    uint32
+	Retire_tmo  uint32
+	Sizeof_priv uint32
+	Features    uint32
+}
+
+const (
+	SizeofSockDiagReq      = 0x2
+	SizeofInetDiagSockID   = 0x30
+	SizeofInetDiagReqV2    = 0x38
+	SizeofInetDiagMsg      = 0x48
+	SizeofInetDiagMeminfo  = 0x10
+	SizeofTCPVegasInfo     = 0x10
+	SizeofTCPDctcpInfo     = 0x10
+	SizeofTCPBBRInfo       = 0x14
+	SizeofUnixDiagReq      = 0x18
+	SizeofUnixDiagMsg      = 0x10
+	SizeofUnixDiagVfs      = 0x8
+	SizeofUnixDiagRQlen    = 0x8
+	SizeofPacketDiagReq    = 0x14
+	SizeofPacketDiagMsg    = 0x10
+	SizeofPacketDiagInfo   = 0x18
+	SizeofPacketDiagMclist = 0x2c
+	SizeofPacketDiagRing   = 0x1c
+)
+
+const (
+	INET_DIAG_REQ_NONE            = 0x0
+	INET_DIAG_REQ_BYTECODE        = 0x1
+	INET_DIAG_REQ_SK_BPF_STORAGES = 0x2
+	INET_DIAG_REQ_PROTOCOL        = 0x3
+	INET_DIAG_NONE                = 0x0
+	INET_DIAG_MEMINFO             = 0x1
+	INET_DIAG_INFO                = 0x2
+	INET_DIAG_VEGASINFO           = 0x3
+	INET_DIAG_CONG                = 0x4
+	INET_DIAG_TOS                 = 0x5
+	INET_DIAG_TCLASS              = 0x6
+	INET_DIAG_SKMEMINFO           = 0x7
+	INET_DIAG_SHUTDOWN            = 0x8
+	INET_DIAG_DCTCPINFO           = 0x9
+	INET_DIAG_PROTOCOL            = 0xa
+	INET_DIAG_SKV6ONLY            = 0xb
+	INET_DIAG_LOCALS              = 0xc
+	INET_DIAG_PEERS               = 0xd
+	INET_DIAG_PAD                 = 0xe
+	INET_DIAG_MARK                = 0xf
+	INET_DIAG_BBRINFO             = 0x10
+	INET_DIAG_CLASS_ID            = 0x11
+	INET_DIAG_MD5SIG              = 0x12
+	INET_DIAG_ULP_INFO            = 0x13
+	INET_DIAG_SK_BPF_STORAGES     = 0x14
+	INET_DIAG_CGROUP_ID           = 0x15
+	INET_DIAG_SOCKOPT             = 0x16
+	SK_MEMINFO_RMEM_ALLOC         = 0x0
+	SK_MEMINFO_RCVBUF             = 0x1
+	SK_MEMINFO_WMEM_ALLOC         = 0x2
+	SK_MEMINFO_SNDBUF             = 0x3
+	SK_MEMINFO_FWD_ALLOC          = 0x4
+	SK_MEMINFO_WMEM_QUEUED        = 0x5
+	SK_MEMINFO_OPTMEM             = 0x6
+	SK_MEMINFO_BACKLOG            = 0x7
+	SK_MEMINFO_DROPS              = 0x8
+	SK_MEMINFO_VARS               = 0x9
+	UNIX_DIAG_NAME                = 0x0
+	UNIX_DIAG_VFS                 = 0x1
+	UNIX_DIAG_PEER                = 0x2
+	UNIX_DIAG_ICONS               = 0x3
+	UNIX_DIAG_RQLEN               = 0x4
+	UNIX_DIAG_MEMINFO             = 0x5
+	UNIX_DIAG_SHUTDOWN            = 0x6
+	UNIX_DIAG_UID                 = 0x7
+	PACKET_DIAG_INFO              = 0x0
+	PACKET_DIAG_MCLIST            = 0x1
+	PACKET_DIAG_RX_RING           = 0x2
+	PACKET_DIAG_TX_RING           = 0x3
+	PACKET_DIAG_FANOUT            = 0x4
+	PACKET_DIAG_UID               = 0x5
+	PACKET_DIAG_MEMINFO           = 0x6
+	PACKET_DIAG_FILTER            = 0x7
 )
 
 const (
@@ -3918,6 +4203,148 @@ type EthtoolDrvinfo struct {
 	Regdump_len  uint32
 }
 
+type EthtoolLinkSettings struct {
+	Cmd                    uint32
+	Speed                  uint32
+	Duplex                 uint8
+	Port                   uint8
+	Phy_address            uint8
+	Autoneg                uint8
+	Mdio_support           uint8
+	Eth_tp_mdix            uint8
+	Eth_tp_mdix_ctrl       uint8
+	Link_mode_masks_nwords int8
+	Transceiver            uint8
+	Master_slave_cfg       uint8
+	Master_slave_state     uint8
+	Rate_matching          uint8
+	Reserved               [7]uint32
+}
+
+type EthtoolRingparam struct {
+	Cmd                  uint32
+	Rx_max_pending       uint32
+	Rx_mini_max_pending  uint32
+	Rx_jumbo_max_pending uint32
+	Tx_max_pending       uint32
+	Rx_pending           uint32
+	Rx_mini_pending      uint32
+	Rx_jumbo_pending     uint32
+	Tx_pending           uint32
+}
+
+type EthtoolChannels struct {
+	Cmd            uint32
+	Max_rx         uint32
+	Max_tx         uint32
+	Max_other      uint32
+	Max_combined   uint32
+	Rx_count       uint32
+	Tx_count       uint32
+	Other_count    uint32
+	Combined_count uint32
+}
+
+type EthtoolCoalesce struct {
+	Cmd                          uint32
+	Rx_coalesce_usecs            uint32
+	Rx_max_coalesced_frames      uint32
+	Rx_coalesce_usecs_irq        uint32
+	Rx_max_coalesced_frames_irq  uint32
+	Tx_coalesce_usecs            uint32
+	Tx_max_coalesced_frames      uint32
+	Tx_coalesce_usecs_irq        uint32
+	Tx_max_coalesced_frames_irq  uint32
+	Stats_block_coalesce_usecs   uint32
+	Use_adaptive_rx_coalesce     uint32
+	Use_adaptive_tx_coalesce     uint32
+	Pkt_rate_low                 uint32
+	Rx_coalesce_usecs_low        uint32
+	Rx_max_coalesced_frames_low  uint32
+	Tx_coalesce_usecs_low        uint32
+	Tx_max_coalesced_frames_low  uint32
+	Pkt_rate_high                uint32
+	Rx_coalesce_usecs_high       uint32
+	Rx_max_coalesced_frames_high uint32
+	Tx_coalesce_usecs_high       uint32
+	Tx_max_coalesced_frames_high uint32
+	Rate_sample_interval         uint32
+}
+
+type EthtoolSsetInfo struct {
+	Cmd       uint32
+	Reserved  uint32
+	Sset_mask uint64
+}
+
+type EthtoolGstrings struct {
+	Cmd        uint32
+	String_set uint32
+	Len        uint32
+}
+
+type EthtoolStats struct {
+	Cmd     uint32
+	N_stats uint32
+}
+
+type EthtoolGfeatures struct {
+	Cmd  uint32
+	Size uint32
+}
+
+type EthtoolSfeatures struct {
+	Cmd  uint32
+	Size uint32
+}
+
+type EthtoolGetFeaturesBlock struct {
+	Available     uint32
+	Requested     uint32
+	Active        uint32
+	Never_changed uint32
+}
+
+type EthtoolSetFeaturesBlock struct {
+	Valid     uint32
+	Requested uint32
+}
+
+const (
+	SizeofEthtoolLinkSettings     = 0x30
+	SizeofEthtoolSsetInfo         = 0x10
+	SizeofEthtoolGstrings         = 0xc
+	SizeofEthtoolStats            = 0x8
+	SizeofEthtoolGfeatures        = 0x8
+	SizeofEthtoolSfeatures        = 0x8
+	SizeofEthtoolGetFeaturesBlock = 0x10
+	SizeofEthtoolSetFeaturesBlock = 0x8
+)
+
+const (
+	ETH_SS_TEST             = 0x0
+	ETH_SS_STATS            = 0x1
+	ETH_SS_PRIV_FLAGS       = 0x2
+	ETH_SS_NTUPLE_FILTERS   = 0x3
+	ETH_SS_FEATURES         = 0x4
+	ETH_SS_RSS_HASH_FUNCS   = 0x5
+	ETH_SS_TUNABLES         = 0x6
+	ETH_SS_PHY_STATS        = 0x7
+	ETH_SS_PHY_TUNABLES     = 0x8
+	ETH_SS_LINK_MODES       = 0x9
+	ETH_SS_MSG_CLASSES      = 0xa
+	ETH_SS_WOL_MODES        = 0xb
+	ETH_SS_SOF_TIMESTAMPING = 0xc
+	ETH_SS_TS_TX_TYPES      = 0xd
+	ETH_SS_TS_RX_FILTERS    = 0xe
+	ETH_SS_UDP_TUNNEL_TYPES = 0xf
+	ETH_SS_STATS_STD        = 0x10
+	ETH_SS_STATS_ETH_PHY    = 0x11
+	ETH_SS_STATS_ETH_MAC    = 0x12
+	ETH_SS_STATS_ETH_CTRL   = 0x13
+	ETH_SS_STATS_RMON       = 0x14
+)
+
 type (
 	HIDRawReportDescriptor struct {
 		Size  uint32
@@ -3936,9 +4363,12 @@ const (
 )
 
 const (
-	NLMSGERR_ATTR_MSG    = 0x1
-	NLMSGERR_ATTR_OFFS   = 0x2
-	NLMSGERR_ATTR_COOKIE = 0x3
+	NLMSGERR_ATTR_MSG       = 0x1
+	NLMSGERR_ATTR_OFFS      = 0x2
+	NLMSGERR_ATTR_COOKIE    = 0x3
+	NLMSGERR_ATTR_POLICY    = 0x4
+	NLMSGERR_ATTR_MISS_TYPE = 0x5
+	NLMSGERR_ATTR_MISS_NEST = 0x6
 )
 
 type (
@@ -4097,7 +4527,9 @@ const (
 )
 
 type LandlockRulesetAttr struct {
-	Access_fs uint64
+	Access_fs  uint64
+	Access_net uint64
+	Scoped     uint64
 }
 
 type LandlockPathBeneathAttr struct {
@@ -4105,8 +4537,120 @@ type LandlockPathBeneathAttr struct {
 	Parent_fd      int32
 }
 
+type LandlockNetPortAttr struct {
+	Allowed_access uint64
+	Port           uint64
+}
+
 const (
 	LANDLOCK_RULE_PATH_BENEATH = 0x1
+	LANDLOCK_RULE_NET_PORT     = 0x2
+)
+
+type PidfdInfo struct {
+	Mask      uint64
+	Cgroupid  uint64
+	Pid       uint32
+	Tgid      uint32
+	Ppid      uint32
+	Ruid      uint32
+	Rgid      uint32
+	Euid      uint32
+	Egid      uint32
+	Suid      uint32
+	Sgid      uint32
+	Fsuid     uint32
+	Fsgid     uint32
+	Exit_code int32
+}
+
+const SizeofPidfdInfo = 0x40
+
+type FutexWaitv struct {
+	Val   uint64
+	Uaddr uint64
+	Flags uint32
+	_     uint32
+}
+
+const SizeofFutexWaitv = 0x18
+
+type UffdMsg struct {
+	Event     uint8
+	Reserved1 uint8
+	Reserved2 uint16
+	Reserved3 uint32
+	Arg       [24]byte
+}
+
+type UffdioAPI struct {
+	Api      uint64
+	Features uint64
+	Ioctls   uint64
+}
+
+type UffdioRange struct {
+	Start uint64
+	Len   uint64
+}
+
+type UffdioRegister struct {
+	Range  UffdioRange
+	Mode   uint64
+	Ioctls uint64
+}
+
+type UffdioCopy struct {
+	Dst  uint64
+	Src  uint64
+	Len  uint64
+	Mode uint64
+	Copy int64
+}
+
+type UffdioZeropage struct {
+	Range    UffdioRange
+	Mode     uint64
+	Zeropage int64
+}
+
+type UffdioWriteprotect struct {
+	Range UffdioRange
+	Mode  uint64
+}
+
+type UffdioContinue struct {
+	Range  UffdioRange
+	Mode   uint64
+	Mapped int64
+}
+
+type UffdioPoison struct {
+	Range   UffdioRange
+	Mode    uint64
+	Updated int64
+}
+
+type UffdioMove struct {
+	Dst  uint64
+	Src  uint64
+	Len  uint64
+	Mode uint64
+	Move int64
+}
+
+const (
+	SizeofUffdMsg = 0x20
+)
+
+const (
+	MPOL_DEFAULT             = 0x0
+	MPOL_PREFERRED           = 0x1
+	MPOL_BIND                = 0x2
+	MPOL_INTERLEAVE          = 0x3
+	MPOL_LOCAL               = 0x4
+	MPOL_PREFERRED_MANY      = 0x5
+	MPOL_WEIGHTED_INTERLEAVE = 0x6
 )
 
 const (
@@ -5810,3 +6354,336 @@ const (
 	VIRTIO_NET_HDR_GSO_TCPV6 = 0x4
 	VIRTIO_NET_HDR_GSO_ECN   = 0x80
 )
+
+type IoUringParams struct {
+	Sq_entries     uint32
+	Cq_entries     uint32
+	Flags          uint32
+	Sq_thread_cpu  uint32
+	Sq_thread_idle uint32
+	Features       uint32
+	Wq_fd          uint32
+	Resv           [3]uint32
+	Sq_off         IoSqringOffsets
+	Cq_off         IoCqringOffsets
+}
+
+type IoSqringOffsets struct {
+	Head         uint32
+	Tail         uint32
+	Ring_mask    uint32
+	Ring_entries uint32
+	Flags        uint32
+	Dropped      uint32
+	Array        uint32
+	Resv1        uint32
+	User_addr    uint64
+}
+
+type IoCqringOffsets struct {
+	Head         uint32
+	Tail         uint32
+	Ring_mask    uint32
+	Ring_entries uint32
+	Overflow     uint32
+	Cqes         uint32
+	Flags        uint32
+	Resv1        uint32
+	User_addr    uint64
+}
+
+type IoUringSqe struct {
+	Opcode       uint8
+	Flags        uint8
+	Ioprio       uint16
+	Fd           int32
+	Off          uint64
+	Addr         uint64
+	Len          uint32
+	Op_flags     uint32
+	User_data    uint64
+	Buf_index    uint16
+	Personality  uint16
+	Splice_fd_in int32
+	Addr3        uint64
+	_            [1]uint64
+}
+
+type IoUringCqe struct {
+	User_data uint64
+	Res       int32
+	Flags     uint32
+}
+
+const (
+	SizeofIoUringParams = 0x78
+	SizeofIoUringSqe    = 0x40
+	SizeofIoUringCqe    = 0x10
+)
+
+const (
+	IORING_OP_NOP             = 0x0
+	IORING_OP_READV           = 0x1
+	IORING_OP_WRITEV          = 0x2
+	IORING_OP_FSYNC           = 0x3
+	IORING_OP_READ_FIXED      = 0x4
+	IORING_OP_WRITE_FIXED     = 0x5
+	IORING_OP_POLL_ADD        = 0x6
+	IORING_OP_POLL_REMOVE     = 0x7
+	IORING_OP_SYNC_FILE_RANGE = 0x8
+	IORING_OP_SENDMSG         = 0x9
+	IORING_OP_RECVMSG         = 0xa
+	IORING_OP_TIMEOUT         = 0xb
+	IORING_OP_TIMEOUT_REMOVE  = 0xc
+	IORING_OP_ACCEPT          = 0xd
+	IORING_OP_ASYNC_CANCEL    = 0xe
+	IORING_OP_LINK_TIMEOUT    = 0xf
+	IORING_OP_CONNECT         = 0x10
+	IORING_OP_FALLOCATE       = 0x11
+	IORING_OP_OPENAT          = 0x12
+	IORING_OP_CLOSE           = 0x13
+	IORING_OP_FILES_UPDATE    = 0x14
+	IORING_OP_STATX           = 0x15
+	IORING_OP_READ            = 0x16
+	IORING_OP_WRITE           = 0x17
+	IORING_OP_FADVISE         = 0x18
+	IORING_OP_MADVISE         = 0x19
+	IORING_OP_SEND            = 0x1a
+	IORING_OP_RECV            = 0x1b
+	IORING_OP_OPENAT2         = 0x1c
+	IORING_OP_EPOLL_CTL       = 0x1d
+	IORING_OP_SPLICE          = 0x1e
+	IORING_OP_PROVIDE_BUFFERS = 0x1f
+	IORING_OP_REMOVE_BUFFERS  = 0x20
+	IORING_OP_TEE             = 0x21
+	IORING_OP_SHUTDOWN        = 0x22
+	IORING_OP_RENAMEAT        = 0x23
+	IORING_OP_UNLINKAT        = 0x24
+	IORING_OP_MKDIRAT         = 0x25
+	IORING_OP_SYMLINKAT       = 0x26
+	IORING_OP_LINKAT          = 0x27
+	IORING_OP_MSG_RING        = 0x28
+	IORING_OP_FSETXATTR       = 0x29
+	IORING_OP_SETXATTR        = 0x2a
+	IORING_OP_FGETXATTR       = 0x2b
+	IORING_OP_GETXATTR        = 0x2c
+	IORING_OP_SOCKET          = 0x2d
+	IORING_OP_URING_CMD       = 0x2e
+	IORING_OP_SEND_ZC         = 0x2f
+	IORING_OP_SENDMSG_ZC      = 0x30
+	IORING_OP_LAST            = 0x31
+)
+
+const (
+	IORING_REGISTER_BUFFERS          = 0x0
+	IORING_UNREGISTER_BUFFERS        = 0x1
+	IORING_REGISTER_FILES            = 0x2
+	IORING_UNREGISTER_FILES          = 0x3
+	IORING_REGISTER_EVENTFD          = 0x4
+	IORING_UNREGISTER_EVENTFD        = 0x5
+	IORING_REGISTER_FILES_UPDATE     = 0x6
+	IORING_REGISTER_EVENTFD_ASYNC    = 0x7
+	IORING_REGISTER_PROBE            = 0x8
+	IORING_REGISTER_PERSONALITY      = 0x9
+	IORING_UNREGISTER_PERSONALITY    = 0xa
+	IORING_REGISTER_RESTRICTIONS     = 0xb
+	IORING_REGISTER_ENABLE_RINGS     = 0xc
+	IORING_REGISTER_FILES2           = 0xd
+	IORING_REGISTER_FILES_UPDATE2    = 0xe
+	IORING_REGISTER_BUFFERS2         = 0xf
+	IORING_REGISTER_BUFFERS_UPDATE   = 0x10
+	IORING_REGISTER_IOWQ_AFF         = 0x11
+	IORING_UNREGISTER_IOWQ_AFF       = 0x12
+	IORING_REGISTER_IOWQ_MAX_WORKERS = 0x13
+	IORING_REGISTER_RING_FDS         = 0x14
+	IORING_UNREGISTER_RING_FDS       = 0x15
+	IORING_REGISTER_PBUF_RING        = 0x16
+	IORING_UNREGISTER_PBUF_RING      = 0x17
+	IORING_REGISTER_SYNC_CANCEL      = 0x18
+	IORING_REGISTER_FILE_ALLOC_RANGE = 0x19
+	IORING_REGISTER_LAST             = 0x1a
+)
+
+type SeccompData struct {
+	Nr                  int32
+	Arch                uint32
+	Instruction_pointer uint64
+	Args                [6]uint64
+}
+
+type SeccompNotifSizes struct {
+	Seccomp_notif      uint16
+	Seccomp_notif_resp uint16
+	Seccomp_data       uint16
+}
+
+type SeccompNotif struct {
+	Id    uint64
+	Pid   uint32
+	Flags uint32
+	Data  SeccompData
+}
+
+type SeccompNotifResp struct {
+	Id    uint64
+	Val   int64
+	Error int32
+	Flags uint32
+}
+
+type SeccompNotifAddfd struct {
+	Id          uint64
+	Flags       uint32
+	Srcfd       uint32
+	Newfd       uint32
+	Newfd_flags uint32
+}
+
+const (
+	SizeofSeccompData       = 0x40
+	SizeofSeccompNotif      = 0x50
+	SizeofSeccompNotifResp  = 0x18
+	SizeofSeccompNotifAddfd = 0x18
+)
+
+type BpfInsn struct {
+	Code uint8
+	Regs uint8
+	Off  int16
+	Imm  int32
+}
+
+type BpfMapCreateAttr struct {
+	Map_type                  uint32
+	Key_size                  uint32
+	Value_size                uint32
+	Max_entries               uint32
+	Map_flags                 uint32
+	Inner_map_fd              uint32
+	Numa_node                 uint32
+	Map_name                  [16]uint8
+	Map_ifindex               uint32
+	Btf_fd                    uint32
+	Btf_key_type_id           uint32
+	Btf_value_type_id         uint32
+	Btf_vmlinux_value_type_id uint32
+	Map_extra                 uint64
+}
+
+type BpfMapElemAttr struct {
+	Map_fd uint32
+	_      [4]byte
+	Key    uint64
+	Value  uint64
+	Flags  uint64
+}
+
+type BpfProgLoadAttr struct {
+	Prog_type            uint32
+	Insn_cnt             uint32
+	Insns                uint64
+	License              uint64
+	Log_level            uint32
+	Log_size             uint32
+	Log_buf              uint64
+	Kern_version         uint32
+	Prog_flags           uint32
+	Prog_name            [16]uint8
+	Prog_ifindex         uint32
+	Expected_attach_type uint32
+	Prog_btf_fd          uint32
+	Func_info_rec_size   uint32
+	Func_info            uint64
+	Func_info_cnt        uint32
+	Line_info_rec_size   uint32
+	Line_info            uint64
+	Line_info_cnt        uint32
+	Attach_btf_id        uint32
+	Attach_prog_fd       uint32
+	Core_relo_cnt        uint32
+	Fd_array             uint64
+	Core_relos           uint64
+	Core_relo_rec_size   uint32
+	Log_true_size        uint32
+}
+
+type BpfObjAttr struct {
+	Pathname   uint64
+	Bpf_fd     uint32
+	File_flags uint32
+}
+
+type BpfProgAttachAttr struct {
+	Target_fd      uint32
+	Attach_bpf_fd  uint32
+	Attach_type    uint32
+	Attach_flags   uint32
+	Replace_bpf_fd uint32
+}
+
+type BpfProgTestRunAttr struct {
+	Prog_fd       uint32
+	Retval        uint32
+	Data_size_in  uint32
+	Data_size_out uint32
+	Data_in       uint64
+	Data_out      uint64
+	Repeat        uint32
+	Duration      uint32
+	Ctx_size_in   uint32
+	Ctx_size_out  uint32
+	Ctx_in        uint64
+	Ctx_out       uint64
+	Flags         uint32
+	Cpu           uint32
+	Batch_size    uint32
+	_             [4]byte
+}
+
+type BpfObjGetInfoAttr struct {
+	Bpf_fd   uint32
+	Info_len uint32
+	Info     uint64
+}
+
+type BpfLinkCreateAttr struct {
+	Prog_fd       uint32
+	Target_fd     uint32
+	Attach_type   uint32
+	Flags         uint32
+	Target_btf_id uint32
+	_             [4]byte
+	_             [3]uint64
+}
+
+type BpfMapInfo struct {
+	Type                      uint32
+	Id                        uint32
+	Key_size                  uint32
+	Value_size                uint32
+	Max_entries               uint32
+	Map_flags                 uint32
+	Name                      [16]uint8
+	Ifindex                   uint32
+	Btf_vmlinux_value_type_id uint32
+	Netns_dev                 uint64
+	Netns_ino                 uint64
+	Btf_id                    uint32
+	Btf_key_type_id           uint32
+	Btf_value_type_id         uint32
+	Btf_vmlinux_id            uint32
+	Map_extra                 uint64
+}
+
+const (
+	SizeofBpfInsn            = 0x8
+	SizeofBpfMapCreateAttr   = 0x48
+	SizeofBpfMapElemAttr     = 0x20
+	SizeofBpfProgLoadAttr    = 0x90
+	SizeofBpfObjAttr         = 0x10
+	SizeofBpfProgAttachAttr  = 0x14
+	SizeofBpfProgTestRunAttr = 0x50
+	SizeofBpfObjGetInfoAttr  = 0x10
+	SizeofBpfLinkCreateAttr  = 0x30
+	SizeofBpfMapInfo         = 0x58
+)